@@ -2,11 +2,14 @@
 package asm
 
 import (
+	"bytes"
+	"compress/gzip"
 	"io"
 	"io/ioutil"
 	"log"
 	"time"
 
+	"github.com/llir/ll"
 	"github.com/llir/ll/ast"
 	"github.com/llir/llvm/ir"
 	"github.com/pkg/errors"
@@ -21,43 +24,223 @@ var (
 )
 
 // ParseFile parses the given LLVM IR assembly file into an LLVM IR module.
-func ParseFile(path string) (*ir.Module, error) {
+// Gzip-compressed files (e.g. foo.ll.gz) are transparently decompressed.
+func ParseFile(path string, opts ...ParseOption) (*ir.Module, error) {
 	buf, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return ParseBytes(path, buf)
+	return ParseBytes(path, buf, opts...)
 }
 
 // Parse parses the given LLVM IR assembly file into an LLVM IR module, reading
 // from r. An optional path to the source file may be specified for error
-// reporting.
-func Parse(path string, r io.Reader) (*ir.Module, error) {
+// reporting. Gzip-compressed input is transparently decompressed.
+func Parse(path string, r io.Reader, opts ...ParseOption) (*ir.Module, error) {
 	buf, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return ParseBytes(path, buf)
+	return ParseBytes(path, buf, opts...)
 }
 
 // ParseBytes parses the given LLVM IR assembly file into an LLVM IR module,
 // reading from b. An optional path to the source file may be specified for
 // error reporting.
-func ParseBytes(path string, b []byte) (*ir.Module, error) {
+//
+// Gzip-compressed input is transparently decompressed; gzip compression is
+// detected based on the magic bytes of the input, regardless of file
+// extension.
+func ParseBytes(path string, b []byte, opts ...ParseOption) (*ir.Module, error) {
+	if isGzip(b) {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to decompress %q", path)
+		}
+		if b, err = ioutil.ReadAll(r); err != nil {
+			return nil, errors.Wrapf(err, "unable to decompress %q", path)
+		}
+	}
 	content := string(b)
-	return ParseString(path, content)
+	return ParseString(path, content, opts...)
+}
+
+// isGzip reports whether the given input is gzip-compressed, based on the magic
+// bytes of the gzip file format.
+func isGzip(b []byte) bool {
+	return len(b) >= 2 && b[0] == 0x1F && b[1] == 0x8B
 }
 
 // ParseString parses the given LLVM IR assembly file into an LLVM IR module,
 // reading from content. An optional path to the source file may be specified
 // for error reporting.
-func ParseString(path, content string) (*ir.Module, error) {
+func ParseString(path, content string, opts ...ParseOption) (*ir.Module, error) {
+	cfg := newParseConfig(path, content, opts)
+	return parse(cfg)
+}
+
+// ParseStringPartial parses the given LLVM IR assembly file into an LLVM IR
+// module, reading from content, recovering from errors in the translation of
+// instructions. An optional path to the source file may be specified for error
+// reporting.
+//
+// Instructions which fail to translate are replaced by placeholders, and the
+// translation errors (of type *PositionedError) are collected and returned
+// together with the best-effort module. The placeholder of a value instruction
+// is a freeze instruction of a poison value of the same type and with the same
+// local identifier (e.g. `%x = freeze i32 poison`), which takes the place of
+// the instruction for later uses; non-value instructions are removed. Operands
+// of partially translated instructions which failed to translate are dropped
+// (e.g. incoming values of phi instructions). The best-effort module may be
+// printed and parsed again.
+//
+// Errors other than instruction translation errors (e.g. syntax errors) are
+// not recovered from; the module is nil and the returned errors contain the
+// unrecoverable error.
+func ParseStringPartial(path, content string, opts ...ParseOption) (*ir.Module, []error) {
+	cfg := newParseConfig(path, content, opts)
+	cfg.recover = true
+	m, err := parse(cfg)
+	if err != nil {
+		return nil, append(cfg.errs, err)
+	}
+	return m, cfg.errs
+}
+
+// ParseDeclarations parses the given LLVM IR assembly file into an LLVM IR
+// module, reading from content, without translating the bodies of function
+// definitions. An optional path to the source file may be specified for error
+// reporting.
+//
+// Function definitions are translated into function declarations; i.e. the
+// function headers (signatures, parameters, attributes, etc) are translated but
+// the basic blocks and use-list orders of function bodies are not. As function
+// declarations may only have external or extern_weak linkage, and no comdat or
+// personality function, definitions with other linkages (e.g. internal) are
+// translated into external declarations, and their comdats and personality
+// functions are dropped. The module header, type definitions, global variables,
+// indirect symbols and metadata are translated as by ParseString. Basic blocks
+// referred to by blockaddress constants are left as unresolved placeholders.
+//
+// ParseDeclarations is intended for tools which only need the signatures of
+// functions and types of global variables (e.g. interface or ABI extraction).
+// Note, function bodies are still parsed into the AST, and only their
+// translation is skipped; as parsing accounts for roughly half of the running
+// time of ParseString, ParseDeclarations is about twice as fast on modules with
+// large function bodies.
+func ParseDeclarations(path, content string, opts ...ParseOption) (*ir.Module, error) {
+	cfg := newParseConfig(path, content, opts)
+	cfg.declsOnly = true
+	return parse(cfg)
+}
+
+// parse parses the LLVM IR assembly file of the given parser configuration into
+// an LLVM IR module.
+func parse(cfg *parseConfig) (*ir.Module, error) {
+	path := cfg.path
+	// Constructs not supported by the grammar (e.g. module summary index
+	// entries, and syntax introduced in later versions of LLVM) are substituted
+	// before parsing.
+	content, summaryEntries, err := preLex(cfg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %q into an AST", path)
+	}
 	parseStart := time.Now()
 	tree, err := ast.Parse(path, content)
 	if err != nil {
+		if e, ok := err.(ll.SyntaxError); ok {
+			err = newPositionedError(path, cfg.content, e.Offset, e.Endoffset, e)
+		}
 		return nil, errors.Wrapf(err, "unable to parse %q into an AST", path)
 	}
 	dbg.Println("parsing into AST took:", time.Since(parseStart))
 	root := ast.ToLlvmNode(tree.Root())
-	return translate(root.(*ast.Module))
+	m, err := translate(root.(*ast.Module), cfg)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	m.SummaryEntries = summaryEntries
+	return m, nil
+}
+
+// ParseOption is an option of the parser.
+type ParseOption func(cfg *parseConfig)
+
+// parseConfig is the configuration of the parser.
+type parseConfig struct {
+	// Path to the source file; empty if not present.
+	path string
+	// Source file content.
+	content string
+	// Record the provenance of parsed top-level entities.
+	provenance bool
+	// Recover from errors in the translation of instructions.
+	recover bool
+	// Translate function definitions into function declarations, skipping the
+	// translation of function bodies.
+	declsOnly bool
+	// Instruction flags not supported by the grammar; maps from the byte offset
+	// of the instruction opcode to the flags of the instruction.
+	instFlags map[int][]string
+	// Byte offsets of bfloat types, which are substituted by half types as
+	// bfloat is not supported by the grammar.
+	bfloatTypes map[int]bool
+	// Byte offsets of scalable vector types, the vscale prefix of which is
+	// extracted as it is not supported by the grammar.
+	scalableTypes map[int]bool
+	// Byte offsets of poison constants, which are substituted by undef
+	// constants as poison is not supported by the grammar.
+	poisonConsts map[int]bool
+	// Byte offsets of freeze instructions, which are substituted by fneg
+	// instructions as freeze is not supported by the grammar.
+	freezeInsts map[int]bool
+	// Byte offsets of DIAssignID specialized metadata nodes, which are
+	// substituted by empty metadata tuples as DIAssignID is not supported by
+	// the grammar.
+	assignIDs map[int]bool
+	// Debug records, which are extracted as they are not supported by the
+	// grammar; in order of occurrence.
+	dbgRecords []dbgRecord
+	// Target extension types, which are substituted by token types as target
+	// extension types are not supported by the grammar; maps from the byte
+	// offset of the target extension type to its parameters (without enclosing
+	// parentheses).
+	targetExtTypes map[int]string
+	// Parameter and return attributes without a type operand not supported by
+	// the grammar, which are substituted by inreg attributes; maps from the
+	// byte offset of the attribute to the attribute (e.g. "noundef" or
+	// "nofpclass(nan)").
+	paramAttrs map[int]string
+	// Partitions of global variables and functions, which are extracted as they
+	// are not supported by the grammar; maps from the byte offset of the global
+	// identifier to the partition name.
+	partitions map[int]string
+	// Alignment of functions, which is extracted as it is not supported by the
+	// grammar; maps from the byte offset of the function name to the alignment.
+	funcAligns map[int]uint64
+	// Parameter attributes with a type operand not supported by the grammar;
+	// maps from the byte offset of the parameter attribute to the parameter
+	// attribute.
+	typedParamAttrs map[int]typedParamAttr
+	// Recovered errors; collected if recover is set.
+	errs []error
+}
+
+// newParseConfig returns a new parser configuration for the given source file,
+// with the given parse options applied.
+func newParseConfig(path, content string, opts []ParseOption) *parseConfig {
+	cfg := &parseConfig{path: path, content: content}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithProvenance returns a parse option which records the provenance (source
+// file and byte offset) of parsed function and global variable declarations
+// and definitions in their Provenance field.
+func WithProvenance() ParseOption {
+	return func(cfg *parseConfig) {
+		cfg.provenance = true
+	}
 }
@@ -1,14 +1,23 @@
 package asm
 
 import (
+	"bytes"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/value"
 	"github.com/mewkiz/pkg/diffutil"
 	"github.com/mewkiz/pkg/osutil"
+	"github.com/pkg/errors"
 )
 
 // words specifies whether to colour words in diff output.
@@ -33,6 +42,9 @@ func TestParseFile(t *testing.T) {
 		{path: "testdata/inst_vector.ll"},
 		{path: "testdata/terminator.ll"},
 
+		// Windows exception handling using catchswitch with multiple handlers.
+		{path: "testdata/catchswitch.ll"},
+
 		// DIExpression used in named metdata definition.
 		{path: "testdata/diexpression.ll"},
 
@@ -43,6 +55,70 @@ func TestParseFile(t *testing.T) {
 		// frem constant expression.
 		{path: "testdata/expr_frem.ll"},
 
+		// Linkage of global variables, functions and aliases.
+		{path: "testdata/linkage.ll"},
+
+		// Aliases of constant expressions and alias chains.
+		{path: "testdata/alias.ll"},
+
+		// Convergence control tokens and convergencectrl operand bundles.
+		{path: "testdata/convergence.ll"},
+
+		// ABI-affecting parameter attributes of declarations and call sites.
+		{path: "testdata/param_attrs.ll"},
+
+		// Attribute group definitions shared by functions and call sites.
+		{path: "testdata/attr_groups.ll"},
+
+		// Modules without functions; metadata-only, and globals and metadata.
+		{path: "testdata/metadata_only.ll"},
+		{path: "testdata/globals_metadata.ll"},
+
+		// Calls to the @llvm.vscale.* intrinsics.
+		{path: "testdata/vscale.ll"},
+
+		// Sections and alignment of global variables and functions.
+		{path: "testdata/section.ll"},
+
+		// Global jump table of blockaddress constants.
+		{path: "testdata/blockaddress.ll"},
+
+		// Unnamed global variables, aliases and functions.
+		{path: "testdata/unnamed_globals.ll"},
+
+		// Module-level inline assembly and linker options metadata.
+		{path: "testdata/module_asm.ll"},
+
+		// Profile metadata attachments.
+		{path: "testdata/prof.ll"},
+
+		// Instruction flags introduced in later versions of LLVM.
+		{path: "testdata/flags.ll"},
+
+		// Integer constants wider than 64 bits.
+		{path: "testdata/big_int.ll"},
+
+		// Parallel loop metadata.
+		{path: "testdata/parallel_loop.ll"},
+
+		// Half and bfloat vectors.
+		{path: "testdata/bfloat.ll"},
+
+		// Scalable vector types.
+		{path: "testdata/scalable_vector.ll"},
+
+		// Module summary index (ThinLTO).
+		{path: "testdata/summary.ll"},
+
+		// Debug records.
+		{path: "testdata/dbg_records.ll"},
+
+		// Target extension types.
+		{path: "testdata/target_ext.ll"},
+
+		// Partitions and function alignment.
+		{path: "testdata/partition.ll"},
+
 		// LLVM IR compatibility.
 		{path: "../testdata/llvm/test/Bitcode/compatibility.ll"},
 
@@ -556,3 +632,497 @@ func TestParseFile(t *testing.T) {
 		}
 	}
 }
+
+func TestParseProvenance(t *testing.T) {
+	const src = `@g = global i32 42
+
+define i32 @f() {
+	ret i32 0
+}
+`
+	const path = "foo.ll"
+	m, err := ParseString(path, src, WithProvenance())
+	if err != nil {
+		t.Fatalf("unable to parse %q; %+v", path, err)
+	}
+	golden := []struct {
+		prov *ir.Provenance
+		want string
+	}{
+		{prov: m.Globals[0].Provenance, want: "foo.ll:0"},
+		{prov: m.Funcs[0].Provenance, want: "foo.ll:20"},
+	}
+	for _, g := range golden {
+		if g.prov == nil {
+			t.Errorf("provenance mismatch; expected %q, got nil", g.want)
+			continue
+		}
+		if got := g.prov.String(); got != g.want {
+			t.Errorf("provenance mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+	// Provenance is not recorded by default.
+	m, err = ParseString(path, src)
+	if err != nil {
+		t.Fatalf("unable to parse %q; %+v", path, err)
+	}
+	if m.Funcs[0].Provenance != nil {
+		t.Errorf("unexpected provenance; %v", m.Funcs[0].Provenance)
+	}
+}
+
+func TestPositionedErrorSnippet(t *testing.T) {
+	const src = "@g = global i32 0\n\ndefine i32 @f() {\n\t%x = add i32 1 2\n\tret i32 %x\n}\n"
+	_, err := ParseString("foo.ll", src)
+	if err == nil {
+		t.Fatal("expected syntax error, got nil")
+	}
+	e, ok := errors.Cause(err).(*PositionedError)
+	if !ok {
+		t.Fatalf("error type mismatch; expected *PositionedError, got %T", errors.Cause(err))
+	}
+	if e.Line != 4 || e.Column != 17 {
+		t.Errorf("position mismatch; expected 4:17, got %d:%d", e.Line, e.Column)
+	}
+	const want = "2 | \n3 | define i32 @f() {\n4 | \t%x = add i32 1 2\n  | \t               ^"
+	if got := e.Snippet(src); got != want {
+		t.Errorf("snippet mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestParseStringPartial(t *testing.T) {
+	const src = `define i32 @f(i32 %a) {
+	%x = add i32 %a, %undef1
+	%y = mul i32 %x, 2
+	store i32 %y, i32* %undef2
+	%z = sub i32 %y, %a
+	ret i32 %z
+}
+`
+	m, errs := ParseStringPartial("foo.ll", src)
+	if m == nil {
+		t.Fatalf("expected best-effort module, got nil; %v", errs)
+	}
+	want := []string{
+		`foo.ll:2:2: unable to locate local identifier "%undef1" of "@f"`,
+		`foo.ll:4:2: unable to locate local identifier "%undef2" of "@f"`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("number of errors mismatch; expected %d, got %d; %v", len(want), len(errs), errs)
+	}
+	for i, err := range errs {
+		if _, ok := err.(*PositionedError); !ok {
+			t.Errorf("error type mismatch; expected *PositionedError, got %T", err)
+		}
+		if got := err.Error(); got != want[i] {
+			t.Errorf("error mismatch; expected %q, got %q", want[i], got)
+		}
+	}
+	const wantFunc = `define i32 @f(i32 %a) {
+; <label>:0
+	%x = freeze i32 poison
+	%y = mul i32 %x, 2
+	%z = sub i32 %y, %a
+	ret i32 %z
+}`
+	if got := m.Funcs[0].LLString(); got != wantFunc {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", wantFunc, got)
+	}
+	// Translation errors are not recovered from by default.
+	if _, err := ParseString("foo.ll", src); err == nil {
+		t.Errorf("expected translation error, got nil")
+	}
+}
+
+func TestParseStringPartialOperands(t *testing.T) {
+	// Undefined operands of phi, call and getelementptr instructions.
+	const src = `declare i32 @g(i32, i32)
+
+define i32 @f(i32 %a, i32* %p) {
+entry:
+	br label %loop
+
+loop:
+	%x = phi i32 [ %a, %entry ], [ %undef1, %loop ]
+	%y = call i32 @g(i32 %x, i32 %undef2)
+	%q = getelementptr i32, i32* %p, i32 %undef3
+	%z = add i32 %x, %y
+	store i32 %z, i32* %q
+	br label %loop
+}
+`
+	m, errs := ParseStringPartial("foo.ll", src)
+	if m == nil {
+		t.Fatalf("expected best-effort module, got nil; %v", errs)
+	}
+	if len(errs) != 3 {
+		t.Fatalf("number of errors mismatch; expected 3, got %d; %v", len(errs), errs)
+	}
+	const wantFunc = `define i32 @f(i32 %a, i32* %p) {
+entry:
+	br label %loop
+
+loop:
+	%x = freeze i32 poison
+	%y = freeze i32 poison
+	%q = freeze i32* poison
+	%z = add i32 %x, %y
+	store i32 %z, i32* %q
+	br label %loop
+}`
+	if got := m.Funcs[1].LLString(); got != wantFunc {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", wantFunc, got)
+	}
+	// The best-effort module may be parsed again.
+	if _, err := ParseString("foo.ll", m.String()); err != nil {
+		t.Errorf("unable to parse best-effort module; %v", err)
+	}
+}
+
+func TestParseDeclarations(t *testing.T) {
+	const src = `@g = global i32 42
+@addr = global i8* blockaddress(@f, %exit)
+
+$c = comdat any
+
+declare i32 @h(i8*)
+
+declare i32 @personality(...)
+
+define internal i32 @f(i32 %x, i32 %y) nounwind {
+entry:
+	%z = add i32 %x, %y
+	br label %exit
+exit:
+	ret i32 %z
+}
+
+define linkonce_odr void @c() comdat($c) personality i32 (...)* @personality {
+entry:
+	ret void
+}
+
+define weak void @w() {
+entry:
+	ret void
+}
+`
+	m, err := ParseDeclarations("foo.ll", src)
+	if err != nil {
+		t.Fatalf("unable to parse declarations; %+v", err)
+	}
+	if len(m.Globals) != 2 {
+		t.Fatalf("number of globals mismatch; expected 2, got %d", len(m.Globals))
+	}
+	golden := []struct {
+		f    *ir.Func
+		want string
+	}{
+		{f: m.Funcs[0], want: "declare i32 @h(i8*)"},
+		{f: m.Funcs[1], want: "declare i32 @personality(...)"},
+		// Linkages of definitions, comdats and personality functions are dropped.
+		{f: m.Funcs[2], want: "declare i32 @f(i32 %x, i32 %y) nounwind"},
+		{f: m.Funcs[3], want: "declare void @c()"},
+		{f: m.Funcs[4], want: "declare void @w()"},
+	}
+	for _, g := range golden {
+		if len(g.f.Blocks) != 0 {
+			t.Errorf("unexpected body of %q; got %d basic blocks", g.f.Ident(), len(g.f.Blocks))
+		}
+		if got := g.f.LLString(); got != g.want {
+			t.Errorf("function mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+}
+
+func TestParseFileGzip(t *testing.T) {
+	const path = "testdata/prof.ll"
+	m, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("unable to parse %q; %+v", path, err)
+	}
+	want := m.String()
+	// Write gzip-compressed module to a file without .gz extension, as
+	// compression is detected by magic bytes.
+	buf := &bytes.Buffer{}
+	if _, err := m.WriteToWithOptions(buf, ir.GzipOutput()); err != nil {
+		t.Fatalf("unable to write module; %+v", err)
+	}
+	if !isGzip(buf.Bytes()) {
+		t.Fatalf("output not gzip-compressed; got %q", buf.Bytes()[:2])
+	}
+	dir, err := ioutil.TempDir("", "asm")
+	if err != nil {
+		t.Fatalf("unable to create temporary directory; %+v", err)
+	}
+	defer os.RemoveAll(dir)
+	gzPath := filepath.Join(dir, "prof.ll")
+	if err := ioutil.WriteFile(gzPath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("unable to write %q; %+v", gzPath, err)
+	}
+	m, err = ParseFile(gzPath)
+	if err != nil {
+		t.Fatalf("unable to parse %q; %+v", gzPath, err)
+	}
+	if got := m.String(); got != want {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", want, got)
+	}
+}
+
+func TestWriteInstCounts(t *testing.T) {
+	const src = `declare i32 @g(i32)
+
+define i32 @f(i32 %x) {
+entry:
+	%cond = icmp eq i32 %x, 0
+	br i1 %cond, label %zero, label %nonzero
+
+zero:
+	ret i32 0
+
+nonzero:
+	%y = call i32 @g(i32 %x)
+	%z = add i32 %y, 1
+	ret i32 %z
+}
+`
+	const want = `declare i32 @g(i32)
+
+; 6 instructions
+define i32 @f(i32 %x) {
+entry: ; 2 instructions
+	%cond = icmp eq i32 %x, 0
+	br i1 %cond, label %zero, label %nonzero
+
+zero: ; 1 instruction
+	ret i32 0
+
+nonzero: ; 3 instructions
+	%y = call i32 @g(i32 %x)
+	%z = add i32 %y, 1
+	ret i32 %z
+}
+`
+	m, err := ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	buf := &strings.Builder{}
+	if _, err := m.WriteToWithOptions(buf, ir.AnnotateInstCounts()); err != nil {
+		t.Fatalf("unable to write module; %+v", err)
+	}
+	got := buf.String()
+	if got != want {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", want, got)
+	}
+	// Instruction count comments are ignored when parsing.
+	m, err = ParseString("<stdin>", got)
+	if err != nil {
+		t.Fatalf("unable to parse annotated module; %+v", err)
+	}
+	if got := m.String(); got != src {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", src, got)
+	}
+}
+
+func TestCallSiteFuncAttrs(t *testing.T) {
+	const src = `declare void @f() nounwind
+
+define void @g() {
+; <label>:0
+	call void @f() nobuiltin
+	call void @f()
+	ret void
+}
+`
+	m, err := ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	if got := m.String(); got != src {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", src, got)
+	}
+	f := m.Funcs[0]
+	insts := m.Funcs[1].Blocks[0].Insts
+	if attrs := insts[0].(*ir.InstCall).FuncAttrs; len(attrs) != 1 || attrs[0] != enum.FuncAttrNoBuiltin {
+		t.Errorf("call-site attributes mismatch; expected [nobuiltin], got %v", attrs)
+	}
+	if attrs := insts[1].(*ir.InstCall).FuncAttrs; len(attrs) != 0 {
+		t.Errorf("call-site attributes mismatch; expected none, got %v", attrs)
+	}
+	if attrs := f.FuncAttrs; len(attrs) != 1 || attrs[0] != enum.FuncAttrNoUnwind {
+		t.Errorf("function attributes mismatch; expected [nounwind], got %v", attrs)
+	}
+}
+
+func TestConvergenceToken(t *testing.T) {
+	m, err := ParseFile("testdata/convergence.ll")
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[len(m.Funcs)-1]
+	entry, loop := f.Blocks[0], f.Blocks[1]
+	anchor := entry.Insts[1].(*ir.InstCall)
+	loopToken := loop.Insts[1].(*ir.InstCall)
+	if got := ir.ConvergenceToken(loopToken.OperandBundles); got != anchor {
+		t.Errorf("convergence token mismatch; expected %v, got %v", anchor, got)
+	}
+	call := loop.Insts[2].(*ir.InstCall)
+	if got := ir.ConvergenceToken(call.OperandBundles); got != loopToken {
+		t.Errorf("convergence token mismatch; expected %v, got %v", loopToken, got)
+	}
+	if got := ir.ConvergenceToken(anchor.OperandBundles); got != nil {
+		t.Errorf("unexpected convergence token of anchor; %v", got)
+	}
+}
+
+func TestVScaleMultiple(t *testing.T) {
+	m, err := ParseFile("testdata/vscale.ll")
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[len(m.Funcs)-1]
+	golden := []struct {
+		n  int64
+		ok bool
+	}{
+		{n: 1, ok: true},  // %vscale = call i64 @llvm.vscale.i64()
+		{n: 4, ok: true},  // %elems = mul i64 %vscale, 4
+		{n: 16, ok: true}, // %bytes = shl i64 %elems, 2
+		{n: 1, ok: true},  // %vscale32 = call i32 @llvm.vscale.i32()
+		{n: 0, ok: false}, // %y = mul i64 %x, %vscale
+	}
+	for i, g := range golden {
+		inst := f.Blocks[0].Insts[i]
+		n, ok := ir.VScaleMultiple(inst.(value.Value))
+		if n != g.n || ok != g.ok {
+			t.Errorf("vscale multiple mismatch of %q; expected (%d, %v), got (%d, %v)", inst.LLString(), g.n, g.ok, n, ok)
+		}
+	}
+}
+
+func TestBlockAddressTable(t *testing.T) {
+	m, err := ParseFile("testdata/blockaddress.ll")
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	table := m.Globals[0].Init.(*constant.Array)
+	if len(table.Elems) != 4 {
+		t.Fatalf("jump table length mismatch; expected 4, got %d", len(table.Elems))
+	}
+	for i, elem := range table.Elems {
+		c := elem.(*constant.BlockAddress)
+		if c.Func != f {
+			t.Errorf("blockaddress %d function mismatch; expected %v, got %v", i, f.Ident(), c.Func.Ident())
+		}
+		// The basic blocks of the jump table are the basic blocks of the
+		// function following the entry basic block.
+		if want := f.Blocks[i+1]; c.Block != want {
+			t.Errorf("blockaddress %d basic block mismatch; expected %v, got %v", i, want.Ident(), c.Block.Ident())
+		}
+	}
+}
+
+func TestAttrGroupDefsShared(t *testing.T) {
+	m, err := ParseFile("testdata/attr_groups.ll")
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	if len(m.AttrGroupDefs) != 2 {
+		t.Fatalf("attribute group count mismatch; expected 2, got %d", len(m.AttrGroupDefs))
+	}
+	def0 := m.AttrGroupDefs[0]
+	f, h := m.Funcs[1], m.Funcs[2]
+	call := h.Blocks[0].Insts[0].(*ir.InstCall)
+	for _, attrs := range [][]ir.FuncAttribute{f.FuncAttrs, h.FuncAttrs, call.FuncAttrs} {
+		if len(attrs) != 1 || attrs[0] != def0 {
+			t.Errorf("attribute group mismatch; expected shared %v, got %v", def0, attrs)
+		}
+	}
+	// Attribute groups added to the module are numbered after existing ones.
+	def := m.NewAttrGroupDef(enum.FuncAttrNoReturn)
+	if def.ID != 2 {
+		t.Errorf("attribute group ID mismatch; expected 2, got %d", def.ID)
+	}
+	if got, want := def.LLString(), "attributes #2 = { noreturn }"; got != want {
+		t.Errorf("attribute group mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func BenchmarkParseLargeModule(b *testing.B) {
+	src := largeModule(20, 50, 40)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseString("large.ll", src); err != nil {
+			b.Fatalf("unable to parse module; %+v", err)
+		}
+	}
+}
+
+func BenchmarkParseDeclarations(b *testing.B) {
+	src := largeModule(20, 50, 40)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseDeclarations("large.ll", src); err != nil {
+			b.Fatalf("unable to parse module declarations; %+v", err)
+		}
+	}
+}
+
+// largeModule returns the LLVM IR assembly of a module with nfuncs functions,
+// each with nblocks basic blocks of ninsts instructions; mixing named and
+// unnamed local variables.
+func largeModule(nfuncs, nblocks, ninsts int) string {
+	buf := &strings.Builder{}
+	for i := 0; i < nfuncs; i++ {
+		fmt.Fprintf(buf, "define i32 @f%d(i32 %%x, i32) {\n", i)
+		// Next unnamed local ID; %0 is the unnamed parameter.
+		id := 1
+		prev := "%0"
+		for j := 0; j < nblocks; j++ {
+			fmt.Fprintf(buf, "block_%d:\n", j)
+			for k := 0; k < ninsts; k++ {
+				var cur string
+				if k%2 == 0 {
+					cur = fmt.Sprintf("%%v_%d_%d", j, k)
+				} else {
+					cur = fmt.Sprintf("%%%d", id)
+					id++
+				}
+				fmt.Fprintf(buf, "\t%s = add i32 %s, %%x\n", cur, prev)
+				prev = cur
+			}
+			if j+1 < nblocks {
+				fmt.Fprintf(buf, "\tbr label %%block_%d\n", j+1)
+			} else {
+				fmt.Fprintf(buf, "\tret i32 %s\n", prev)
+			}
+		}
+		buf.WriteString("}\n\n")
+	}
+	return buf.String()
+}
+
+func TestGEPTypeTargetExt(t *testing.T) {
+	// Target extension types are opaque, and may not be indexed into by
+	// getelementptr.
+	const src = `
+define void @f(target("spirv.Event")* %p) {
+	%q = getelementptr target("spirv.Event"), target("spirv.Event")* %p, i64 0, i32 0
+	ret void
+}
+`
+	_, err := ParseString("<stdin>", src)
+	if err == nil {
+		t.Fatalf("expected error for getelementptr into target extension type, got nil")
+	}
+	const want = `unable to index into element of target extension type`
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error mismatch; expected %q, got %q", want, err.Error())
+	}
+}
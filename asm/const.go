@@ -2,6 +2,7 @@ package asm
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/llir/ll/ast"
 	"github.com/llir/llvm/internal/enc"
@@ -37,6 +38,9 @@ func (gen *generator) irConstant(t types.Type, old ast.Constant) (constant.Const
 	case *ast.ZeroInitializerConst:
 		return constant.NewZeroInitializer(t), nil
 	case *ast.UndefConst:
+		if gen.cfg.poisonConsts[old.Offset()] {
+			return constant.NewPoison(t), nil
+		}
 		return constant.NewUndef(t), nil
 	case *ast.BlockAddressConst:
 		return gen.irBlockAddressConst(t, old)
@@ -105,6 +109,11 @@ func (gen *generator) irFloatConst(t types.Type, old *ast.FloatConst) (*constant
 		return nil, errors.Errorf("invalid type of floating-point constant; expected *types.FloatType, got %T", t)
 	}
 	s := old.FloatLit().Text()
+	if typ.Kind == types.FloatKindBFloat && strings.HasPrefix(s, "0xH") {
+		// Substituted bfloat hexadecimal floating-point literal (see
+		// preLexer).
+		s = "0xR" + s[len("0xH"):]
+	}
 	return constant.NewFloatFromString(typ, s)
 }
 
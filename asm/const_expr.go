@@ -518,6 +518,8 @@ func (gen *generator) irOrExpr(t types.Type, old *ast.OrExpr) (*constant.ExprOr,
 		return nil, errors.WithStack(err)
 	}
 	expr := constant.NewOr(x, y)
+	// (optional) Disjoint.
+	expr.Disjoint = gen.hasInstFlag(old.Offset(), "disjoint")
 	if !t.Equal(expr.Typ) {
 		return nil, errors.Errorf("constant expression type mismatch; expected %q, got %q", expr.Typ, t)
 	}
@@ -701,6 +703,8 @@ func (gen *generator) irGetElementPtrExpr(t types.Type, old *ast.GetElementPtrEx
 	expr := constant.NewGetElementPtr(src, indices...)
 	// (optional) In-bounds.
 	_, expr.InBounds = old.InBounds()
+	// (optional) getelementptr flags.
+	expr.Flags = gen.gepFlags(old.Offset())
 	if !elemType.Equal(expr.ElemType) {
 		return nil, errors.Errorf("constant expression element type mismatch; expected %q, got %q", expr.ElemType, elemType)
 	}
@@ -15,11 +15,12 @@ func _() {
 	_ = x[types.FloatKindFP128-3]
 	_ = x[types.FloatKindX86_FP80-4]
 	_ = x[types.FloatKindPPC_FP128-5]
+	_ = x[types.FloatKindBFloat-6]
 }
 
-const _FloatKind_name = "halffloatdoublefp128x86_fp80ppc_fp128"
+const _FloatKind_name = "halffloatdoublefp128x86_fp80ppc_fp128bfloat"
 
-var _FloatKind_index = [...]uint8{0, 4, 9, 15, 20, 28, 37}
+var _FloatKind_index = [...]uint8{0, 4, 9, 15, 20, 28, 37, 43}
 
 func FloatKindFromString(s string) types.FloatKind {
 	if len(s) == 0 {
@@ -9,27 +9,31 @@ func _() {
 	// An "invalid array index" compiler error signifies that the constant values have changed.
 	// Re-run the string2enum command to generate them again.
 	var x [1]struct{}
-	_ = x[enum.ParamAttrByval-0]
-	_ = x[enum.ParamAttrInAlloca-1]
-	_ = x[enum.ParamAttrInReg-2]
-	_ = x[enum.ParamAttrNest-3]
-	_ = x[enum.ParamAttrNoAlias-4]
-	_ = x[enum.ParamAttrNoCapture-5]
-	_ = x[enum.ParamAttrNonNull-6]
-	_ = x[enum.ParamAttrReadNone-7]
-	_ = x[enum.ParamAttrReadOnly-8]
-	_ = x[enum.ParamAttrReturned-9]
-	_ = x[enum.ParamAttrSignExt-10]
-	_ = x[enum.ParamAttrSRet-11]
-	_ = x[enum.ParamAttrSwiftError-12]
-	_ = x[enum.ParamAttrSwiftSelf-13]
-	_ = x[enum.ParamAttrWriteOnly-14]
-	_ = x[enum.ParamAttrZeroExt-15]
+	_ = x[enum.ParamAttrByRef-0]
+	_ = x[enum.ParamAttrByval-1]
+	_ = x[enum.ParamAttrElementType-2]
+	_ = x[enum.ParamAttrInAlloca-3]
+	_ = x[enum.ParamAttrInReg-4]
+	_ = x[enum.ParamAttrNest-5]
+	_ = x[enum.ParamAttrNoAlias-6]
+	_ = x[enum.ParamAttrNoCapture-7]
+	_ = x[enum.ParamAttrNonNull-8]
+	_ = x[enum.ParamAttrNoUndef-9]
+	_ = x[enum.ParamAttrPreallocated-10]
+	_ = x[enum.ParamAttrReadNone-11]
+	_ = x[enum.ParamAttrReadOnly-12]
+	_ = x[enum.ParamAttrReturned-13]
+	_ = x[enum.ParamAttrSignExt-14]
+	_ = x[enum.ParamAttrSRet-15]
+	_ = x[enum.ParamAttrSwiftError-16]
+	_ = x[enum.ParamAttrSwiftSelf-17]
+	_ = x[enum.ParamAttrWriteOnly-18]
+	_ = x[enum.ParamAttrZeroExt-19]
 }
 
-const _ParamAttr_name = "byvalinallocainregnestnoaliasnocapturenonnullreadnonereadonlyreturnedsignextsretswifterrorswiftselfwriteonlyzeroext"
+const _ParamAttr_name = "byrefbyvalelementtypeinallocainregnestnoaliasnocapturenonnullnoundefpreallocatedreadnonereadonlyreturnedsignextsretswifterrorswiftselfwriteonlyzeroext"
 
-var _ParamAttr_index = [...]uint8{0, 5, 13, 18, 22, 29, 38, 45, 53, 61, 69, 76, 80, 90, 99, 108, 115}
+var _ParamAttr_index = [...]uint8{0, 5, 10, 21, 29, 34, 38, 45, 54, 61, 68, 80, 88, 96, 104, 111, 115, 125, 134, 143, 150}
 
 func ParamAttrFromString(s string) enum.ParamAttr {
 	if len(s) == 0 {
@@ -12,13 +12,14 @@ func _() {
 	_ = x[enum.ReturnAttrInReg-0]
 	_ = x[enum.ReturnAttrNoAlias-1]
 	_ = x[enum.ReturnAttrNonNull-2]
-	_ = x[enum.ReturnAttrSignExt-3]
-	_ = x[enum.ReturnAttrZeroExt-4]
+	_ = x[enum.ReturnAttrNoUndef-3]
+	_ = x[enum.ReturnAttrSignExt-4]
+	_ = x[enum.ReturnAttrZeroExt-5]
 }
 
-const _ReturnAttr_name = "inregnoaliasnonnullsignextzeroext"
+const _ReturnAttr_name = "inregnoaliasnonnullnoundefsignextzeroext"
 
-var _ReturnAttr_index = [...]uint8{0, 5, 12, 19, 26, 33}
+var _ReturnAttr_index = [...]uint8{0, 5, 12, 19, 26, 33, 40}
 
 func ReturnAttrFromString(s string) enum.ReturnAttr {
 	if len(s) == 0 {
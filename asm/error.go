@@ -0,0 +1,101 @@
+package asm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PositionedError is an error at a known position of an LLVM IR assembly file.
+type PositionedError struct {
+	// Path to the source file; empty if not present.
+	Path string
+	// Line number (1-based) of the error.
+	Line int
+	// Column number (1-based byte offset within the line) of the error.
+	Column int
+	// Byte offset of the start of the offending token.
+	Offset int
+	// Byte offset of the end of the offending token.
+	EndOffset int
+	// Underlying error.
+	Err error
+}
+
+// newPositionedError returns a new positioned error at the given byte offsets
+// of the source file content.
+func newPositionedError(path, content string, offset, endOffset int, err error) *PositionedError {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	line := 1 + strings.Count(content[:offset], "\n")
+	column := offset - strings.LastIndex(content[:offset], "\n")
+	return &PositionedError{
+		Path:      path,
+		Line:      line,
+		Column:    column,
+		Offset:    offset,
+		EndOffset: endOffset,
+		Err:       err,
+	}
+}
+
+// Error returns the error message of the positioned error, prefixed with its
+// position; e.g. "foo.ll:3:14: syntax error at line 3".
+func (e *PositionedError) Error() string {
+	if len(e.Path) > 0 {
+		return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%d:%d: %v", e.Line, e.Column, e.Err)
+}
+
+// snippetContext specifies the number of source lines preceding the offending
+// line to include in snippets.
+const snippetContext = 2
+
+// Snippet returns a snippet of the given source file content surrounding the
+// position of the error, with a caret under the offending token. Each source
+// line is prefixed by its line number; e.g.
+//
+//    3 | define i32 @f() {
+//    4 | 	%x = add i32 1 2
+//      | 	               ^
+func (e *PositionedError) Snippet(src string) string {
+	lines := strings.Split(src, "\n")
+	if e.Line < 1 || e.Line > len(lines) {
+		return ""
+	}
+	// Width of line number column.
+	width := len(fmt.Sprint(e.Line))
+	buf := &strings.Builder{}
+	start := e.Line - snippetContext
+	if start < 1 {
+		start = 1
+	}
+	for i := start; i <= e.Line; i++ {
+		fmt.Fprintf(buf, "%*d | %s\n", width, i, lines[i-1])
+	}
+	// Caret line; preserve tabs of the offending line to align the caret.
+	line := lines[e.Line-1]
+	col := e.Column - 1
+	if col > len(line) {
+		col = len(line)
+	}
+	if col < 0 {
+		col = 0
+	}
+	prefix := []byte(line[:col])
+	for i, b := range prefix {
+		if b != '\t' {
+			prefix[i] = ' '
+		}
+	}
+	n := e.EndOffset - e.Offset
+	if max := len(line) - col; n > max {
+		n = max
+	}
+	if n < 1 {
+		n = 1
+	}
+	fmt.Fprintf(buf, "%*s | %s%s", width, "", prefix, strings.Repeat("^", n))
+	return buf.String()
+}
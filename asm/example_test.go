@@ -41,10 +41,12 @@ func Example() {
 	//             UnnamedAddr:           0x0,
 	//             ExternallyInitialized: false,
 	//             Section:               "",
+	//             Partition:             "",
 	//             Comdat:                (*ir.ComdatDef)(nil),
 	//             Align:                 0x0,
 	//             FuncAttrs:             nil,
 	//             Metadata:              nil,
+	//             Provenance:            (*ir.Provenance)(nil),
 	//         },
 	//     },
 	//     Funcs: {
@@ -80,6 +82,7 @@ func Example() {
 	//             UnnamedAddr:     0x0,
 	//             FuncAttrs:       nil,
 	//             Section:         "",
+	//             Partition:       "",
 	//             Comdat:          (*ir.ComdatDef)(nil),
 	//             GC:              "",
 	//             Prefix:          nil,
@@ -87,6 +90,7 @@ func Example() {
 	//             Personality:     nil,
 	//             UseListOrders:   nil,
 	//             Metadata:        nil,
+	//             Provenance:      (*ir.Provenance)(nil),
 	//             mu:              sync.Mutex{},
 	//         },
 	//         &ir.Func{
@@ -204,6 +208,7 @@ func Example() {
 	//             UnnamedAddr:     0x0,
 	//             FuncAttrs:       nil,
 	//             Section:         "",
+	//             Partition:       "",
 	//             Comdat:          (*ir.ComdatDef)(nil),
 	//             GC:              "",
 	//             Prefix:          nil,
@@ -211,6 +216,7 @@ func Example() {
 	//             Personality:     nil,
 	//             UseListOrders:   nil,
 	//             Metadata:        nil,
+	//             Provenance:      (*ir.Provenance)(nil),
 	//             mu:              sync.Mutex{},
 	//         },
 	//     },
@@ -227,5 +233,6 @@ func Example() {
 	//     MetadataDefs:    nil,
 	//     UseListOrders:   nil,
 	//     UseListOrderBBs: nil,
+	//     SummaryEntries:  nil,
 	// }
 }
@@ -0,0 +1,143 @@
+package asm
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/pkg/errors"
+)
+
+// instFlags maps from instruction opcode to the instruction flags not supported
+// by the grammar of the parser (e.g. `zext nneg`), as introduced in later
+// versions of LLVM.
+var instFlags = map[string][]string{
+	"getelementptr": {"nusw", "nuw"},
+	"icmp":          {"samesign"},
+	"or":            {"disjoint"},
+	"zext":          {"nneg"},
+}
+
+// instKeywords maps from instruction opcode to the keywords supported by the
+// grammar of the parser which may be interleaved with the flags of instFlags
+// (e.g. `getelementptr inbounds nuw`).
+var instKeywords = map[string][]string{
+	"getelementptr": {"inbounds"},
+}
+
+// instAligns specifies the opcodes of instructions with an alignment not
+// supported by the grammar of the parser (e.g. `cmpxchg ..., align 4`); the
+// alignment is recorded as an "align N" flag of the instruction.
+var instAligns = map[string]bool{
+	"cmpxchg": true,
+}
+
+// alignRegexp matches a trailing alignment of an instruction (e.g. `, align
+// 4`).
+var alignRegexp = regexp.MustCompile(`^,[ \t]*align[ \t]+[0-9]+`)
+
+// hasInstFlag reports whether the instruction with opcode at the given byte
+// offset has the given flag, as extracted before parsing (see preLexer).
+func (gen *generator) hasInstFlag(offset int, flag string) bool {
+	return contains(gen.cfg.instFlags[offset], flag)
+}
+
+// gepFlags returns the getelementptr flags of the getelementptr instruction or
+// constant expression with opcode at the given byte offset, as extracted before
+// parsing (see preLexer).
+func (gen *generator) gepFlags(offset int) enum.GEPFlags {
+	var flags enum.GEPFlags
+	if gen.hasInstFlag(offset, "nusw") {
+		flags = flags.With(enum.GEPFlagNUSW)
+	}
+	if gen.hasInstFlag(offset, "nuw") {
+		flags = flags.With(enum.GEPFlagNUW)
+	}
+	return flags
+}
+
+// instAlign returns the alignment of the instruction with opcode at the given
+// byte offset, as extracted before parsing (see preLexer); or zero if not
+// present.
+func (gen *generator) instAlign(offset int) (ir.Align, error) {
+	for _, flag := range gen.cfg.instFlags[offset] {
+		if !strings.HasPrefix(flag, "align ") {
+			continue
+		}
+		x, err := strconv.ParseUint(flag[len("align "):], 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid alignment %q; %v", flag, err)
+		}
+		return ir.Align(x), nil
+	}
+	return 0, nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// findAlign returns the start and end byte offsets of the trailing alignment
+// (e.g. `, align 4`) of the instruction following the given byte offset of
+// content. The boolean return value indicates success.
+func findAlign(content string, start int) (int, int, bool) {
+	for i := start; i < len(content); {
+		switch content[i] {
+		case '"':
+			i = skipString(content, i)
+		case ';', '\n':
+			// End of instruction.
+			return 0, 0, false
+		case ',':
+			if loc := alignRegexp.FindStringIndex(content[i:]); loc != nil {
+				return i, i + loc[1], true
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	return 0, 0, false
+}
+
+// isWordChar reports whether the given character may be part of a keyword or
+// identifier.
+func isWordChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	case c == '_', c == '.', c == '$', c == '-':
+		return true
+	}
+	return false
+}
+
+// isIdentPrefix reports whether the given character is the prefix of an
+// identifier (e.g. '%' of local identifiers).
+func isIdentPrefix(c byte) bool {
+	switch c {
+	case '%', '@', '!', '#', '^', '$':
+		return true
+	}
+	return false
+}
+
+// skipWord returns the byte offset following the keyword or identifier starting
+// at the given byte offset of content.
+func skipWord(content string, start int) int {
+	i := start
+	for i < len(content) && isWordChar(content[i]) {
+		i++
+	}
+	return i
+}
+
+// contains reports whether the given list of strings contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -13,6 +13,8 @@ import (
 type generator struct {
 	// LLVM IR module being generated.
 	m *ir.Module
+	// Parser configuration.
+	cfg *parseConfig
 	// index of AST top-level entities.
 	old oldIndex
 	// index of IR top-level entities.
@@ -27,9 +29,10 @@ type generator struct {
 
 // newGenerator returns a new generator for translating an LLVM IR module from
 // AST to IR representation.
-func newGenerator() *generator {
+func newGenerator(cfg *parseConfig) *generator {
 	return &generator{
-		m: ir.NewModule(),
+		m:   ir.NewModule(),
+		cfg: cfg,
 		old: oldIndex{
 			typeDefs:          make(map[string]*ast.TypeDef),
 			comdatDefs:        make(map[string]*ast.ComdatDef),
@@ -8,6 +8,7 @@ import (
 	"github.com/llir/llvm/internal/enc"
 	"github.com/llir/llvm/ir"
 	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
 	"github.com/llir/llvm/ir/types"
 	"github.com/pkg/errors"
 )
@@ -31,6 +32,9 @@ func (gen *generator) createGlobalEntities() error {
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		if gen.cfg.provenance {
+			gen.setProvenance(new, old)
+		}
 		gen.new.globals[ident] = new
 	}
 	return nil
@@ -112,6 +116,19 @@ func (gen *generator) newFunc(ident ir.GlobalIdent, hdr ast.FuncHeader) (*ir.Fun
 
 // ### [ Helper functions ] ####################################################
 
+// setProvenance records the provenance of the given AST global declaration or
+// definition, or function declaration or definition in the corresponding IR
+// value.
+func (gen *generator) setProvenance(new constant.Constant, old ast.LlvmNode) {
+	prov := &ir.Provenance{Path: gen.cfg.path, Offset: old.LlvmNode().Offset()}
+	switch new := new.(type) {
+	case *ir.Global:
+		new.Provenance = prov
+	case *ir.Func:
+		new.Provenance = prov
+	}
+}
+
 // irSigFromHeader translates the AST function signature to an equivalent IR
 // function type.
 func (gen *generator) irSigFromHeader(old ast.FuncHeader) (*types.FuncType, error) {
@@ -254,6 +271,8 @@ func (gen *generator) irGlobal(new *ir.Global, old *ast.GlobalDecl) error {
 	if n, ok := old.Section(); ok {
 		new.Section = stringLit(n.Name())
 	}
+	// (optional) Partition name; extracted before parsing (see preLexer).
+	new.Partition = gen.cfg.partitions[old.Name().LlvmNode().Offset()]
 	// (optional) Comdat.
 	if n, ok := old.Comdat(); ok {
 		// When comdat name is omitted, the global name is used as an implicit
@@ -294,6 +313,10 @@ func (gen *generator) irGlobal(new *ir.Global, old *ast.GlobalDecl) error {
 // irAlias translates the AST indirect symbol definition (alias) into an
 // equivalent IR alias definition.
 func (gen *generator) irAlias(new *ir.Alias, old *ast.IndirectSymbolDef) error {
+	// Partition names are only supported by global variables and functions.
+	if _, ok := gen.cfg.partitions[old.Name().LlvmNode().Offset()]; ok {
+		return errors.Errorf("support for partition of alias %q not yet implemented", new.Ident())
+	}
 	// (optional) Linkage.
 	if n, ok := old.Linkage(); ok {
 		new.Linkage = asmenum.LinkageFromString(n.Text())
@@ -336,6 +359,10 @@ func (gen *generator) irAlias(new *ir.Alias, old *ast.IndirectSymbolDef) error {
 // irIFunc translates the AST indirect symbol definition (IFunc) into an
 // equivalent IR indirect function definition.
 func (gen *generator) irIFunc(new *ir.IFunc, old *ast.IndirectSymbolDef) error {
+	// Partition names are only supported by global variables and functions.
+	if _, ok := gen.cfg.partitions[old.Name().LlvmNode().Offset()]; ok {
+		return errors.Errorf("support for partition of ifunc %q not yet implemented", new.Ident())
+	}
 	// (optional) Linkage.
 	if n, ok := old.Linkage(); ok {
 		new.Linkage = asmenum.LinkageFromString(n.Text())
@@ -403,6 +430,17 @@ func (gen *generator) irFuncDef(new *ir.Func, old *ast.FuncDef) error {
 		return errors.WithStack(err)
 	}
 	new.Metadata = md
+	if gen.cfg.declsOnly {
+		// Skip function body. Linkages other than external and extern_weak,
+		// comdats and personality functions are only valid on function
+		// definitions, and are dropped from the translated declaration.
+		if new.Linkage != enum.LinkageExternWeak {
+			new.Linkage = enum.LinkageNone
+		}
+		new.Comdat = nil
+		new.Personality = nil
+		return nil
+	}
 	// Basic blocks.
 	fgen := newFuncGen(gen, new)
 	oldBody := old.Body()
@@ -455,7 +493,10 @@ func (gen *generator) irFuncHeader(new *ir.Func, old ast.FuncHeader) error {
 	if oldReturnAttrs := old.ReturnAttrs(); len(oldReturnAttrs) > 0 {
 		new.ReturnAttrs = make([]ir.ReturnAttribute, len(oldReturnAttrs))
 		for i, oldRetAttr := range oldReturnAttrs {
-			retAttr := irReturnAttribute(oldRetAttr)
+			retAttr, err := gen.irReturnAttribute(oldRetAttr)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 			new.ReturnAttrs[i] = retAttr
 		}
 	}
@@ -480,7 +521,10 @@ func (gen *generator) irFuncHeader(new *ir.Func, old ast.FuncHeader) error {
 			if oldParamAttrs := oldParam.Attrs(); len(oldParamAttrs) > 0 {
 				param.Attrs = make([]ir.ParamAttribute, len(oldParamAttrs))
 				for j, oldParamAttr := range oldParamAttrs {
-					paramAttr := irParamAttribute(oldParamAttr)
+					paramAttr, err := gen.irParamAttribute(oldParamAttr)
+					if err != nil {
+						return errors.WithStack(err)
+					}
 					param.Attrs[j] = paramAttr
 				}
 			}
@@ -500,11 +544,16 @@ func (gen *generator) irFuncHeader(new *ir.Func, old ast.FuncHeader) error {
 			new.FuncAttrs[i] = funcAttr
 		}
 	}
-
+	// (optional) Alignment; extracted before parsing (see preLexer).
+	if align, ok := gen.cfg.funcAligns[old.Name().LlvmNode().Offset()]; ok {
+		new.FuncAttrs = append(new.FuncAttrs, ir.Align(align))
+	}
 	// (optional) Section name.
 	if n, ok := old.Section(); ok {
 		new.Section = stringLit(n.Name())
 	}
+	// (optional) Partition name; extracted before parsing (see preLexer).
+	new.Partition = gen.cfg.partitions[old.Name().LlvmNode().Offset()]
 	// (optional) Comdat.
 	if n, ok := old.Comdat(); ok {
 		// When comdat name is omitted, the function name is used as an implicit
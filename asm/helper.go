@@ -30,8 +30,10 @@ func globalIdent(old ast.GlobalIdent) ir.GlobalIdent {
 		panic(fmt.Errorf("invalid global identifier %q; missing '%s' prefix", ident, prefix))
 	}
 	ident = ident[len(prefix):]
-	if id, err := strconv.ParseInt(ident, 10, 64); err == nil {
-		return ir.GlobalIdent{GlobalID: id}
+	if isID(ident) {
+		if id, err := strconv.ParseInt(ident, 10, 64); err == nil {
+			return ir.GlobalIdent{GlobalID: id}
+		}
 	}
 	// Unquote after trying to parse as ID, since @"42" is recognized as named
 	// and not unnamed.
@@ -50,8 +52,10 @@ func localIdent(old ast.LocalIdent) ir.LocalIdent {
 		panic(fmt.Errorf("invalid local identifier %q; missing '%s' prefix", ident, prefix))
 	}
 	ident = ident[len(prefix):]
-	if id, err := strconv.ParseInt(ident, 10, 64); err == nil {
-		return ir.LocalIdent{LocalID: id}
+	if isID(ident) {
+		if id, err := strconv.ParseInt(ident, 10, 64); err == nil {
+			return ir.LocalIdent{LocalID: id}
+		}
 	}
 	// Unquote after trying to parse as ID, since %"42" is recognized as named
 	// and not unnamed.
@@ -221,7 +225,10 @@ func (fgen *funcGen) irArg(old ast.Arg) (value.Value, error) {
 		if oldAttrs := old.Attrs(); len(oldAttrs) > 0 {
 			attrs := make([]ir.ParamAttribute, len(oldAttrs))
 			for i, oldAttr := range old.Attrs() {
-				attr := irParamAttribute(oldAttr)
+				attr, err := fgen.gen.irParamAttribute(oldAttr)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
 				attrs[i] = attr
 			}
 			return &ir.Arg{Attrs: attrs, Value: x}, nil
@@ -529,26 +536,32 @@ func irOverflowFlags(olds []ast.OverflowFlag) []enum.OverflowFlag {
 
 // irParamAttribute returns the IR parameter attribute corresponding to the given
 // AST parameter attribute.
-func irParamAttribute(old ast.ParamAttribute) ir.ParamAttribute {
+func (gen *generator) irParamAttribute(old ast.ParamAttribute) (ir.ParamAttribute, error) {
 	switch old := old.(type) {
 	case *ast.AttrString:
-		return ir.AttrString(unquote(old.Text()))
+		return ir.AttrString(unquote(old.Text())), nil
 	case *ast.AttrPair:
 		return ir.AttrPair{
 			Key:   unquote(old.Key().Text()),
 			Value: unquote(old.Val().Text()),
-		}
+		}, nil
 	case *ast.Align:
-		return ir.Align(uintLit(old.N()))
+		return ir.Align(uintLit(old.N())), nil
 	case *ast.Dereferenceable:
-		return ir.Dereferenceable{N: uintLit(old.N())}
+		return ir.Dereferenceable{N: uintLit(old.N())}, nil
 	case *ast.DereferenceableOrNull:
 		return ir.Dereferenceable{
 			N:           uintLit(old.N()),
 			DerefOrNull: true,
-		}
+		}, nil
 	case *ast.ParamAttr:
-		return asmenum.ParamAttrFromString(old.Text())
+		if attr, ok := gen.cfg.typedParamAttrs[old.Offset()]; ok {
+			return gen.irTypedParamAttr(attr)
+		}
+		if attr, ok := gen.cfg.paramAttrs[old.Offset()]; ok {
+			return irLexedParamAttr(attr)
+		}
+		return asmenum.ParamAttrFromString(old.Text()), nil
 	default:
 		panic(fmt.Errorf("support for parameter attribute %T not yet implemented", old))
 	}
@@ -556,7 +569,7 @@ func irParamAttribute(old ast.ParamAttribute) ir.ParamAttribute {
 
 // irReturnAttribute returns the IR return attribute corresponding to the given
 // AST return attribute.
-func irReturnAttribute(old ast.ReturnAttribute) ir.ReturnAttribute {
+func (gen *generator) irReturnAttribute(old ast.ReturnAttribute) (ir.ReturnAttribute, error) {
 	switch old := old.(type) {
 	// TODO: add support for AttrString.
 	//case *ast.AttrString:
@@ -568,16 +581,19 @@ func irReturnAttribute(old ast.ReturnAttribute) ir.ReturnAttribute {
 	//		Value: unquote(old.Val().Text()),
 	//	}
 	case *ast.Align:
-		return ir.Align(uintLit(old.N()))
+		return ir.Align(uintLit(old.N())), nil
 	case *ast.Dereferenceable:
-		return ir.Dereferenceable{N: uintLit(old.N())}
+		return ir.Dereferenceable{N: uintLit(old.N())}, nil
 	case *ast.DereferenceableOrNull:
 		return ir.Dereferenceable{
 			N:           uintLit(old.N()),
 			DerefOrNull: true,
-		}
+		}, nil
 	case *ast.ReturnAttr:
-		return asmenum.ReturnAttrFromString(old.Text())
+		if attr, ok := gen.cfg.paramAttrs[old.Offset()]; ok {
+			return irLexedReturnAttr(attr)
+		}
+		return asmenum.ReturnAttrFromString(old.Text()), nil
 	default:
 		panic(fmt.Errorf("support for return attribute %T not yet implemented", old))
 	}
@@ -636,6 +652,21 @@ func unquote(s string) string {
 	return s
 }
 
+// isID reports whether s is a non-empty sequence of decimal digits, as used by
+// unnamed identifiers. Checking before parsing avoids allocating parse errors
+// for named identifiers.
+func isID(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // text returns the text of the given node.
 func text(n ast.LlvmNode) string {
 	if n := n.LlvmNode(); n.IsValid() {
@@ -35,6 +35,13 @@ func (fgen *funcGen) newInst(old ast.Instruction) (ir.Instruction, error) {
 // based on the given AST value instruction.
 func (fgen *funcGen) newValueInst(ident ir.LocalIdent, old ast.ValueInstruction) (ir.Instruction, error) {
 	switch old := old.(type) {
+	// Unary instructions
+	case *ast.FNegInst:
+		if fgen.gen.cfg.freezeInsts[old.Offset()] {
+			// Substituted freeze instruction (see preLexer).
+			return fgen.newFreezeInst(ident, old)
+		}
+		return fgen.newFNegInst(ident, old)
 	// Binary instructions
 	case *ast.AddInst:
 		return fgen.newAddInst(ident, old)
@@ -158,7 +165,10 @@ func (fgen *funcGen) translateInsts(oldBlocks []ast.BasicBlock) error {
 		for j, old := range oldBlock.Insts() {
 			new := block.Insts[j]
 			if err := fgen.irInst(new, old); err != nil {
-				return errors.WithStack(err)
+				if !fgen.gen.cfg.recover {
+					return errors.WithStack(err)
+				}
+				fgen.recoverInst(new, old, err)
 			}
 		}
 	}
@@ -187,6 +197,13 @@ func (fgen *funcGen) irInst(new ir.Instruction, old ast.Instruction) error {
 // instruction.
 func (fgen *funcGen) irValueInst(new ir.Instruction, old ast.ValueInstruction) error {
 	switch old := old.(type) {
+	// Unary instructions
+	case *ast.FNegInst:
+		if fgen.gen.cfg.freezeInsts[old.Offset()] {
+			// Substituted freeze instruction (see preLexer).
+			return fgen.irFreezeInst(new, old)
+		}
+		return fgen.irFNegInst(new, old)
 	// Binary instructions
 	case *ast.AddInst:
 		return fgen.irAddInst(new, old)
@@ -207,6 +207,8 @@ func (fgen *funcGen) irOrInst(new ir.Instruction, old *ast.OrInst) error {
 	if !ok {
 		panic(fmt.Errorf("invalid IR instruction for AST instruction; expected *ir.InstOr, got %T", new))
 	}
+	// (optional) Disjoint.
+	inst.Disjoint = fgen.gen.hasInstFlag(old.Offset(), "disjoint")
 	// X operand.
 	x, err := fgen.irTypeValue(old.X())
 	if err != nil {
@@ -181,6 +181,8 @@ func (fgen *funcGen) irZExtInst(new ir.Instruction, old *ast.ZExtInst) error {
 	if !ok {
 		panic(fmt.Errorf("invalid IR instruction for AST instruction; expected *ir.InstZExt, got %T", new))
 	}
+	// (optional) Non-negative.
+	inst.NNeg = fgen.gen.hasInstFlag(old.Offset(), "nneg")
 	// Value before conversion.
 	from, err := fgen.irTypeValue(old.From())
 	if err != nil {
@@ -0,0 +1,175 @@
+package asm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/llir/ll/ast"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+// dbgRecordKinds specifies the kinds of debug records (e.g. `#dbg_value`),
+// without '#' prefix.
+var dbgRecordKinds = map[string]bool{
+	"dbg_value":   true,
+	"dbg_declare": true,
+	"dbg_assign":  true,
+	"dbg_label":   true,
+}
+
+// dbgRecordArgs maps from debug record kind to the number of arguments of the
+// debug record.
+var dbgRecordArgs = map[string]int{
+	"dbg_value":   4,
+	"dbg_declare": 4,
+	"dbg_assign":  7,
+	"dbg_label":   2,
+}
+
+// dbgRecord is a debug record (e.g. `#dbg_value(...)`), as extracted before
+// parsing (see preLexer).
+type dbgRecord struct {
+	// Byte offset of '#'.
+	offset int
+	// Byte offset following the closing parenthesis.
+	end int
+	// Debug record kind, without '#' prefix (e.g. dbg_value).
+	kind string
+	// Arguments of the debug record, without enclosing parentheses.
+	args string
+}
+
+// === [ Translate AST to IR ] =================================================
+
+// translateDbgRecords translates the debug records of the given function body,
+// as extracted before parsing, to IR; inserting each debug record before the
+// instruction or terminator following it.
+func (fgen *funcGen) translateDbgRecords(old ast.FuncBody) error {
+	cfg := fgen.gen.cfg
+	n := old.LlvmNode()
+	start := sort.Search(len(cfg.dbgRecords), func(i int) bool {
+		return cfg.dbgRecords[i].offset >= n.Offset()
+	})
+	end := sort.Search(len(cfg.dbgRecords), func(i int) bool {
+		return cfg.dbgRecords[i].offset >= n.Endoffset()
+	})
+	records := cfg.dbgRecords[start:end]
+	if len(records) == 0 {
+		return nil
+	}
+	for i, oldBlock := range old.Blocks() {
+		block := fgen.f.Blocks[i]
+		var insts []ir.Instruction
+		for j, oldInst := range oldBlock.Insts() {
+			for ; len(records) > 0 && records[0].offset < oldInst.LlvmNode().Offset(); records = records[1:] {
+				if err := fgen.appendDbgRecord(&insts, records[0]); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			insts = append(insts, block.Insts[j])
+		}
+		// Debug records preceding the terminator.
+		for ; len(records) > 0 && records[0].offset < oldBlock.Term().LlvmNode().Offset(); records = records[1:] {
+			if err := fgen.appendDbgRecord(&insts, records[0]); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		block.Insts = insts
+	}
+	return nil
+}
+
+// appendDbgRecord appends the IR debug record corresponding to the given debug
+// record to insts. Errors are recorded and the debug record skipped if error
+// recovery is enabled.
+func (fgen *funcGen) appendDbgRecord(insts *[]ir.Instruction, old dbgRecord) error {
+	record, err := fgen.irDbgRecord(old)
+	if err != nil {
+		cfg := fgen.gen.cfg
+		e := newPositionedError(cfg.path, cfg.content, old.offset, old.end, err)
+		if !cfg.recover {
+			return e
+		}
+		cfg.errs = append(cfg.errs, e)
+		return nil
+	}
+	*insts = append(*insts, record)
+	return nil
+}
+
+// irDbgRecord returns the IR debug record corresponding to the given debug
+// record.
+func (fgen *funcGen) irDbgRecord(old dbgRecord) (*ir.DbgRecord, error) {
+	args := splitArgs(old.args)
+	if len(args) != dbgRecordArgs[old.kind] {
+		return nil, errors.Errorf("invalid number of arguments of #%s; expected %d, got %d", old.kind, dbgRecordArgs[old.kind], len(args))
+	}
+	// Parse the arguments as metadata arguments of a call instruction, to
+	// translate them in the context of the function. The arguments are
+	// pre-lexed separately, as they may contain constructs not supported by
+	// the grammar (e.g. `poison`).
+	for i, arg := range args {
+		args[i] = "metadata " + arg
+	}
+	cfg := &parseConfig{path: fgen.gen.cfg.path, content: fmt.Sprintf("define void @f() {\n\tcall void @f(%s)\n\tret void\n}", strings.Join(args, ", "))}
+	content, _, err := preLex(cfg)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tree, err := ast.Parse(cfg.path, content)
+	if err != nil {
+		return nil, errors.Errorf("invalid arguments of #%s", old.kind)
+	}
+	root := ast.ToLlvmNode(tree.Root()).(*ast.Module)
+	def := root.TopLevelEntities()[0].(*ast.FuncDef)
+	call := def.Body().Blocks()[0].Insts()[0].(*ast.CallInst)
+	// Translate the arguments using the substitutions of their own content.
+	orig := fgen.gen.cfg
+	fgen.gen.cfg = cfg
+	defer func() { fgen.gen.cfg = orig }()
+	var mds []metadata.Field
+	for _, oldArg := range call.Args().Args() {
+		arg, err := fgen.irArg(oldArg)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		md, ok := arg.(*metadata.Value)
+		if !ok {
+			return nil, errors.Errorf("invalid argument of #%s; expected metadata, got %T", old.kind, arg)
+		}
+		mds = append(mds, md.Value)
+	}
+	loc := mds[len(mds)-1]
+	switch old.kind {
+	case "dbg_value", "dbg_declare":
+		v, ok := mds[0].(value.Value)
+		if !ok {
+			return nil, errors.Errorf("invalid value of #%s; expected value.Value, got %T", old.kind, mds[0])
+		}
+		if old.kind == "dbg_declare" {
+			return ir.NewDbgDeclare(v, mds[1], mds[2], loc), nil
+		}
+		return ir.NewDbgValue(v, mds[1], mds[2], loc), nil
+	case "dbg_assign":
+		v, ok := mds[0].(value.Value)
+		if !ok {
+			return nil, errors.Errorf("invalid value of #%s; expected value.Value, got %T", old.kind, mds[0])
+		}
+		id, ok := mds[3].(*metadata.DIAssignID)
+		if !ok {
+			return nil, errors.Errorf("invalid assignment ID of #%s; expected *metadata.DIAssignID, got %T", old.kind, mds[3])
+		}
+		addr, ok := mds[4].(value.Value)
+		if !ok {
+			return nil, errors.Errorf("invalid address of #%s; expected value.Value, got %T", old.kind, mds[4])
+		}
+		return ir.NewDbgAssign(v, mds[1], mds[2], id, addr, mds[5], loc), nil
+	default:
+		// dbg_label
+		return ir.NewDbgLabel(mds[0], loc), nil
+	}
+}
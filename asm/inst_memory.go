@@ -2,7 +2,7 @@ package asm
 
 import (
 	"fmt"
-	"strconv"
+	"math/big"
 
 	"github.com/llir/ll/ast"
 	asmenum "github.com/llir/llvm/asm/enum"
@@ -44,7 +44,7 @@ func (fgen *funcGen) newCmpXchgInst(ident ir.LocalIdent, old *ast.CmpXchgInst) (
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	typ := types.NewStruct(oldType, types.I8)
+	typ := types.NewStruct(oldType, types.I1)
 	return &ir.InstCmpXchg{LocalIdent: ident, Typ: typ}, nil
 }
 
@@ -272,6 +272,12 @@ func (fgen *funcGen) irCmpXchgInst(new ir.Instruction, old *ast.CmpXchgInst) err
 	if n, ok := old.SyncScope(); ok {
 		inst.SyncScope = stringLit(n.Scope())
 	}
+	// (optional) Alignment.
+	align, err := fgen.gen.instAlign(old.Offset())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	inst.Align = align
 	// (optional) Metadata.
 	md, err := fgen.gen.irMetadataAttachments(old.Metadata())
 	if err != nil {
@@ -350,6 +356,8 @@ func (fgen *funcGen) irGetElementPtrInst(new ir.Instruction, old *ast.GetElement
 	}
 	// (optional) In-bounds.
 	_, inst.InBounds = old.InBounds()
+	// (optional) getelementptr flags.
+	inst.Flags = fgen.gen.gepFlags(old.Offset())
 	// (optional) Metadata.
 	md, err := fgen.gen.irMetadataAttachments(old.Metadata())
 	if err != nil {
@@ -382,12 +390,15 @@ func (gen *generator) gepType(elemType types.Type, indices []ast.TypeValue) (typ
 			e = t.ElemType
 		case *types.ArrayType:
 			e = t.ElemType
+		case *types.TargetExtType:
+			// Target extension types are opaque.
+			return nil, errors.Errorf("unable to index into element of target extension type `%v`; target extension types are opaque", e)
 		case *types.StructType:
 			switch index := index.Val().(type) {
 			case *ast.IntConst:
-				i, err := strconv.ParseInt(index.Text(), 10, 64)
+				i, err := structFieldIndex(t, index.Text())
 				if err != nil {
-					panic(fmt.Errorf("unable to parse integer %q; %v", index.Text(), err))
+					return nil, errors.WithStack(err)
 				}
 				e = t.Fields[i]
 			case *ast.VectorConst:
@@ -398,9 +409,9 @@ func (gen *generator) gepType(elemType types.Type, indices []ast.TypeValue) (typ
 				if !ok {
 					panic(fmt.Errorf("invalid index type for structure element; expected *ast.IntConst, got %T", elem))
 				}
-				i, err := strconv.ParseInt(idx.Text(), 10, 64)
+				i, err := structFieldIndex(t, idx.Text())
 				if err != nil {
-					panic(fmt.Errorf("unable to parse integer %q; %v", idx.Text(), err))
+					return nil, errors.WithStack(err)
 				}
 				// Sanity check. All vector elements must be integers, and must have
 				// the same value.
@@ -409,9 +420,9 @@ func (gen *generator) gepType(elemType types.Type, indices []ast.TypeValue) (typ
 					if !ok {
 						panic(fmt.Errorf("invalid index type for structure element; expected *ast.IntConst, got %T", elem.Val()))
 					}
-					j, err := strconv.ParseInt(idx.Text(), 10, 64)
+					j, err := structFieldIndex(t, idx.Text())
 					if err != nil {
-						panic(fmt.Errorf("unable to parse integer %q; %v", idx.Text(), err))
+						return nil, errors.WithStack(err)
 					}
 					if i != j {
 						return nil, errors.Errorf("struct index mismatch; vector elements %d and %d differ", i, j)
@@ -439,8 +450,25 @@ func (gen *generator) gepType(elemType types.Type, indices []ast.TypeValue) (typ
 			return nil, errors.WithStack(err)
 		}
 		if t, ok := t.(*types.VectorType); ok {
-			return types.NewVector(t.Len, types.NewPointer(e)), nil
+			vt := types.NewVector(t.Len, types.NewPointer(e))
+			vt.Scalable = t.Scalable
+			return vt, nil
 		}
 	}
 	return types.NewPointer(e), nil
 }
+
+// structFieldIndex returns the field index of the given struct type, as
+// specified by the given integer constant. Integer constants of arbitrary width
+// are parsed without overflow, and out of bounds field indices are reported as
+// errors.
+func structFieldIndex(t *types.StructType, text string) (int, error) {
+	x, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return 0, errors.Errorf("unable to parse struct index %q", text)
+	}
+	if x.Sign() < 0 || x.Cmp(big.NewInt(int64(len(t.Fields)))) >= 0 {
+		return 0, errors.Errorf("invalid struct index %v of struct type %v with %d fields", x, t, len(t.Fields))
+	}
+	return int(x.Int64()), nil
+}
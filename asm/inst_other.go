@@ -25,7 +25,9 @@ func (fgen *funcGen) newICmpInst(ident ir.LocalIdent, old *ast.ICmpInst) (*ir.In
 	case *types.IntType, *types.PointerType:
 		typ = types.I1
 	case *types.VectorType:
-		typ = types.NewVector(xType.Len, types.I1)
+		t := types.NewVector(xType.Len, types.I1)
+		t.Scalable = xType.Scalable
+		typ = t
 	default:
 		panic(fmt.Errorf("invalid icmp operand type; expected *types.IntType, *types.PointerType or *types.VectorType, got %T", xType))
 	}
@@ -44,7 +46,9 @@ func (fgen *funcGen) newFCmpInst(ident ir.LocalIdent, old *ast.FCmpInst) (*ir.In
 	case *types.FloatType:
 		typ = types.I1
 	case *types.VectorType:
-		typ = types.NewVector(xType.Len, types.I1)
+		t := types.NewVector(xType.Len, types.I1)
+		t.Scalable = xType.Scalable
+		typ = t
 	default:
 		panic(fmt.Errorf("invalid fcmp operand type; expected *types.FloatType or *types.VectorType, got %T", xType))
 	}
@@ -71,6 +75,17 @@ func (fgen *funcGen) newSelectInst(ident ir.LocalIdent, old *ast.SelectInst) (*i
 	return &ir.InstSelect{LocalIdent: ident, Typ: typ}, nil
 }
 
+// newFreezeInst returns a new IR freeze instruction (without body but with
+// type) based on the given AST fneg instruction substituted for a freeze
+// instruction (see preLexer).
+func (fgen *funcGen) newFreezeInst(ident ir.LocalIdent, old *ast.FNegInst) (*ir.InstFreeze, error) {
+	typ, err := fgen.gen.irType(old.X().Typ())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &ir.InstFreeze{LocalIdent: ident, Typ: typ}, nil
+}
+
 // newCallInst returns a new IR call instruction (without body but with type)
 // based on the given AST call instruction.
 func (fgen *funcGen) newCallInst(ident ir.LocalIdent, old *ast.CallInst) (*ir.InstCall, error) {
@@ -114,6 +129,8 @@ func (fgen *funcGen) irICmpInst(new ir.Instruction, old *ast.ICmpInst) error {
 	if !ok {
 		panic(fmt.Errorf("invalid IR instruction for AST instruction; expected *ir.InstICmp, got %T", new))
 	}
+	// (optional) Same sign.
+	inst.SameSign = fgen.gen.hasInstFlag(old.Offset(), "samesign")
 	// Integer comparison predicate.
 	inst.Pred = asmenum.IPredFromString(old.Pred().Text())
 	// X operand.
@@ -241,6 +258,30 @@ func (fgen *funcGen) irSelectInst(new ir.Instruction, old *ast.SelectInst) error
 	return nil
 }
 
+// --- [ freeze ] --------------------------------------------------------------
+
+// irFreezeInst translates the given AST fneg instruction substituted for a
+// freeze instruction (see preLexer) into an equivalent IR instruction.
+func (fgen *funcGen) irFreezeInst(new ir.Instruction, old *ast.FNegInst) error {
+	inst, ok := new.(*ir.InstFreeze)
+	if !ok {
+		panic(fmt.Errorf("invalid IR instruction for AST instruction; expected *ir.InstFreeze, got %T", new))
+	}
+	// X operand.
+	x, err := fgen.irTypeValue(old.X())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	inst.X = x
+	// (optional) Metadata.
+	md, err := fgen.gen.irMetadataAttachments(old.Metadata())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	inst.Metadata = md
+	return nil
+}
+
 // --- [ call ] ----------------------------------------------------------------
 
 // irCallInst translates the given AST call instruction into an equivalent IR
@@ -298,7 +339,10 @@ func (fgen *funcGen) irCallInst(new ir.Instruction, old *ast.CallInst) error {
 	if oldReturnAttrs := old.ReturnAttrs(); len(oldReturnAttrs) > 0 {
 		inst.ReturnAttrs = make([]ir.ReturnAttribute, len(oldReturnAttrs))
 		for i, oldRetAttr := range oldReturnAttrs {
-			retAttr := irReturnAttribute(oldRetAttr)
+			retAttr, err := fgen.gen.irReturnAttribute(oldRetAttr)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 			inst.ReturnAttrs[i] = retAttr
 		}
 	}
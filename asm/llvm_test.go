@@ -0,0 +1,116 @@
+//+build llvm
+
+// Differential round-trip tests against the LLVM tools; run with
+//
+//    go test -tags llvm
+//
+// The llvm-as and llvm-dis tools are located through the LLVM_AS and LLVM_DIS
+// environment variables if set, and through PATH otherwise.
+
+package asm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mewkiz/pkg/diffutil"
+	"github.com/pkg/errors"
+)
+
+func TestLLVMRoundTrip(t *testing.T) {
+	llvmAs, err := llvmTool("LLVM_AS", "llvm-as")
+	if err != nil {
+		t.Skip(err)
+	}
+	llvmDis, err := llvmTool("LLVM_DIS", "llvm-dis")
+	if err != nil {
+		t.Skip(err)
+	}
+	paths, err := filepath.Glob("testdata/differential/*.ll")
+	if err != nil {
+		t.Fatalf("unable to locate corpus; %v", err)
+	}
+	for _, path := range paths {
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Errorf("unable to read %q; %v", path, err)
+			continue
+		}
+		m, err := ParseFile(path)
+		if err != nil {
+			t.Errorf("unable to parse %q; %+v", path, err)
+			continue
+		}
+		want, err := llvmCanonical(llvmAs, llvmDis, buf)
+		if err != nil {
+			t.Errorf("unable to canonicalize original %q; %v", path, err)
+			continue
+		}
+		got, err := llvmCanonical(llvmAs, llvmDis, []byte(m.String()))
+		if err != nil {
+			t.Errorf("unable to canonicalize re-emitted %q; %v", path, err)
+			continue
+		}
+		if want != got {
+			if err := diffutil.Diff(want, got, words, filepath.Base(path)); err != nil {
+				panic(err)
+			}
+			t.Errorf("canonical module mismatch %q; expected `%s`, got `%s`", path, want, got)
+		}
+	}
+}
+
+// llvmTool returns the path to the given LLVM tool, as specified by the
+// environment variable env if set, or as located through PATH otherwise.
+func llvmTool(env, name string) (string, error) {
+	if path := os.Getenv(env); len(path) > 0 {
+		return path, nil
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", errors.Errorf("unable to locate %s; set %s or add it to PATH", name, env)
+	}
+	return path, nil
+}
+
+// llvmCanonical returns the canonical form of the given LLVM IR assembly, as
+// produced by assembling it with llvm-as and disassembling it with llvm-dis.
+func llvmCanonical(llvmAs, llvmDis string, src []byte) (string, error) {
+	bc, err := run(llvmAs, src, "-o", "-", "-")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	ll, err := run(llvmDis, bc, "-o", "-", "-")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	// Normalize output; drop module identifier and trailing whitespace.
+	var lines []string
+	for _, line := range strings.Split(string(ll), "\n") {
+		if strings.HasPrefix(line, "; ModuleID") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+// run runs the given command with input on standard input, and returns its
+// standard output.
+func run(name string, input []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = bytes.NewReader(input)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "%s failed; %s", filepath.Base(name), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -22,6 +22,7 @@ package asm
 import (
 	"github.com/llir/ll/ast"
 	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/types"
 	"github.com/llir/llvm/ir/value"
 	"github.com/pkg/errors"
@@ -34,16 +35,18 @@ type funcGen struct {
 	// LLVM IR function being generated.
 	f *ir.Func
 	// locals maps from local identifier (without '%' prefix) to corresponding IR
-	// value.
+	// value; allocated by indexLocals, sized by the number of local variables.
 	locals map[ir.LocalIdent]value.Value
+	// Instructions which failed to translate; recorded by recoverInst if error
+	// recovery is enabled.
+	failed []ir.Instruction
 }
 
 // newFuncGen returns a new generator for the given IR function.
 func newFuncGen(gen *generator, f *ir.Func) *funcGen {
 	return &funcGen{
-		gen:    gen,
-		f:      f,
-		locals: make(map[ir.LocalIdent]value.Value),
+		gen: gen,
+		f:   f,
 	}
 }
 
@@ -61,7 +64,16 @@ func (fgen *funcGen) resolveLocals(old ast.FuncBody) error {
 		return errors.WithStack(err)
 	}
 	// Translate AST terminators to IR.
-	return fgen.translateTerms(oldBlocks)
+	if err := fgen.translateTerms(oldBlocks); err != nil {
+		return errors.WithStack(err)
+	}
+	// Translate debug records to IR.
+	if err := fgen.translateDbgRecords(old); err != nil {
+		return errors.WithStack(err)
+	}
+	// Replace instructions which failed to translate by placeholders.
+	fgen.replaceFailedInsts()
+	return nil
 }
 
 // === [ Create and index IR ] =================================================
@@ -133,8 +145,16 @@ func (fgen *funcGen) newLocals(oldBlocks []ast.BasicBlock) error {
 
 // indexLocals indexes local identifiers of the given function.
 func (fgen *funcGen) indexLocals() error {
-	// Index function parameters.
+	// Preallocate the map of local variables, to prevent rehashing as the map
+	// grows. The number of instructions and terminators is an upper bound, as
+	// non-value instructions are not indexed.
 	f := fgen.f
+	n := len(f.Params) + 2*len(f.Blocks)
+	for _, block := range f.Blocks {
+		n += len(block.Insts)
+	}
+	fgen.locals = make(map[ir.LocalIdent]value.Value, n)
+	// Index function parameters.
 	for _, param := range f.Params {
 		if err := fgen.addLocal(param.LocalIdent, param); err != nil {
 			return errors.WithStack(err)
@@ -171,6 +191,93 @@ func (fgen *funcGen) indexLocals() error {
 	return nil
 }
 
+// === [ Error recovery ] ======================================================
+
+// recoverInst records the given error of translating the AST instruction into
+// the given IR instruction, for later replacement of the IR instruction by a
+// placeholder.
+func (fgen *funcGen) recoverInst(new ir.Instruction, old ast.Instruction, err error) {
+	cfg := fgen.gen.cfg
+	n := old.LlvmNode()
+	e := newPositionedError(cfg.path, cfg.content, n.Offset(), n.Endoffset(), err)
+	cfg.errs = append(cfg.errs, e)
+	cleanFailedInst(new)
+	fgen.failed = append(fgen.failed, new)
+}
+
+// cleanFailedInst removes the operands which failed to translate from the given
+// partially translated IR instruction (e.g. nil incoming values of phi
+// instructions), so that the operands of the instruction may be traversed
+// during the replacement of failed instructions.
+func cleanFailedInst(inst ir.Instruction) {
+	switch inst := inst.(type) {
+	case *ir.InstPhi:
+		incs := inst.Incs[:0]
+		for _, inc := range inst.Incs {
+			if inc != nil {
+				incs = append(incs, inc)
+			}
+		}
+		inst.Incs = incs
+	case *ir.InstCall:
+		inst.Args = withoutNilValues(inst.Args)
+		bundles := inst.OperandBundles[:0]
+		for _, bundle := range inst.OperandBundles {
+			if bundle != nil {
+				bundle.Inputs = withoutNilValues(bundle.Inputs)
+				bundles = append(bundles, bundle)
+			}
+		}
+		inst.OperandBundles = bundles
+	case *ir.InstGetElementPtr:
+		inst.Indices = withoutNilValues(inst.Indices)
+	case *ir.InstLandingPad:
+		clauses := inst.Clauses[:0]
+		for _, clause := range inst.Clauses {
+			if clause != nil {
+				clauses = append(clauses, clause)
+			}
+		}
+		inst.Clauses = clauses
+	}
+}
+
+// replaceFailedInsts replaces the instructions which failed to translate by
+// placeholders. Value instructions are replaced by a freeze instruction of a
+// poison value of the same type and with the same local identifier, to retain
+// the numbering of unnamed local variables; non-value instructions are
+// removed.
+func (fgen *funcGen) replaceFailedInsts() {
+	if len(fgen.failed) == 0 {
+		return
+	}
+	failed := make(map[ir.Instruction]bool)
+	for _, inst := range fgen.failed {
+		failed[inst] = true
+	}
+	f := fgen.f
+	for _, block := range f.Blocks {
+		insts := block.Insts[:0]
+		for _, inst := range block.Insts {
+			if !failed[inst] {
+				insts = append(insts, inst)
+				continue
+			}
+			v, ok := inst.(local)
+			if !ok || v.Type().Equal(types.Void) {
+				// Remove non-value instructions.
+				continue
+			}
+			placeholder := ir.NewFreeze(constant.NewPoison(v.Type()))
+			placeholder.LocalIdent = localIdentOfValue(v)
+			f.ReplaceAllUsesWith(v, placeholder)
+			insts = append(insts, placeholder)
+		}
+		block.Insts = insts
+	}
+	fgen.failed = nil
+}
+
 // ### [ Helper functions ] ####################################################
 
 // addLocal adds the local variable with the given local identifier to the map
@@ -183,6 +290,17 @@ func (fgen *funcGen) addLocal(ident ir.LocalIdent, v value.Value) error {
 	return nil
 }
 
+// withoutNilValues returns the given values with nil values removed.
+func withoutNilValues(vs []value.Value) []value.Value {
+	res := vs[:0]
+	for _, v := range vs {
+		if v != nil {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
 // localIdentOfValue returns the local identifier of the given local variable.
 func localIdentOfValue(v local) ir.LocalIdent {
 	if v.IsUnnamed() {
@@ -48,6 +48,9 @@ func (gen *generator) irMetadataAttachment(old ast.MetadataAttachment) (*metadat
 func (gen *generator) irMDNode(old ast.MDNode) (metadata.MDNode, error) {
 	switch old := old.(type) {
 	case *ast.MDTuple:
+		if gen.cfg.assignIDs[old.LlvmNode().Offset()] {
+			return metadata.NewDIAssignID(), nil
+		}
 		return gen.irMDTuple(nil, old)
 	case *ast.MetadataID:
 		return gen.metadataDefFromID(*old)
@@ -123,6 +126,9 @@ func (gen *generator) irMetadata(old ast.Metadata) (metadata.Metadata, error) {
 		s := stringLit(old.Val())
 		return &metadata.String{Value: s}, nil
 	case *ast.MDTuple:
+		if gen.cfg.assignIDs[old.LlvmNode().Offset()] {
+			return metadata.NewDIAssignID(), nil
+		}
 		return gen.irMDTuple(nil, old)
 	case *ast.MetadataID:
 		return gen.metadataDefFromID(*old)
@@ -172,6 +172,14 @@ func (gen *generator) createMetadataDefs() {
 	// 4a4. Index metadata IDs and create scaffolding IR metadata definitions
 	//      (without bodies).
 	for id, md := range gen.old.metadataDefs {
+		if gen.cfg.assignIDs[md.MDNode().LlvmNode().Offset()] {
+			// DIAssignID specialized metadata node, substituted by an empty
+			// metadata tuple (see preLexer).
+			new := &metadata.DIAssignID{}
+			new.SetID(id)
+			gen.new.metadataDefs[id] = new
+			continue
+		}
 		new := newMetadataDef(id, md)
 		gen.new.metadataDefs[id] = new
 	}
@@ -386,6 +394,10 @@ func (gen *generator) irMetadataDef(new metadata.Definition, old *ast.MetadataDe
 	}
 	switch oldNode := old.MDNode().(type) {
 	case *ast.MDTuple:
+		if _, ok := new.(*metadata.DIAssignID); ok {
+			// DIAssignID specialized metadata nodes have no fields.
+			break
+		}
 		_, err := gen.irMDTuple(new, oldNode)
 		if err != nil {
 			return errors.WithStack(err)
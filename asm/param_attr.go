@@ -0,0 +1,128 @@
+package asm
+
+import (
+	"strings"
+
+	asmenum "github.com/llir/llvm/asm/enum"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/pkg/errors"
+)
+
+// typedParamAttrs specifies the parameter attributes with a type operand (e.g.
+// `byval(%T)`), which are not supported by the grammar of the parser.
+var typedParamAttrs = map[string]bool{
+	"byref":        true,
+	"byval":        true,
+	"elementtype":  true,
+	"preallocated": true,
+	"sret":         true,
+}
+
+// typedParamAttr is a parameter attribute with a type operand, as extracted
+// before parsing (see preLexer).
+type typedParamAttr struct {
+	// Parameter attribute kind; e.g. "byval".
+	kind string
+	// LLVM syntax representation of the type operand; e.g. "%T".
+	typ string
+}
+
+// irTypedParamAttr returns the IR parameter attribute with a type operand of the
+// given parameter attribute, as extracted before parsing (see preLexer).
+func (gen *generator) irTypedParamAttr(attr typedParamAttr) (ir.ParamAttribute, error) {
+	ts, err := gen.irTypes([]string{attr.typ})
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid type operand %q of parameter attribute %q", attr.typ, attr.kind)
+	}
+	return ir.NewTypedParamAttr(asmenum.ParamAttrFromString(attr.kind), ts[0]), nil
+}
+
+// fpClasses maps from the name of a floating-point class, as used by the
+// nofpclass attribute, to the corresponding floating-point class.
+var fpClasses = map[string]enum.FPClass{
+	"none":  0,
+	"all":   enum.FPClassAll,
+	"nan":   enum.FPClassNaN,
+	"snan":  enum.FPClassSNaN,
+	"qnan":  enum.FPClassQNaN,
+	"inf":   enum.FPClassInf,
+	"ninf":  enum.FPClassNegInf,
+	"pinf":  enum.FPClassPosInf,
+	"zero":  enum.FPClassZero,
+	"nzero": enum.FPClassNegZero,
+	"pzero": enum.FPClassPosZero,
+	"sub":   enum.FPClassSubnormal,
+	"nsub":  enum.FPClassNegSubnormal,
+	"psub":  enum.FPClassPosSubnormal,
+	"norm":  enum.FPClassNormal,
+	"nnorm": enum.FPClassNegNormal,
+	"pnorm": enum.FPClassPosNormal,
+}
+
+// irLexedParamAttr returns the IR parameter attribute corresponding to the
+// given parameter attribute (e.g. `noundef`), as extracted before parsing (see
+// preLexer).
+func irLexedParamAttr(attr string) (ir.ParamAttribute, error) {
+	if attr == "noundef" {
+		return enum.ParamAttrNoUndef, nil
+	}
+	return irNoFPClass(attr)
+}
+
+// irLexedReturnAttr returns the IR return attribute corresponding to the given
+// return attribute (e.g. `noundef`), as extracted before parsing (see
+// preLexer).
+func irLexedReturnAttr(attr string) (ir.ReturnAttribute, error) {
+	if attr == "noundef" {
+		return enum.ReturnAttrNoUndef, nil
+	}
+	return irNoFPClass(attr)
+}
+
+// irNoFPClass returns the IR nofpclass attribute corresponding to the given
+// nofpclass attribute (e.g. `nofpclass(nan inf)`).
+func irNoFPClass(attr string) (ir.NoFPClass, error) {
+	// 'nofpclass' '(' Classes=FPClass+ ')'
+	s := strings.TrimSuffix(strings.TrimPrefix(attr, "nofpclass("), ")")
+	names := strings.Fields(s)
+	if len(names) == 0 {
+		return ir.NoFPClass{}, errors.Errorf("invalid attribute %q; missing floating-point class", attr)
+	}
+	var classes enum.FPClass
+	for _, name := range names {
+		class, ok := fpClasses[name]
+		if !ok {
+			return ir.NoFPClass{}, errors.Errorf("invalid floating-point class %q of attribute %q", name, attr)
+		}
+		classes |= class
+	}
+	return ir.NoFPClass{Classes: classes}, nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// findCloseParen returns the byte offset following the closing parenthesis
+// matching the opening parenthesis at the given byte offset of content. The
+// boolean return value indicates success.
+func findCloseParen(content string, start int) (int, bool) {
+	depth := 0
+	for i := start; i < len(content); {
+		switch content[i] {
+		case '"':
+			i = skipString(content, i)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		case '\n':
+			return 0, false
+		}
+		i++
+	}
+	return 0, false
+}
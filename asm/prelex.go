@@ -0,0 +1,442 @@
+package asm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/llir/llvm/ir"
+)
+
+// preLexer substitutes the constructs of an LLVM IR assembly file which are not
+// supported by the grammar of the parser, as introduced in later versions of
+// LLVM, in a single pass over the input. Substitutions retain the byte offsets
+// and line numbers of the input; the byte offsets of substituted constructs are
+// recorded in the parser configuration, for their translation into the
+// corresponding IR constructs.
+//
+// Substituted constructs include module summary index entries (e.g. `^0 =
+// module: (...)`), instruction flags (see instFlags and instAligns), bfloat
+// types and literals, the `vscale x` prefix of scalable vector types, poison
+// constants, freeze instructions, target extension types (e.g.
+// `target("spirv.Event")`), parameter attributes with a type operand (e.g.
+// `byval(%T)`), noundef and nofpclass attributes, partitions, the alignment of
+// functions, debug records (e.g. `#dbg_value(...)`) and DIAssignID specialized
+// metadata nodes.
+type preLexer struct {
+	// Parser configuration; records the byte offsets of substituted constructs.
+	cfg *parseConfig
+	// Original content.
+	content string
+	// Copy of content with substitutions; allocated on first substitution.
+	buf []byte
+	// Module summary index entries.
+	summaryEntries []*ir.SummaryEntry
+	// Byte offset of the global identifier of the current top-level entity
+	// (e.g. `@f`); or -1 if not present.
+	global int
+}
+
+// preLex substitutes the constructs of the source file of the given parser
+// configuration which are not supported by the grammar of the parser (see
+// preLexer), and returns the substituted content and the module summary index
+// entries of the source file.
+func preLex(cfg *parseConfig) (string, []*ir.SummaryEntry, error) {
+	p := &preLexer{
+		cfg:     cfg,
+		content: cfg.content,
+		global:  -1,
+	}
+	cfg.instFlags = make(map[int][]string)
+	cfg.bfloatTypes = make(map[int]bool)
+	cfg.scalableTypes = make(map[int]bool)
+	cfg.poisonConsts = make(map[int]bool)
+	cfg.freezeInsts = make(map[int]bool)
+	cfg.assignIDs = make(map[int]bool)
+	cfg.typedParamAttrs = make(map[int]typedParamAttr)
+	cfg.paramAttrs = make(map[int]string)
+	cfg.partitions = make(map[int]string)
+	cfg.funcAligns = make(map[int]uint64)
+	cfg.targetExtTypes = make(map[int]string)
+	content := p.content
+	// Only whitespace since start of line.
+	lineStart := true
+	for i := 0; i < len(content); {
+		if i == 0 || content[i-1] == '\n' {
+			// Top-level entities start at the beginning of a line, and global
+			// variables with their global identifier.
+			p.global = -1
+			if content[i] == '@' {
+				p.global = i
+			}
+		}
+		switch c := content[i]; {
+		case c == '"':
+			i = skipString(content, i)
+			lineStart = false
+		case c == ';':
+			i = skipComment(content, i)
+		case c == '\n':
+			lineStart = true
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '^' && lineStart:
+			// Summary entries are top-level entities starting on a new line.
+			entry, end, err := scanSummaryEntry(content, i)
+			if err != nil {
+				return "", nil, newPositionedError(cfg.path, content, i, end, err)
+			}
+			p.summaryEntries = append(p.summaryEntries, entry)
+			p.blank(i, end)
+			i = end
+			lineStart = false
+		case c == '#' && lineStart:
+			// Debug records are located on separate lines.
+			i = p.dbgRecord(i)
+			lineStart = false
+		case c == '<':
+			i = p.vectorType(i)
+			lineStart = false
+		case isWordChar(c):
+			i = p.word(i)
+			lineStart = false
+		default:
+			i++
+			lineStart = false
+		}
+	}
+	if p.buf == nil {
+		return content, p.summaryEntries, nil
+	}
+	return string(p.buf), p.summaryEntries, nil
+}
+
+// vectorType substitutes the `vscale x` prefix of the scalable vector type
+// starting with '<' at the given byte offset, and returns the byte offset at
+// which to resume scanning.
+func (p *preLexer) vectorType(start int) int {
+	i := start + 1
+	end, ok := findVScalePrefix(p.content, i)
+	if !ok {
+		return i
+	}
+	p.blank(i, end)
+	p.cfg.scalableTypes[start] = true
+	return end
+}
+
+// dbgRecord extracts the debug record (e.g. `#dbg_value(...)`) starting with
+// '#' at the given byte offset, and returns the byte offset at which to resume
+// scanning.
+func (p *preLexer) dbgRecord(start int) int {
+	content := p.content
+	i := start + 1
+	end := skipWord(content, i)
+	kind := content[i:end]
+	if !dbgRecordKinds[kind] || end >= len(content) || content[end] != '(' {
+		return i
+	}
+	close, ok := findCloseParen(content, end)
+	if !ok {
+		return i
+	}
+	record := dbgRecord{offset: start, end: close, kind: kind, args: content[end+1 : close-1]}
+	p.cfg.dbgRecords = append(p.cfg.dbgRecords, record)
+	p.blank(start, close)
+	return close
+}
+
+// word substitutes the keyword starting at the given byte offset, and returns
+// the byte offset at which to resume scanning.
+func (p *preLexer) word(start int) int {
+	content := p.content
+	i := skipWord(content, start)
+	if start > 0 && content[start-1] == '!' && content[start:i] == "DIAssignID" && strings.HasPrefix(content[i:], "()") {
+		// The DIAssignID specialized metadata node has no fields, and is
+		// substituted by an empty metadata tuple.
+		p.replace(start-1, i+len("()"), "!{}")
+		p.cfg.assignIDs[start-1] = true
+		return i + len("()")
+	}
+	if start > 0 && isIdentPrefix(content[start-1]) {
+		// Part of identifier (e.g. %bfloat).
+		return i
+	}
+	switch word := content[start:i]; {
+	case (word == "define" || word == "declare") && (start == 0 || content[start-1] == '\n'):
+		p.funcHeader(i)
+	case word == "partition" && p.global != -1:
+		return p.partition(start, i)
+	case word == "bfloat":
+		p.replace(start, i, "half")
+		p.cfg.bfloatTypes[start] = true
+	case isBFloatLit(word):
+		// The type of floating-point literals is known from context.
+		p.replace(start+len("0x"), start+len("0xR"), "H")
+	case word == "poison":
+		p.replace(start, i, "undef")
+		p.cfg.poisonConsts[start] = true
+	case word == "freeze":
+		// The fneg instruction has the same syntax as freeze.
+		p.replace(start, i, "fneg")
+		p.cfg.freezeInsts[start] = true
+	case word == "target" && i < len(content) && content[i] == '(':
+		// Target extension type (e.g. `target("spirv.Event")`); distinguished
+		// from target definitions (e.g. `target triple = "..."`) by the
+		// parenthesis.
+		end, ok := findCloseParen(content, i)
+		if !ok {
+			break
+		}
+		p.cfg.targetExtTypes[start] = content[i+1 : end-1]
+		// Substitute a type without parameters supported by the grammar.
+		p.replace(start, end, "token")
+		return end
+	case typedParamAttrs[word] && i < len(content) && content[i] == '(':
+		end, ok := findCloseParen(content, i)
+		if !ok {
+			break
+		}
+		typ := strings.TrimSpace(content[i+1 : end-1])
+		p.cfg.typedParamAttrs[start] = typedParamAttr{kind: word, typ: typ}
+		// Substitute a parameter attribute supported by the grammar.
+		placeholder := "byval"
+		if word == "sret" {
+			placeholder = word
+		}
+		p.replace(start, end, placeholder)
+		return end
+	case word == "noundef":
+		p.cfg.paramAttrs[start] = word
+		// Substitute a parameter and return attribute supported by the grammar.
+		p.replace(start, i, "inreg")
+	case word == "nofpclass" && i < len(content) && content[i] == '(':
+		end, ok := findCloseParen(content, i)
+		if !ok {
+			break
+		}
+		p.cfg.paramAttrs[start] = content[start:end]
+		// Substitute a parameter and return attribute supported by the grammar.
+		p.replace(start, end, "inreg")
+		return end
+	case len(instFlags[word]) > 0 || instAligns[word]:
+		return p.instFlags(start, i)
+	}
+	return i
+}
+
+// funcHeader extracts the alignment of the function header following the
+// define or declare keyword ending at the given byte offset, as the grammar does
+// not support the alignment of functions (e.g. `define void @f() align 16`).
+func (p *preLexer) funcHeader(end int) {
+	content := p.content
+	// Locate the function name, which precedes the function parameters.
+	i := end
+	for i < len(content) && content[i] != '@' && content[i] != '\n' {
+		if content[i] == '"' {
+			i = skipString(content, i)
+			continue
+		}
+		i++
+	}
+	if i >= len(content) || content[i] != '@' {
+		return
+	}
+	p.global = i
+	i++
+	if i < len(content) && content[i] == '"' {
+		i = skipString(content, i)
+	} else {
+		i = skipWord(content, i)
+	}
+	if i >= len(content) || content[i] != '(' {
+		return
+	}
+	i, ok := findCloseParen(content, i)
+	if !ok {
+		return
+	}
+	// The alignment follows the function parameters on the same line.
+	for i < len(content) && content[i] != '\n' && content[i] != '{' && content[i] != ';' {
+		switch c := content[i]; {
+		case c == '"':
+			i = skipString(content, i)
+		case isWordChar(c):
+			end := skipWord(content, i)
+			if content[i:end] == "align" && !isIdentPrefix(content[i-1]) {
+				j := skipSpace(content, end)
+				k := skipWord(content, j)
+				if n, err := strconv.ParseUint(content[j:k], 10, 64); err == nil {
+					p.cfg.funcAligns[p.global] = n
+					p.blank(i, k)
+					end = k
+				}
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+}
+
+// partition extracts the partition (e.g. `partition "p"`) with keyword at the
+// given start and end byte offsets of the current global variable or function,
+// and returns the byte offset at which to resume scanning.
+func (p *preLexer) partition(start, end int) int {
+	content := p.content
+	i := skipSpace(content, end)
+	if i >= len(content) || content[i] != '"' {
+		return end
+	}
+	end = skipString(content, i)
+	p.cfg.partitions[p.global] = unquote(content[i:end])
+	// The partition of global variables is preceded by a comma.
+	j := start - 1
+	for j > 0 && (content[j] == ' ' || content[j] == '\t') {
+		j--
+	}
+	if content[j] == ',' {
+		start = j
+	}
+	p.blank(start, end)
+	return end
+}
+
+// instFlags extracts the instruction flags and alignment not supported by the
+// grammar of the instruction with opcode at the given start and end byte
+// offsets, and returns the byte offset at which to resume scanning.
+func (p *preLexer) instFlags(start, end int) int {
+	content := p.content
+	opcode := content[start:end]
+	i := end
+	// Flags directly follow the opcode.
+	if supported := instFlags[opcode]; len(supported) > 0 {
+		for {
+			j := skipSpace(content, i)
+			end := skipWord(content, j)
+			if j == end {
+				break
+			}
+			flag := content[j:end]
+			if contains(instKeywords[opcode], flag) {
+				// Keyword supported by the grammar; left as is.
+				i = end
+				continue
+			}
+			if !contains(supported, flag) {
+				break
+			}
+			p.cfg.instFlags[start] = append(p.cfg.instFlags[start], flag)
+			p.blank(j, end)
+			i = end
+		}
+	}
+	// Trailing alignment.
+	if instAligns[opcode] {
+		if j, end, ok := findAlign(content, i); ok {
+			align := strings.Join(strings.Fields(content[j+len(","):end]), " ")
+			p.cfg.instFlags[start] = append(p.cfg.instFlags[start], align)
+			p.blank(j, end)
+		}
+	}
+	return i
+}
+
+// blank replaces content[start:end] by whitespace, retaining line breaks.
+func (p *preLexer) blank(start, end int) {
+	if p.buf == nil {
+		p.buf = []byte(p.content)
+	}
+	for i := start; i < end; i++ {
+		if p.buf[i] != '\n' {
+			p.buf[i] = ' '
+		}
+	}
+}
+
+// replace replaces content[start:end] by s, padded with whitespace to retain
+// byte offsets; s must not be longer than the replaced content.
+func (p *preLexer) replace(start, end int, s string) {
+	p.blank(start, end)
+	copy(p.buf[start:end], s)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// skipComment returns the byte offset of the line break terminating the comment
+// starting at the given byte offset of content.
+func skipComment(content string, start int) int {
+	if j := strings.IndexByte(content[start:], '\n'); j != -1 {
+		return start + j
+	}
+	return len(content)
+}
+
+// skipSpace returns the byte offset following the whitespace starting at the
+// given byte offset of content.
+func skipSpace(content string, start int) int {
+	i := start
+	for i < len(content) && (content[i] == ' ' || content[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// findVScalePrefix returns the end byte offset of the `vscale x` prefix of a
+// scalable vector type following the given byte offset of content (directly
+// after '<'). The boolean return value indicates success.
+func findVScalePrefix(content string, start int) (int, bool) {
+	i := skipSpace(content, start)
+	end := skipWord(content, i)
+	if content[i:end] != "vscale" {
+		return 0, false
+	}
+	i = skipSpace(content, end)
+	end = skipWord(content, i)
+	if content[i:end] != "x" {
+		return 0, false
+	}
+	return end, true
+}
+
+// isBFloatLit reports whether the given word is a bfloat hexadecimal
+// floating-point literal (e.g. `0xR3F80`).
+func isBFloatLit(word string) bool {
+	if len(word) != len("0xR0000") || !strings.HasPrefix(word, "0xR") {
+		return false
+	}
+	for _, c := range word[len("0xR"):] {
+		switch {
+		case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// splitArgs splits the given comma-separated arguments (e.g. of a debug record)
+// at top-level commas.
+func splitArgs(s string) []string {
+	var args []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '"':
+			i = skipString(s, i)
+			continue
+		case '(', '[', '{', '<':
+			depth++
+		case ')', ']', '}', '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+		i++
+	}
+	return append(args, strings.TrimSpace(s[start:]))
+}
@@ -0,0 +1,67 @@
+package asm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/llir/llvm/ir"
+	"github.com/pkg/errors"
+)
+
+// scanSummaryEntry scans the module summary index entry starting at the given
+// byte offset of content, and returns the summary entry and the byte offset of
+// the end of the entry (excluding trailing comments).
+func scanSummaryEntry(content string, start int) (*ir.SummaryEntry, int, error) {
+	// ID=SummaryID '=' Body
+	i := start + 1
+	for i < len(content) && '0' <= content[i] && content[i] <= '9' {
+		i++
+	}
+	id, err := strconv.ParseInt(content[start+1:i], 10, 64)
+	if err != nil {
+		return nil, i, errors.Errorf("invalid summary entry ID %q", content[start:i])
+	}
+	for i < len(content) && (content[i] == ' ' || content[i] == '\t') {
+		i++
+	}
+	if i >= len(content) || content[i] != '=' {
+		return nil, i, errors.Errorf("invalid summary entry %s; expected '='", content[start:i])
+	}
+	i++
+	bodyStart := i
+	depth := 0
+loop:
+	for i < len(content) {
+		switch content[i] {
+		case '"':
+			i = skipString(content, i)
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ';', '\n':
+			if depth <= 0 {
+				break loop
+			}
+		}
+		i++
+	}
+	if depth > 0 {
+		return nil, i, errors.Errorf("unbalanced parentheses in summary entry ^%d", id)
+	}
+	body := strings.TrimSpace(content[bodyStart:i])
+	if len(body) == 0 {
+		return nil, i, errors.Errorf("empty summary entry ^%d", id)
+	}
+	return &ir.SummaryEntry{ID: id, Body: body}, i, nil
+}
+
+// skipString returns the byte offset following the string literal starting at
+// the given byte offset of content.
+func skipString(content string, start int) int {
+	if j := strings.IndexByte(content[start+1:], '"'); j != -1 {
+		return start + 1 + j + 1
+	}
+	return len(content)
+}
@@ -364,7 +364,10 @@ func (fgen *funcGen) irInvokeTerm(new ir.Terminator, old *ast.InvokeTerm) error
 	if oldReturnAttrs := old.ReturnAttrs(); len(oldReturnAttrs) > 0 {
 		term.ReturnAttrs = make([]ir.ReturnAttribute, len(oldReturnAttrs))
 		for i, oldRetAttr := range oldReturnAttrs {
-			retAttr := irReturnAttribute(oldRetAttr)
+			retAttr, err := fgen.gen.irReturnAttribute(oldRetAttr)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 			term.ReturnAttrs[i] = retAttr
 		}
 	}
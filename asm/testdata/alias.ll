@@ -0,0 +1,12 @@
+@g = global i32 0
+@arr = global [2 x i32] zeroinitializer
+
+@a = alias void (i32), bitcast (void ()* @f to void (i32)*)
+@b = alias void (i32), void (i32)* @a
+@c = alias i8, bitcast (i32* @g to i8*)
+@d = internal alias i32, getelementptr ([2 x i32], [2 x i32]* @arr, i64 0, i64 1)
+
+define void @f() {
+; <label>:0
+	ret void
+}
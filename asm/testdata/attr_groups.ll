@@ -0,0 +1,16 @@
+declare void @g() #1
+
+define void @f() #0 {
+; <label>:0
+	call void @g() #1
+	ret void
+}
+
+define void @h() #0 {
+; <label>:0
+	call void @f() #0
+	ret void
+}
+
+attributes #0 = { noinline nounwind "frame-pointer"="all" }
+attributes #1 = { nounwind readnone }
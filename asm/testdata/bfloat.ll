@@ -0,0 +1,14 @@
+@bf = global bfloat 1.5
+@bf_vec = global <4 x bfloat> <bfloat 1.0, bfloat -2.5, bfloat 0xR3DCD, bfloat 0xR7FC0>
+@half_vec = global <4 x half> <half 1.0, half 2.0, half 0xH2E66, half 0xH7C00>
+@bf_ext = global <4 x float> fpext (<4 x bfloat> <bfloat 1.0, bfloat -2.5, bfloat 0xR3DCD, bfloat 0xR7F80> to <4 x float>)
+
+define <4 x float> @f(<4 x bfloat> %x, <4 x half> %y) {
+entry:
+	%ext = fpext <4 x bfloat> %x to <4 x float>
+	%trunc = fptrunc <4 x float> %ext to <4 x bfloat>
+	%half_ext = fpext <4 x half> %y to <4 x float>
+	%half_trunc = fptrunc <4 x float> %half_ext to <4 x half>
+	%bfloat = fadd bfloat 1.0, 2.0
+	ret <4 x float> %ext
+}
@@ -0,0 +1,13 @@
+%s = type { i32, i64 }
+
+@a = global i128 170141183460469231731687303715884105727
+@b = global i128 -170141183460469231731687303715884105728
+@c = global i128 18446744073709551616
+@d = global i256 -57896044618658097711785492504343953926634992332820282019728792003956564819968
+@e = global <2 x i128> <i128 -9223372036854775809, i128 9223372036854775808>
+
+define i64* @f(%s* %p) {
+entry:
+	%x = getelementptr %s, %s* %p, i128 18446744073709551616, i128 1
+	ret i64* %x
+}
@@ -0,0 +1,20 @@
+@table = internal constant [4 x i8*] [i8* blockaddress(@f, %0), i8* blockaddress(@f, %b), i8* blockaddress(@f, %c), i8* blockaddress(@f, %d)]
+
+define i32 @f(i32 %i) {
+entry:
+	%p = getelementptr [4 x i8*], [4 x i8*]* @table, i32 0, i32 %i
+	%target = load i8*, i8** %p
+	indirectbr i8* %target, [label %0, label %b, label %c, label %d]
+
+; <label>:0
+	ret i32 1
+
+b:
+	ret i32 2
+
+c:
+	ret i32 3
+
+d:
+	ret i32 4
+}
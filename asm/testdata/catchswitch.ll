@@ -0,0 +1,27 @@
+declare i32 @__CxxFrameHandler3(...)
+
+declare void @g()
+
+define void @f() personality i32 (...)* @__CxxFrameHandler3 {
+entry:
+	invoke void @g()
+		to label %exit unwind label %dispatch
+
+handler0:
+	%0 = catchpad within %cs [i8* null, i32 64, i8* null]
+	catchret from %0 to label %exit
+
+handler1:
+	%1 = catchpad within %cs [i8* null, i32 0, i8* null]
+	catchret from %1 to label %exit
+
+dispatch:
+	%cs = catchswitch within none [label %handler0, label %handler1] unwind label %cleanup
+
+cleanup:
+	%2 = cleanuppad within none []
+	cleanupret from %2 unwind to caller
+
+exit:
+	ret void
+}
@@ -0,0 +1,26 @@
+declare token @llvm.experimental.convergence.entry()
+
+declare token @llvm.experimental.convergence.anchor()
+
+declare token @llvm.experimental.convergence.loop()
+
+declare i32 @llvm.amdgcn.readfirstlane(i32)
+
+define i32 @f(i32 %x, i32 %n) convergent {
+entry:
+	%entry_token = call token @llvm.experimental.convergence.entry()
+	%anchor = call token @llvm.experimental.convergence.anchor()
+	br label %loop
+
+loop:
+	%i = phi i32 [ 0, %entry ], [ %next, %loop ]
+	%loop_token = call token @llvm.experimental.convergence.loop() [ "convergencectrl"(token %anchor) ]
+	%y = call i32 @llvm.amdgcn.readfirstlane(i32 %x) [ "convergencectrl"(token %loop_token) ]
+	%next = add i32 %i, 1
+	%cond = icmp slt i32 %next, %n
+	br i1 %cond, label %loop, label %exit
+
+exit:
+	%z = call i32 @llvm.amdgcn.readfirstlane(i32 %y) [ "convergencectrl"(token %entry_token) ]
+	ret i32 %z
+}
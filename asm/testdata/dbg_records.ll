@@ -0,0 +1,30 @@
+define void @f(i32 %x) !dbg !3 {
+entry:
+	%p = alloca i32, !DIAssignID !8
+	#dbg_declare(i32* %p, !6, !DIExpression(), !7)
+	#dbg_assign(i32 poison, !6, !DIExpression(), !8, i32* %p, !DIExpression(), !7)
+	store i32 %x, i32* %p, !DIAssignID !9
+	#dbg_assign(i32 %x, !6, !DIExpression(), !9, i32* %p, !DIExpression(), !7)
+	#dbg_value(i32 %x, !6, !DIExpression(DW_OP_plus_uconst, 1), !7)
+	br label %exit
+
+exit:
+	#dbg_label(!10, !7)
+	ret void
+}
+
+!llvm.dbg.cu = !{!0}
+!llvm.module.flags = !{!2}
+
+!0 = distinct !DICompileUnit(language: DW_LANG_C99, file: !1, emissionKind: FullDebug)
+!1 = !DIFile(filename: "foo.c", directory: "/tmp")
+!2 = !{i32 2, !"Debug Info Version", i32 3}
+!3 = distinct !DISubprogram(name: "f", scope: !1, file: !1, line: 1, type: !4, scopeLine: 1, spFlags: DISPFlagDefinition, unit: !0)
+!4 = !DISubroutineType(types: !5)
+!5 = !{null}
+!6 = !DILocalVariable(name: "x", arg: 1, scope: !3, file: !1, line: 1, type: !11)
+!7 = !DILocation(line: 1, column: 1, scope: !3)
+!8 = distinct !DIAssignID()
+!9 = distinct !DIAssignID()
+!10 = !DILabel(scope: !3, name: "exit", file: !1, line: 2)
+!11 = !DIBasicType(name: "int", size: 32, encoding: DW_ATE_signed)
@@ -1,3 +1,4 @@
 !bar = !{!DIExpression(42)}
 !baz = !{!DIExpression(42, DW_OP_addr)}
 !foo = !{!DIExpression()}
+!qux = !{!DIExpression(DW_OP_plus_uconst, 8, DW_OP_deref)}
@@ -0,0 +1,27 @@
+define <4 x float> @vec(<4 x float> %a, <4 x float> %b, float %s) {
+entry:
+	%sum = fadd fast <4 x float> %a, %b
+	%ins = insertelement <4 x float> %sum, float %s, i32 0
+	%ext = extractelement <4 x float> %ins, i32 3
+	%neg = fneg float %ext
+	%shuf = shufflevector <4 x float> %ins, <4 x float> %b, <4 x i32> <i32 0, i32 5, i32 2, i32 7>
+	%cmp = fcmp olt <4 x float> %shuf, %a
+	%res = select <4 x i1> %cmp, <4 x float> %shuf, <4 x float> %a
+	ret <4 x float> %res
+}
+
+define { i32, double } @agg(i32 %x, double %y) {
+entry:
+	%a = insertvalue { i32, double } undef, i32 %x, 0
+	%b = insertvalue { i32, double } %a, double %y, 1
+	%c = extractvalue { i32, double } %b, 0
+	%d = mul nuw i32 %c, 3
+	%e = udiv exact i32 %d, 3
+	%f = shl i32 %e, 2
+	%g = ashr exact i32 %f, 1
+	%h = trunc i32 %g to i16
+	%i = zext i16 %h to i64
+	%j = uitofp i64 %i to double
+	%k = insertvalue { i32, double } %b, double %j, 1
+	ret { i32, double } %k
+}
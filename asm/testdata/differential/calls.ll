@@ -0,0 +1,24 @@
+@str = private unnamed_addr constant [6 x i8] c"hello\00", align 1
+@entry = alias i32 (), i32 ()* @main
+
+declare i32 @printf(i8*, ...)
+
+declare i32 @__gxx_personality_v0(...)
+
+declare void @may_throw() noreturn
+
+define i32 @main() personality i32 (...)* @__gxx_personality_v0 {
+entry:
+	%n = call i32 (i8*, ...) @printf(i8* getelementptr inbounds ([6 x i8], [6 x i8]* @str, i64 0, i64 0))
+	invoke void @may_throw()
+			to label %cont unwind label %lpad
+
+cont:
+	unreachable
+
+lpad:
+	%lp = landingpad { i8*, i32 }
+			cleanup
+	%r = tail call i32 @entry() nounwind
+	resume { i8*, i32 } %lp
+}
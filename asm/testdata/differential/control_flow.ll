@@ -0,0 +1,43 @@
+define i32 @abs(i32 %x) {
+entry:
+	%cond = icmp slt i32 %x, 0
+	br i1 %cond, label %neg, label %exit
+
+neg:
+	%y = sub nsw i32 0, %x
+	br label %exit
+
+exit:
+	%res = phi i32 [ %y, %neg ], [ %x, %entry ]
+	ret i32 %res
+}
+
+define i32 @classify(i32 %x) {
+entry:
+	switch i32 %x, label %default [
+		i32 0, label %zero
+		i32 1, label %one
+	]
+
+zero:
+	ret i32 10
+
+one:
+	ret i32 20
+
+default:
+	%cmp = icmp ugt i32 %x, 100
+	%sel = select i1 %cmp, i32 30, i32 40
+	ret i32 %sel
+}
+
+define void @indirect(i8* %addr) {
+entry:
+	indirectbr i8* %addr, [label %a, label %b]
+
+a:
+	ret void
+
+b:
+	unreachable
+}
@@ -0,0 +1,25 @@
+%struct.pair = type { i32, i64 }
+
+@counter = global i32 0, align 4
+@pairs = internal global [4 x %struct.pair] zeroinitializer, align 16
+
+define i64 @load_pair(i32 %i) {
+entry:
+	%idx = sext i32 %i to i64
+	%p = getelementptr inbounds [4 x %struct.pair], [4 x %struct.pair]* @pairs, i64 0, i64 %idx, i32 1
+	%v = load i64, i64* %p, align 8
+	ret i64 %v
+}
+
+define void @atomics(i32* %p, i32 %v) {
+entry:
+	%tmp = alloca i32, align 4
+	store volatile i32 %v, i32* %tmp, align 4
+	%old = atomicrmw add i32* %p, i32 %v seq_cst
+	%pair = cmpxchg i32* %p, i32 %old, i32 %v acq_rel monotonic
+	%ok = extractvalue { i32, i1 } %pair, 1
+	fence release
+	%l = load atomic i32, i32* @counter acquire, align 4
+	store atomic i32 %l, i32* @counter release, align 4
+	ret void
+}
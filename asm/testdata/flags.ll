@@ -0,0 +1,42 @@
+@arr = global [2 x i32] zeroinitializer
+@p = global i32* getelementptr inbounds nuw ([2 x i32], [2 x i32]* @arr, i64 0, i64 1)
+@q = global i32* getelementptr nusw nuw ([2 x i32], [2 x i32]* @arr, i64 0, i64 1)
+
+define i32 @f(i8 %x, i8 %zext) {
+entry:
+	%a = zext nneg i8 %x to i32
+	%b = zext i8 %zext to i32
+	%c = add i32 %a, %b
+	ret i32 %c
+}
+
+define i32 @g(i32 %a, i32 %b) {
+entry:
+	%c = or disjoint i32 %a, %b
+	%d = or i32 %c, 1
+	ret i32 %d
+}
+
+define i1 @h(i32 %a, i32 %b) {
+entry:
+	%c = icmp samesign ult i32 %a, %b
+	%d = icmp ult i32 %a, %b
+	%e = and i1 %c, %d
+	ret i1 %e
+}
+
+define { i32, i1 } @cas(i32* %p, i32 %c, i32 %n) {
+entry:
+	%x = cmpxchg weak volatile i32* %p, i32 %c, i32 %n syncscope("agent") acq_rel monotonic, align 4
+	%y = cmpxchg i32* %p, i32 %c, i32 %n seq_cst seq_cst
+	ret { i32, i1 } %x
+}
+
+define i8* @gep(i8* %p, i64 %i) {
+entry:
+	%a = getelementptr inbounds nuw i8, i8* %p, i64 %i
+	%b = getelementptr nusw nuw i8, i8* %a, i64 1
+	%c = getelementptr nuw i8, i8* %b, i64 %i
+	%d = getelementptr inbounds i8, i8* %c, i64 %i
+	ret i8* %d
+}
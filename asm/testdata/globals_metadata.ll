@@ -0,0 +1,10 @@
+source_filename = "summary.c"
+
+@count = global i32 0, !dbg !0
+@names = external global [4 x i8*]
+
+!llvm.named = !{!1}
+
+!0 = !{!"count"}
+!1 = !{i32 7, !2}
+!2 = !{!"names", [4 x i8*]* @names}
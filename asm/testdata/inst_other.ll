@@ -41,3 +41,10 @@ handler1:
 dispatch:
 	%cs = catchswitch within none [label %handler0, label %handler1] unwind to caller
 }
+
+define i32 @freeze(i32 %x) {
+; <label>:0
+	%1 = freeze i32 %x
+	%2 = freeze i32 poison
+	ret i32 %1
+}
@@ -0,0 +1,73 @@
+@private = private global i32 0
+@internal = internal global i32 0
+@available_externally = available_externally global i32 0
+@linkonce = linkonce global i32 0
+@weak = weak global i32 0
+@common = common global i32 0
+@appending = appending global [1 x i32] zeroinitializer
+@llvm.used = appending global [1 x i8*] [i8* bitcast (i32* @default to i8*)], section "llvm.metadata"
+@extern_weak = extern_weak global i32
+@linkonce_odr = linkonce_odr global i32 0
+@weak_odr = weak_odr global i32 0
+@external = external global i32
+@default = global i32 0
+
+@alias_private = private alias i32, i32* @default
+@alias_internal = internal alias i32, i32* @default
+@alias_linkonce = linkonce alias i32, i32* @default
+@alias_weak = weak alias i32, i32* @default
+@alias_linkonce_odr = linkonce_odr alias i32, i32* @default
+@alias_weak_odr = weak_odr alias i32, i32* @default
+@alias_external = external alias i32, i32* @default
+@alias_default = alias i32, i32* @default
+
+define private void @f_private() {
+; <label>:0
+	ret void
+}
+
+define internal void @f_internal() {
+; <label>:0
+	ret void
+}
+
+define available_externally void @f_available_externally() {
+; <label>:0
+	ret void
+}
+
+define linkonce void @f_linkonce() {
+; <label>:0
+	ret void
+}
+
+define weak void @f_weak() {
+; <label>:0
+	ret void
+}
+
+define linkonce_odr void @f_linkonce_odr() {
+; <label>:0
+	ret void
+}
+
+define weak_odr void @f_weak_odr() {
+; <label>:0
+	ret void
+}
+
+define external void @f_external() {
+; <label>:0
+	ret void
+}
+
+define void @f_default() {
+; <label>:0
+	ret void
+}
+
+declare extern_weak void @f_extern_weak()
+
+declare external void @f_declare_external()
+
+declare void @f_declare()
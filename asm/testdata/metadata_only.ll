@@ -0,0 +1,11 @@
+!llvm.ident = !{!0}
+!llvm.module.flags = !{!1, !2}
+!summary = !{!3, !4}
+
+!0 = !{!"clang version 8.0.0"}
+!1 = !{i32 2, !"Dwarf Version", i32 4}
+!2 = !{i32 1, !"wchar_size", i32 4}
+!3 = !{!"remark", i64 42, !5}
+!4 = distinct !{!4}
+!5 = !{}
+!6 = !{!"unreferenced"}
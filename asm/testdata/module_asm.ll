@@ -0,0 +1,14 @@
+source_filename = "foo.c"
+target datalayout = "e-m:e-i64:64-f80:128-n8:16:32:64-S128"
+target triple = "x86_64-unknown-linux-gnu"
+
+module asm ".globl foo"
+module asm "foo:"
+module asm "\09ret"
+
+declare void @foo()
+
+!llvm.linker.options = !{!0, !1}
+
+!0 = !{!"/DEFAULTLIB:libcmt.lib"}
+!1 = !{!"-lfoo", !"-lbar"}
@@ -0,0 +1,22 @@
+define void @f(i32* %a, i32* %b, i64 %n) {
+entry:
+	br label %loop
+
+loop:
+	%i = phi i64 [ 0, %entry ], [ %next, %loop ]
+	%src = getelementptr inbounds i32, i32* %b, i64 %i
+	%x = load i32, i32* %src, align 4, !llvm.access.group !1
+	%dst = getelementptr inbounds i32, i32* %a, i64 %i
+	store i32 %x, i32* %dst, align 4, !llvm.access.group !1
+	%next = add nuw i64 %i, 1
+	%cond = icmp ult i64 %next, %n
+	br i1 %cond, label %loop, label %exit, !llvm.loop !0
+
+exit:
+	ret void
+}
+
+!0 = distinct !{!0, !2, !3}
+!1 = distinct !{}
+!2 = !{!"llvm.loop.parallel_accesses", !1}
+!3 = !{!"llvm.loop.vectorize.enable", i1 true}
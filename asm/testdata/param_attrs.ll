@@ -0,0 +1,30 @@
+%struct.T = type { i32, i64 }
+
+declare zeroext i8 @zext(i8 zeroext, i16 signext, i32 inreg)
+
+declare i8* @nest(i8* nest, i8* returned)
+
+declare void @nocapture(i8* nocapture readonly, i8* nocapture writeonly)
+
+declare void @byval(%struct.T* byval, %struct.T* sret, %struct.T* inalloca)
+
+declare void @typed(%struct.T* byval(%struct.T), %struct.T* byref(%struct.T) align 8, %struct.T* noalias sret(%struct.T), { i32, i64 }* preallocated({ i32, i64 }), i32* elementtype(i32))
+
+declare void @typed_lexed(bfloat* byval(bfloat), <vscale x 4 x i32>* byref(<vscale x 4 x i32>))
+
+declare noundef i32 @noundef(i32 noundef, i8* noundef nonnull)
+
+declare nofpclass(nan) float @nofpclass(float nofpclass(nan inf), double nofpclass(zero sub), <2 x float> nofpclass(snan pinf nnorm))
+
+define i8 @f(i8 zeroext %x, i8* %p, %struct.T* %t, i32* %p32) {
+; <label>:0
+	%1 = call zeroext i8 @zext(i8 zeroext %x, i16 signext 1, i32 inreg 2)
+	%2 = call i8* @nest(i8* nest %p, i8* returned %p)
+	call void @nocapture(i8* nocapture readonly %p, i8* nocapture writeonly %p)
+	call void @byval(%struct.T* byval %t, %struct.T* sret %t, %struct.T* inalloca %t)
+	%u = bitcast %struct.T* %t to { i32, i64 }*
+	call void @typed(%struct.T* byval(%struct.T) %t, %struct.T* byref(%struct.T) align 8 %t, %struct.T* noalias sret(%struct.T) %t, { i32, i64 }* preallocated({ i32, i64 }) %u, i32* elementtype(i32) %p32)
+	%3 = call noundef i32 @noundef(i32 noundef 0, i8* noundef nonnull %p)
+	%4 = call nofpclass(nan) float @nofpclass(float nofpclass(nan inf) 1.0, double nofpclass(zero sub) 2.0, <2 x float> nofpclass(snan pinf nnorm) zeroinitializer)
+	ret i8 %1
+}
@@ -0,0 +1,17 @@
+$c = comdat any
+
+@x = global i32 0, section ".data.x", partition "part1", align 4
+@y = global i32 1, partition "part2"
+
+declare void @g() partition "part1" align 8
+
+define void @f() nounwind section ".text.hot" partition "part2" comdat($c) align 16 {
+; <label>:0
+	ret void
+}
+
+define void @h() align 32 {
+; <label>:0
+	%x = load i32, i32* @x, align 4
+	ret void
+}
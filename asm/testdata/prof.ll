@@ -0,0 +1,16 @@
+define i32 @f(i32 %x) !prof !0 {
+entry:
+	%c = icmp sgt i32 %x, 0, !annotation !1
+	br i1 %c, label %a, label %b, !prof !2, !misexpect !3
+
+a:
+	ret i32 1
+
+b:
+	ret i32 0
+}
+
+!0 = !{!"function_entry_count", i64 42}
+!1 = !{!"auto-init"}
+!2 = !{!"branch_weights", i32 2000, i32 1}
+!3 = !{!"misexpect", i64 0, i64 2000, i64 1}
@@ -0,0 +1,12 @@
+@splat = global <vscale x 4 x i32> shufflevector (<vscale x 4 x i32> insertelement (<vscale x 4 x i32> poison, i32 1, i64 0), <vscale x 4 x i32> poison, <vscale x 4 x i32> zeroinitializer)
+
+define <vscale x 4 x i32> @f(<vscale x 4 x i32> %x, <4 x i32> %y, <vscale x 2 x i64>* %p) {
+entry:
+	%a = add <vscale x 4 x i32> %x, %x
+	%b = insertelement <vscale x 4 x i32> poison, i32 1, i64 0
+	%c = shufflevector <vscale x 4 x i32> %b, <vscale x 4 x i32> poison, <vscale x 4 x i32> zeroinitializer
+	%d = mul <vscale x 4 x i32> %a, %c
+	%e = load <vscale x 2 x i64>, <vscale x 2 x i64>* %p
+	%f = add <4 x i32> %y, %y
+	ret <vscale x 4 x i32> %d
+}
@@ -0,0 +1,18 @@
+$g = comdat any
+
+@g = global i32 0, section ".data", comdat, align 8
+@h = internal constant [2 x i8] c"hi", section ".rodata.str", align 1
+
+declare void @ext() section ".text.ext"
+
+define void @f() section ".text.hot" {
+; <label>:0
+	ret void
+}
+
+define void @k() #0 section ".text.cold" {
+; <label>:0
+	ret void
+}
+
+attributes #0 = { cold }
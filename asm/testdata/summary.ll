@@ -0,0 +1,19 @@
+source_filename = "s.ll"
+
+@g = global i32 42
+
+define i32 @foo() {
+	%1 = load i32, i32* @g
+	ret i32 %1
+}
+
+define i32 @main() {
+	%1 = call i32 @foo()
+	ret i32 %1
+}
+
+^0 = module: (path: "/tmp/s.bc", hash: (0, 0, 0, 0, 0))
+^1 = gv: (name: "foo", summaries: (function: (module: ^0, flags: (linkage: external, visibility: default, notEligibleToImport: 0, live: 0, dsoLocal: 0, canAutoHide: 0), insts: 2, refs: (readonly ^2)))) ; guid = 6699318081062747564
+^2 = gv: (name: "g", summaries: (variable: (module: ^0, flags: (linkage: external, visibility: default, notEligibleToImport: 0, live: 0, dsoLocal: 0, canAutoHide: 0), varFlags: (readonly: 1, writeonly: 1, constant: 0)))) ; guid = 13146401226427987378
+^3 = gv: (name: "main", summaries: (function: (module: ^0, flags: (linkage: external, visibility: default, notEligibleToImport: 0, live: 0, dsoLocal: 0, canAutoHide: 0), insts: 2, calls: ((callee: ^1))))) ; guid = 15822663052811949562
+^4 = blockcount: 2
@@ -0,0 +1,13 @@
+%event = type target("spirv.Event")
+
+@image = global target("spirv.Image", void, 1) zeroinitializer
+
+declare void @use(target("spirv.Image", void, 1), %event, target("spirv.Sampler", <4 x bfloat>, i32, 0, 1))
+
+define void @f() {
+entry:
+	%img = alloca target("spirv.Image", void, 1)
+	%ev = alloca %event
+	%x = load target("spirv.Image", void, 1), target("spirv.Image", void, 1)* %img
+	ret void
+}
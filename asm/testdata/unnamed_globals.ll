@@ -0,0 +1,19 @@
+@0 = global i32 1
+@g = global i32 2
+@1 = global i32* @0
+
+@2 = alias i32, i32* @g
+
+define i32** @3() {
+; <label>:0
+	ret i32** @1
+}
+
+declare void @4()
+
+define void @f() {
+; <label>:0
+	call void @4()
+	%1 = call i32** @3()
+	ret void
+}
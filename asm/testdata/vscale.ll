@@ -0,0 +1,13 @@
+declare i64 @llvm.vscale.i64()
+
+declare i32 @llvm.vscale.i32()
+
+define i64 @f(i64 %x) {
+; <label>:0
+	%vscale = call i64 @llvm.vscale.i64()
+	%elems = mul i64 %vscale, 4
+	%bytes = shl i64 %elems, 2
+	%vscale32 = call i32 @llvm.vscale.i32()
+	%y = mul i64 %x, %vscale
+	ret i64 %bytes
+}
@@ -98,8 +98,8 @@ import (
 )
 
 // translate translates the given AST module into an equivalent IR module.
-func translate(old *ast.Module) (*ir.Module, error) {
-	gen := newGenerator()
+func translate(old *ast.Module, cfg *parseConfig) (*ir.Module, error) {
+	gen := newGenerator(cfg)
 	// 1. Index AST top-level entities.
 	indexStart := time.Now()
 	if err := gen.indexTopLevelEntities(old); err != nil {
@@ -139,15 +139,19 @@ func translate(old *ast.Module) (*ir.Module, error) {
 	if err := gen.translateUseListOrders(); err != nil {
 		return nil, errors.WithStack(err)
 	}
-	// 6. Translate basic block specific use-list orders.
-	if err := gen.translateUseListOrderBBs(); err != nil {
-		return nil, errors.WithStack(err)
-	}
-	// 7. Fix basic block references in blockaddress constants.
-	for _, c := range gen.todo {
-		if err := fixBlockAddressConst(c); err != nil {
+	// Note: step 6-7 refer to the basic blocks of function bodies, and are
+	// skipped when function bodies are not translated.
+	if !gen.cfg.declsOnly {
+		// 6. Translate basic block specific use-list orders.
+		if err := gen.translateUseListOrderBBs(); err != nil {
 			return nil, errors.WithStack(err)
 		}
+		// 7. Fix basic block references in blockaddress constants.
+		for _, c := range gen.todo {
+			if err := fixBlockAddressConst(c); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
 	}
 	// 8. Add IR top-level declarations and definitions to the IR module in order
 	//    of occurrence in the input.
@@ -41,7 +41,7 @@ func (gen *generator) createTypeDefs() error {
 	for typeName, old := range gen.old.typeDefs {
 		// track is used to identify self-referential named types.
 		track := make(map[string]bool)
-		t, err := newType(typeName, old.Typ(), gen.old.typeDefs, track)
+		t, err := gen.newType(typeName, old.Typ(), gen.old.typeDefs, track)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -67,7 +67,7 @@ func (gen *generator) createTypeDefs() error {
 //
 //    ; struct type containing pointer to itself.
 //    %d = type { %d* }
-func newType(typeName string, old ast.LlvmNode, index map[string]*ast.TypeDef, track map[string]bool) (types.Type, error) {
+func (gen *generator) newType(typeName string, old ast.LlvmNode, index map[string]*ast.TypeDef, track map[string]bool) (types.Type, error) {
 	switch old := old.(type) {
 	case *ast.VoidType:
 		return &types.VoidType{TypeName: typeName}, nil
@@ -86,6 +86,10 @@ func newType(typeName string, old ast.LlvmNode, index map[string]*ast.TypeDef, t
 	case *ast.LabelType:
 		return &types.LabelType{TypeName: typeName}, nil
 	case *ast.TokenType:
+		if _, ok := gen.cfg.targetExtTypes[old.Offset()]; ok {
+			// Substituted target extension type (see preLexer).
+			return &types.TargetExtType{TypeName: typeName}, nil
+		}
 		return &types.TokenType{TypeName: typeName}, nil
 	case *ast.MetadataType:
 		return &types.MetadataType{TypeName: typeName}, nil
@@ -110,7 +114,7 @@ func newType(typeName string, old ast.LlvmNode, index map[string]*ast.TypeDef, t
 		newIdent := localIdent(old.Name())
 		newName := getTypeName(newIdent)
 		newTyp := index[newName].Typ()
-		return newType(newName, newTyp, index, track)
+		return gen.newType(newName, newTyp, index, track)
 	default:
 		panic(fmt.Errorf("support for type %T not yet implemented", old))
 	}
@@ -268,6 +272,10 @@ func (gen *generator) irFloatType(t types.Type, old *ast.FloatType) (types.Type,
 	}
 	// Floating-point kind.
 	typ.Kind = asmenum.FloatKindFromString(old.FloatKind().Text())
+	if gen.cfg.bfloatTypes[old.Offset()] {
+		// Substituted bfloat type (see preLexer).
+		typ.Kind = types.FloatKindBFloat
+	}
 	return typ, nil
 }
 
@@ -326,6 +334,8 @@ func (gen *generator) irVectorType(t types.Type, old *ast.VectorType) (types.Typ
 	}
 	// Vector length.
 	typ.Len = uintLit(old.Len())
+	// (optional) Scalable.
+	typ.Scalable = gen.cfg.scalableTypes[old.Offset()]
 	// Element type.
 	elem, err := gen.irType(old.Elem())
 	if err != nil {
@@ -357,6 +367,10 @@ func (gen *generator) irLabelType(t types.Type, old *ast.LabelType) (types.Type,
 // IR type correspoding to the AST type is created if t is nil, otherwise the
 // body of t is populated.
 func (gen *generator) irTokenType(t types.Type, old *ast.TokenType) (types.Type, error) {
+	if params, ok := gen.cfg.targetExtTypes[old.Offset()]; ok {
+		// Substituted target extension type (see preLexer).
+		return gen.irTargetExtType(t, params)
+	}
 	typ, ok := t.(*types.TokenType)
 	if t == nil {
 		typ = &types.TokenType{}
@@ -367,6 +381,49 @@ func (gen *generator) irTokenType(t types.Type, old *ast.TokenType) (types.Type,
 	return typ, nil
 }
 
+// --- [ Target extension types ] ----------------------------------------------
+
+// irTargetExtType translates the parameters of the target extension type (e.g.
+// `"spirv.Image", void, 1`), as extracted before parsing (see preLexer), into
+// an equivalent IR type. A new IR type correspoding to the target extension
+// type is created if t is nil, otherwise the body of t is populated.
+func (gen *generator) irTargetExtType(t types.Type, params string) (types.Type, error) {
+	typ, ok := t.(*types.TargetExtType)
+	if t == nil {
+		typ = &types.TargetExtType{}
+	} else if !ok {
+		panic(fmt.Errorf("invalid IR type for target extension type; expected *types.TargetExtType, got %T", t))
+	}
+	args := splitArgs(params)
+	// Target extension type name.
+	if !strings.HasPrefix(args[0], `"`) {
+		return nil, errors.Errorf("invalid name %q of target extension type; expected string literal", args[0])
+	}
+	typ.ExtName = unquote(args[0])
+	// Type parameters, followed by integer parameters.
+	i := 1
+	for ; i < len(args); i++ {
+		if _, err := strconv.ParseUint(args[i], 10, 64); err == nil {
+			break
+		}
+	}
+	if typeParams := args[1:i]; len(typeParams) > 0 {
+		ts, err := gen.irTypes(typeParams)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		typ.TypeParams = ts
+	}
+	for _, arg := range args[i:] {
+		x, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid integer parameter %q of target extension type %q; type parameters must precede integer parameters", arg, typ.ExtName)
+		}
+		typ.IntParams = append(typ.IntParams, x)
+	}
+	return typ, nil
+}
+
 // --- [ Metadata types ] ------------------------------------------------------
 
 // irMetadataType translates the AST metadata type into an equivalent IR type. A
@@ -500,6 +557,46 @@ func (gen *generator) irType(old ast.LlvmNode) (types.Type, error) {
 	return gen.irTypeDef(nil, old)
 }
 
+// irTypes returns the IR types corresponding to the given types in LLVM IR
+// assembly, as extracted before parsing (see preLexer).
+func (gen *generator) irTypes(typs []string) ([]types.Type, error) {
+	// Parse the types as the parameter types of a function declaration, to
+	// translate them in the context of the type definitions of the module. The
+	// types are pre-lexed separately, as they may contain constructs not
+	// supported by the grammar (e.g. `bfloat`).
+	cfg := &parseConfig{path: gen.cfg.path, content: fmt.Sprintf("declare void @f(%s)", strings.Join(typs, ", "))}
+	content, _, err := preLex(cfg)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tree, err := ast.Parse(cfg.path, content)
+	if err != nil {
+		return nil, errors.Errorf("invalid types %q", typs)
+	}
+	root := ast.ToLlvmNode(tree.Root()).(*ast.Module)
+	decl, ok := root.TopLevelEntities()[0].(*ast.FuncDecl)
+	if !ok {
+		return nil, errors.Errorf("invalid types %q", typs)
+	}
+	params := decl.Header().Params().Params()
+	if len(params) != len(typs) {
+		return nil, errors.Errorf("invalid types %q", typs)
+	}
+	// Translate the types using the substitutions of their own content.
+	orig := gen.cfg
+	gen.cfg = cfg
+	defer func() { gen.cfg = orig }()
+	var ts []types.Type
+	for _, param := range params {
+		t, err := gen.irType(param.Typ())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
 // getTypeName returns the identifier (without '%' prefix) of the given type
 // identifier.
 func getTypeName(ident ir.LocalIdent) string {
@@ -69,7 +69,8 @@ func (a *Alias) LLString() string {
 	// ContentType=Type ',' Aliasee=TypeConst
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "%s =", a.Ident())
-	if a.Linkage != enum.LinkageNone {
+	// External linkage is the default, and is therefore omitted.
+	if a.Linkage != enum.LinkageNone && a.Linkage != enum.LinkageExternal {
 		fmt.Fprintf(buf, " %s", a.Linkage)
 	}
 	if a.Preemption != enum.PreemptionNone {
@@ -96,3 +97,31 @@ func (a *Alias) LLString() string {
 	}
 	return buf.String()
 }
+
+// Resolve returns the ultimate target of the alias, as resolved through alias
+// chains (aliases of aliases) and pointer casts (bitcast and addrspacecast
+// constant expressions) of the aliasee. The returned constant is typically a
+// global variable or function, but may be a constant expression (e.g.
+// getelementptr) if the alias is not a plain alias of a global identifier. A nil
+// value is returned if the alias chain is cyclic.
+func (a *Alias) Resolve() constant.Constant {
+	visited := map[*Alias]bool{a: true}
+	c := a.Aliasee
+	for {
+		switch x := c.(type) {
+		case *Alias:
+			if visited[x] {
+				// Cyclic alias chain.
+				return nil
+			}
+			visited[x] = true
+			c = x.Aliasee
+		case *constant.ExprBitCast:
+			c = x.From
+		case *constant.ExprAddrSpaceCast:
+			c = x.From
+		default:
+			return c
+		}
+	}
+}
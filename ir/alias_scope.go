@@ -0,0 +1,145 @@
+package ir
+
+import (
+	"github.com/llir/llvm/ir/metadata"
+)
+
+// === [ Alias scopes ] ========================================================
+
+// AliasScopes returns the alias scopes of the load instruction, as specified
+// by its !alias.scope metadata attachment; or nil if not present.
+func (inst *InstLoad) AliasScopes() []*metadata.Tuple {
+	return scopeList(inst.Metadata, "alias.scope")
+}
+
+// NoAliasScopes returns the noalias scopes of the load instruction, as
+// specified by its !noalias metadata attachment; or nil if not present.
+func (inst *InstLoad) NoAliasScopes() []*metadata.Tuple {
+	return scopeList(inst.Metadata, "noalias")
+}
+
+// AliasScopes returns the alias scopes of the store instruction, as specified
+// by its !alias.scope metadata attachment; or nil if not present.
+func (inst *InstStore) AliasScopes() []*metadata.Tuple {
+	return scopeList(inst.Metadata, "alias.scope")
+}
+
+// NoAliasScopes returns the noalias scopes of the store instruction, as
+// specified by its !noalias metadata attachment; or nil if not present.
+func (inst *InstStore) NoAliasScopes() []*metadata.Tuple {
+	return scopeList(inst.Metadata, "noalias")
+}
+
+// ScopeDomain returns the alias scope domain of the given alias scope (e.g.
+// !0 of `!1 = distinct !{!1, !0, !"scope"}`), or nil if not present.
+func ScopeDomain(scope *metadata.Tuple) *metadata.Tuple {
+	if len(scope.Fields) < 2 {
+		return nil
+	}
+	domain, _ := scope.Fields[1].(*metadata.Tuple)
+	return domain
+}
+
+// NoAliasScopeDecl returns the alias scopes declared by the given call to the
+// @llvm.experimental.noalias.scope.decl intrinsic, or nil if the call is not a
+// call to the intrinsic.
+func NoAliasScopeDecl(call *InstCall) []*metadata.Tuple {
+	callee, ok := call.Callee.(*Func)
+	if !ok || callee.Name() != "llvm.experimental.noalias.scope.decl" || len(call.Args) != 1 {
+		return nil
+	}
+	arg, ok := call.Args[0].(*metadata.Value)
+	if !ok {
+		return nil
+	}
+	list, ok := arg.Value.(*metadata.Tuple)
+	if !ok {
+		return nil
+	}
+	return scopes(list)
+}
+
+// DisjointScopes reports whether the memory accesses of the instructions a and
+// b are known not to alias based on their alias scope metadata; i.e. whether
+// the alias scopes (!alias.scope) of either instruction are covered by the
+// noalias scopes (!noalias) of the other instruction.
+//
+// The alias scopes of an instruction are covered by a list of noalias scopes
+// if, for some alias scope domain, every alias scope of the instruction within
+// the domain is in the list of noalias scopes.
+func DisjointScopes(a, b Instruction) bool {
+	return !mayAliasInScopes(instScopes(a, "alias.scope"), instScopes(b, "noalias")) ||
+		!mayAliasInScopes(instScopes(b, "alias.scope"), instScopes(a, "noalias"))
+}
+
+// ### [ Helper functions ] ####################################################
+
+// instScopes returns the list of scopes of the given instruction, as specified
+// by the metadata attachment of the given name; or nil if not present.
+func instScopes(inst Instruction, name string) []*metadata.Tuple {
+	n, ok := inst.(interface {
+		MDAttachments() []*metadata.Attachment
+	})
+	if !ok {
+		return nil
+	}
+	return scopeList(n.MDAttachments(), name)
+}
+
+// scopeList returns the list of scopes specified by the metadata attachment of
+// the given name, or nil if not present.
+func scopeList(mds []*metadata.Attachment, name string) []*metadata.Tuple {
+	for _, md := range mds {
+		if md.Name != name {
+			continue
+		}
+		list, ok := md.Node.(*metadata.Tuple)
+		if !ok {
+			return nil
+		}
+		return scopes(list)
+	}
+	return nil
+}
+
+// scopes returns the scopes of the given list of scopes.
+func scopes(list *metadata.Tuple) []*metadata.Tuple {
+	var scopes []*metadata.Tuple
+	for _, field := range list.Fields {
+		if scope, ok := field.(*metadata.Tuple); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// mayAliasInScopes reports whether an access with the given alias scopes may
+// alias an access with the given noalias scopes.
+func mayAliasInScopes(aliasScopes, noAliasScopes []*metadata.Tuple) bool {
+	if len(aliasScopes) == 0 || len(noAliasScopes) == 0 {
+		return true
+	}
+	noAlias := make(map[*metadata.Tuple]bool)
+	var domains []*metadata.Tuple
+	for _, scope := range noAliasScopes {
+		noAlias[scope] = true
+		domains = append(domains, ScopeDomain(scope))
+	}
+	for _, domain := range domains {
+		covered, found := true, false
+		for _, scope := range aliasScopes {
+			if ScopeDomain(scope) != domain {
+				continue
+			}
+			found = true
+			if !noAlias[scope] {
+				covered = false
+				break
+			}
+		}
+		if found && covered {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestDisjointScopes(t *testing.T) {
+	const src = `
+declare void @llvm.experimental.noalias.scope.decl(metadata)
+
+define i32 @f(i32* %p, i32* %q) {
+entry:
+	call void @llvm.experimental.noalias.scope.decl(metadata !2)
+	%a = load i32, i32* %p, !alias.scope !2, !noalias !4
+	%b = load i32, i32* %q, !alias.scope !4, !noalias !2
+	%c = load i32, i32* %q
+	%d = load i32, i32* %q, !alias.scope !5
+	store i32 %a, i32* %q, !alias.scope !4
+	ret i32 %b
+}
+
+!0 = distinct !{!0, !"domain"}
+!1 = distinct !{!1, !0, !"scope p"}
+!2 = !{!1}
+!3 = distinct !{!3, !0, !"scope q"}
+!4 = !{!3}
+!5 = !{!1, !3}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	entry := m.Funcs[1].Blocks[0]
+	decl := entry.Insts[0].(*ir.InstCall)
+	a := entry.Insts[1].(*ir.InstLoad)
+	b := entry.Insts[2].(*ir.InstLoad)
+	c := entry.Insts[3].(*ir.InstLoad)
+	d := entry.Insts[4].(*ir.InstLoad)
+	store := entry.Insts[5].(*ir.InstStore)
+	scopeP := a.AliasScopes()[0]
+	if got := ir.NoAliasScopeDecl(decl); len(got) != 1 || got[0] != scopeP {
+		t.Errorf("declared scopes mismatch; expected [%v], got %v", scopeP, got)
+	}
+	if got := a.NoAliasScopes(); len(got) != 1 || got[0] != b.AliasScopes()[0] {
+		t.Errorf("noalias scopes mismatch; expected %v, got %v", b.AliasScopes(), got)
+	}
+	if domain := ir.ScopeDomain(scopeP); domain == nil || domain.LLString() != `distinct !{!0, !"domain"}` {
+		t.Errorf("scope domain mismatch; got %v", domain)
+	}
+	golden := []struct {
+		name string
+		a, b ir.Instruction
+		want bool
+	}{
+		{name: "%a, %b", a: a, b: b, want: true},
+		{name: "%b, %a", a: b, b: a, want: true},
+		{name: "%a, store", a: a, b: store, want: true},
+		// No scope metadata.
+		{name: "%a, %c", a: a, b: c, want: false},
+		// Scopes of %d not covered by the noalias scopes of %a.
+		{name: "%a, %d", a: a, b: d, want: false},
+		{name: "%b, store", a: b, b: store, want: false},
+	}
+	for _, g := range golden {
+		if got := ir.DisjointScopes(g.a, g.b); got != g.want {
+			t.Errorf("disjoint scopes mismatch of %s; expected %v, got %v", g.name, g.want, got)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestAliasResolve(t *testing.T) {
+	m := NewModule()
+	f := m.NewFunc("f", types.Void)
+	global := m.NewGlobalDef("g", constant.NewInt(types.I32, 0))
+	// Alias of bitcast of function.
+	a := m.NewAlias("a", constant.NewBitCast(f, types.NewPointer(types.NewFunc(types.Void, types.I32))))
+	// Alias of alias.
+	b := m.NewAlias("b", a)
+	// Alias of getelementptr.
+	gep := constant.NewGetElementPtr(global, constant.NewInt(types.I64, 0))
+	c := m.NewAlias("c", gep)
+	// Cyclic alias chain.
+	d := &Alias{Typ: types.I8Ptr}
+	e := &Alias{Aliasee: d, Typ: types.I8Ptr}
+	d.Aliasee = e
+	golden := []struct {
+		alias *Alias
+		want  constant.Constant
+	}{
+		{alias: a, want: f},
+		{alias: b, want: f},
+		{alias: c, want: gep},
+		{alias: d, want: nil},
+	}
+	for _, g := range golden {
+		if got := g.alias.Resolve(); got != g.want {
+			t.Errorf("resolved aliasee mismatch of alias %s; expected %v, got %v", g.alias.Ident(), g.want, got)
+		}
+	}
+	const want = "@a = alias void (i32), bitcast (void ()* @f to void (i32)*)"
+	if got := a.LLString(); got != want {
+		t.Errorf("alias mismatch; expected %q, got %q", want, got)
+	}
+}
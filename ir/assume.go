@@ -0,0 +1,85 @@
+package ir
+
+import (
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Assumptions ] =========================================================
+
+// Assumption is an assumption made by a call to the @llvm.assume intrinsic; as
+// specified by the asserted condition of the call and the facts carried by its
+// operand bundles (e.g. `call void @llvm.assume(i1 true) ["align"(i8* %p,
+// i64 16)]`).
+type Assumption struct {
+	// Call to @llvm.assume which makes the assumption.
+	Call *InstCall
+	// Parent basic block of Call.
+	Block *Block
+	// Asserted condition of the assumption; of type i1. The condition is
+	// constant true for assumptions only consisting of operand bundle facts.
+	Cond value.Value
+	// Facts of the assumption, as carried by operand bundles; in order of
+	// occurrence.
+	Facts []AssumeFact
+}
+
+// AssumeFact is a fact carried by an operand bundle of a call to the
+// @llvm.assume intrinsic; e.g. `"align"(i8* %p, i64 16)` asserts that %p is
+// aligned to 16 bytes.
+type AssumeFact struct {
+	// Attribute kind of the fact (e.g. "align", "nonnull" or
+	// "dereferenceable"); as specified by the operand bundle tag.
+	Kind string
+	// (optional) Value the fact is about; nil if not present.
+	On value.Value
+	// (optional) Arguments of the fact (e.g. the alignment in bytes of "align"
+	// facts); nil if not present.
+	Args []value.Value
+}
+
+// Assumptions returns the assumptions of the function, as made by calls to the
+// @llvm.assume intrinsic; in the order of the instructions of the function.
+// Operand bundles with the "ignore" tag are skipped, as they carry no facts.
+func (f *Func) Assumptions() []Assumption {
+	var assumptions []Assumption
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			call, ok := inst.(*InstCall)
+			if !ok || !isAssumeCall(call) {
+				continue
+			}
+			a := Assumption{
+				Call:  call,
+				Block: block,
+				Cond:  call.Args[0],
+			}
+			for _, bundle := range call.OperandBundles {
+				if bundle.Tag == "ignore" {
+					continue
+				}
+				fact := AssumeFact{Kind: bundle.Tag}
+				if len(bundle.Inputs) > 0 {
+					fact.On = bundle.Inputs[0]
+				}
+				if len(bundle.Inputs) > 1 {
+					fact.Args = bundle.Inputs[1:]
+				}
+				a.Facts = append(a.Facts, fact)
+			}
+			assumptions = append(assumptions, a)
+		}
+	}
+	return assumptions
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isAssumeCall reports whether the given call is a call to the @llvm.assume
+// intrinsic.
+func isAssumeCall(call *InstCall) bool {
+	callee, ok := call.Callee.(*Func)
+	if !ok {
+		return false
+	}
+	return callee.Name() == "llvm.assume" && len(call.Args) == 1
+}
@@ -0,0 +1,62 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestFuncAssumptions(t *testing.T) {
+	const src = `
+declare void @llvm.assume(i1)
+
+define void @f(i8* %p, i32 %x) {
+entry:
+	call void @llvm.assume(i1 true) [ "align"(i8* %p, i64 16), "ignore"(i8* %p), "nonnull"(i8* %p) ]
+	%cond = icmp sgt i32 %x, 0
+	call void @llvm.assume(i1 %cond)
+	ret void
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[1]
+	entry := f.Blocks[0]
+	p := f.Params[0]
+	assumptions := f.Assumptions()
+	if len(assumptions) != 2 {
+		t.Fatalf("number of assumptions mismatch; expected 2, got %d", len(assumptions))
+	}
+	// Operand bundle facts.
+	a := assumptions[0]
+	if a.Call != entry.Insts[0] || a.Block != entry {
+		t.Errorf("assume call mismatch; expected %v, got %v", entry.Insts[0], a.Call)
+	}
+	if got, want := a.Cond.String(), "i1 true"; got != want {
+		t.Errorf("condition mismatch; expected %q, got %q", want, got)
+	}
+	if len(a.Facts) != 2 {
+		t.Fatalf("number of facts mismatch; expected 2, got %d", len(a.Facts))
+	}
+	align := a.Facts[0]
+	if align.Kind != "align" || align.On != p || len(align.Args) != 1 {
+		t.Errorf("align fact mismatch; got %+v", align)
+	} else if got, want := align.Args[0].String(), "i64 16"; got != want {
+		t.Errorf("alignment mismatch; expected %q, got %q", want, got)
+	}
+	nonnull := a.Facts[1]
+	if nonnull.Kind != "nonnull" || nonnull.On != p || nonnull.Args != nil {
+		t.Errorf("nonnull fact mismatch; got %+v", nonnull)
+	}
+	// Asserted condition.
+	b := assumptions[1]
+	if b.Cond != entry.Insts[1].(value.Value) {
+		t.Errorf("condition mismatch; expected %v, got %v", entry.Insts[1], b.Cond)
+	}
+	if len(b.Facts) != 0 {
+		t.Errorf("unexpected facts; %v", b.Facts)
+	}
+}
@@ -0,0 +1,156 @@
+package ir
+
+import "sort"
+
+// === [ Data dependency order ] ===============================================
+
+// DataDependencyOrder returns the instructions of the basic block in a
+// topological order of their dependencies, for use by instruction schedulers.
+//
+// An instruction depends on the instructions of the basic block used as its
+// operands, and on the memory-ordering constraints of the basic block. Memory
+// reads (non-volatile and non-atomic loads, and integer division and remainder
+// instructions which may trap) may not be reordered with memory writes (stores,
+// volatile and atomic loads, atomic instructions, fences, calls, va_arg and
+// alloca), and memory writes may not be reordered with each other.
+// Memory reads may be reordered with each other, and pure instructions may be
+// reordered subject only to the availability of their operands.
+//
+// Leading phi instructions and exception handling pads (landingpad, catchpad
+// and cleanuppad) are kept in place at the start of the basic block. The
+// remaining instructions are ordered by their depth in the dependency graph
+// (i.e. the length of the longest chain of dependencies of the instruction),
+// with ties broken by the original order of the instructions. As such,
+// instructions are scheduled as early as possible.
+//
+// Debug records do not constrain the order of instructions; they are kept
+// immediately before the instruction they are attached to (i.e. the instruction
+// following them), or at the end of the basic block if trailing.
+func (block *Block) DataDependencyOrder() []Instruction {
+	// Leading phi instructions and exception handling pads.
+	n := 0
+	for i, inst := range block.Insts {
+		if _, ok := inst.(*DbgRecord); ok {
+			continue
+		}
+		if !isLeadingInst(inst) {
+			break
+		}
+		n = i + 1
+	}
+	order := make([]Instruction, 0, len(block.Insts))
+	order = append(order, block.Insts[:n]...)
+	// Instructions to order, excluding debug records.
+	var insts []Instruction
+	// records maps from instruction to the debug records attached to it.
+	records := make(map[Instruction][]Instruction)
+	// Debug records not yet attached to an instruction.
+	var pending []Instruction
+	for _, inst := range block.Insts[n:] {
+		if _, ok := inst.(*DbgRecord); ok {
+			pending = append(pending, inst)
+			continue
+		}
+		records[inst] = pending
+		pending = nil
+		insts = append(insts, inst)
+	}
+	// index maps from instruction to index in insts.
+	index := make(map[Instruction]int)
+	for i, inst := range insts {
+		index[inst] = i
+	}
+	depth := make([]int, len(insts))
+	// Index of last memory write; or -1 if not present.
+	lastWrite := -1
+	// Indices of memory reads since last memory write.
+	var reads []int
+	for i, inst := range insts {
+		var deps []int
+		for _, use := range inst.Operands() {
+			if op, ok := (*use).(Instruction); ok {
+				if j, ok := index[op]; ok && j < i {
+					deps = append(deps, j)
+				}
+			}
+		}
+		switch memoryAccess(inst) {
+		case memRead:
+			if lastWrite != -1 {
+				deps = append(deps, lastWrite)
+			}
+			reads = append(reads, i)
+		case memWrite:
+			if lastWrite != -1 {
+				deps = append(deps, lastWrite)
+			}
+			deps = append(deps, reads...)
+			lastWrite = i
+			reads = nil
+		}
+		for _, j := range deps {
+			if depth[j]+1 > depth[i] {
+				depth[i] = depth[j] + 1
+			}
+		}
+	}
+	rest := make([]int, len(insts))
+	for i := range rest {
+		rest[i] = i
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return depth[rest[i]] < depth[rest[j]]
+	})
+	for _, i := range rest {
+		order = append(order, records[insts[i]]...)
+		order = append(order, insts[i])
+	}
+	// Trailing debug records.
+	order = append(order, pending...)
+	return order
+}
+
+// ### [ Helper functions ] ####################################################
+
+// memAccess specifies the memory-ordering constraints of an instruction.
+type memAccess uint8
+
+// Memory-ordering constraints.
+const (
+	// Pure instruction; may be freely reordered.
+	memNone memAccess = iota
+	// Memory read; may be reordered with other memory reads.
+	memRead
+	// Memory write; may not be reordered with memory reads or writes.
+	memWrite
+)
+
+// memoryAccess returns the memory-ordering constraints of the given
+// instruction.
+func memoryAccess(inst Instruction) memAccess {
+	switch inst := inst.(type) {
+	case *InstLoad:
+		if inst.Volatile || inst.Atomic {
+			return memWrite
+		}
+		return memRead
+	case *InstUDiv, *InstSDiv, *InstURem, *InstSRem:
+		// Integer division may trap, and may therefore not be hoisted above
+		// instructions which may not return (e.g. calls).
+		return memRead
+	case *InstStore, *InstFence, *InstCmpXchg, *InstAtomicRMW, *InstCall, *InstVAArg, *InstAlloca:
+		return memWrite
+	}
+	return memNone
+}
+
+// isLeadingInst reports whether the given instruction must be placed at the
+// start of its parent basic block; i.e. phi instructions and exception handling
+// pads.
+func isLeadingInst(inst Instruction) bool {
+	switch inst.(type) {
+	case *InstPhi, *InstLandingPad, *InstCatchPad, *InstCleanupPad:
+		return true
+	}
+	return false
+}
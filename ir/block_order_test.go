@@ -0,0 +1,67 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestBlockDataDependencyOrder(t *testing.T) {
+	const src = `
+declare void @g()
+
+define i32 @f(i32* %p, i32* %q, i32 %x) {
+entry:
+	br label %loop
+
+loop:
+	%i = phi i32 [ 0, %entry ], [ %i.next, %loop ]
+	%a = load i32, i32* %p
+	%b = add i32 %a, 1
+	store i32 %x, i32* %q
+	%c = mul i32 %x, 2
+	%d = load i32, i32* %q
+	call void @g()
+	%e = sdiv i32 %x, 3
+	%i.next = add i32 %i, 1
+	%cond = icmp eq i32 %i.next, 10
+	br i1 %cond, label %exit, label %loop
+
+exit:
+	ret i32 %b
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[1]
+	want := []string{
+		// Leading phi instructions are kept in place.
+		"%i = phi i32 [ 0, %entry ], [ %i.next, %loop ]",
+		// Depth 0.
+		"%a = load i32, i32* %p",
+		"%c = mul i32 %x, 2",
+		"%i.next = add i32 %i, 1",
+		// Depth 1; the store may not be moved before the prior (possibly
+		// aliasing) load.
+		"%b = add i32 %a, 1",
+		"store i32 %x, i32* %q",
+		"%cond = icmp eq i32 %i.next, 10",
+		// Depth 2.
+		"%d = load i32, i32* %q",
+		// Depth 3.
+		"call void @g()",
+		// Depth 4; division may trap, and is not hoisted above the call.
+		"%e = sdiv i32 %x, 3",
+	}
+	got := f.Blocks[1].DataDependencyOrder()
+	if len(got) != len(want) {
+		t.Fatalf("instruction count mismatch; expected %d, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if s := got[i].LLString(); s != want[i] {
+			t.Errorf("instruction %d mismatch; expected %q, got %q", i, want[i], s)
+		}
+	}
+}
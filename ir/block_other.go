@@ -49,6 +49,16 @@ func (block *Block) NewSelect(cond, x, y value.Value) *InstSelect {
 	return inst
 }
 
+// ~~~ [ freeze ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+// NewFreeze appends a new freeze instruction to the basic block based on the
+// given operand.
+func (block *Block) NewFreeze(x value.Value) *InstFreeze {
+	inst := NewFreeze(x)
+	block.Insts = append(block.Insts, inst)
+	return inst
+}
+
 // ~~~ [ call ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // NewCall appends a new call instruction to the basic block based on the given
@@ -73,6 +73,19 @@ func (block *Block) NewInvoke(invokee value.Value, args []value.Value, normal, e
 	return term
 }
 
+// ~~~ [ callbr ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+// NewCallBr sets the terminator of the basic block to a new callbr terminator
+// based on the given callee, function arguments and control flow return points
+// for default and indirect execution.
+//
+// TODO: specify the set of underlying types of callee.
+func (block *Block) NewCallBr(callee value.Value, args []value.Value, normal *Block, others ...*Block) *TermCallBr {
+	term := NewCallBr(callee, args, normal, others...)
+	block.Term = term
+	return term
+}
+
 // ~~~ [ resume ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // NewResume sets the terminator of the basic block to a new resume terminator
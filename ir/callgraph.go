@@ -0,0 +1,254 @@
+package ir
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+// ExternalNode is the name of the synthetic external node of call graphs,
+// which represents the unknown callees of indirect calls.
+const ExternalNode = "external"
+
+// === [ Call graph ] ==========================================================
+
+// CallGraph is the direct-call graph of a module; i.e. the graph of functions
+// with edges from callers to the functions they call directly (through call,
+// invoke and callbr instructions).
+type CallGraph struct {
+	// Functions of the module, in order of occurrence.
+	Funcs []*Func
+	// Callees maps from function to the functions called directly by the
+	// function; without duplicates and in order of first call.
+	Callees map[*Func][]*Func
+	// Indirect specifies the functions with indirect calls (e.g. through
+	// function pointers); i.e. calls to the synthetic external node.
+	Indirect map[*Func]bool
+}
+
+// CallGraph returns the direct-call graph of the module. Calls through bitcast
+// constant expressions and aliases of functions are considered direct calls.
+// Indirect calls (e.g. through function pointers) are recorded in Indirect,
+// and calls to inline assembly are not part of the call graph.
+func (m *Module) CallGraph() *CallGraph {
+	cg := &CallGraph{
+		Funcs:    m.Funcs,
+		Callees:  make(map[*Func][]*Func),
+		Indirect: make(map[*Func]bool),
+	}
+	for _, f := range m.Funcs {
+		seen := make(map[*Func]bool)
+		add := func(callee value.Value) {
+			g := calledFunc(callee)
+			if g == nil {
+				if _, ok := callee.(*InlineAsm); !ok {
+					cg.Indirect[f] = true
+				}
+				return
+			}
+			if seen[g] {
+				return
+			}
+			seen[g] = true
+			cg.Callees[f] = append(cg.Callees[f], g)
+		}
+		for _, block := range f.Blocks {
+			for _, inst := range block.Insts {
+				if call, ok := inst.(*InstCall); ok {
+					add(call.Callee)
+				}
+			}
+			switch term := block.Term.(type) {
+			case *TermInvoke:
+				add(term.Invokee)
+			case *TermCallBr:
+				add(term.Callee)
+			}
+		}
+	}
+	return cg
+}
+
+// SCCs returns the strongly connected components of the call graph, in reverse
+// topological order; i.e. callees before callers. The functions of each
+// strongly connected component are in order of occurrence in the module.
+//
+// The strongly connected components are computed using Tarjan's algorithm.
+func (cg *CallGraph) SCCs() [][]*Func {
+	order := make(map[*Func]int)
+	for i, f := range cg.Funcs {
+		order[f] = i
+	}
+	var (
+		sccs    [][]*Func
+		stack   []*Func
+		onStack = make(map[*Func]bool)
+		index   = make(map[*Func]int)
+		lowlink = make(map[*Func]int)
+	)
+	var visit func(f *Func)
+	visit = func(f *Func) {
+		index[f] = len(index)
+		lowlink[f] = index[f]
+		stack = append(stack, f)
+		onStack[f] = true
+		for _, g := range cg.Callees[f] {
+			if _, ok := index[g]; !ok {
+				visit(g)
+				if lowlink[g] < lowlink[f] {
+					lowlink[f] = lowlink[g]
+				}
+			} else if onStack[g] && index[g] < lowlink[f] {
+				lowlink[f] = index[g]
+			}
+		}
+		if lowlink[f] != index[f] {
+			return
+		}
+		// f is the root of a strongly connected component.
+		var scc []*Func
+		for {
+			g := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[g] = false
+			scc = append(scc, g)
+			if g == f {
+				break
+			}
+		}
+		// Sort functions in order of occurrence (insertion sort, as strongly
+		// connected components are typically small).
+		for i := 1; i < len(scc); i++ {
+			for j := i; j > 0 && order[scc[j]] < order[scc[j-1]]; j-- {
+				scc[j], scc[j-1] = scc[j-1], scc[j]
+			}
+		}
+		sccs = append(sccs, scc)
+	}
+	for _, f := range cg.Funcs {
+		if _, ok := index[f]; !ok {
+			visit(f)
+		}
+	}
+	return sccs
+}
+
+// RecursiveFunctions returns the set of recursive functions of the module;
+// i.e. the functions part of a cycle in the direct-call graph of the module,
+// either calling themselves directly (self-recursive) or through other
+// functions (mutually recursive).
+func (m *Module) RecursiveFunctions() map[*Func]bool {
+	cg := m.CallGraph()
+	recursive := make(map[*Func]bool)
+	for _, scc := range cg.SCCs() {
+		if len(scc) > 1 {
+			for _, f := range scc {
+				recursive[f] = true
+			}
+			continue
+		}
+		// Self-recursive function.
+		f := scc[0]
+		for _, callee := range cg.Callees[f] {
+			if callee == f {
+				recursive[f] = true
+				break
+			}
+		}
+	}
+	return recursive
+}
+
+// Adjacency returns the adjacency list of the call graph; a map from the
+// identifier of each function (e.g. "@main") to the identifiers of its callees,
+// in order of first call. Indirect calls are represented by a trailing edge to
+// the synthetic external node (see ExternalNode).
+func (cg *CallGraph) Adjacency() map[string][]string {
+	adj := make(map[string][]string)
+	for _, f := range cg.Funcs {
+		callees := []string{}
+		for _, g := range cg.Callees[f] {
+			callees = append(callees, g.Ident())
+		}
+		if cg.Indirect[f] {
+			callees = append(callees, ExternalNode)
+		}
+		adj[f.Ident()] = callees
+	}
+	return adj
+}
+
+// WriteCallGraphDOT writes the direct-call graph of the module (see CallGraph)
+// to w, in Graphviz DOT format.
+//
+// Nodes and edges are written in order of occurrence of the functions of the
+// module. Recursive edges (edges within a cycle of the call graph, including
+// self-recursive calls) are drawn in red. Indirect calls are drawn as dashed
+// edges to the synthetic external node, which is drawn as a dashed box.
+func (m *Module) WriteCallGraphDOT(w io.Writer) error {
+	cg := m.CallGraph()
+	// Strongly connected component of each function; edges within a strongly
+	// connected component are part of a cycle.
+	scc := make(map[*Func]int)
+	for i, fs := range cg.SCCs() {
+		for _, f := range fs {
+			scc[f] = i
+		}
+	}
+	buf := &strings.Builder{}
+	buf.WriteString("digraph callgraph {\n")
+	if len(cg.Indirect) > 0 {
+		fmt.Fprintf(buf, "\t%q [shape=box, style=dashed]\n", ExternalNode)
+	}
+	for _, f := range cg.Funcs {
+		fmt.Fprintf(buf, "\t%q\n", f.Ident())
+		for _, g := range cg.Callees[f] {
+			if scc[f] == scc[g] {
+				fmt.Fprintf(buf, "\t%q -> %q [color=red]\n", f.Ident(), g.Ident())
+				continue
+			}
+			fmt.Fprintf(buf, "\t%q -> %q\n", f.Ident(), g.Ident())
+		}
+		if cg.Indirect[f] {
+			fmt.Fprintf(buf, "\t%q -> %q [style=dashed]\n", f.Ident(), ExternalNode)
+		}
+	}
+	buf.WriteString("}\n")
+	if _, err := io.WriteString(w, buf.String()); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// calledFunc returns the function called through the given callee operand, or
+// nil if the callee is not a function (e.g. a function pointer or inline
+// assembly). Bitcast constant expressions and aliases are looked through.
+func calledFunc(callee value.Value) *Func {
+	// Visited aliases; used to guard against cyclic aliases.
+	var seen map[*Alias]bool
+	for {
+		switch c := callee.(type) {
+		case *Func:
+			return c
+		case *constant.ExprBitCast:
+			callee = c.From
+		case *Alias:
+			if seen[c] {
+				return nil
+			}
+			if seen == nil {
+				seen = make(map[*Alias]bool)
+			}
+			seen[c] = true
+			callee = c.Aliasee
+		default:
+			return nil
+		}
+	}
+}
@@ -5,6 +5,7 @@ package ir_test
 import (
 	"fmt"
 	"strings"
+	"testing"
 
 	"github.com/llir/llvm/asm"
 	"github.com/llir/llvm/ir"
@@ -69,3 +70,125 @@ func genCallgraph(m *ir.Module) string {
 	buf.WriteString("}")
 	return buf.String()
 }
+
+func TestModuleRecursiveFunctions(t *testing.T) {
+	const src = `
+@odd.alias = alias void (i32), void (i32)* @odd
+
+declare void @ext()
+
+define void @even(i32 %n) {
+	call void @odd.alias(i32 %n)
+	ret void
+}
+
+define void @odd(i32 %n) {
+	call void @even(i32 %n)
+	ret void
+}
+
+define void @fact(i32 %n) {
+	call void @fact(i32 %n)
+	call void @ext()
+	ret void
+}
+
+define void @main(void ()* %fp) {
+	call void @even(i32 1)
+	call void @fact(i32 5)
+	call void %fp()
+	ret void
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	recursive := m.RecursiveFunctions()
+	golden := []struct {
+		f    *ir.Func
+		want bool
+	}{
+		{f: m.Funcs[0], want: false}, // @ext
+		{f: m.Funcs[1], want: true},  // @even
+		{f: m.Funcs[2], want: true},  // @odd
+		{f: m.Funcs[3], want: true},  // @fact
+		{f: m.Funcs[4], want: false}, // @main
+	}
+	for _, g := range golden {
+		if got := recursive[g.f]; got != g.want {
+			t.Errorf("recursive mismatch of %q; expected %v, got %v", g.f.Ident(), g.want, got)
+		}
+	}
+	// Strongly connected components in reverse topological order.
+	var sccs []string
+	for _, scc := range m.CallGraph().SCCs() {
+		var names []string
+		for _, f := range scc {
+			names = append(names, f.Ident())
+		}
+		sccs = append(sccs, strings.Join(names, " "))
+	}
+	const want = "[@ext] [@even @odd] [@fact] [@main]"
+	if got := fmt.Sprintf("[%s]", strings.Join(sccs, "] [")); got != want {
+		t.Errorf("strongly connected components mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestModuleWriteCallGraphDOT(t *testing.T) {
+	const src = `
+declare void @ext()
+
+define void @fact(i32 %n) {
+	call void @fact(i32 %n)
+	call void @ext()
+	ret void
+}
+
+define void @main(void ()* %fp) {
+	call void @fact(i32 5)
+	call void %fp()
+	call void asm "nop", ""()
+	ret void
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	buf := &strings.Builder{}
+	if err := m.WriteCallGraphDOT(buf); err != nil {
+		t.Fatalf("unable to write call graph; %+v", err)
+	}
+	const want = `digraph callgraph {
+	"external" [shape=box, style=dashed]
+	"@ext"
+	"@fact"
+	"@fact" -> "@fact" [color=red]
+	"@fact" -> "@ext"
+	"@main"
+	"@main" -> "@fact"
+	"@main" -> "external" [style=dashed]
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("call graph mismatch; expected `%s`, got `%s`", want, got)
+	}
+	adj := m.CallGraph().Adjacency()
+	golden := []struct {
+		f    string
+		want string
+	}{
+		{f: "@ext", want: ""},
+		{f: "@fact", want: "@fact @ext"},
+		{f: "@main", want: "@fact external"},
+	}
+	if len(adj) != len(golden) {
+		t.Errorf("adjacency list length mismatch; expected %d, got %d", len(golden), len(adj))
+	}
+	for _, g := range golden {
+		if got := strings.Join(adj[g.f], " "); got != g.want {
+			t.Errorf("callees mismatch of %q; expected %q, got %q", g.f, g.want, got)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package ir
+
+// === [ Control flow graph ] ==================================================
+
+// ReversePostOrder returns the basic blocks of the function reachable from the
+// entry basic block, in reverse post-order of a depth-first traversal of the
+// control flow graph. The entry basic block is the first block of the returned
+// order. Basic blocks unreachable from the entry basic block are not included.
+func (f *Func) ReversePostOrder() []*Block {
+	if len(f.Blocks) == 0 {
+		return nil
+	}
+	var post []*Block
+	visited := make(map[*Block]bool)
+	// Iterative depth-first traversal, to handle large functions without deep
+	// recursion.
+	type frame struct {
+		block *Block
+		succs []*Block
+	}
+	entry := f.Blocks[0]
+	visited[entry] = true
+	stack := []*frame{{block: entry, succs: succs(entry)}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if len(top.succs) == 0 {
+			post = append(post, top.block)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		succ := top.succs[0]
+		top.succs = top.succs[1:]
+		if visited[succ] {
+			continue
+		}
+		visited[succ] = true
+		stack = append(stack, &frame{block: succ, succs: succs(succ)})
+	}
+	// Reverse post-order.
+	for i, j := 0, len(post)-1; i < j; i, j = i+1, j-1 {
+		post[i], post[j] = post[j], post[i]
+	}
+	return post
+}
+
+// Predecessors returns a mapping from basic block to predecessor basic blocks
+// of the function. Predecessors are listed in the order of the basic blocks of
+// the function, and a predecessor is listed once for each control flow edge to
+// the basic block.
+func (f *Func) Predecessors() map[*Block][]*Block {
+	preds := make(map[*Block][]*Block)
+	for _, block := range f.Blocks {
+		for _, succ := range succs(block) {
+			preds[succ] = append(preds[succ], block)
+		}
+	}
+	return preds
+}
+
+// ReorderBlocksRPO reorders the basic blocks of the function into reverse
+// post-order of the control flow graph, keeping the entry basic block first.
+// Basic blocks unreachable from the entry basic block are placed last, in their
+// original order.
+//
+// Reordering does not change the semantics of the function, as basic blocks
+// are referred to by pointer. Unnamed basic blocks and local variables are
+// renumbered in the new order when printed.
+func (f *Func) ReorderBlocksRPO() {
+	rpo := f.ReversePostOrder()
+	reachable := make(map[*Block]bool)
+	for _, block := range rpo {
+		reachable[block] = true
+	}
+	blocks := make([]*Block, 0, len(f.Blocks))
+	blocks = append(blocks, rpo...)
+	for _, block := range f.Blocks {
+		if !reachable[block] {
+			blocks = append(blocks, block)
+		}
+	}
+	f.Blocks = blocks
+	// Reset IDs of unnamed basic blocks and local variables, to have them
+	// reassigned in the new order.
+	for _, block := range f.Blocks {
+		resetID(block)
+		for _, inst := range block.Insts {
+			resetID(inst)
+		}
+		resetID(block.Term)
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// succs returns the successor basic blocks of the given basic block. A nil
+// slice is returned for basic blocks without terminators.
+func succs(block *Block) []*Block {
+	if block.Term == nil {
+		return nil
+	}
+	return block.Term.Succs()
+}
+
+// resetID resets the ID of the given value if it is an unnamed local variable.
+func resetID(v interface{}) {
+	if n, ok := v.(local); ok && n.IsUnnamed() {
+		n.SetID(0)
+	}
+}
@@ -0,0 +1,58 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestReorderBlocksRPO(t *testing.T) {
+	const src = `
+define i32 @f(i1 %c) {
+; <label>:0
+	br i1 %c, label %3, label %2
+
+; <label>:1
+	ret i32 0
+
+; <label>:2
+	br label %4
+
+; <label>:3
+	br label %4
+
+; <label>:4
+	%5 = phi i32 [ 1, %2 ], [ 2, %3 ]
+	ret i32 %5
+}`
+	const want = `define i32 @f(i1 %c) {
+; <label>:0
+	br i1 %c, label %2, label %1
+
+; <label>:1
+	br label %3
+
+; <label>:2
+	br label %3
+
+; <label>:3
+	%4 = phi i32 [ 1, %1 ], [ 2, %2 ]
+	ret i32 %4
+
+; <label>:5
+	ret i32 0
+}`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	f.ReorderBlocksRPO()
+	if got := f.LLString(); got != want {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+	}
+	// The reordered function is re-parseable.
+	if _, err := asm.ParseString("<stdin>", m.String()); err != nil {
+		t.Errorf("unable to parse reordered module; %+v", err)
+	}
+}
@@ -0,0 +1,541 @@
+package ir
+
+import (
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Cloning of instructions ] =============================================
+
+// --- [ Unary instructions ] --------------------------------------------------
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFNeg) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.FastMathFlags = append([]enum.FastMathFlag(nil), inst.FastMathFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// --- [ Binary instructions ] -------------------------------------------------
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstAdd) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.OverflowFlags = append([]enum.OverflowFlag(nil), inst.OverflowFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFAdd) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.FastMathFlags = append([]enum.FastMathFlag(nil), inst.FastMathFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstSub) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.OverflowFlags = append([]enum.OverflowFlag(nil), inst.OverflowFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFSub) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.FastMathFlags = append([]enum.FastMathFlag(nil), inst.FastMathFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstMul) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.OverflowFlags = append([]enum.OverflowFlag(nil), inst.OverflowFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFMul) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.FastMathFlags = append([]enum.FastMathFlag(nil), inst.FastMathFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstUDiv) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstSDiv) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFDiv) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.FastMathFlags = append([]enum.FastMathFlag(nil), inst.FastMathFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstURem) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstSRem) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFRem) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.FastMathFlags = append([]enum.FastMathFlag(nil), inst.FastMathFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// --- [ Bitwise instructions ] ------------------------------------------------
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstShl) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.OverflowFlags = append([]enum.OverflowFlag(nil), inst.OverflowFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstLShr) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstAShr) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstAnd) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstOr) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstXor) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// --- [ Vector instructions ] -------------------------------------------------
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstExtractElement) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstInsertElement) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstShuffleVector) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// --- [ Aggregate instructions ] ----------------------------------------------
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstExtractValue) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Indices = append([]uint64(nil), inst.Indices...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstInsertValue) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Indices = append([]uint64(nil), inst.Indices...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// --- [ Memory instructions ] -------------------------------------------------
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstAlloca) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstLoad) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction.
+func (inst *InstStore) Clone() Instruction {
+	c := *inst
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction.
+func (inst *InstFence) Clone() Instruction {
+	c := *inst
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstCmpXchg) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstAtomicRMW) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstGetElementPtr) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Indices = append([]value.Value(nil), inst.Indices...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// --- [ Conversion instructions ] ---------------------------------------------
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstTrunc) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstZExt) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstSExt) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFPTrunc) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFPExt) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFPToUI) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFPToSI) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstUIToFP) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstSIToFP) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstPtrToInt) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstIntToPtr) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstBitCast) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstAddrSpaceCast) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// --- [ Other instructions ] --------------------------------------------------
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstICmp) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFCmp) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.FastMathFlags = append([]enum.FastMathFlag(nil), inst.FastMathFlags...)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstPhi) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Incs = cloneIncs(inst.Incs)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstSelect) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstFreeze) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstCall) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Args = cloneArgs(inst.Args)
+	c.FastMathFlags = append([]enum.FastMathFlag(nil), inst.FastMathFlags...)
+	c.ReturnAttrs = append([]ReturnAttribute(nil), inst.ReturnAttrs...)
+	c.FuncAttrs = append([]FuncAttribute(nil), inst.FuncAttrs...)
+	c.OperandBundles = cloneOperandBundles(inst.OperandBundles)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstVAArg) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstLandingPad) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Clauses = cloneClauses(inst.Clauses)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstCatchPad) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Args = cloneArgs(inst.Args)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// Clone returns a copy of the instruction with an empty name.
+func (inst *InstCleanupPad) Clone() Instruction {
+	c := *inst
+	c.LocalIdent = LocalIdent{}
+	c.Args = cloneArgs(inst.Args)
+	c.Metadata = append(Metadata(nil), inst.Metadata...)
+	return &c
+}
+
+// --- [ Debug records ] -------------------------------------------------------
+
+// Clone returns a copy of the debug record.
+func (r *DbgRecord) Clone() Instruction {
+	c := *r
+	return &c
+}
+
+// ### [ Helper functions ] ####################################################
+
+// cloneArgs returns a copy of the given function arguments, where arguments
+// with parameter attributes (*ir.Arg) are copied as well.
+func cloneArgs(args []value.Value) []value.Value {
+	if args == nil {
+		return nil
+	}
+	cs := make([]value.Value, len(args))
+	for i, arg := range args {
+		if a, ok := arg.(*Arg); ok {
+			arg = &Arg{Value: a.Value, Attrs: append([]ParamAttribute(nil), a.Attrs...)}
+		}
+		cs[i] = arg
+	}
+	return cs
+}
+
+// cloneIncs returns a copy of the given incoming values.
+func cloneIncs(incs []*Incoming) []*Incoming {
+	if incs == nil {
+		return nil
+	}
+	cs := make([]*Incoming, len(incs))
+	for i, inc := range incs {
+		c := *inc
+		cs[i] = &c
+	}
+	return cs
+}
+
+// cloneClauses returns a copy of the given landingpad clauses.
+func cloneClauses(clauses []*Clause) []*Clause {
+	if clauses == nil {
+		return nil
+	}
+	cs := make([]*Clause, len(clauses))
+	for i, clause := range clauses {
+		c := *clause
+		cs[i] = &c
+	}
+	return cs
+}
+
+// cloneOperandBundles returns a copy of the given operand bundles.
+func cloneOperandBundles(bundles []*OperandBundle) []*OperandBundle {
+	if bundles == nil {
+		return nil
+	}
+	cs := make([]*OperandBundle, len(bundles))
+	for i, bundle := range bundles {
+		cs[i] = &OperandBundle{Tag: bundle.Tag, Inputs: append([]value.Value(nil), bundle.Inputs...)}
+	}
+	return cs
+}
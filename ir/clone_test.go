@@ -0,0 +1,49 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestInstClone(t *testing.T) {
+	x := NewParam("x", types.I64)
+	y := NewParam("y", types.I64)
+	f := NewFunc("f", types.I64, x, y)
+	entry := f.NewBlock("entry")
+	add := entry.NewAdd(x, y)
+	add.SetName("sum")
+	add.OverflowFlags = []enum.OverflowFlag{enum.OverflowFlagNSW}
+	clone, ok := add.Clone().(*InstAdd)
+	if !ok {
+		t.Fatalf("clone type mismatch; expected *ir.InstAdd, got %T", add.Clone())
+	}
+	if !clone.IsUnnamed() {
+		t.Errorf("expected unnamed clone, got %q", clone.Name())
+	}
+	if !clone.Type().Equal(types.I64) {
+		t.Errorf("clone type mismatch; expected %v, got %v", types.I64, clone.Type())
+	}
+	if len(clone.OverflowFlags) != 1 || clone.OverflowFlags[0] != enum.OverflowFlagNSW {
+		t.Errorf("clone overflow flags mismatch; expected [nsw], got %v", clone.OverflowFlags)
+	}
+	// Remap operands of the clone, leaving the original unchanged.
+	*clone.Operands()[1] = constant.NewInt(types.I64, 1)
+	clone.OverflowFlags[0] = enum.OverflowFlagNUW
+	clone.SetName("inc")
+	if got, want := clone.LLString(), "%inc = add nuw i64 %x, 1"; got != want {
+		t.Errorf("clone mismatch; expected %q, got %q", want, got)
+	}
+	if got, want := add.LLString(), "%sum = add nsw i64 %x, %y"; got != want {
+		t.Errorf("original mismatch; expected %q, got %q", want, got)
+	}
+	// Arguments with parameter attributes are copied.
+	call := entry.NewCall(f, NewArg(x, enum.ParamAttrSignExt), y)
+	callClone := call.Clone().(*InstCall)
+	*callClone.Operands()[1] = y
+	if got, want := call.Args[0].(*Arg).Value, x; got != want {
+		t.Errorf("original argument mismatch; expected %v, got %v", want, got)
+	}
+}
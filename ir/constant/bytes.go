@@ -0,0 +1,212 @@
+package constant
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/llir/llvm/ir/types"
+	"github.com/mewmew/float/binary16"
+	"github.com/mewmew/float/float80x86"
+	"github.com/pkg/errors"
+)
+
+// === [ Byte representation ] =================================================
+
+// DataLayout specifies the endianness of a target and the sizes and offsets of
+// types in memory; as used to determine the in-memory byte representation of
+// constants. DataLayout is implemented by *ir.DataLayout.
+type DataLayout interface {
+	// IsLittleEndian reports whether the target is little-endian.
+	IsLittleEndian() bool
+	// TypeStoreSize returns the maximum number of bytes which may be written
+	// when storing a value of the given type.
+	TypeStoreSize(t types.Type) uint64
+	// TypeAllocSize returns the offset in bytes between successive values of
+	// the given type (e.g. elements of an array), including alignment padding.
+	TypeAllocSize(t types.Type) uint64
+	// StructFieldOffset returns the offset in bytes of the given field of the
+	// struct type.
+	StructFieldOffset(t *types.StructType, field int) uint64
+}
+
+// Bytes returns the in-memory byte representation of the given constant, as
+// laid out by the given data layout; i.e. the bytes written when storing the
+// constant to memory. The length of the byte representation is the store size
+// of the type of the constant.
+//
+// Integer and floating-point constants are encoded in the endianness of the
+// data layout. Elements of arrays are laid out at offsets of the allocation
+// size of the element type, and fields of structs at the field offsets of the
+// data layout; padding bytes are zero. Elements of vectors are laid out
+// consecutively, and must have a size which is a multiple of 8 bits.
+//
+// Null pointer and zeroinitializer constants are represented by zero bytes.
+// Undefined and poison values are also represented by zero bytes, which is one
+// of the permitted values of undef; note however that the bytes of poison
+// values do not carry poison semantics.
+//
+// Constant expressions are folded (see Fold) before being encoded. Constants
+// whose byte representation is not known until link or load time (e.g.
+// addresses of global variables and functions, and blockaddress constants)
+// cannot be encoded, and result in an error.
+func Bytes(c Constant, dl DataLayout) ([]byte, error) {
+	buf := make([]byte, dl.TypeStoreSize(c.Type()))
+	if err := writeBytes(buf, c, dl); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf, nil
+}
+
+// writeBytes writes the in-memory byte representation of the given constant to
+// buf, which has a length of at least the store size of the type of c and is
+// zero-initialized.
+func writeBytes(buf []byte, c Constant, dl DataLayout) error {
+	switch c := c.(type) {
+	case *Int:
+		writeInt(buf[:dl.TypeStoreSize(c.Typ)], c.X, dl.IsLittleEndian())
+	case *Float:
+		bits, err := floatBits(c)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		writeInt(buf[:dl.TypeStoreSize(c.Typ)], bits, dl.IsLittleEndian())
+	case *Null, *NoneToken, *ZeroInitializer, *Undef, *Poison:
+		// Zero bytes.
+	case *Array:
+		size := dl.TypeAllocSize(c.Typ.ElemType)
+		for i, elem := range c.Elems {
+			offset := uint64(i) * size
+			if err := writeBytes(buf[offset:], elem, dl); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	case *CharArray:
+		copy(buf, c.X)
+	case *Vector:
+		elemBits := vectorElemBits(c.Typ.ElemType, dl)
+		if elemBits%8 != 0 {
+			return errors.Errorf("unable to encode vector constant %q; element size of %d bits is not a multiple of 8", c.Ident(), elemBits)
+		}
+		size := elemBits / 8
+		for i, elem := range c.Elems {
+			offset := uint64(i) * size
+			if err := writeBytes(buf[offset:], elem, dl); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	case *Struct:
+		for i, field := range c.Fields {
+			offset := dl.StructFieldOffset(c.Typ, i)
+			if err := writeBytes(buf[offset:], field, dl); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	case Expression:
+		folded := Fold(c)
+		if folded == c {
+			return errors.Errorf("unable to encode constant expression %q; unable to fold", c.Ident())
+		}
+		return writeBytes(buf, folded, dl)
+	default:
+		return errors.Errorf("unable to encode constant %q of type %T; value not known until link time", c.Ident(), c)
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// writeInt writes the two's complement representation of x to buf, in the
+// given endianness. Bits of x beyond the length of buf are discarded.
+func writeInt(buf []byte, x *big.Int, littleEndian bool) {
+	n := len(buf)
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(8*n))
+	mask.Sub(mask, big.NewInt(1))
+	// Note, And uses two's complement representation for negative values.
+	v := new(big.Int).And(x, mask)
+	// Big-endian byte representation, without leading zero bytes.
+	b := v.Bytes()
+	for i := range b {
+		// Byte index from the least significant byte.
+		j := len(b) - 1 - i
+		if littleEndian {
+			buf[j] = b[i]
+		} else {
+			buf[n-1-j] = b[i]
+		}
+	}
+}
+
+// floatBits returns the bit representation of the given floating-point
+// constant.
+func floatBits(c *Float) (*big.Int, error) {
+	switch c.Typ.Kind {
+	case types.FloatKindHalf:
+		var bits uint16
+		switch {
+		case c.NaN && c.X.Signbit():
+			bits = binary16.NegNaN.Bits()
+		case c.NaN:
+			bits = binary16.NaN.Bits()
+		default:
+			f, _ := binary16.NewFromBig(c.X)
+			bits = f.Bits()
+		}
+		return new(big.Int).SetUint64(uint64(bits)), nil
+	case types.FloatKindBFloat:
+		return new(big.Int).SetUint64(uint64(bfloatBits(c))), nil
+	case types.FloatKindFloat:
+		var f float32
+		if c.NaN {
+			f = float32(math.NaN())
+			if c.X.Signbit() {
+				f = float32(math.Copysign(math.NaN(), -1))
+			}
+		} else {
+			f, _ = c.X.Float32()
+		}
+		return new(big.Int).SetUint64(uint64(math.Float32bits(f))), nil
+	case types.FloatKindDouble:
+		var f float64
+		if c.NaN {
+			f = math.NaN()
+			if c.X.Signbit() {
+				f = math.Copysign(f, -1)
+			}
+		} else {
+			f, _ = c.X.Float64()
+		}
+		return new(big.Int).SetUint64(math.Float64bits(f)), nil
+	case types.FloatKindX86_FP80:
+		var se uint16
+		var m uint64
+		if c.NaN {
+			// Quiet NaN.
+			se, m = 0x7FFF, 0xC000000000000000
+			if c.X.Signbit() {
+				se |= 0x8000
+			}
+		} else {
+			f, _ := float80x86.NewFromBig(c.X)
+			se, m = f.Bits()
+		}
+		bits := new(big.Int).SetUint64(uint64(se))
+		bits.Lsh(bits, 64)
+		bits.Or(bits, new(big.Int).SetUint64(m))
+		return bits, nil
+	default:
+		return nil, errors.Errorf("support for floating-point kind %v not yet implemented", c.Typ.Kind)
+	}
+}
+
+// vectorElemBits returns the size in bits of the given vector element type.
+func vectorElemBits(t types.Type, dl DataLayout) uint64 {
+	switch t := t.(type) {
+	case *types.IntType:
+		return t.BitSize
+	case *types.FloatType, *types.PointerType:
+		return 8 * dl.TypeStoreSize(t)
+	default:
+		panic(fmt.Errorf("support for vector element type %T not yet implemented", t))
+	}
+}
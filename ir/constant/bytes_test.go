@@ -0,0 +1,71 @@
+package constant_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestBytes(t *testing.T) {
+	le, err := ir.ParseDataLayout("e-i64:64")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %+v", err)
+	}
+	be, err := ir.ParseDataLayout("E-i64:64")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %+v", err)
+	}
+	st := types.NewStruct(types.I16, types.I8)
+	s := constant.NewStruct(st, constant.NewInt(types.I16, 0x1234), constant.NewInt(types.I8, 0x56))
+	golden := []struct {
+		c    constant.Constant
+		dl   *ir.DataLayout
+		want []byte
+	}{
+		// Struct with tail padding.
+		{c: s, dl: le, want: []byte{0x34, 0x12, 0x56, 0x00}},
+		{c: s, dl: be, want: []byte{0x12, 0x34, 0x56, 0x00}},
+		// Struct with inner padding.
+		{
+			c:    constant.NewStruct(types.NewStruct(types.I8, types.I32), constant.NewInt(types.I8, 1), constant.NewInt(types.I32, -2)),
+			dl:   le,
+			want: []byte{0x01, 0x00, 0x00, 0x00, 0xFE, 0xFF, 0xFF, 0xFF},
+		},
+		// Array of structs.
+		{
+			c:    constant.NewArray(types.NewArray(2, st), s, constant.NewZeroInitializer(st)),
+			dl:   le,
+			want: []byte{0x34, 0x12, 0x56, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+		{c: constant.NewCharArrayFromString("hi\x00"), dl: le, want: []byte("hi\x00")},
+		{
+			c:    constant.NewVector(types.NewVector(2, types.I16), constant.NewInt(types.I16, 1), constant.NewInt(types.I16, 2)),
+			dl:   be,
+			want: []byte{0x00, 0x01, 0x00, 0x02},
+		},
+		{c: constant.NewFloat(types.Float, 1), dl: le, want: []byte{0x00, 0x00, 0x80, 0x3F}},
+		{c: constant.NewFloat(types.Double, -2), dl: be, want: []byte{0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{c: constant.NewNull(types.I8Ptr), dl: le, want: make([]byte, 8)},
+		{c: constant.NewUndef(types.I32), dl: le, want: make([]byte, 4)},
+		// Folded constant expression.
+		{c: constant.NewAdd(constant.NewInt(types.I32, 1), constant.NewInt(types.I32, 2)), dl: le, want: []byte{0x03, 0x00, 0x00, 0x00}},
+	}
+	for _, g := range golden {
+		got, err := constant.Bytes(g.c, g.dl)
+		if err != nil {
+			t.Errorf("unable to encode %q; %v", g.c, err)
+			continue
+		}
+		if !bytes.Equal(got, g.want) {
+			t.Errorf("bytes mismatch of %q; expected % X, got % X", g.c, g.want, got)
+		}
+	}
+	// Addresses of global variables are not known until link time.
+	g := ir.NewGlobalDef("g", constant.NewInt(types.I32, 0))
+	if _, err := constant.Bytes(g, le); err == nil {
+		t.Errorf("expected error encoding %q, got nil", g.Ident())
+	}
+}
@@ -56,6 +56,7 @@ func NewFloat(typ *types.FloatType, x float64) *Float {
 //         0xL[0-9A-Fa-f]{32} // HexFP128
 //         0xM[0-9A-Fa-f]{32} // HexPPC128
 //         0xH[0-9A-Fa-f]{4}  // HexHalf
+//         0xR[0-9A-Fa-f]{4}  // HexBFloat
 func NewFloatFromString(typ *types.FloatType, s string) (*Float, error) {
 	// TODO: implement NewFloatFromString. return 0 for now.
 	if strings.HasPrefix(s, "0x") {
@@ -88,6 +89,14 @@ func NewFloatFromString(typ *types.FloatType, s string) (*Float, error) {
 			f := binary16.NewFromBits(uint16(bits))
 			x, nan := f.Big()
 			return &Float{Typ: typ, X: x, NaN: nan}, nil
+		case strings.HasPrefix(s, "0xR"):
+			hex := s[len("0xR"):]
+			bits, err := strconv.ParseUint(hex, 16, 16)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			x, nan := bfloatFromBits(uint16(bits))
+			return &Float{Typ: typ, X: x, NaN: nan}, nil
 		default:
 			hex := s[len("0x"):]
 			bits, err := strconv.ParseUint(hex, 16, 64)
@@ -114,6 +123,20 @@ func NewFloatFromString(typ *types.FloatType, s string) (*Float, error) {
 					Typ: typ,
 					X:   c,
 				}, nil
+			case types.FloatKindBFloat:
+				f := math.Float64frombits(bits)
+				if math.IsNaN(f) {
+					c := &Float{Typ: typ, X: &big.Float{}, NaN: true}
+					// Store sign of NaN.
+					if math.Signbit(f) {
+						c.X.SetFloat64(-1)
+					}
+					return c, nil
+				}
+				x := big.NewFloat(f)
+				const precision = 8
+				x.SetPrec(precision)
+				return &Float{Typ: typ, X: x}, nil
 			case types.FloatKindFloat:
 				// ref: https://groups.google.com/d/msg/llvm-dev/IlqV3TbSk6M/27dAggZOMb0J
 				//
@@ -169,6 +192,17 @@ func NewFloatFromString(typ *types.FloatType, s string) (*Float, error) {
 			X:   x,
 		}
 		return c, nil
+	case types.FloatKindBFloat:
+		const precision = 8
+		x, _, err := big.ParseFloat(s, 10, precision, big.ToNearestEven)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		c := &Float{
+			Typ: typ,
+			X:   x,
+		}
+		return c, nil
 	case types.FloatKindFloat:
 		const precision = 24
 		x, _, err := big.ParseFloat(s, 10, precision, big.ToNearestEven)
@@ -213,6 +247,7 @@ func (c *Float) Ident() string {
 	// TODO: add support for hexadecimal format.
 	// TODO: add support for NaN, +-Inf.
 
+	x := c.X
 	switch c.Typ.Kind {
 	case types.FloatKindHalf:
 		if c.NaN || c.X.IsInf() || !float.IsExact16(c.X) {
@@ -231,6 +266,13 @@ func (c *Float) Ident() string {
 			}
 			return fmt.Sprintf("0xH%04X", bits)
 		}
+	case types.FloatKindBFloat:
+		if c.NaN || c.X.IsInf() || !isExactBFloat(c.X) {
+			return fmt.Sprintf("0xR%04X", bfloatBits(c))
+		}
+		// Format with the shortest decimal representation of single precision,
+		// which is exact for the value.
+		x = new(big.Float).SetPrec(24).Set(c.X)
 	case types.FloatKindFloat:
 		// ref: https://groups.google.com/d/msg/llvm-dev/IlqV3TbSk6M/27dAggZOMb0J
 		//
@@ -318,7 +360,7 @@ func (c *Float) Ident() string {
 	// Insert decimal point if not present.
 	//    3e4 -> 3.0e4
 	//    42  -> 42.0
-	s := c.X.Text('g', -1)
+	s := x.Text('g', -1)
 	if !strings.ContainsRune(s, '.') {
 		if pos := strings.IndexByte(s, 'e'); pos != -1 {
 			s = s[:pos] + ".0" + s[pos:]
@@ -328,3 +370,53 @@ func (c *Float) Ident() string {
 	}
 	return s
 }
+
+// ### [ Helper functions ] ####################################################
+
+// bfloatFromBits returns the floating-point value of the given bit
+// representation of a bfloat (brain floating-point format); i.e. the upper 16
+// bits of a single precision floating-point value. The boolean return value
+// indicates whether the value is Not-a-Number, in which case the sign of the
+// returned value is the sign of the NaN.
+func bfloatFromBits(bits uint16) (*big.Float, bool) {
+	f := math.Float32frombits(uint32(bits) << 16)
+	if math.IsNaN(float64(f)) {
+		x := &big.Float{}
+		if math.Signbit(float64(f)) {
+			x.SetFloat64(-1)
+		}
+		return x, true
+	}
+	x := big.NewFloat(float64(f))
+	const precision = 8
+	x.SetPrec(precision)
+	return x, false
+}
+
+// bfloatBits returns the bit representation of the given bfloat constant,
+// rounded to nearest even.
+func bfloatBits(c *Float) uint16 {
+	if c.NaN {
+		if c.X.Signbit() {
+			return 0xFFC0
+		}
+		return 0x7FC0
+	}
+	const precision = 8
+	x := new(big.Float).SetMode(big.ToNearestEven).SetPrec(precision).Set(c.X)
+	f, _ := x.Float32()
+	return uint16(math.Float32bits(f) >> 16)
+}
+
+// isExactBFloat reports whether the given floating-point value may be
+// represented exactly as a bfloat, and its shortest decimal representation of
+// single precision is exact.
+func isExactBFloat(x *big.Float) bool {
+	f, acc := x.Float32()
+	if acc != big.Exact || math.Float32bits(f)&0xFFFF != 0 {
+		return false
+	}
+	s := strconv.FormatFloat(float64(f), 'g', -1, 32)
+	y, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	return err == nil && y.Cmp(x) == 0
+}
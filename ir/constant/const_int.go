@@ -2,7 +2,6 @@ package constant
 
 import (
 	"fmt"
-	"log"
 	"math/big"
 	"strings"
 
@@ -70,15 +69,18 @@ func NewIntFromString(typ *types.IntType, s string) (*Int, error) {
 		}
 		return &Int{Typ: typ, X: x}, nil
 	case strings.HasPrefix(s, "s0x"):
-		// TODO: figure out how to handle negative values. Use typ.BitSize.
-		// e.g. what value should s0x0012312 represent?
-		log.Printf("support for signed hexadecimal integers (%q) not yet implemented", s)
 		s = s[len("s0x"):]
 		const base = 16
 		x, _ := (&big.Int{}).SetString(s, base)
 		if x == nil {
 			return nil, errors.Errorf("unable to parse integer constant %q", s)
 		}
+		// As in LLVM, the hexadecimal digits specify a two's complement value
+		// of the minimum bit width required to represent the digits; i.e. the
+		// most significant set bit is the sign bit (e.g. s0xFF is -1).
+		if n := x.BitLen(); n > 0 {
+			x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(n)))
+		}
 		return &Int{Typ: typ, X: x}, nil
 	}
 	// Integer literal.
@@ -1,5 +1,11 @@
 package constant
 
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/types"
+)
+
 // Assert that each constant implements the constant.Constant interface.
 var (
 	// Constant expressions.
@@ -16,6 +22,7 @@ var (
 	_ Constant = (*Vector)(nil)
 	_ Constant = (*ZeroInitializer)(nil)
 	_ Constant = (*Undef)(nil)
+	_ Constant = (*Poison)(nil)
 	_ Constant = (*BlockAddress)(nil)
 )
 
@@ -69,3 +76,49 @@ var (
 	_ Expression = (*ExprFCmp)(nil)
 	_ Expression = (*ExprSelect)(nil)
 )
+
+func TestScalableSplat(t *testing.T) {
+	// shufflevector (insertelement <vscale x 4 x i32> undef, i32 1, i64 0),
+	// <vscale x 4 x i32> undef, <vscale x 4 x i32> zeroinitializer
+	vecType := &types.VectorType{Len: 4, ElemType: types.I32, Scalable: true}
+	maskType := &types.VectorType{Len: 4, ElemType: types.I32, Scalable: true}
+	elem := NewInsertElement(NewUndef(vecType), NewInt(types.I32, 1), NewInt(types.I64, 0))
+	splat := NewShuffleVector(elem, NewUndef(vecType), NewZeroInitializer(maskType))
+	if !splat.Type().Equal(vecType) {
+		t.Errorf("type mismatch; expected %v, got %v", vecType, splat.Type())
+	}
+	if fixed := types.NewVector(4, types.I32); splat.Type().Equal(fixed) {
+		t.Errorf("scalable vector type %v equal to fixed-length vector type %v", splat.Type(), fixed)
+	}
+	const want = "<vscale x 4 x i32> shufflevector (<vscale x 4 x i32> insertelement (<vscale x 4 x i32> undef, i32 1, i64 0), <vscale x 4 x i32> undef, <vscale x 4 x i32> zeroinitializer)"
+	if got := splat.String(); got != want {
+		t.Errorf("splat mismatch; expected %q, got %q", want, got)
+	}
+}
+
+func TestNewIntFromString(t *testing.T) {
+	golden := []struct {
+		typ  *types.IntType
+		s    string
+		want string
+	}{
+		{typ: types.I32, s: "-42", want: "i32 -42"},
+		{typ: types.I128, s: "170141183460469231731687303715884105727", want: "i128 170141183460469231731687303715884105727"},
+		{typ: types.I128, s: "-170141183460469231731687303715884105728", want: "i128 -170141183460469231731687303715884105728"},
+		{typ: types.I128, s: "u0xFFFFFFFFFFFFFFFFFF", want: "i128 4722366482869645213695"},
+		{typ: types.I32, s: "s0xFFFFFFFF", want: "i32 -1"},
+		{typ: types.I32, s: "s0x80", want: "i32 -128"},
+		{typ: types.I32, s: "s0x0", want: "i32 0"},
+		{typ: types.I1, s: "true", want: "i1 true"},
+	}
+	for _, g := range golden {
+		c, err := NewIntFromString(g.typ, g.s)
+		if err != nil {
+			t.Errorf("unable to parse integer constant %q; %v", g.s, err)
+			continue
+		}
+		if got := c.String(); got != g.want {
+			t.Errorf("integer constant mismatch of %q; expected %q, got %q", g.s, g.want, got)
+		}
+	}
+}
@@ -239,6 +239,9 @@ type ExprOr struct {
 
 	// Type of result produced by the constant expression.
 	Typ types.Type
+	// (optional) The result is a poison value if any bit is set in both
+	// operands.
+	Disjoint bool
 }
 
 // NewOr returns a new or expression based on the given operands.
@@ -266,8 +269,14 @@ func (e *ExprOr) Type() types.Type {
 
 // Ident returns the identifier associated with the constant expression.
 func (e *ExprOr) Ident() string {
-	// 'or' '(' X=TypeConst ',' Y=TypeConst ')'
-	return fmt.Sprintf("or (%s, %s)", e.X, e.Y)
+	// 'or' Disjointopt '(' X=TypeConst ',' Y=TypeConst ')'
+	buf := &strings.Builder{}
+	buf.WriteString("or")
+	if e.Disjoint {
+		buf.WriteString(" disjoint")
+	}
+	fmt.Fprintf(buf, " (%s, %s)", e.X, e.Y)
+	return buf.String()
 }
 
 // Simplify returns an equivalent (and potentially simplified) constant to the
@@ -2,8 +2,10 @@ package constant
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
+	"github.com/llir/llvm/ir/enum"
 	"github.com/llir/llvm/ir/types"
 )
 
@@ -27,6 +29,8 @@ type ExprGetElementPtr struct {
 	// (optional) The result is a poison value if the calculated pointer is not
 	// an in bounds address of the allocated source object.
 	InBounds bool
+	// (optional) getelementptr flags (in addition to in-bounds).
+	Flags enum.GEPFlags
 }
 
 // NewGetElementPtr returns a new getelementptr expression based on the given
@@ -70,13 +74,21 @@ func (e *ExprGetElementPtr) Type() types.Type {
 
 // Ident returns the identifier associated with the constant expression.
 func (e *ExprGetElementPtr) Ident() string {
-	// 'getelementptr' InBoundsopt '(' ElemType=Type ',' Src=TypeConst
-	// Indices=(',' GEPIndex)* ')'
+	// 'getelementptr' InBoundsopt Flags=GEPFlag* '(' ElemType=Type ','
+	// Src=TypeConst Indices=(',' GEPIndex)* ')'
 	buf := &strings.Builder{}
 	buf.WriteString("getelementptr")
 	if e.InBounds {
 		buf.WriteString(" inbounds")
 	}
+	flags := e.Flags
+	if e.InBounds {
+		// inbounds implies nusw.
+		flags = flags.Without(enum.GEPFlagNUSW)
+	}
+	for _, flag := range flags.List() {
+		fmt.Fprintf(buf, " %s", flag)
+	}
 	fmt.Fprintf(buf, " (%s, %s", e.ElemType, e.Src)
 	for _, index := range e.Indices {
 		fmt.Fprintf(buf, ", %s", index)
@@ -147,17 +159,20 @@ func gepType(elemType types.Type, indices []Constant) types.Type {
 			e = t.ElemType
 		case *types.ArrayType:
 			e = t.ElemType
+		case *types.TargetExtType:
+			// Target extension types are opaque.
+			panic(fmt.Errorf("unable to index into element of target extension type `%s`; target extension types are opaque", e))
 		case *types.StructType:
 			switch index := index.(type) {
 			case *Int:
-				e = t.Fields[index.X.Int64()]
+				e = t.Fields[structFieldIndex(t, index.X)]
 			case *Vector:
 				// TODO: Validate how index vectors in gep are supposed to work.
 				idx, ok := index.Elems[0].(*Int)
 				if !ok {
 					panic(fmt.Errorf("invalid index type for structure element; expected *constant.Int, got %T", index.Elems[0]))
 				}
-				i := idx.X.Int64()
+				i := idx.X
 				// Sanity check. All vector elements must be integers, and must have
 				// the same value.
 				for _, elem := range index.Elems {
@@ -165,12 +180,12 @@ func gepType(elemType types.Type, indices []Constant) types.Type {
 					if !ok {
 						panic(fmt.Errorf("invalid index type for structure element; expected *constant.Int, got %T", elem))
 					}
-					j := idx.X.Int64()
-					if i != j {
+					j := idx.X
+					if i.Cmp(j) != 0 {
 						panic(fmt.Errorf("struct index mismatch; vector elements %d and %d differ", i, j))
 					}
 				}
-				e = t.Fields[i]
+				e = t.Fields[structFieldIndex(t, i)]
 			case *ZeroInitializer:
 				e = t.Fields[0]
 			default:
@@ -193,8 +208,20 @@ func gepType(elemType types.Type, indices []Constant) types.Type {
 			index = idx.Constant
 		}
 		if t, ok := index.Type().(*types.VectorType); ok {
-			return types.NewVector(t.Len, types.NewPointer(e))
+			vt := types.NewVector(t.Len, types.NewPointer(e))
+			vt.Scalable = t.Scalable
+			return vt
 		}
 	}
 	return types.NewPointer(e)
 }
+
+// structFieldIndex returns the field index of the given struct type, as
+// specified by the given integer. Integers of arbitrary width are handled
+// without overflow.
+func structFieldIndex(t *types.StructType, x *big.Int) int {
+	if x.Sign() < 0 || x.Cmp(big.NewInt(int64(len(t.Fields)))) >= 0 {
+		panic(fmt.Errorf("invalid struct index %v of struct type %v with %d fields", x, t, len(t.Fields)))
+	}
+	return int(x.Int64())
+}
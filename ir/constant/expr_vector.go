@@ -162,7 +162,9 @@ func (e *ExprShuffleVector) Type() types.Type {
 		if !ok {
 			panic(fmt.Errorf("invalid vector type; expected *types.VectorType, got %T", e.Mask.Type()))
 		}
-		e.Typ = types.NewVector(maskType.Len, xType.ElemType)
+		t := types.NewVector(maskType.Len, xType.ElemType)
+		t.Scalable = maskType.Scalable
+		e.Typ = t
 	}
 	return e.Typ
 }
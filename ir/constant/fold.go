@@ -0,0 +1,537 @@
+package constant
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+)
+
+// === [ Constant folding ] ====================================================
+
+// Fold returns a constant equivalent to the given constant expression, folded
+// based on the values of its operands; or e if e cannot be folded.
+//
+// Integer binary and bitwise expressions are folded if both operands are
+// integer constants, or if either operand is an undefined or poison value.
+// Folding follows the LLVM semantics of undef and poison; e.g.
+//
+//    add (undef, 5)           -> undef
+//    and (undef, 0)           -> 0
+//    mul (undef, 5)           -> 0
+//    or (undef, 5)            -> -1
+//    xor (undef, undef)       -> 0
+//    mul (poison, 5)          -> poison
+//    udiv (5, 0)              -> poison
+//    udiv (5, undef)          -> poison
+//    shl (1, 32)              -> poison ; shift amount >= bit width of i32
+//    add nsw (2147483647, 1)  -> poison ; signed overflow of i32
+//    udiv exact (5, 2)        -> poison ; non-zero remainder
+//    or disjoint (5, 4)       -> poison ; overlapping bits
+//
+// Getelementptr expressions with all-zero indices are folded to the source
+// address; bitcast to the result type if the source address is a pointer of
+// different type. Getelementptr expressions with inrange indices are not
+// folded.
+//
+//    getelementptr (i32, i32* @x, i64 0)                   -> @x
+//    getelementptr ([4 x i32], [4 x i32]* @a, i64 0, i64 0) -> bitcast ([4 x i32]* @a to i32*)
+//
+// Fpext and fptrunc expressions are folded if the operand is a floating-point
+// constant, or a vector of floating-point constants in which case the
+// conversion is applied element-wise. Values are rounded to nearest even to
+// the precision of the result type.
+//
+//    fpext (<2 x bfloat> <bfloat 1.0, bfloat 0xR3DCD> to <2 x float>) -> <float 1.0, float 0x3FB9A00000000000>
+//    fptrunc (double 0.1 to half)                                      -> half 0xH2E66
+//
+// Compare expressions are folded if both operands are constants of the same
+// kind, producing i1 constants (or vectors of i1 constants). Floating-point
+// compares follow the semantics of ordered and unordered predicates; ordered
+// predicates are false and unordered predicates are true if either operand is
+// NaN.
+//
+//    icmp slt (i32 -1, i32 0)                          -> true
+//    fcmp oeq (double 1.0, double 1.0)                 -> true
+//    fcmp oeq (double 0x7FF8000000000000, double 1.0)  -> false
+//    fcmp uno (double 0x7FF8000000000000, double 1.0)  -> true
+//
+// Select expressions are folded if the condition is constant; trunc, zext and
+// sext expressions if the operand is an integer constant; bitcast expressions
+// between integer and floating-point scalars of identical size; and
+// extractelement and insertelement expressions if the vector and index are
+// constants. Out of bounds indices produce poison values.
+//
+//    select (i1 true, i32 1, i32 2)                   -> 1
+//    sext (i8 -1 to i32)                              -> -1
+//    zext (i8 -1 to i32)                              -> 255
+//    bitcast (float 1.0 to i32)                       -> 1065353216
+//    extractelement (<2 x i32> <i32 1, i32 2>, i32 1) -> 2
+//    extractelement (<2 x i32> <i32 1, i32 2>, i32 2) -> poison
+func Fold(e Expression) Constant {
+	switch e := e.(type) {
+	// Binary expressions.
+	case *ExprAdd:
+		return foldBinary(e, opAdd, e.X, e.Y, e.OverflowFlags, false)
+	case *ExprSub:
+		return foldBinary(e, opSub, e.X, e.Y, e.OverflowFlags, false)
+	case *ExprMul:
+		return foldBinary(e, opMul, e.X, e.Y, e.OverflowFlags, false)
+	case *ExprUDiv:
+		return foldBinary(e, opUDiv, e.X, e.Y, nil, e.Exact)
+	case *ExprSDiv:
+		return foldBinary(e, opSDiv, e.X, e.Y, nil, e.Exact)
+	case *ExprURem:
+		return foldBinary(e, opURem, e.X, e.Y, nil, false)
+	case *ExprSRem:
+		return foldBinary(e, opSRem, e.X, e.Y, nil, false)
+	// Bitwise expressions.
+	case *ExprShl:
+		return foldBinary(e, opShl, e.X, e.Y, e.OverflowFlags, false)
+	case *ExprLShr:
+		return foldBinary(e, opLShr, e.X, e.Y, nil, e.Exact)
+	case *ExprAShr:
+		return foldBinary(e, opAShr, e.X, e.Y, nil, e.Exact)
+	case *ExprAnd:
+		return foldBinary(e, opAnd, e.X, e.Y, nil, false)
+	case *ExprOr:
+		return foldBinary(e, opOr, e.X, e.Y, nil, e.Disjoint)
+	case *ExprXor:
+		return foldBinary(e, opXor, e.X, e.Y, nil, false)
+	// Memory expressions.
+	case *ExprGetElementPtr:
+		return foldGetElementPtr(e)
+	// Conversion expressions.
+	case *ExprTrunc:
+		return foldIntCast(e, opTrunc, e.From, e.To)
+	case *ExprZExt:
+		return foldIntCast(e, opZExt, e.From, e.To)
+	case *ExprSExt:
+		return foldIntCast(e, opSExt, e.From, e.To)
+	case *ExprBitCast:
+		return foldBitCast(e)
+	case *ExprFPTrunc:
+		return foldFPConv(e, e.From, e.To)
+	case *ExprFPExt:
+		return foldFPConv(e, e.From, e.To)
+	// Vector expressions.
+	case *ExprExtractElement:
+		return foldExtractElement(e)
+	case *ExprInsertElement:
+		return foldInsertElement(e)
+	// Other expressions.
+	case *ExprICmp:
+		return foldICmp(e)
+	case *ExprFCmp:
+		return foldFCmp(e)
+	case *ExprSelect:
+		return foldSelect(e)
+	}
+	return e
+}
+
+// foldFPConv folds the floating-point conversion expression e (fpext or
+// fptrunc) of the given operand to the given type. The expression e is
+// returned if it cannot be folded.
+func foldFPConv(e Expression, from Constant, to types.Type) Constant {
+	switch from := from.(type) {
+	case *Poison:
+		return NewPoison(to)
+	case *Undef:
+		return NewUndef(to)
+	case *ZeroInitializer:
+		return NewZeroInitializer(to)
+	case *Float:
+		if t, ok := to.(*types.FloatType); ok {
+			return convertFloat(from, t)
+		}
+	case *Vector:
+		t, ok := to.(*types.VectorType)
+		if !ok {
+			return e
+		}
+		elemType, ok := t.ElemType.(*types.FloatType)
+		if !ok {
+			return e
+		}
+		elems := make([]Constant, len(from.Elems))
+		for i, elem := range from.Elems {
+			switch elem := elem.(type) {
+			case *Float:
+				elems[i] = convertFloat(elem, elemType)
+			case *Poison:
+				elems[i] = NewPoison(elemType)
+			case *Undef:
+				elems[i] = NewUndef(elemType)
+			default:
+				return e
+			}
+		}
+		return NewVector(t, elems...)
+	}
+	return e
+}
+
+// convertFloat returns the floating-point constant x converted to the given
+// floating-point type, rounded to nearest even.
+func convertFloat(x *Float, typ *types.FloatType) *Float {
+	if x.NaN {
+		// Retain sign of NaN.
+		return &Float{Typ: typ, X: new(big.Float).Set(x.X), NaN: true}
+	}
+	switch typ.Kind {
+	case types.FloatKindHalf:
+		return &Float{Typ: typ, X: roundHalf(x.X)}
+	case types.FloatKindBFloat:
+		v, nan := bfloatFromBits(bfloatBits(x))
+		return &Float{Typ: typ, X: v, NaN: nan}
+	case types.FloatKindFloat:
+		f, _ := x.X.Float32()
+		const precision = 24
+		return &Float{Typ: typ, X: big.NewFloat(float64(f)).SetPrec(precision)}
+	case types.FloatKindDouble:
+		f, _ := x.X.Float64()
+		return &Float{Typ: typ, X: big.NewFloat(f)}
+	}
+	// Extended precision; retain value.
+	return &Float{Typ: typ, X: new(big.Float).Set(x.X)}
+}
+
+// foldGetElementPtr folds the getelementptr expression e with all-zero indices
+// to its source address. The expression e is returned if it cannot be folded.
+func foldGetElementPtr(e *ExprGetElementPtr) Constant {
+	for _, index := range e.Indices {
+		if idx, ok := index.(*Index); ok {
+			if idx.InRange {
+				return e
+			}
+			index = idx.Constant
+		}
+		if !isZeroInt(index) {
+			return e
+		}
+	}
+	typ, srcType := e.Type(), e.Src.Type()
+	if typ.Equal(srcType) {
+		return e.Src
+	}
+	// Pointer of different element type.
+	_, ok1 := typ.(*types.PointerType)
+	_, ok2 := srcType.(*types.PointerType)
+	if ok1 && ok2 {
+		return NewBitCast(e.Src, typ)
+	}
+	// Vector of pointers computed from scalar source address.
+	return e
+}
+
+// binaryOp is an integer binary operator.
+type binaryOp uint8
+
+// Integer binary operators.
+const (
+	opAdd binaryOp = iota
+	opSub
+	opMul
+	opUDiv
+	opSDiv
+	opURem
+	opSRem
+	opShl
+	opLShr
+	opAShr
+	opAnd
+	opOr
+	opXor
+)
+
+// foldBinary folds the integer binary expression e with the given operator,
+// operands, overflow flags and exact flag (or disjoint flag of or expressions).
+// The expression e is returned if it cannot be folded.
+func foldBinary(e Expression, op binaryOp, x, y Constant, flags []enum.OverflowFlag, exact bool) Constant {
+	typ := e.Type()
+	// Poison operands.
+	if isPoison(x) || isPoison(y) {
+		return NewPoison(typ)
+	}
+	// Division by zero or undef, and shift by undef or by an amount greater
+	// than or equal to the bit width.
+	switch op {
+	case opUDiv, opSDiv, opURem, opSRem:
+		if isUndef(y) || isZeroInt(y) {
+			return NewPoison(typ)
+		}
+	case opShl, opLShr, opAShr:
+		if isUndef(y) {
+			return NewPoison(typ)
+		}
+		if yInt, ok := y.(*Int); ok {
+			bitSize := yInt.Typ.BitSize
+			if toUnsigned(yInt.X, bitSize).Cmp(new(big.Int).SetUint64(bitSize)) >= 0 {
+				return NewPoison(typ)
+			}
+		}
+	}
+	// Undefined operands.
+	if isUndef(x) || isUndef(y) {
+		switch op {
+		case opAdd, opSub:
+			return NewUndef(typ)
+		case opXor:
+			if isUndef(x) && isUndef(y) {
+				return zeroValue(typ)
+			}
+			return NewUndef(typ)
+		case opMul, opAnd:
+			return zeroValue(typ)
+		case opOr:
+			return allOnesValue(typ)
+		case opUDiv, opSDiv, opURem, opSRem, opShl, opLShr, opAShr:
+			// undef op C -> 0, as y is neither undef nor zero, nor an
+			// out-of-range shift amount.
+			return zeroValue(typ)
+		}
+	}
+	// Integer constant operands.
+	xInt, ok := x.(*Int)
+	if !ok {
+		return e
+	}
+	yInt, ok := y.(*Int)
+	if !ok {
+		return e
+	}
+	t, ok := typ.(*types.IntType)
+	if !ok {
+		return e
+	}
+	if r, ok := evalBinary(op, t.BitSize, xInt.X, yInt.X, flags, exact); ok {
+		return newIntFromBig(t, r)
+	}
+	return NewPoison(typ)
+}
+
+// evalBinary evaluates the integer binary operation on x and y of the given bit
+// size. The boolean return value is false if the result is poison.
+func evalBinary(op binaryOp, bitSize uint64, x, y *big.Int, flags []enum.OverflowFlag, exact bool) (*big.Int, bool) {
+	ux, uy := toUnsigned(x, bitSize), toUnsigned(y, bitSize)
+	sx, sy := toSigned(x, bitSize), toSigned(y, bitSize)
+	nsw := hasFlag(flags, enum.OverflowFlagNSW)
+	nuw := hasFlag(flags, enum.OverflowFlagNUW)
+	r := &big.Int{}
+	switch op {
+	case opAdd:
+		if nuw && !fitsUnsigned(r.Add(ux, uy), bitSize) {
+			return nil, false
+		}
+		if nsw && !fitsSigned(r.Add(sx, sy), bitSize) {
+			return nil, false
+		}
+		r.Add(ux, uy)
+	case opSub:
+		if nuw && ux.Cmp(uy) < 0 {
+			return nil, false
+		}
+		if nsw && !fitsSigned(r.Sub(sx, sy), bitSize) {
+			return nil, false
+		}
+		r.Sub(ux, uy)
+	case opMul:
+		if nuw && !fitsUnsigned(r.Mul(ux, uy), bitSize) {
+			return nil, false
+		}
+		if nsw && !fitsSigned(r.Mul(sx, sy), bitSize) {
+			return nil, false
+		}
+		r.Mul(ux, uy)
+	case opUDiv:
+		m := &big.Int{}
+		r.QuoRem(ux, uy, m)
+		if exact && m.Sign() != 0 {
+			return nil, false
+		}
+	case opSDiv:
+		m := &big.Int{}
+		r.QuoRem(sx, sy, m)
+		if !fitsSigned(r, bitSize) {
+			// Signed overflow (e.g. INT_MIN / -1).
+			return nil, false
+		}
+		if exact && m.Sign() != 0 {
+			return nil, false
+		}
+	case opURem:
+		r.Rem(ux, uy)
+	case opSRem:
+		if !fitsSigned(new(big.Int).Quo(sx, sy), bitSize) {
+			// Signed overflow (e.g. INT_MIN % -1).
+			return nil, false
+		}
+		r.Rem(sx, sy)
+	case opShl, opLShr, opAShr:
+		if uy.Cmp(new(big.Int).SetUint64(bitSize)) >= 0 {
+			// Shift amount greater than or equal to bit width.
+			return nil, false
+		}
+		n := uint(uy.Uint64())
+		switch op {
+		case opShl:
+			r.Lsh(ux, n)
+			if nuw && !fitsUnsigned(r, bitSize) {
+				return nil, false
+			}
+			if nsw && new(big.Int).Rsh(toSigned(r, bitSize), n).Cmp(sx) != 0 {
+				return nil, false
+			}
+		case opLShr:
+			r.Rsh(ux, n)
+			if exact && new(big.Int).Lsh(r, n).Cmp(ux) != 0 {
+				return nil, false
+			}
+		case opAShr:
+			r.Rsh(sx, n)
+			if exact && new(big.Int).Lsh(r, n).Cmp(sx) != 0 {
+				return nil, false
+			}
+		}
+	case opAnd:
+		r.And(ux, uy)
+	case opOr:
+		// The exact flag denotes the disjoint flag of or expressions.
+		if exact && r.And(ux, uy).Sign() != 0 {
+			return nil, false
+		}
+		r.Or(ux, uy)
+	case opXor:
+		r.Xor(ux, uy)
+	}
+	return r, true
+}
+
+// roundHalf returns x rounded to nearest even to half precision; overflowing
+// to infinity and underflowing to subnormal values.
+func roundHalf(x *big.Float) *big.Float {
+	const (
+		precision = 11
+		// Largest finite half precision value.
+		maxHalf = 65504
+		// Smallest positive normal half precision value.
+		minNormal = 0x1p-14
+		// Exponent of the smallest positive subnormal half precision value.
+		minExp = -24
+	)
+	y := new(big.Float).SetMode(big.ToNearestEven).SetPrec(precision).Set(x)
+	abs := new(big.Float).Abs(y)
+	switch {
+	case y.IsInf():
+		return y
+	case abs.Cmp(big.NewFloat(maxHalf)) > 0:
+		return y.SetInf(y.Signbit())
+	case abs.Sign() != 0 && abs.Cmp(big.NewFloat(minNormal)) < 0:
+		// Subnormal values are multiples of 2^-24.
+		f, _ := new(big.Float).SetMantExp(x, -minExp).Float64()
+		y.SetMantExp(big.NewFloat(math.RoundToEven(f)), minExp)
+	}
+	return y
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isPoison reports whether the given constant is a poison value.
+func isPoison(c Constant) bool {
+	_, ok := c.(*Poison)
+	return ok
+}
+
+// isUndef reports whether the given constant is an undefined value.
+func isUndef(c Constant) bool {
+	_, ok := c.(*Undef)
+	return ok
+}
+
+// isZeroInt reports whether the given constant is an integer zero constant.
+func isZeroInt(c Constant) bool {
+	switch c := c.(type) {
+	case *Int:
+		return c.X.Sign() == 0
+	case *ZeroInitializer:
+		return true
+	}
+	return false
+}
+
+// hasFlag reports whether the given overflow flag is present in flags.
+func hasFlag(flags []enum.OverflowFlag, flag enum.OverflowFlag) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// zeroValue returns the zero value of the given integer scalar or integer
+// vector type.
+func zeroValue(typ types.Type) Constant {
+	if t, ok := typ.(*types.IntType); ok {
+		return NewInt(t, 0)
+	}
+	return NewZeroInitializer(typ)
+}
+
+// allOnesValue returns the value with all bits set of the given integer scalar
+// or integer vector type.
+func allOnesValue(typ types.Type) Constant {
+	switch t := typ.(type) {
+	case *types.IntType:
+		return newIntFromBig(t, big.NewInt(-1))
+	case *types.VectorType:
+		if !t.Scalable {
+			elems := make([]Constant, t.Len)
+			for i := range elems {
+				elems[i] = allOnesValue(t.ElemType)
+			}
+			return NewVector(t, elems...)
+		}
+	}
+	return NewUndef(typ)
+}
+
+// newIntFromBig returns a new integer constant of the given type, based on the
+// two's complement representation of x truncated to the bit size of typ.
+func newIntFromBig(typ *types.IntType, x *big.Int) *Int {
+	if typ.BitSize == 1 {
+		// Boolean constants are represented as 0 or 1.
+		return &Int{Typ: typ, X: toUnsigned(x, typ.BitSize)}
+	}
+	return &Int{Typ: typ, X: toSigned(x, typ.BitSize)}
+}
+
+// toUnsigned returns the unsigned value of x truncated to the given bit size.
+func toUnsigned(x *big.Int, bitSize uint64) *big.Int {
+	mod := new(big.Int).Lsh(big.NewInt(1), uint(bitSize))
+	// Mod returns the Euclidean modulus, which is non-negative.
+	return new(big.Int).Mod(x, mod)
+}
+
+// toSigned returns the signed value of x truncated to the given bit size.
+func toSigned(x *big.Int, bitSize uint64) *big.Int {
+	u := toUnsigned(x, bitSize)
+	if u.Bit(int(bitSize-1)) == 1 {
+		mod := new(big.Int).Lsh(big.NewInt(1), uint(bitSize))
+		u.Sub(u, mod)
+	}
+	return u
+}
+
+// fitsUnsigned reports whether x fits in an unsigned integer of the given bit
+// size.
+func fitsUnsigned(x *big.Int, bitSize uint64) bool {
+	return x.Sign() >= 0 && uint64(x.BitLen()) <= bitSize
+}
+
+// fitsSigned reports whether x fits in a signed integer of the given bit size.
+func fitsSigned(x *big.Int, bitSize uint64) bool {
+	return toSigned(x, bitSize).Cmp(x) == 0
+}
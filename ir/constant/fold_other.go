@@ -0,0 +1,460 @@
+package constant
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/mewmew/float/binary16"
+)
+
+// --- [ Compare expressions ] -------------------------------------------------
+
+// foldICmp folds the icmp expression e. The expression e is returned if it
+// cannot be folded.
+func foldICmp(e *ExprICmp) Constant {
+	typ := e.Type()
+	return foldElementwise(e, typ, func(x, y Constant) (Constant, bool) {
+		if isPoison(x) || isPoison(y) {
+			return NewPoison(types.I1), true
+		}
+		if isUndef(x) || isUndef(y) {
+			// An undefined operand may be chosen to make the predicate hold or
+			// not hold, if both operands are undefined or for equality
+			// predicates.
+			if (isUndef(x) && isUndef(y)) || e.Pred == enum.IPredEQ || e.Pred == enum.IPredNE {
+				return NewUndef(types.I1), true
+			}
+			return nil, false
+		}
+		xInt, ok := intOperand(x)
+		if !ok {
+			return nil, false
+		}
+		yInt, ok := intOperand(y)
+		if !ok {
+			return nil, false
+		}
+		bitSize := xInt.Typ.BitSize
+		ux, uy := toUnsigned(xInt.X, bitSize), toUnsigned(yInt.X, bitSize)
+		sx, sy := toSigned(xInt.X, bitSize), toSigned(yInt.X, bitSize)
+		var r bool
+		switch e.Pred {
+		case enum.IPredEQ:
+			r = ux.Cmp(uy) == 0
+		case enum.IPredNE:
+			r = ux.Cmp(uy) != 0
+		case enum.IPredSGE:
+			r = sx.Cmp(sy) >= 0
+		case enum.IPredSGT:
+			r = sx.Cmp(sy) > 0
+		case enum.IPredSLE:
+			r = sx.Cmp(sy) <= 0
+		case enum.IPredSLT:
+			r = sx.Cmp(sy) < 0
+		case enum.IPredUGE:
+			r = ux.Cmp(uy) >= 0
+		case enum.IPredUGT:
+			r = ux.Cmp(uy) > 0
+		case enum.IPredULE:
+			r = ux.Cmp(uy) <= 0
+		case enum.IPredULT:
+			r = ux.Cmp(uy) < 0
+		default:
+			return nil, false
+		}
+		return NewBool(r), true
+	}, e.X, e.Y)
+}
+
+// foldFCmp folds the fcmp expression e. The expression e is returned if it
+// cannot be folded.
+//
+// Ordered predicates (e.g. oeq) are false and unordered predicates (e.g. ueq)
+// are true if either operand is NaN.
+func foldFCmp(e *ExprFCmp) Constant {
+	typ := e.Type()
+	return foldElementwise(e, typ, func(x, y Constant) (Constant, bool) {
+		switch e.Pred {
+		case enum.FPredFalse:
+			return False, true
+		case enum.FPredTrue:
+			return True, true
+		}
+		if isPoison(x) || isPoison(y) {
+			return NewPoison(types.I1), true
+		}
+		xFloat, ok := floatOperand(x)
+		if !ok {
+			return nil, false
+		}
+		yFloat, ok := floatOperand(y)
+		if !ok {
+			return nil, false
+		}
+		if xFloat.NaN || yFloat.NaN {
+			switch e.Pred {
+			case enum.FPredUEQ, enum.FPredUGE, enum.FPredUGT, enum.FPredULE, enum.FPredULT, enum.FPredUNE, enum.FPredUNO:
+				return True, true
+			}
+			// Ordered predicates.
+			return False, true
+		}
+		// Note, Cmp considers -0 equal to +0.
+		cmp := xFloat.X.Cmp(yFloat.X)
+		var r bool
+		switch e.Pred {
+		case enum.FPredOEQ, enum.FPredUEQ:
+			r = cmp == 0
+		case enum.FPredOGE, enum.FPredUGE:
+			r = cmp >= 0
+		case enum.FPredOGT, enum.FPredUGT:
+			r = cmp > 0
+		case enum.FPredOLE, enum.FPredULE:
+			r = cmp <= 0
+		case enum.FPredOLT, enum.FPredULT:
+			r = cmp < 0
+		case enum.FPredONE, enum.FPredUNE:
+			r = cmp != 0
+		case enum.FPredORD:
+			r = true
+		case enum.FPredUNO:
+			r = false
+		default:
+			return nil, false
+		}
+		return NewBool(r), true
+	}, e.X, e.Y)
+}
+
+// --- [ Select expressions ] --------------------------------------------------
+
+// foldSelect folds the select expression e with a constant condition. The
+// expression e is returned if it cannot be folded.
+func foldSelect(e *ExprSelect) Constant {
+	if isPoison(e.Cond) {
+		return NewPoison(e.Type())
+	}
+	if cond, ok := e.Cond.(*Int); ok {
+		if cond.X.Sign() != 0 {
+			return e.X
+		}
+		return e.Y
+	}
+	if _, ok := e.Cond.Type().(*types.VectorType); !ok {
+		// Undefined condition; either operand may be chosen.
+		if isUndef(e.Cond) {
+			return e.X
+		}
+		return e
+	}
+	// Vector condition; select element-wise.
+	conds, ok := vectorElems(e.Cond)
+	if !ok {
+		return e
+	}
+	xs, ok := vectorElems(e.X)
+	if !ok {
+		return e
+	}
+	ys, ok := vectorElems(e.Y)
+	if !ok {
+		return e
+	}
+	elems := make([]Constant, len(conds))
+	for i, cond := range conds {
+		switch cond := cond.(type) {
+		case *Int:
+			if cond.X.Sign() != 0 {
+				elems[i] = xs[i]
+			} else {
+				elems[i] = ys[i]
+			}
+		case *Undef:
+			elems[i] = xs[i]
+		case *Poison:
+			elems[i] = NewPoison(xs[i].Type())
+		default:
+			return e
+		}
+	}
+	return NewVector(e.Type().(*types.VectorType), elems...)
+}
+
+// --- [ Conversion expressions ] ----------------------------------------------
+
+// castOp is an integer conversion operator.
+type castOp uint8
+
+// Integer conversion operators.
+const (
+	opTrunc castOp = iota
+	opZExt
+	opSExt
+)
+
+// foldIntCast folds the integer conversion expression e (trunc, zext or sext)
+// with the given operator, operand and result type. The expression e is
+// returned if it cannot be folded.
+func foldIntCast(e Expression, op castOp, from Constant, to types.Type) Constant {
+	return foldElementwise(e, to, func(x, _ Constant) (Constant, bool) {
+		t, ok := scalarType(to).(*types.IntType)
+		if !ok {
+			return nil, false
+		}
+		switch {
+		case isPoison(x):
+			return NewPoison(t), true
+		case isUndef(x):
+			if op == opTrunc {
+				return NewUndef(t), true
+			}
+			// The extended bits of zext and sext are known, thus at least
+			// the upper bit of the result is defined; fold to zero.
+			return NewInt(t, 0), true
+		}
+		xInt, ok := intOperand(x)
+		if !ok {
+			return nil, false
+		}
+		switch op {
+		case opSExt:
+			return newIntFromBig(t, toSigned(xInt.X, xInt.Typ.BitSize)), true
+		default:
+			// trunc and zext.
+			return newIntFromBig(t, toUnsigned(xInt.X, xInt.Typ.BitSize)), true
+		}
+	}, from, from)
+}
+
+// foldBitCast folds the bitcast expression e between integer and
+// floating-point scalar types, and of identical types. The expression e is
+// returned if it cannot be folded.
+func foldBitCast(e *ExprBitCast) Constant {
+	to := e.To
+	if e.From.Type().Equal(to) {
+		return e.From
+	}
+	switch from := e.From.(type) {
+	case *Poison:
+		return NewPoison(to)
+	case *Undef:
+		return NewUndef(to)
+	case *Int:
+		t, ok := to.(*types.FloatType)
+		if !ok {
+			return e
+		}
+		if c, ok := floatFromBits(t, toUnsigned(from.X, from.Typ.BitSize)); ok {
+			return c
+		}
+	case *Float:
+		t, ok := to.(*types.IntType)
+		if !ok {
+			return e
+		}
+		bits, err := floatBits(from)
+		if err != nil {
+			return e
+		}
+		return newIntFromBig(t, bits)
+	}
+	return e
+}
+
+// --- [ Vector expressions ] --------------------------------------------------
+
+// foldExtractElement folds the extractelement expression e of a constant
+// vector and constant index. The expression e is returned if it cannot be
+// folded.
+func foldExtractElement(e *ExprExtractElement) Constant {
+	typ := e.Type()
+	switch {
+	case isPoison(e.X), isPoison(e.Index), isUndef(e.Index):
+		return NewPoison(typ)
+	case isUndef(e.X):
+		return NewUndef(typ)
+	}
+	elems, ok := vectorElems(e.X)
+	if !ok {
+		return e
+	}
+	index, ok := e.Index.(*Int)
+	if !ok {
+		return e
+	}
+	i := toUnsigned(index.X, index.Typ.BitSize)
+	if !i.IsInt64() || i.Int64() >= int64(len(elems)) {
+		// Out of bounds index.
+		return NewPoison(typ)
+	}
+	return elems[i.Int64()]
+}
+
+// foldInsertElement folds the insertelement expression e of a constant vector
+// and constant index. The expression e is returned if it cannot be folded.
+func foldInsertElement(e *ExprInsertElement) Constant {
+	typ := e.Type()
+	if isPoison(e.Index) || isUndef(e.Index) {
+		return NewPoison(typ)
+	}
+	elems, ok := vectorElems(e.X)
+	if !ok {
+		return e
+	}
+	index, ok := e.Index.(*Int)
+	if !ok {
+		return e
+	}
+	i := toUnsigned(index.X, index.Typ.BitSize)
+	if !i.IsInt64() || i.Int64() >= int64(len(elems)) {
+		// Out of bounds index.
+		return NewPoison(typ)
+	}
+	elems[i.Int64()] = e.Elem
+	return NewVector(typ.(*types.VectorType), elems...)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// foldElementwise folds the scalar operation op on the operands x and y of the
+// expression e with the given result type; applied element-wise if the result
+// type is a vector type. The expression e is returned if op fails to fold any
+// element.
+func foldElementwise(e Expression, typ types.Type, op func(x, y Constant) (Constant, bool), x, y Constant) Constant {
+	t, ok := typ.(*types.VectorType)
+	if !ok {
+		if r, ok := op(x, y); ok {
+			return r
+		}
+		return e
+	}
+	if t.Scalable {
+		return e
+	}
+	xs, ok := vectorElems(x)
+	if !ok {
+		return e
+	}
+	ys, ok := vectorElems(y)
+	if !ok {
+		return e
+	}
+	elems := make([]Constant, len(xs))
+	for i := range xs {
+		r, ok := op(xs[i], ys[i])
+		if !ok {
+			return e
+		}
+		elems[i] = r
+	}
+	return NewVector(t, elems...)
+}
+
+// vectorElems returns a copy of the elements of the given constant of fixed
+// vector type. The boolean return value indicates success.
+func vectorElems(c Constant) ([]Constant, bool) {
+	t, ok := c.Type().(*types.VectorType)
+	if !ok || t.Scalable {
+		return nil, false
+	}
+	elems := make([]Constant, t.Len)
+	switch c := c.(type) {
+	case *Vector:
+		copy(elems, c.Elems)
+	case *ZeroInitializer:
+		for i := range elems {
+			elems[i] = zeroElem(t.ElemType)
+		}
+	case *Undef:
+		for i := range elems {
+			elems[i] = NewUndef(t.ElemType)
+		}
+	case *Poison:
+		for i := range elems {
+			elems[i] = NewPoison(t.ElemType)
+		}
+	default:
+		return nil, false
+	}
+	return elems, true
+}
+
+// zeroElem returns the zero value of the given vector element type.
+func zeroElem(typ types.Type) Constant {
+	switch t := typ.(type) {
+	case *types.IntType:
+		return NewInt(t, 0)
+	case *types.FloatType:
+		return NewFloat(t, 0)
+	case *types.PointerType:
+		return NewNull(t)
+	}
+	return NewZeroInitializer(typ)
+}
+
+// scalarType returns the element type of the given vector type, or typ if not
+// a vector type.
+func scalarType(typ types.Type) types.Type {
+	if t, ok := typ.(*types.VectorType); ok {
+		return t.ElemType
+	}
+	return typ
+}
+
+// intOperand returns the integer constant of the given integer constant or
+// integer zero initializer. The boolean return value indicates success.
+func intOperand(c Constant) (*Int, bool) {
+	switch c := c.(type) {
+	case *Int:
+		return c, true
+	case *ZeroInitializer:
+		if t, ok := c.Typ.(*types.IntType); ok {
+			return NewInt(t, 0), true
+		}
+	}
+	return nil, false
+}
+
+// floatOperand returns the floating-point constant of the given floating-point
+// constant or floating-point zero initializer. The boolean return value
+// indicates success.
+func floatOperand(c Constant) (*Float, bool) {
+	switch c := c.(type) {
+	case *Float:
+		return c, true
+	case *ZeroInitializer:
+		if t, ok := c.Typ.(*types.FloatType); ok {
+			return NewFloat(t, 0), true
+		}
+	}
+	return nil, false
+}
+
+// floatFromBits returns the floating-point constant of the given type based on
+// the given bit representation. The boolean return value indicates success.
+func floatFromBits(typ *types.FloatType, bits *big.Int) (*Float, bool) {
+	if !bits.IsUint64() {
+		return nil, false
+	}
+	b := bits.Uint64()
+	switch typ.Kind {
+	case types.FloatKindHalf:
+		x, nan := binary16.NewFromBits(uint16(b)).Big()
+		return &Float{Typ: typ, X: x, NaN: nan}, true
+	case types.FloatKindBFloat:
+		x, nan := bfloatFromBits(uint16(b))
+		return &Float{Typ: typ, X: x, NaN: nan}, true
+	case types.FloatKindFloat:
+		f := math.Float32frombits(uint32(b))
+		if math.IsNaN(float64(f)) {
+			return NewFloat(typ, float64(f)), true
+		}
+		const precision = 24
+		return &Float{Typ: typ, X: big.NewFloat(float64(f)).SetPrec(precision)}, true
+	case types.FloatKindDouble:
+		return NewFloat(typ, math.Float64frombits(b)), true
+	}
+	return nil, false
+}
@@ -0,0 +1,154 @@
+package constant
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestFold(t *testing.T) {
+	i32 := types.I32
+	c := func(x int64) *Int { return NewInt(i32, x) }
+	undef := NewUndef(i32)
+	poison := NewPoison(i32)
+	nsw := []enum.OverflowFlag{enum.OverflowFlagNSW}
+	nuw := []enum.OverflowFlag{enum.OverflowFlagNUW}
+	arr := types.NewArray(4, i32)
+	bf := func(s string) *Float {
+		x, err := NewFloatFromString(types.BFloat, s)
+		if err != nil {
+			t.Fatalf("unable to parse bfloat %q; %v", s, err)
+		}
+		return x
+	}
+	bfVec := NewVector(types.NewVector(4, types.BFloat), bf("1.0"), bf("-2.5"), bf("0xR3DCD"), bf("0xR7F80"))
+	nan := NewFloat(types.Double, math.NaN())
+	vec := NewVector(types.NewVector(2, i32), c(1), c(2))
+	halfVec := NewVector(types.NewVector(2, types.Half), NewFloat(types.Half, 0.5), NewFloat(types.Half, 65504))
+	golden := []struct {
+		in   Expression
+		want string
+	}{
+		// Integer constant operands.
+		{in: NewAdd(c(2), c(3)), want: "i32 5"},
+		{in: NewAdd(c(2147483647), c(1)), want: "i32 -2147483648"},
+		{in: &ExprAdd{X: c(2147483647), Y: c(1), OverflowFlags: nsw}, want: "i32 poison"},
+		{in: &ExprAdd{X: c(-1), Y: c(1), OverflowFlags: nuw}, want: "i32 poison"},
+		{in: NewSub(c(2), c(3)), want: "i32 -1"},
+		{in: &ExprSub{X: c(2), Y: c(3), OverflowFlags: nuw}, want: "i32 poison"},
+		{in: NewMul(c(-2), c(3)), want: "i32 -6"},
+		{in: NewUDiv(c(-1), c(2)), want: "i32 2147483647"},
+		{in: &ExprUDiv{X: c(5), Y: c(2), Exact: true}, want: "i32 poison"},
+		{in: NewSDiv(c(-7), c(2)), want: "i32 -3"},
+		{in: NewSDiv(c(-2147483648), c(-1)), want: "i32 poison"},
+		{in: NewURem(c(-1), c(10)), want: "i32 5"},
+		{in: NewSRem(c(-7), c(2)), want: "i32 -1"},
+		{in: NewShl(c(1), c(31)), want: "i32 -2147483648"},
+		{in: &ExprShl{X: c(1), Y: c(31), OverflowFlags: nsw}, want: "i32 poison"},
+		{in: NewShl(c(1), c(32)), want: "i32 poison"},
+		{in: NewLShr(c(-1), c(28)), want: "i32 15"},
+		{in: &ExprLShr{X: c(3), Y: c(1), Exact: true}, want: "i32 poison"},
+		{in: NewAShr(c(-16), c(2)), want: "i32 -4"},
+		{in: NewAnd(c(12), c(10)), want: "i32 8"},
+		{in: NewOr(c(12), c(10)), want: "i32 14"},
+		{in: &ExprOr{X: c(12), Y: c(3), Disjoint: true}, want: "i32 15"},
+		{in: &ExprOr{X: c(12), Y: c(10), Disjoint: true}, want: "i32 poison"},
+		{in: NewXor(c(12), c(10)), want: "i32 6"},
+		{in: NewXor(True, True), want: "i1 false"},
+		{in: NewAdd(True, True), want: "i1 false"},
+		// Division by zero.
+		{in: NewUDiv(c(5), c(0)), want: "i32 poison"},
+		{in: NewSDiv(c(5), c(0)), want: "i32 poison"},
+		{in: NewURem(c(5), c(0)), want: "i32 poison"},
+		{in: NewSRem(c(5), c(0)), want: "i32 poison"},
+		// Undefined operands.
+		{in: NewAdd(undef, c(5)), want: "i32 undef"},
+		{in: NewSub(c(5), undef), want: "i32 undef"},
+		{in: NewMul(undef, c(5)), want: "i32 0"},
+		{in: NewUDiv(undef, c(5)), want: "i32 0"},
+		{in: NewSDiv(undef, c(5)), want: "i32 0"},
+		{in: NewURem(undef, c(5)), want: "i32 0"},
+		{in: NewSRem(undef, c(5)), want: "i32 0"},
+		{in: NewUDiv(c(5), undef), want: "i32 poison"},
+		{in: NewSDiv(c(5), undef), want: "i32 poison"},
+		{in: NewURem(c(5), undef), want: "i32 poison"},
+		{in: NewSRem(c(5), undef), want: "i32 poison"},
+		{in: NewShl(undef, c(5)), want: "i32 0"},
+		{in: NewLShr(undef, c(5)), want: "i32 0"},
+		{in: NewAShr(undef, c(5)), want: "i32 0"},
+		{in: NewShl(c(5), undef), want: "i32 poison"},
+		{in: NewLShr(c(5), undef), want: "i32 poison"},
+		{in: NewAShr(c(5), undef), want: "i32 poison"},
+		{in: NewShl(undef, c(32)), want: "i32 poison"},
+		{in: NewLShr(undef, c(32)), want: "i32 poison"},
+		{in: NewAShr(undef, c(33)), want: "i32 poison"},
+		{in: NewAnd(undef, c(0)), want: "i32 0"},
+		{in: NewAnd(undef, c(5)), want: "i32 0"},
+		{in: NewOr(undef, c(5)), want: "i32 -1"},
+		{in: NewXor(undef, c(5)), want: "i32 undef"},
+		{in: NewXor(undef, undef), want: "i32 0"},
+		// Poison operands.
+		{in: NewAdd(poison, c(5)), want: "i32 poison"},
+		{in: NewSub(c(5), poison), want: "i32 poison"},
+		{in: NewMul(poison, c(5)), want: "i32 poison"},
+		{in: NewMul(poison, c(0)), want: "i32 poison"},
+		{in: NewUDiv(poison, c(5)), want: "i32 poison"},
+		{in: NewSDiv(c(5), poison), want: "i32 poison"},
+		{in: NewURem(poison, c(5)), want: "i32 poison"},
+		{in: NewSRem(c(5), poison), want: "i32 poison"},
+		{in: NewShl(poison, c(5)), want: "i32 poison"},
+		{in: NewLShr(c(5), poison), want: "i32 poison"},
+		{in: NewAShr(poison, c(5)), want: "i32 poison"},
+		{in: NewAnd(poison, c(0)), want: "i32 poison"},
+		{in: NewOr(poison, c(-1)), want: "i32 poison"},
+		{in: NewXor(poison, undef), want: "i32 poison"},
+		// Getelementptr with all-zero indices.
+		{in: NewGetElementPtr(NewNull(types.NewPointer(i32)), NewInt(types.I64, 0)), want: "i32* null"},
+		{in: NewGetElementPtr(NewNull(types.NewPointer(arr)), NewInt(types.I64, 0), NewInt(types.I64, 0)), want: "i32* bitcast ([4 x i32]* null to i32*)"},
+		// Floating-point conversions.
+		{in: NewFPExt(bfVec, types.NewVector(4, types.Float)), want: "<4 x float> <float 1.0, float -2.5, float 0x3FB9A00000000000, float 0x7FF0000000000000>"},
+		{in: NewFPTrunc(Fold(NewFPExt(bfVec, types.NewVector(4, types.Float))), bfVec.Typ), want: "<4 x bfloat> <bfloat 1.0, bfloat -2.5, bfloat 0xR3DCD, bfloat 0xR7F80>"},
+		{in: NewFPTrunc(NewFloat(types.Double, 0.1), types.BFloat), want: "bfloat 0xR3DCD"},
+		{in: NewFPTrunc(NewFloat(types.Double, 0.1), types.Half), want: "half 0xH2E66"},
+		{in: NewFPTrunc(NewFloat(types.Float, 1e10), types.Half), want: "half 0xH7C00"},
+		{in: NewFPTrunc(NewFloat(types.Float, 1e-7), types.Half), want: "half 0xH0002"},
+		{in: NewFPExt(halfVec, types.NewVector(2, types.Double)), want: "<2 x double> <double 0.5, double 65504.0>"},
+		{in: NewFPExt(NewUndef(types.Half), types.Float), want: "float undef"},
+		// Compare expressions.
+		{in: NewICmp(enum.IPredSLT, c(-1), c(0)), want: "i1 true"},
+		{in: NewICmp(enum.IPredULT, c(-1), c(0)), want: "i1 false"},
+		{in: NewICmp(enum.IPredEQ, undef, c(0)), want: "i1 undef"},
+		{in: NewICmp(enum.IPredEQ, poison, c(0)), want: "i1 poison"},
+		{in: NewFCmp(enum.FPredUNO, nan, NewFloat(types.Double, 1)), want: "i1 true"},
+		{in: NewFCmp(enum.FPredUNO, NewFloat(types.Double, 2), NewFloat(types.Double, 1)), want: "i1 false"},
+		{in: NewFCmp(enum.FPredONE, nan, NewFloat(types.Double, 1)), want: "i1 false"},
+		{in: NewFCmp(enum.FPredUNE, nan, nan), want: "i1 true"},
+		{in: NewFCmp(enum.FPredOGT, NewFloat(types.Double, 2), NewFloat(types.Double, 1)), want: "i1 true"},
+		// Select expressions.
+		{in: NewSelect(True, c(1), c(2)), want: "i32 1"},
+		{in: NewSelect(False, c(1), c(2)), want: "i32 2"},
+		{in: NewSelect(NewPoison(types.I1), c(1), c(2)), want: "i32 poison"},
+		// Integer conversions and bitcasts.
+		{in: NewTrunc(c(257), types.I8), want: "i8 1"},
+		{in: NewZExt(NewInt(types.I8, -1), i32), want: "i32 255"},
+		{in: NewSExt(NewInt(types.I8, -1), i32), want: "i32 -1"},
+		{in: NewZExt(NewUndef(types.I8), i32), want: "i32 0"},
+		{in: NewBitCast(NewFloat(types.Float, 1), i32), want: "i32 1065353216"},
+		{in: NewBitCast(c(1065353216), types.Float), want: "float 1.0"},
+		// Vector expressions.
+		{in: NewExtractElement(vec, c(1)), want: "i32 2"},
+		{in: NewExtractElement(vec, c(2)), want: "i32 poison"},
+		{in: NewInsertElement(vec, c(7), c(0)), want: "<2 x i32> <i32 7, i32 2>"},
+		// Not folded.
+		{in: NewAdd(c(5), NewPtrToInt(NewNull(types.I8Ptr), i32)), want: "i32 add (i32 5, i32 ptrtoint (i8* null to i32))"},
+		{in: NewGetElementPtr(NewNull(types.NewPointer(i32)), NewInt(types.I64, 1)), want: "i32* getelementptr (i32, i32* null, i64 1)"},
+	}
+	for _, g := range golden {
+		got := Fold(g.in).String()
+		if got != g.want {
+			t.Errorf("result mismatch of folding %q; expected %q, got %q", g.in.Ident(), g.want, got)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package constant
+
+import "github.com/llir/llvm/ir/value"
+
+// === [ Value kinds ] =========================================================
+
+// --- [ Simple constants ] ----------------------------------------------------
+
+// ValueKind returns the kind of the value.
+func (*Int) ValueKind() value.Kind {
+	return value.KindIntConstant
+}
+
+// ValueKind returns the kind of the value.
+func (*Float) ValueKind() value.Kind {
+	return value.KindFloatConstant
+}
+
+// ValueKind returns the kind of the value.
+func (*Null) ValueKind() value.Kind {
+	return value.KindNullConstant
+}
+
+// ValueKind returns the kind of the value.
+func (*NoneToken) ValueKind() value.Kind {
+	return value.KindNoneToken
+}
+
+// ValueKind returns the kind of the value.
+func (*Struct) ValueKind() value.Kind {
+	return value.KindStructConstant
+}
+
+// ValueKind returns the kind of the value.
+func (*Array) ValueKind() value.Kind {
+	return value.KindArrayConstant
+}
+
+// ValueKind returns the kind of the value.
+func (*CharArray) ValueKind() value.Kind {
+	return value.KindArrayConstant
+}
+
+// ValueKind returns the kind of the value.
+func (*Vector) ValueKind() value.Kind {
+	return value.KindVectorConstant
+}
+
+// ValueKind returns the kind of the value.
+func (*ZeroInitializer) ValueKind() value.Kind {
+	return value.KindZeroInitializer
+}
+
+// ValueKind returns the kind of the value.
+func (*Undef) ValueKind() value.Kind {
+	return value.KindUndef
+}
+
+// ValueKind returns the kind of the value.
+func (*Poison) ValueKind() value.Kind {
+	return value.KindPoison
+}
+
+// ValueKind returns the kind of the value.
+func (*BlockAddress) ValueKind() value.Kind {
+	return value.KindBlockAddress
+}
+
+// --- [ Constant expressions ] ------------------------------------------------
+
+// ValueKind returns the kind of the value.
+func (*ExprFNeg) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprAdd) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFAdd) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprSub) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFSub) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprMul) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFMul) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprUDiv) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprSDiv) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFDiv) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprURem) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprSRem) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFRem) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprShl) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprLShr) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprAShr) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprAnd) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprOr) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprXor) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprExtractElement) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprInsertElement) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprShuffleVector) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprExtractValue) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprInsertValue) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprGetElementPtr) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprTrunc) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprZExt) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprSExt) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFPTrunc) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFPExt) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFPToUI) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFPToSI) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprUIToFP) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprSIToFP) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprPtrToInt) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprIntToPtr) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprBitCast) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprAddrSpaceCast) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprICmp) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprFCmp) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
+
+// ValueKind returns the kind of the value.
+func (*ExprSelect) ValueKind() value.Kind {
+	return value.KindConstantExpr
+}
@@ -0,0 +1,375 @@
+package ir
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/llir/llvm/ir/types"
+	"github.com/pkg/errors"
+)
+
+// === [ Data layout ] =========================================================
+
+// DataLayout is a data layout, which specifies the endianness of a target and
+// the sizes and alignments of types in memory.
+//
+// Types which are not specified by the data layout string use the default
+// specifications of LLVM; e.g. 64-bit pointers and 32-bit ABI alignment of i64.
+type DataLayout struct {
+	// Endianness; 'e' for little-endian, 'E' for big-endian, or 0 if not
+	// specified.
+	endianness byte
+	// Pointer specifications, keyed by address space.
+	pointers map[uint]layoutSpec
+	// Integer type specifications, keyed by bit size.
+	ints map[uint64]layoutSpec
+	// Floating-point type specifications, keyed by bit size.
+	floats map[uint64]layoutSpec
+	// Vector type specifications, keyed by bit size.
+	vectors map[uint64]layoutSpec
+}
+
+// layoutSpec is the size and ABI alignment in bits of a type.
+type layoutSpec struct {
+	// Size in bits.
+	size uint64
+	// ABI alignment in bits.
+	abi uint64
+}
+
+// ParseDataLayout parses the given data layout string (e.g.
+// "e-m:e-i64:64-f80:128-n8:16:32:64-S128").
+func ParseDataLayout(layout string) (*DataLayout, error) {
+	dl := &DataLayout{
+		pointers: map[uint]layoutSpec{0: {size: 64, abi: 64}},
+		ints: map[uint64]layoutSpec{
+			1:  {size: 1, abi: 8},
+			8:  {size: 8, abi: 8},
+			16: {size: 16, abi: 16},
+			32: {size: 32, abi: 32},
+			64: {size: 64, abi: 32},
+		},
+		floats: map[uint64]layoutSpec{
+			16:  {size: 16, abi: 16},
+			32:  {size: 32, abi: 32},
+			64:  {size: 64, abi: 64},
+			128: {size: 128, abi: 128},
+		},
+		vectors: map[uint64]layoutSpec{
+			64:  {size: 64, abi: 64},
+			128: {size: 128, abi: 128},
+		},
+	}
+	if len(layout) == 0 {
+		return dl, nil
+	}
+	for _, spec := range strings.Split(layout, "-") {
+		if len(spec) == 0 {
+			return nil, errors.Errorf("invalid empty specification in data layout %q", layout)
+		}
+		switch spec[0] {
+		case 'e', 'E':
+			if len(spec) != 1 {
+				return nil, errors.Errorf("invalid endianness specification %q in data layout %q", spec, layout)
+			}
+			dl.endianness = spec[0]
+		case 'p', 'i', 'f', 'v':
+			fields := strings.Split(spec[1:], ":")
+			if len(fields) < 2 {
+				return nil, errors.Errorf("invalid type specification %q in data layout %q", spec, layout)
+			}
+			var nums []uint64
+			for _, field := range fields {
+				if len(field) == 0 {
+					nums = append(nums, 0)
+					continue
+				}
+				n, err := strconv.ParseUint(field, 10, 32)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid type specification %q in data layout %q", spec, layout)
+				}
+				nums = append(nums, n)
+			}
+			switch spec[0] {
+			case 'p':
+				// p[n]:<size>:<abi>[:<pref>][:<idx>]
+				abi := nums[1]
+				if len(nums) >= 3 && nums[2] != 0 {
+					abi = nums[2]
+				}
+				dl.pointers[uint(nums[0])] = layoutSpec{size: nums[1], abi: abi}
+			case 'i':
+				// i<size>:<abi>[:<pref>]
+				dl.ints[nums[0]] = layoutSpec{size: nums[0], abi: nums[1]}
+			case 'f':
+				// f<size>:<abi>[:<pref>]
+				dl.floats[nums[0]] = layoutSpec{size: nums[0], abi: nums[1]}
+			case 'v':
+				// v<size>:<abi>[:<pref>]
+				dl.vectors[nums[0]] = layoutSpec{size: nums[0], abi: nums[1]}
+			}
+		default:
+			// Ignore specifications which do not affect type layout (e.g. mangling,
+			// native integer widths, stack alignment and aggregate alignment).
+		}
+	}
+	return dl, nil
+}
+
+// IsLittleEndian reports whether the data layout specifies a little-endian
+// target; the default if endianness is not specified.
+func (dl *DataLayout) IsLittleEndian() bool {
+	return dl.endianness != 'E'
+}
+
+// PointerSize returns the size in bytes of pointers in the given address space.
+// If the address space is not specified by the data layout, the pointer size
+// of the default address space is used.
+func (dl *DataLayout) PointerSize(addrSpace uint) uint64 {
+	return bitsToBytes(dl.pointerSpec(addrSpace).size)
+}
+
+// TypeStoreSize returns the maximum number of bytes which may be written when
+// storing a value of the given type.
+func (dl *DataLayout) TypeStoreSize(t types.Type) uint64 {
+	return bitsToBytes(dl.typeSizeInBits(t))
+}
+
+// TypeAllocSize returns the offset in bytes between successive values of the
+// given type (e.g. elements of an array), including alignment padding.
+func (dl *DataLayout) TypeAllocSize(t types.Type) uint64 {
+	return alignTo(dl.TypeStoreSize(t), dl.ABIAlign(t))
+}
+
+// ABIAlign returns the minimum ABI alignment in bytes of the given type.
+func (dl *DataLayout) ABIAlign(t types.Type) uint64 {
+	switch t := t.(type) {
+	case *types.IntType:
+		return bitsToBytes(lookupSpec(dl.ints, t.BitSize).abi)
+	case *types.FloatType:
+		size := dl.typeSizeInBits(t)
+		if spec, ok := dl.floats[size]; ok {
+			return bitsToBytes(spec.abi)
+		}
+		return nextPowerOfTwo(bitsToBytes(size))
+	case *types.MMXType:
+		if spec, ok := dl.vectors[64]; ok {
+			return bitsToBytes(spec.abi)
+		}
+		return 8
+	case *types.PointerType:
+		return bitsToBytes(dl.pointerSpec(uint(t.AddrSpace)).abi)
+	case *types.VectorType:
+		size := dl.typeSizeInBits(t)
+		if spec, ok := dl.vectors[size]; ok {
+			return bitsToBytes(spec.abi)
+		}
+		return nextPowerOfTwo(bitsToBytes(size))
+	case *types.ArrayType:
+		return dl.ABIAlign(t.ElemType)
+	case *types.StructType:
+		if t.Packed {
+			return 1
+		}
+		align := uint64(1)
+		for _, field := range t.Fields {
+			if a := dl.ABIAlign(field); a > align {
+				align = a
+			}
+		}
+		return align
+	default:
+		return 1
+	}
+}
+
+// StructFieldOffset returns the offset in bytes of the given field of the
+// struct type.
+func (dl *DataLayout) StructFieldOffset(t *types.StructType, field int) uint64 {
+	offset := uint64(0)
+	for i, f := range t.Fields {
+		if !t.Packed {
+			offset = alignTo(offset, dl.ABIAlign(f))
+		}
+		if i == field {
+			break
+		}
+		offset += dl.TypeAllocSize(f)
+	}
+	return offset
+}
+
+// typeSizeInBits returns the size in bits of the given type.
+func (dl *DataLayout) typeSizeInBits(t types.Type) uint64 {
+	switch t := t.(type) {
+	case *types.IntType:
+		return t.BitSize
+	case *types.FloatType:
+		switch t.Kind {
+		case types.FloatKindHalf, types.FloatKindBFloat:
+			return 16
+		case types.FloatKindFloat:
+			return 32
+		case types.FloatKindDouble:
+			return 64
+		case types.FloatKindX86_FP80:
+			return 80
+		default:
+			// fp128 and ppc_fp128.
+			return 128
+		}
+	case *types.MMXType:
+		return 64
+	case *types.PointerType:
+		return dl.pointerSpec(uint(t.AddrSpace)).size
+	case *types.VectorType:
+		return t.Len * dl.typeSizeInBits(t.ElemType)
+	case *types.ArrayType:
+		return 8 * t.Len * dl.TypeAllocSize(t.ElemType)
+	case *types.StructType:
+		if len(t.Fields) == 0 {
+			return 0
+		}
+		size := dl.StructFieldOffset(t, len(t.Fields)-1) + dl.TypeAllocSize(t.Fields[len(t.Fields)-1])
+		if !t.Packed {
+			size = alignTo(size, dl.ABIAlign(t))
+		}
+		return 8 * size
+	default:
+		return 0
+	}
+}
+
+// pointerSpec returns the specification of pointers in the given address space,
+// falling back to the default address space.
+func (dl *DataLayout) pointerSpec(addrSpace uint) layoutSpec {
+	if spec, ok := dl.pointers[addrSpace]; ok {
+		return spec
+	}
+	return dl.pointers[0]
+}
+
+// --- [ Module data layout ] --------------------------------------------------
+
+// PointerSize returns the size in bytes of pointers in the given address space,
+// as specified by the data layout of the module. If the data layout does not
+// specify the pointer size of the address space, the pointer size of the
+// default address space is used; and if not present, the default pointer size
+// of LLVM data layouts (8 bytes).
+//
+// If the module has no data layout, the default pointer size of the target
+// triple of the module is used (8 bytes for 64-bit architectures and 4 bytes
+// otherwise, where unknown architectures are treated as 64-bit).
+func (m *Module) PointerSize(addrSpace uint) uint64 {
+	if len(m.DataLayout) > 0 {
+		if dl, err := ParseDataLayout(m.DataLayout); err == nil {
+			return dl.PointerSize(addrSpace)
+		}
+	}
+	if is32BitArch(tripleArch(m.TargetTriple)) {
+		return 4
+	}
+	return 8
+}
+
+// IsLittleEndian reports whether the target of the module is little-endian, as
+// specified by the data layout of the module. If the data layout does not
+// specify endianness, the default endianness of the target triple of the module
+// is used (where unknown architectures are treated as little-endian).
+func (m *Module) IsLittleEndian() bool {
+	if dl, err := ParseDataLayout(m.DataLayout); err == nil && dl.endianness != 0 {
+		return dl.IsLittleEndian()
+	}
+	return !isBigEndianArch(tripleArch(m.TargetTriple))
+}
+
+// ### [ Helper functions ] ####################################################
+
+// lookupSpec returns the integer type specification of the given bit size; or
+// if not present, the specification of the smallest larger integer type; or if
+// not present, the specification of the largest integer type.
+func lookupSpec(specs map[uint64]layoutSpec, size uint64) layoutSpec {
+	if spec, ok := specs[size]; ok {
+		return spec
+	}
+	var best, largest layoutSpec
+	for s, spec := range specs {
+		if s > size && (best.size == 0 || s < best.size) {
+			best = spec
+		}
+		if s > largest.size {
+			largest = spec
+		}
+	}
+	if best.size != 0 {
+		return best
+	}
+	return largest
+}
+
+// bitsToBytes returns the number of bytes required to store the given number of
+// bits.
+func bitsToBytes(bits uint64) uint64 {
+	return (bits + 7) / 8
+}
+
+// alignTo returns the given offset rounded up to a multiple of align.
+func alignTo(offset, align uint64) uint64 {
+	if align == 0 {
+		return offset
+	}
+	return (offset + align - 1) / align * align
+}
+
+// nextPowerOfTwo returns the smallest power of two greater than or equal to n.
+func nextPowerOfTwo(n uint64) uint64 {
+	p := uint64(1)
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// tripleArch returns the architecture of the given target triple; e.g.
+// "x86_64" of "x86_64-unknown-linux-gnu".
+func tripleArch(triple string) string {
+	if pos := strings.IndexByte(triple, '-'); pos != -1 {
+		return triple[:pos]
+	}
+	return triple
+}
+
+// is32BitArch reports whether the given architecture of a target triple has
+// 32-bit pointers.
+func is32BitArch(arch string) bool {
+	switch {
+	case arch == "i386", arch == "i486", arch == "i586", arch == "i686", arch == "x86":
+		return true
+	case arch == "arm", arch == "armeb", arch == "thumb", arch == "thumbeb", arch == "arm64_32":
+		return true
+	case isSubArch(arch, "armv"), isSubArch(arch, "armebv"), isSubArch(arch, "thumbv"), isSubArch(arch, "thumbebv"):
+		// ARM sub-architectures; e.g. "armv7a" or "thumbv7em".
+		return true
+	case arch == "mips", arch == "mipsel", arch == "powerpc", arch == "ppc", arch == "powerpcle":
+		return true
+	case arch == "sparc", arch == "sparcel", arch == "riscv32", arch == "wasm32", arch == "nvptx", arch == "hexagon", arch == "msp430", arch == "xcore", arch == "le32", arch == "lanai":
+		return true
+	}
+	return false
+}
+
+// isSubArch reports whether the given architecture of a target triple is a
+// versioned sub-architecture of the given prefix; e.g. "armv7" of "armv".
+func isSubArch(arch, prefix string) bool {
+	return strings.HasPrefix(arch, prefix) && len(arch) > len(prefix) && '0' <= arch[len(prefix)] && arch[len(prefix)] <= '9'
+}
+
+// isBigEndianArch reports whether the given architecture of a target triple is
+// big-endian.
+func isBigEndianArch(arch string) bool {
+	switch arch {
+	case "armeb", "thumbeb", "aarch64_be", "mips", "mips64", "powerpc", "ppc", "powerpc64", "ppc64", "sparc", "sparcv9", "sparc64", "s390x", "systemz", "lanai", "tce", "bpfeb":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestModulePointerSize(t *testing.T) {
+	golden := []struct {
+		layout, triple string
+		addrSpace      uint
+		size           uint64
+		littleEndian   bool
+	}{
+		// Explicit data layout.
+		{layout: "e-m:e-i64:64-f80:128-n8:16:32:64-S128", triple: "x86_64-unknown-linux-gnu", size: 8, littleEndian: true},
+		{layout: "e-m:x-p:32:32-i64:64-f80:32-n8:16:32-a:0:32-S32", triple: "x86_64-pc-windows-msvc", size: 4, littleEndian: true},
+		{layout: "e-p:64:64-p3:32:32-p5:32:32", addrSpace: 3, size: 4, littleEndian: true},
+		{layout: "e-p:64:64-p3:32:32-p5:32:32", addrSpace: 1, size: 8, littleEndian: true},
+		{layout: "E-m:e-p:32:32-i64:64-n32", triple: "x86_64-unknown-linux-gnu", size: 4, littleEndian: false},
+		// Default pointer size of data layout.
+		{layout: "e-m:o-i64:64-i128:128-n32:64-S128", triple: "arm64-apple-macosx14.0.0", size: 8, littleEndian: true},
+		{layout: "e-m:e-i64:64-n32", triple: "i686-pc-linux-gnu", size: 8, littleEndian: true},
+		// Defaults of target triple.
+		{triple: "x86_64-unknown-linux-gnu", size: 8, littleEndian: true},
+		{triple: "i686-pc-linux-gnu", size: 4, littleEndian: true},
+		{triple: "armv7-unknown-linux-gnueabihf", size: 4, littleEndian: true},
+		{triple: "thumbv7em-none-eabi", size: 4, littleEndian: true},
+		{triple: "arm64-apple-macosx14.0.0", size: 8, littleEndian: true},
+		{triple: "arm64_32-apple-watchos", size: 4, littleEndian: true},
+		{triple: "aarch64-unknown-linux-gnu", size: 8, littleEndian: true},
+		{triple: "powerpc64-unknown-linux-gnu", size: 8, littleEndian: false},
+		{triple: "mips-unknown-linux-gnu", size: 4, littleEndian: false},
+		{layout: "m:e-i64:64", triple: "s390x-unknown-linux-gnu", size: 8, littleEndian: false},
+		// Defaults without target triple.
+		{size: 8, littleEndian: true},
+	}
+	for _, g := range golden {
+		m := NewModule()
+		m.DataLayout = g.layout
+		m.TargetTriple = g.triple
+		if got := m.PointerSize(g.addrSpace); got != g.size {
+			t.Errorf("pointer size mismatch of address space %d (datalayout %q, triple %q); expected %d, got %d", g.addrSpace, g.layout, g.triple, g.size, got)
+		}
+		if got := m.IsLittleEndian(); got != g.littleEndian {
+			t.Errorf("endianness mismatch (datalayout %q, triple %q); expected little-endian %v, got %v", g.layout, g.triple, g.littleEndian, got)
+		}
+	}
+}
+
+func TestDataLayoutTypeSizes(t *testing.T) {
+	dl, err := ParseDataLayout("e-m:e-p:32:32-p1:64:64-i64:64-f80:128-n8:16:32:64-S128")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	// { i8, i32, i64 }
+	st := types.NewStruct(types.I8, types.I32, types.I64)
+	golden := []struct {
+		typ                  types.Type
+		storeSize, allocSize uint64
+		align                uint64
+	}{
+		{typ: types.I1, storeSize: 1, allocSize: 1, align: 1},
+		{typ: types.I32, storeSize: 4, allocSize: 4, align: 4},
+		{typ: types.I64, storeSize: 8, allocSize: 8, align: 8},
+		{typ: types.NewInt(24), storeSize: 3, allocSize: 4, align: 4},
+		{typ: types.X86_FP80, storeSize: 10, allocSize: 16, align: 16},
+		{typ: types.I8Ptr, storeSize: 4, allocSize: 4, align: 4},
+		{typ: &types.PointerType{ElemType: types.I8, AddrSpace: 1}, storeSize: 8, allocSize: 8, align: 8},
+		{typ: types.NewArray(3, types.I16), storeSize: 6, allocSize: 6, align: 2},
+		{typ: types.NewVector(4, types.Float), storeSize: 16, allocSize: 16, align: 16},
+		{typ: st, storeSize: 16, allocSize: 16, align: 8},
+		{typ: &types.StructType{Fields: []types.Type{types.I8, types.I32}, Packed: true}, storeSize: 5, allocSize: 5, align: 1},
+	}
+	for _, g := range golden {
+		if got := dl.TypeStoreSize(g.typ); got != g.storeSize {
+			t.Errorf("store size mismatch of %v; expected %d, got %d", g.typ, g.storeSize, got)
+		}
+		if got := dl.TypeAllocSize(g.typ); got != g.allocSize {
+			t.Errorf("alloc size mismatch of %v; expected %d, got %d", g.typ, g.allocSize, got)
+		}
+		if got := dl.ABIAlign(g.typ); got != g.align {
+			t.Errorf("ABI alignment mismatch of %v; expected %d, got %d", g.typ, g.align, got)
+		}
+	}
+	for field, want := range []uint64{0, 4, 8} {
+		if got := dl.StructFieldOffset(st, field); got != want {
+			t.Errorf("field offset mismatch of field %d of %v; expected %d, got %d", field, st, want, got)
+		}
+	}
+	if _, err := ParseDataLayout("e-p:x:64"); err == nil {
+		t.Errorf("expected error for invalid data layout, got nil")
+	}
+}
@@ -0,0 +1,148 @@
+package ir
+
+// === [ Dominator tree ] ======================================================
+
+// DomTree is the dominator tree of a function.
+type DomTree struct {
+	// Entry basic block of the function; root of the dominator tree.
+	Entry *Block
+	// IDom maps from basic block to immediate dominator. The entry basic block
+	// and basic blocks unreachable from the entry basic block are not present.
+	IDom map[*Block]*Block
+
+	// extra.
+
+	// Index of reachable basic blocks in reverse post-order.
+	order map[*Block]int
+}
+
+// DomTree returns the dominator tree of the function, as computed by the
+// iterative algorithm of Cooper, Harvey and Kennedy [1].
+//
+// [1]: https://www.cs.rice.edu/~keith/EMBED/dom.pdf
+func (f *Func) DomTree() *DomTree {
+	dt := &DomTree{
+		IDom:  make(map[*Block]*Block),
+		order: make(map[*Block]int),
+	}
+	rpo := f.ReversePostOrder()
+	if len(rpo) == 0 {
+		return dt
+	}
+	dt.Entry = rpo[0]
+	for i, block := range rpo {
+		dt.order[block] = i
+	}
+	preds := f.Predecessors()
+	// The entry basic block is temporarily its own immediate dominator during
+	// computation.
+	dt.IDom[dt.Entry] = dt.Entry
+	for changed := true; changed; {
+		changed = false
+		for _, block := range rpo[1:] {
+			var idom *Block
+			for _, pred := range preds[block] {
+				if _, ok := dt.IDom[pred]; !ok {
+					// Skip unprocessed and unreachable predecessors.
+					continue
+				}
+				if idom == nil {
+					idom = pred
+					continue
+				}
+				idom = dt.intersect(pred, idom)
+			}
+			if dt.IDom[block] != idom {
+				dt.IDom[block] = idom
+				changed = true
+			}
+		}
+	}
+	delete(dt.IDom, dt.Entry)
+	return dt
+}
+
+// Dominates reports whether the basic block a dominates the basic block b. A
+// basic block dominates itself. Basic blocks unreachable from the entry basic
+// block neither dominate nor are dominated by any basic block.
+func (dt *DomTree) Dominates(a, b *Block) bool {
+	if !dt.Reachable(a) || !dt.Reachable(b) {
+		return false
+	}
+	for {
+		if a == b {
+			return true
+		}
+		idom, ok := dt.IDom[b]
+		if !ok {
+			// b is the entry basic block.
+			return false
+		}
+		b = idom
+	}
+}
+
+// Reachable reports whether the basic block is reachable from the entry basic
+// block.
+func (dt *DomTree) Reachable(block *Block) bool {
+	_, ok := dt.order[block]
+	return ok
+}
+
+// DominanceFrontiers returns the dominance frontiers of the basic blocks of
+// the function, as computed from the dominator tree of the function using the
+// algorithm of Cooper, Harvey and Kennedy [1].
+//
+// The dominance frontier of a basic block b is the set of basic blocks y such
+// that b dominates a predecessor of y but does not strictly dominate y; i.e.
+// the join points at which the definitions of b meet other definitions, where
+// phi instructions are placed during SSA construction.
+//
+// The basic blocks of each dominance frontier are in the order of the basic
+// blocks of the function. Basic blocks with an empty dominance frontier and
+// basic blocks unreachable from the entry basic block are not present.
+//
+// [1]: https://www.cs.rice.edu/~keith/EMBED/dom.pdf
+func (f *Func) DominanceFrontiers() map[*Block][]*Block {
+	dt := f.DomTree()
+	preds := f.Predecessors()
+	frontiers := make(map[*Block][]*Block)
+	for _, block := range f.Blocks {
+		if !dt.Reachable(block) {
+			continue
+		}
+		var reachablePreds []*Block
+		for _, pred := range preds[block] {
+			if dt.Reachable(pred) && !containsBlock(reachablePreds, pred) {
+				reachablePreds = append(reachablePreds, pred)
+			}
+		}
+		if len(reachablePreds) < 2 {
+			// Only join points are part of dominance frontiers.
+			continue
+		}
+		idom := dt.IDom[block]
+		for _, pred := range reachablePreds {
+			for runner := pred; runner != idom; runner = dt.IDom[runner] {
+				if !containsBlock(frontiers[runner], block) {
+					frontiers[runner] = append(frontiers[runner], block)
+				}
+			}
+		}
+	}
+	return frontiers
+}
+
+// intersect returns the nearest common dominator of the basic blocks a and b,
+// based on the partially computed dominator tree.
+func (dt *DomTree) intersect(a, b *Block) *Block {
+	for a != b {
+		for dt.order[a] > dt.order[b] {
+			a = dt.IDom[a]
+		}
+		for dt.order[b] > dt.order[a] {
+			b = dt.IDom[b]
+		}
+	}
+	return a
+}
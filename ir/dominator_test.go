@@ -0,0 +1,53 @@
+package ir_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestFuncDominanceFrontiers(t *testing.T) {
+	const src = `
+define void @f(i1 %c) {
+entry:
+	br i1 %c, label %then, label %else
+
+then:
+	br label %merge
+
+else:
+	br label %merge
+
+merge:
+	br label %loop
+
+loop:
+	br i1 %c, label %loop, label %exit
+
+exit:
+	ret void
+}`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	frontiers := f.DominanceFrontiers()
+	golden := map[string][]string{
+		"%entry": nil,
+		// The frontier of the two branch arms is the merge block.
+		"%then":  {"%merge"},
+		"%else":  {"%merge"},
+		"%merge": nil,
+		// Loop headers are part of their own dominance frontier.
+		"%loop": {"%loop"},
+		"%exit": nil,
+	}
+	for _, block := range f.Blocks {
+		want := golden[block.Ident()]
+		if got := idents(frontiers[block]); !reflect.DeepEqual(got, want) {
+			t.Errorf("dominance frontier mismatch of %s; expected %v, got %v", block.Ident(), want, got)
+		}
+	}
+}
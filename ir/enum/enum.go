@@ -212,6 +212,19 @@ const (
 	DLLStorageClassDLLImport                        // dllimport
 )
 
+//go:generate stringer -linecomment -type DbgRecordKind
+
+// DbgRecordKind is a debug record kind.
+type DbgRecordKind uint8
+
+// Debug record kinds.
+const (
+	DbgRecordKindValue   DbgRecordKind = iota // dbg_value
+	DbgRecordKindDeclare                      // dbg_declare
+	DbgRecordKindAssign                       // dbg_assign
+	DbgRecordKindLabel                        // dbg_label
+)
+
 //go:generate stringer -linecomment -type DwarfAttEncoding
 
 // DwarfAttEncoding is a DWARF attribute type encoding.
@@ -668,6 +681,36 @@ const (
 	FastMathFlagReassoc                      // reassoc
 )
 
+// FPClass is a floating-point class bitfield, as used by the nofpclass
+// attribute.
+type FPClass uint16
+
+// Floating-point classes.
+//
+// From include/llvm/ADT/FloatingPointMode.h
+const (
+	FPClassSNaN         FPClass = 1 << iota // snan
+	FPClassQNaN                             // qnan
+	FPClassNegInf                           // ninf
+	FPClassNegNormal                        // nnorm
+	FPClassNegSubnormal                     // nsub
+	FPClassNegZero                          // nzero
+	FPClassPosZero                          // pzero
+	FPClassPosSubnormal                     // psub
+	FPClassPosNormal                        // pnorm
+	FPClassPosInf                           // pinf
+)
+
+// Compound floating-point classes.
+const (
+	FPClassNaN       = FPClassSNaN | FPClassQNaN
+	FPClassInf       = FPClassNegInf | FPClassPosInf
+	FPClassNormal    = FPClassNegNormal | FPClassPosNormal
+	FPClassSubnormal = FPClassNegSubnormal | FPClassPosSubnormal
+	FPClassZero      = FPClassNegZero | FPClassPosZero
+	FPClassAll       = FPClassNaN | FPClassInf | FPClassNormal | FPClassSubnormal | FPClassZero
+)
+
 //go:generate stringer -linecomment -type FPred
 
 // FPred is a floating-point comparison predicate.
@@ -740,6 +783,20 @@ const (
 	FuncAttrWriteOnly                                   // writeonly
 )
 
+//go:generate stringer -linecomment -type GEPFlag
+
+// GEPFlag is a getelementptr flag.
+type GEPFlag uint8
+
+// getelementptr flags.
+const (
+	GEPFlagNUSW GEPFlag = iota // nusw
+	GEPFlagNUW                 // nuw
+)
+
+// GEPFlags is a set of getelementptr flags.
+type GEPFlags uint8
+
 //go:generate stringer -linecomment -type IPred
 
 // IPred is an integer comparison predicate.
@@ -781,6 +838,20 @@ const (
 	LinkageExternWeak // extern_weak
 )
 
+//go:generate stringer -linecomment -type ModRef
+
+// ModRef specifies the kind of memory access (i.e. whether memory may be read
+// or written) of a memory effects attribute.
+type ModRef uint8
+
+// Memory access kinds.
+const (
+	ModRefNone      ModRef = 0                        // none
+	ModRefRead      ModRef = 1                        // read
+	ModRefWrite     ModRef = 2                        // write
+	ModRefReadWrite ModRef = ModRefRead | ModRefWrite // readwrite
+)
+
 //go:generate stringer -linecomment -type NameTableKind
 
 // NameTableKind is a name table specifier.
@@ -813,22 +884,26 @@ type ParamAttr uint8
 
 // Parameter attributes.
 const (
-	ParamAttrByval      ParamAttr = iota // byval
-	ParamAttrInAlloca                    // inalloca
-	ParamAttrInReg                       // inreg
-	ParamAttrNest                        // nest
-	ParamAttrNoAlias                     // noalias
-	ParamAttrNoCapture                   // nocapture
-	ParamAttrNonNull                     // nonnull
-	ParamAttrReadNone                    // readnone
-	ParamAttrReadOnly                    // readonly
-	ParamAttrReturned                    // returned
-	ParamAttrSignExt                     // signext
-	ParamAttrSRet                        // sret
-	ParamAttrSwiftError                  // swifterror
-	ParamAttrSwiftSelf                   // swiftself
-	ParamAttrWriteOnly                   // writeonly
-	ParamAttrZeroExt                     // zeroext
+	ParamAttrByRef        ParamAttr = iota // byref
+	ParamAttrByval                         // byval
+	ParamAttrElementType                   // elementtype
+	ParamAttrInAlloca                      // inalloca
+	ParamAttrInReg                         // inreg
+	ParamAttrNest                          // nest
+	ParamAttrNoAlias                       // noalias
+	ParamAttrNoCapture                     // nocapture
+	ParamAttrNonNull                       // nonnull
+	ParamAttrNoUndef                       // noundef
+	ParamAttrPreallocated                  // preallocated
+	ParamAttrReadNone                      // readnone
+	ParamAttrReadOnly                      // readonly
+	ParamAttrReturned                      // returned
+	ParamAttrSignExt                       // signext
+	ParamAttrSRet                          // sret
+	ParamAttrSwiftError                    // swifterror
+	ParamAttrSwiftSelf                     // swiftself
+	ParamAttrWriteOnly                     // writeonly
+	ParamAttrZeroExt                       // zeroext
 )
 
 //go:generate stringer -linecomment -type Preemption
@@ -853,6 +928,7 @@ const (
 	ReturnAttrInReg   ReturnAttr = iota // inreg
 	ReturnAttrNoAlias                   // noalias
 	ReturnAttrNonNull                   // nonnull
+	ReturnAttrNoUndef                   // noundef
 	ReturnAttrSignExt                   // signext
 	ReturnAttrZeroExt                   // zeroext
 )
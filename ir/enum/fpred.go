@@ -0,0 +1,26 @@
+package enum
+
+// Swapped returns the floating-point comparison predicate obtained by swapping
+// the operands of the comparison; e.g. `x olt y` is equivalent to `y ogt x`.
+func (pred FPred) Swapped() FPred {
+	switch pred {
+	case FPredOGE:
+		return FPredOLE
+	case FPredOGT:
+		return FPredOLT
+	case FPredOLE:
+		return FPredOGE
+	case FPredOLT:
+		return FPredOGT
+	case FPredUGE:
+		return FPredULE
+	case FPredUGT:
+		return FPredULT
+	case FPredULE:
+		return FPredUGE
+	case FPredULT:
+		return FPredUGT
+	}
+	// false, oeq, one, ord, true, ueq, une and uno are symmetric.
+	return pred
+}
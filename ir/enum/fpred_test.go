@@ -0,0 +1,36 @@
+package enum
+
+import "testing"
+
+func TestFPred(t *testing.T) {
+	golden := []struct {
+		pred    FPred
+		swapped FPred
+	}{
+		{pred: FPredFalse, swapped: FPredFalse},
+		{pred: FPredOEQ, swapped: FPredOEQ},
+		{pred: FPredOGE, swapped: FPredOLE},
+		{pred: FPredOGT, swapped: FPredOLT},
+		{pred: FPredOLE, swapped: FPredOGE},
+		{pred: FPredOLT, swapped: FPredOGT},
+		{pred: FPredONE, swapped: FPredONE},
+		{pred: FPredORD, swapped: FPredORD},
+		{pred: FPredTrue, swapped: FPredTrue},
+		{pred: FPredUEQ, swapped: FPredUEQ},
+		{pred: FPredUGE, swapped: FPredULE},
+		{pred: FPredUGT, swapped: FPredULT},
+		{pred: FPredULE, swapped: FPredUGE},
+		{pred: FPredULT, swapped: FPredUGT},
+		{pred: FPredUNE, swapped: FPredUNE},
+		{pred: FPredUNO, swapped: FPredUNO},
+	}
+	for _, g := range golden {
+		if got := g.pred.Swapped(); got != g.swapped {
+			t.Errorf("swapped predicate mismatch of %v; expected %v, got %v", g.pred, g.swapped, got)
+		}
+		// Swapping twice yields the original predicate.
+		if got := g.pred.Swapped().Swapped(); got != g.pred {
+			t.Errorf("double swap mismatch of %v; got %v", g.pred, got)
+		}
+	}
+}
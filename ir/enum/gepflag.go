@@ -0,0 +1,49 @@
+package enum
+
+import "strings"
+
+// NewGEPFlags returns the set of the given getelementptr flags.
+func NewGEPFlags(flags ...GEPFlag) GEPFlags {
+	var set GEPFlags
+	for _, flag := range flags {
+		set = set.With(flag)
+	}
+	return set
+}
+
+// Has reports whether the set contains the given getelementptr flag.
+func (flags GEPFlags) Has(flag GEPFlag) bool {
+	return flags&(1<<flag) != 0
+}
+
+// With returns the set with the given getelementptr flag added.
+func (flags GEPFlags) With(flag GEPFlag) GEPFlags {
+	return flags | 1<<flag
+}
+
+// Without returns the set with the given getelementptr flag removed.
+func (flags GEPFlags) Without(flag GEPFlag) GEPFlags {
+	return flags &^ (1 << flag)
+}
+
+// List returns the getelementptr flags of the set, in canonical order (i.e.
+// nusw before nuw).
+func (flags GEPFlags) List() []GEPFlag {
+	var list []GEPFlag
+	for _, flag := range []GEPFlag{GEPFlagNUSW, GEPFlagNUW} {
+		if flags.Has(flag) {
+			list = append(list, flag)
+		}
+	}
+	return list
+}
+
+// String returns the getelementptr flags of the set in canonical order,
+// separated by spaces; e.g. "nusw nuw".
+func (flags GEPFlags) String() string {
+	var names []string
+	for _, flag := range flags.List() {
+		names = append(names, flag.String())
+	}
+	return strings.Join(names, " ")
+}
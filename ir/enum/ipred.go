@@ -0,0 +1,64 @@
+package enum
+
+// Swapped returns the integer comparison predicate obtained by swapping the
+// operands of the comparison; e.g. `x slt y` is equivalent to `y sgt x`.
+func (pred IPred) Swapped() IPred {
+	switch pred {
+	case IPredSGE:
+		return IPredSLE
+	case IPredSGT:
+		return IPredSLT
+	case IPredSLE:
+		return IPredSGE
+	case IPredSLT:
+		return IPredSGT
+	case IPredUGE:
+		return IPredULE
+	case IPredUGT:
+		return IPredULT
+	case IPredULE:
+		return IPredUGE
+	case IPredULT:
+		return IPredUGT
+	}
+	// eq and ne are symmetric.
+	return pred
+}
+
+// Negated returns the integer comparison predicate of the logical negation of
+// the comparison; e.g. `!(x slt y)` is equivalent to `x sge y`.
+func (pred IPred) Negated() IPred {
+	switch pred {
+	case IPredEQ:
+		return IPredNE
+	case IPredNE:
+		return IPredEQ
+	case IPredSGE:
+		return IPredSLT
+	case IPredSGT:
+		return IPredSLE
+	case IPredSLE:
+		return IPredSGT
+	case IPredSLT:
+		return IPredSGE
+	case IPredUGE:
+		return IPredULT
+	case IPredUGT:
+		return IPredULE
+	case IPredULE:
+		return IPredUGT
+	case IPredULT:
+		return IPredUGE
+	}
+	return pred
+}
+
+// IsSigned reports whether the integer comparison predicate interprets its
+// operands as signed integers (sge, sgt, sle and slt).
+func (pred IPred) IsSigned() bool {
+	switch pred {
+	case IPredSGE, IPredSGT, IPredSLE, IPredSLT:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package enum
+
+import "testing"
+
+func TestIPred(t *testing.T) {
+	golden := []struct {
+		pred    IPred
+		swapped IPred
+		negated IPred
+		signed  bool
+	}{
+		{pred: IPredEQ, swapped: IPredEQ, negated: IPredNE, signed: false},
+		{pred: IPredNE, swapped: IPredNE, negated: IPredEQ, signed: false},
+		{pred: IPredSGE, swapped: IPredSLE, negated: IPredSLT, signed: true},
+		{pred: IPredSGT, swapped: IPredSLT, negated: IPredSLE, signed: true},
+		{pred: IPredSLE, swapped: IPredSGE, negated: IPredSGT, signed: true},
+		{pred: IPredSLT, swapped: IPredSGT, negated: IPredSGE, signed: true},
+		{pred: IPredUGE, swapped: IPredULE, negated: IPredULT, signed: false},
+		{pred: IPredUGT, swapped: IPredULT, negated: IPredULE, signed: false},
+		{pred: IPredULE, swapped: IPredUGE, negated: IPredUGT, signed: false},
+		{pred: IPredULT, swapped: IPredUGT, negated: IPredUGE, signed: false},
+	}
+	for _, g := range golden {
+		if got := g.pred.Swapped(); got != g.swapped {
+			t.Errorf("swapped predicate mismatch of %v; expected %v, got %v", g.pred, g.swapped, got)
+		}
+		if got := g.pred.Negated(); got != g.negated {
+			t.Errorf("negated predicate mismatch of %v; expected %v, got %v", g.pred, g.negated, got)
+		}
+		if got := g.pred.IsSigned(); got != g.signed {
+			t.Errorf("signedness mismatch of %v; expected %t, got %t", g.pred, g.signed, got)
+		}
+		// Swapping and negating twice yields the original predicate.
+		if got := g.pred.Swapped().Swapped(); got != g.pred {
+			t.Errorf("double swap mismatch of %v; got %v", g.pred, got)
+		}
+		if got := g.pred.Negated().Negated(); got != g.pred {
+			t.Errorf("double negation mismatch of %v; got %v", g.pred, got)
+		}
+	}
+}
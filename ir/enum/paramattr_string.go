@@ -8,27 +8,31 @@ func _() {
 	// An "invalid array index" compiler error signifies that the constant values have changed.
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
-	_ = x[ParamAttrByval-0]
-	_ = x[ParamAttrInAlloca-1]
-	_ = x[ParamAttrInReg-2]
-	_ = x[ParamAttrNest-3]
-	_ = x[ParamAttrNoAlias-4]
-	_ = x[ParamAttrNoCapture-5]
-	_ = x[ParamAttrNonNull-6]
-	_ = x[ParamAttrReadNone-7]
-	_ = x[ParamAttrReadOnly-8]
-	_ = x[ParamAttrReturned-9]
-	_ = x[ParamAttrSignExt-10]
-	_ = x[ParamAttrSRet-11]
-	_ = x[ParamAttrSwiftError-12]
-	_ = x[ParamAttrSwiftSelf-13]
-	_ = x[ParamAttrWriteOnly-14]
-	_ = x[ParamAttrZeroExt-15]
+	_ = x[ParamAttrByRef-0]
+	_ = x[ParamAttrByval-1]
+	_ = x[ParamAttrElementType-2]
+	_ = x[ParamAttrInAlloca-3]
+	_ = x[ParamAttrInReg-4]
+	_ = x[ParamAttrNest-5]
+	_ = x[ParamAttrNoAlias-6]
+	_ = x[ParamAttrNoCapture-7]
+	_ = x[ParamAttrNonNull-8]
+	_ = x[ParamAttrNoUndef-9]
+	_ = x[ParamAttrPreallocated-10]
+	_ = x[ParamAttrReadNone-11]
+	_ = x[ParamAttrReadOnly-12]
+	_ = x[ParamAttrReturned-13]
+	_ = x[ParamAttrSignExt-14]
+	_ = x[ParamAttrSRet-15]
+	_ = x[ParamAttrSwiftError-16]
+	_ = x[ParamAttrSwiftSelf-17]
+	_ = x[ParamAttrWriteOnly-18]
+	_ = x[ParamAttrZeroExt-19]
 }
 
-const _ParamAttr_name = "byvalinallocainregnestnoaliasnocapturenonnullreadnonereadonlyreturnedsignextsretswifterrorswiftselfwriteonlyzeroext"
+const _ParamAttr_name = "byrefbyvalelementtypeinallocainregnestnoaliasnocapturenonnullnoundefpreallocatedreadnonereadonlyreturnedsignextsretswifterrorswiftselfwriteonlyzeroext"
 
-var _ParamAttr_index = [...]uint8{0, 5, 13, 18, 22, 29, 38, 45, 53, 61, 69, 76, 80, 90, 99, 108, 115}
+var _ParamAttr_index = [...]uint8{0, 5, 10, 21, 29, 34, 38, 45, 54, 61, 68, 80, 88, 96, 104, 111, 115, 125, 134, 143, 150}
 
 func (i ParamAttr) String() string {
 	if i >= ParamAttr(len(_ParamAttr_index)-1) {
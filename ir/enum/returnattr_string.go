@@ -11,13 +11,14 @@ func _() {
 	_ = x[ReturnAttrInReg-0]
 	_ = x[ReturnAttrNoAlias-1]
 	_ = x[ReturnAttrNonNull-2]
-	_ = x[ReturnAttrSignExt-3]
-	_ = x[ReturnAttrZeroExt-4]
+	_ = x[ReturnAttrNoUndef-3]
+	_ = x[ReturnAttrSignExt-4]
+	_ = x[ReturnAttrZeroExt-5]
 }
 
-const _ReturnAttr_name = "inregnoaliasnonnullsignextzeroext"
+const _ReturnAttr_name = "inregnoaliasnonnullnoundefsignextzeroext"
 
-var _ReturnAttr_index = [...]uint8{0, 5, 12, 19, 26, 33}
+var _ReturnAttr_index = [...]uint8{0, 5, 12, 19, 26, 33, 40}
 
 func (i ReturnAttr) String() string {
 	if i >= ReturnAttr(len(_ReturnAttr_index)-1) {
@@ -54,18 +54,28 @@ func (e *evaluator) eval() uint32 {
 	if !types.Equal(f.Sig.RetType, types.I32) {
 		panic(fmt.Errorf("support for function return type %s not yet implemented", f.Sig.RetType))
 	}
-	for _, block := range f.Blocks {
+	// Follow the control flow of f from its entry basic block until a ret
+	// terminator is reached.
+	block := f.Blocks[0]
+	for {
 		switch term := block.Term.(type) {
 		case *ir.TermRet:
-			// Note: support for functions with more than one ret terminator not
-			// yet implemented.
-			if term.X != nil {
-				// The result of the first return value of a function is evaluated.
-				return e.evalValue(term.X)
+			if term.X == nil {
+				panic(fmt.Errorf("missing return value in function %q", f.Ident()))
 			}
+			return e.evalValue(term.X)
+		case *ir.TermBr:
+			block = term.Target
+		case *ir.TermCallBr:
+			// The callee of callbr is an inline assembly (asm goto) statement,
+			// the side effects and indirect targets of which cannot be modelled
+			// by the evaluator. Therefore, control flow is assumed to continue at
+			// the default (fallthrough) destination.
+			block = term.Normal
+		default:
+			panic(fmt.Errorf("support for terminator type %T not yet implemented", term))
 		}
 	}
-	panic(fmt.Errorf("unable to locate ret terminator in function %q", f.Ident()))
 }
 
 // evalInst evaluates inst and returns the corresponding 32-bit integer.
@@ -0,0 +1,222 @@
+package ir
+
+import (
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/value"
+)
+
+// FoldInst returns the constant result of the given instruction with constant
+// operands, as folded by constant.Fold. The boolean return value indicates
+// success; false if an operand is not constant or if the instruction cannot be
+// folded.
+//
+// Folded instructions include integer binary and bitwise instructions, icmp
+// and fcmp, select, trunc, zext, sext, fpext, fptrunc, bitcast, extractelement
+// and insertelement.
+//
+// Note, fcmp instructions with nnan, ninf or fast flags are not folded, as
+// their result is poison for NaN or infinite operands.
+func FoldInst(inst Instruction) (constant.Constant, bool) {
+	var e constant.Expression
+	switch inst := inst.(type) {
+	// Binary instructions.
+	case *InstAdd:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprAdd{X: x, Y: y, OverflowFlags: inst.OverflowFlags}
+	case *InstSub:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprSub{X: x, Y: y, OverflowFlags: inst.OverflowFlags}
+	case *InstMul:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprMul{X: x, Y: y, OverflowFlags: inst.OverflowFlags}
+	case *InstUDiv:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprUDiv{X: x, Y: y, Exact: inst.Exact}
+	case *InstSDiv:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprSDiv{X: x, Y: y, Exact: inst.Exact}
+	case *InstURem:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewURem(x, y)
+	case *InstSRem:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewSRem(x, y)
+	// Bitwise instructions.
+	case *InstShl:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprShl{X: x, Y: y, OverflowFlags: inst.OverflowFlags}
+	case *InstLShr:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprLShr{X: x, Y: y, Exact: inst.Exact}
+	case *InstAShr:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprAShr{X: x, Y: y, Exact: inst.Exact}
+	case *InstAnd:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewAnd(x, y)
+	case *InstOr:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = &constant.ExprOr{X: x, Y: y, Disjoint: inst.Disjoint}
+	case *InstXor:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewXor(x, y)
+	// Vector instructions.
+	case *InstExtractElement:
+		x, index, ok := constOperands2(inst.X, inst.Index)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewExtractElement(x, index)
+	case *InstInsertElement:
+		x, elem, ok := constOperands2(inst.X, inst.Elem)
+		if !ok {
+			return nil, false
+		}
+		index, ok := inst.Index.(constant.Constant)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewInsertElement(x, elem, index)
+	// Conversion instructions.
+	case *InstTrunc:
+		from, ok := inst.From.(constant.Constant)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewTrunc(from, inst.To)
+	case *InstZExt:
+		from, ok := inst.From.(constant.Constant)
+		if !ok {
+			return nil, false
+		}
+		if inst.NNeg {
+			// The result of zext nneg is poison for negative operands.
+			x, ok := from.(*constant.Int)
+			if !ok {
+				return nil, false
+			}
+			// Boolean constants are represented as 0 or 1, with 1 having the
+			// sign bit set.
+			if x.X.Sign() < 0 || (x.Typ.BitSize == 1 && x.X.Sign() != 0) {
+				return constant.NewPoison(inst.To), true
+			}
+		}
+		e = constant.NewZExt(from, inst.To)
+	case *InstSExt:
+		from, ok := inst.From.(constant.Constant)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewSExt(from, inst.To)
+	case *InstFPTrunc:
+		from, ok := inst.From.(constant.Constant)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewFPTrunc(from, inst.To)
+	case *InstFPExt:
+		from, ok := inst.From.(constant.Constant)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewFPExt(from, inst.To)
+	case *InstBitCast:
+		from, ok := inst.From.(constant.Constant)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewBitCast(from, inst.To)
+	// Other instructions.
+	case *InstICmp:
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewICmp(inst.Pred, x, y)
+	case *InstFCmp:
+		for _, flag := range inst.FastMathFlags {
+			switch flag {
+			case enum.FastMathFlagNNaN, enum.FastMathFlagNInf, enum.FastMathFlagFast:
+				return nil, false
+			}
+		}
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewFCmp(inst.Pred, x, y)
+	case *InstSelect:
+		cond, ok := inst.Cond.(constant.Constant)
+		if !ok {
+			return nil, false
+		}
+		x, y, ok := constOperands2(inst.X, inst.Y)
+		if !ok {
+			return nil, false
+		}
+		e = constant.NewSelect(cond, x, y)
+	default:
+		return nil, false
+	}
+	c := constant.Fold(e)
+	if _, ok := c.(constant.Expression); ok {
+		return nil, false
+	}
+	return c, true
+}
+
+// ### [ Helper functions ] ####################################################
+
+// constOperands2 returns the given operands as constants. The boolean return
+// value indicates whether both operands are constants.
+func constOperands2(x, y value.Value) (constant.Constant, constant.Constant, bool) {
+	cx, ok := x.(constant.Constant)
+	if !ok {
+		return nil, nil, false
+	}
+	cy, ok := y.(constant.Constant)
+	if !ok {
+		return nil, nil, false
+	}
+	return cx, cy, true
+}
@@ -0,0 +1,103 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestFoldInst(t *testing.T) {
+	const src = `
+define void @f(i32 %x) {
+entry:
+	%add = add i32 2, 3
+	%add_nsw = add nsw i32 2147483647, 1
+	%icmp_eq = icmp eq i32 5, 5
+	%icmp_slt = icmp slt i32 -1, 0
+	%icmp_ult = icmp ult i32 -1, 0
+	%icmp_vec = icmp sgt <2 x i32> <i32 1, i32 -1>, zeroinitializer
+	%fcmp_oeq = fcmp oeq double 1.0, 1.0
+	%fcmp_oeq_nan = fcmp oeq double 0x7FF8000000000000, 1.0
+	%fcmp_une_nan = fcmp une double 0x7FF8000000000000, 0x7FF8000000000000
+	%fcmp_ord_nan = fcmp ord float 1.0, 0x7FF8000000000000
+	%fcmp_uno_nan = fcmp uno double 0x7FF8000000000000, 1.0
+	%fcmp_uno = fcmp uno double 2.0, 1.0
+	%fcmp_olt = fcmp olt float -0.0, 0.0
+	%fcmp_ole = fcmp ole float -0.0, 0.0
+	%fcmp_vec = fcmp ult <2 x double> <double 1.0, double 0x7FF8000000000000>, <double 2.0, double 0.0>
+	%select = select i1 true, i32 1, i32 2
+	%select_vec = select <2 x i1> <i1 true, i1 false>, <2 x i32> <i32 1, i32 2>, <2 x i32> <i32 3, i32 4>
+	%trunc = trunc i32 257 to i8
+	%zext = zext i8 -1 to i32
+	%zext_nneg = zext nneg i8 -1 to i32
+	%sext = sext i8 -1 to i32
+	%sext_vec = sext <2 x i1> <i1 true, i1 false> to <2 x i8>
+	%bitcast = bitcast float 1.0 to i32
+	%bitcast_fp = bitcast i64 4607182418800017408 to double
+	%extractelement = extractelement <2 x i32> <i32 1, i32 2>, i32 1
+	%extractelement_oob = extractelement <2 x i32> <i32 1, i32 2>, i32 2
+	%insertelement = insertelement <2 x i32> zeroinitializer, i32 7, i32 0
+	%not_const = add i32 %x, 1
+	%fcmp_nnan = fcmp nnan oeq double 0x7FF8000000000000, 1.0
+	ret void
+}`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	golden := map[string]string{
+		"add":                "i32 5",
+		"add_nsw":            "i32 poison",
+		"icmp_eq":            "i1 true",
+		"icmp_slt":           "i1 true",
+		"icmp_ult":           "i1 false",
+		"icmp_vec":           "<2 x i1> <i1 true, i1 false>",
+		"fcmp_oeq":           "i1 true",
+		"fcmp_oeq_nan":       "i1 false",
+		"fcmp_une_nan":       "i1 true",
+		"fcmp_ord_nan":       "i1 false",
+		"fcmp_uno_nan":       "i1 true",
+		"fcmp_uno":           "i1 false",
+		"fcmp_olt":           "i1 false",
+		"fcmp_ole":           "i1 true",
+		"fcmp_vec":           "<2 x i1> <i1 true, i1 true>",
+		"select":             "i32 1",
+		"select_vec":         "<2 x i32> <i32 1, i32 4>",
+		"trunc":              "i8 1",
+		"zext":               "i32 255",
+		"zext_nneg":          "i32 poison",
+		"sext":               "i32 -1",
+		"sext_vec":           "<2 x i8> <i8 -1, i8 0>",
+		"bitcast":            "i32 1065353216",
+		"bitcast_fp":         "double 1.0",
+		"extractelement":     "i32 2",
+		"extractelement_oob": "i32 poison",
+		"insertelement":      "<2 x i32> <i32 7, i32 0>",
+		// Not folded.
+		"not_const": "",
+		"fcmp_nnan": "",
+	}
+	insts := m.Funcs[0].Blocks[0].Insts
+	if len(insts) != len(golden) {
+		t.Fatalf("instruction count mismatch; expected %d, got %d", len(golden), len(insts))
+	}
+	for _, inst := range insts {
+		name := inst.(interface{ Name() string }).Name()
+		want := golden[name]
+		c, ok := ir.FoldInst(inst)
+		if len(want) == 0 {
+			if ok {
+				t.Errorf("unexpected folding of %%%s; got %v", name, c)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("unable to fold %%%s; expected %q", name, want)
+			continue
+		}
+		if got := c.String(); got != want {
+			t.Errorf("result mismatch of folding %%%s; expected %q, got %q", name, want, got)
+		}
+	}
+}
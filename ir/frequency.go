@@ -0,0 +1,264 @@
+package ir
+
+import (
+	"math/big"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+)
+
+// === [ Block frequencies ] ===================================================
+
+// Branch weights of the loop branch heuristic, as used for terminators without
+// !prof branch weights; edges staying within a loop are assumed to be taken
+// more frequently than edges exiting the loop.
+const (
+	// Weight of edges staying within the innermost loop of a basic block.
+	loopTakenWeight = 124
+	// Weight of edges exiting the innermost loop of a basic block.
+	loopExitWeight = 4
+)
+
+// maxLoopScale is the maximum frequency of a loop header relative to the
+// frequency of entering the loop; used to bound the frequencies of loops which
+// (based on branch probabilities) never exit.
+const maxLoopScale = 4096
+
+// BlockFrequencies returns the estimated relative execution frequencies of the
+// basic blocks of the function, with the entry basic block having frequency 1.
+// Basic blocks unreachable from the entry basic block have frequency 0.
+//
+// Frequencies are propagated from the entry basic block through the control
+// flow graph, based on the branch probabilities of the terminators of each
+// basic block, as specified by !prof branch weights metadata (e.g.
+// `!{!"branch_weights", i32 90, i32 10}`, with one weight per successor basic
+// block of the terminator, in order). Terminators without valid branch weights
+// have equal probabilities for each successor, except for terminators of basic
+// blocks within loops, for which edges staying within the innermost loop are
+// assumed to be taken more frequently than edges exiting the loop.
+//
+// Loops are handled as described by Wu and Larus [1]; the cyclic probability
+// of each loop (i.e. the probability of returning to the loop header through a
+// back edge) is computed from the innermost loop outwards, and the frequency of
+// a loop header is its frequency of entry divided by one minus its cyclic
+// probability.
+//
+// [1]: https://dl.acm.org/citation.cfm?id=192725
+func (f *Func) BlockFrequencies() map[*Block]float64 {
+	freqs := make(map[*Block]float64)
+	for _, block := range f.Blocks {
+		freqs[block] = 0
+	}
+	rpo := f.ReversePostOrder()
+	if len(rpo) == 0 {
+		return freqs
+	}
+	loops := f.Loops()
+	probs := branchProbabilities(f, loops)
+	// headers maps from loop header to loop.
+	headers := make(map[*Block]*Loop)
+	for _, loop := range loops {
+		headers[loop.Header] = loop
+	}
+	preds := f.Predecessors()
+	// backProbs maps from loop header to the probabilities of reaching the loop
+	// header through each back edge (in order of latches), given that the loop
+	// header is executed.
+	backProbs := make(map[*Block][]float64)
+	propagate := func(head *Block, contains func(block *Block) bool) {
+		for _, block := range rpo {
+			if !contains(block) {
+				continue
+			}
+			if block == head {
+				freqs[block] = 1
+				continue
+			}
+			loop := headers[block]
+			freq := 0.0
+			for _, pred := range preds[block] {
+				if !contains(pred) || (loop != nil && loop.Contains(pred)) {
+					// Skip back edges.
+					continue
+				}
+				freq += freqs[pred] * probs[pred][block]
+			}
+			if loop != nil {
+				freq /= 1 - cyclicProb(backProbs[block])
+			}
+			freqs[block] = freq
+		}
+	}
+	// Compute cyclic probabilities from the innermost loop outwards.
+	for i := len(loops) - 1; i >= 0; i-- {
+		loop := loops[i]
+		propagate(loop.Header, loop.Contains)
+		for _, latch := range loop.Latches {
+			backProbs[loop.Header] = append(backProbs[loop.Header], freqs[latch]*probs[latch][loop.Header])
+		}
+	}
+	// Propagate frequencies from the entry basic block.
+	entry := rpo[0]
+	reachable := make(map[*Block]bool)
+	for _, block := range rpo {
+		reachable[block] = true
+	}
+	propagate(entry, func(block *Block) bool { return reachable[block] })
+	if _, ok := headers[entry]; ok {
+		// The entry basic block is a loop header.
+		freqs[entry] /= 1 - cyclicProb(backProbs[entry])
+	}
+	return freqs
+}
+
+// === [ Function entry counts ] ===============================================
+
+// EntryCount returns the profile entry count of the function; i.e. the number
+// of times the function was entered during profiling, as specified by the !prof
+// metadata attachment of the function (e.g. `!{!"function_entry_count", i64
+// 42}`). Synthetic entry counts (i.e. "synthetic_function_entry_count") are
+// also recognized. The boolean return value indicates success.
+func (f *Func) EntryCount() (int64, bool) {
+	for _, md := range f.Metadata {
+		if md.Name != "prof" {
+			continue
+		}
+		tuple, ok := md.Node.(*metadata.Tuple)
+		if !ok || len(tuple.Fields) < 2 {
+			return 0, false
+		}
+		name, ok := tuple.Fields[0].(*metadata.String)
+		if !ok || (name.Value != "function_entry_count" && name.Value != "synthetic_function_entry_count") {
+			return 0, false
+		}
+		count, ok := tuple.Fields[1].(*constant.Int)
+		if !ok || !count.X.IsInt64() {
+			return 0, false
+		}
+		return count.X.Int64(), true
+	}
+	return 0, false
+}
+
+// SetEntryCount sets the profile entry count of the function, as specified by
+// the !prof metadata attachment of the function. An existing !prof metadata
+// attachment of the function is replaced by a new metadata tuple, as metadata
+// tuples may be shared between functions.
+func (f *Func) SetEntryCount(count int64) {
+	tuple := &metadata.Tuple{
+		MetadataID: -1,
+		Fields: []metadata.Field{
+			&metadata.String{Value: "function_entry_count"},
+			constant.NewInt(types.I64, count),
+		},
+	}
+	for _, md := range f.Metadata {
+		if md.Name == "prof" {
+			md.Node = tuple
+			return
+		}
+	}
+	f.Metadata = append(f.Metadata, &metadata.Attachment{Name: "prof", Node: tuple})
+}
+
+// ### [ Helper functions ] ####################################################
+
+// branchProbabilities returns the branch probabilities of the control flow
+// edges of the given function, as a map from source basic block to target
+// basic block to probability. The probabilities of duplicate edges are summed.
+func branchProbabilities(f *Func, loops []*Loop) map[*Block]map[*Block]float64 {
+	// innermost maps from basic block to innermost loop containing the basic
+	// block. Loops are in reverse post-order of their loop headers, and inner
+	// loops thus follow outer loops.
+	innermost := make(map[*Block]*Loop)
+	for _, loop := range loops {
+		for _, block := range loop.Blocks {
+			innermost[block] = loop
+		}
+	}
+	probs := make(map[*Block]map[*Block]float64)
+	for _, block := range f.Blocks {
+		targets := succs(block)
+		if len(targets) == 0 {
+			continue
+		}
+		weights := branchWeights(block.Term, len(targets))
+		if weights == nil {
+			weights = make([]float64, len(targets))
+			loop := innermost[block]
+			for i, target := range targets {
+				switch {
+				case loop == nil:
+					weights[i] = 1
+				case loop.Contains(target):
+					weights[i] = loopTakenWeight
+				default:
+					weights[i] = loopExitWeight
+				}
+			}
+		}
+		total := 0.0
+		for _, weight := range weights {
+			total += weight
+		}
+		probs[block] = make(map[*Block]float64)
+		for i, target := range targets {
+			if total == 0 {
+				probs[block][target] += 1 / float64(len(targets))
+				continue
+			}
+			probs[block][target] += weights[i] / total
+		}
+	}
+	return probs
+}
+
+// branchWeights returns the branch weights specified by the !prof metadata
+// attachment of the given terminator with n successors, or nil if not present
+// or invalid.
+func branchWeights(term Terminator, n int) []float64 {
+	t, ok := term.(interface {
+		MDAttachments() []*metadata.Attachment
+	})
+	if !ok {
+		return nil
+	}
+	for _, md := range t.MDAttachments() {
+		if md.Name != "prof" {
+			continue
+		}
+		tuple, ok := md.Node.(*metadata.Tuple)
+		if !ok || len(tuple.Fields) != n+1 {
+			return nil
+		}
+		if name, ok := tuple.Fields[0].(*metadata.String); !ok || name.Value != "branch_weights" {
+			return nil
+		}
+		var weights []float64
+		for _, field := range tuple.Fields[1:] {
+			w, ok := field.(*constant.Int)
+			if !ok || w.X.Sign() < 0 {
+				return nil
+			}
+			weight, _ := new(big.Float).SetInt(w.X).Float64()
+			weights = append(weights, weight)
+		}
+		return weights
+	}
+	return nil
+}
+
+// cyclicProb returns the cyclic probability of a loop, based on the given
+// probabilities of reaching the loop header through each back edge. The cyclic
+// probability is bounded by maxLoopScale.
+func cyclicProb(backProbs []float64) float64 {
+	p := 0.0
+	for _, prob := range backProbs {
+		p += prob
+	}
+	if max := 1 - 1.0/maxLoopScale; p > max {
+		return max
+	}
+	return p
+}
@@ -0,0 +1,150 @@
+package ir_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestFuncBlockFrequencies(t *testing.T) {
+	const src = `
+define void @f(i1 %c, i1 %d) {
+entry:
+	br i1 %c, label %hot, label %cold, !prof !0
+
+hot:
+	br label %loop
+
+cold:
+	br label %loop
+
+loop:
+	br i1 %d, label %loop, label %exit, !prof !1
+
+exit:
+	ret void
+}
+
+define void @g(i1 %c, i1 %d) {
+entry:
+	br i1 %c, label %then, label %loop
+
+then:
+	br label %loop
+
+loop:
+	br i1 %d, label %loop, label %exit
+
+exit:
+	ret void
+}
+
+!0 = !{!"branch_weights", i32 90, i32 10}
+!1 = !{!"branch_weights", i32 9, i32 1}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	golden := []struct {
+		// Function index.
+		f int
+		// Expected frequency of each basic block, in order.
+		want []float64
+	}{
+		// Weighted branches; the loop header is reached 10 times per entry.
+		{f: 0, want: []float64{1, 0.9, 0.1, 10, 1}},
+		// Unweighted branches; equal probabilities outside of loops, and the loop
+		// branch heuristic (124:4) for the loop back edge.
+		{f: 1, want: []float64{1, 0.5, 32, 1}},
+	}
+	for _, g := range golden {
+		f := m.Funcs[g.f]
+		freqs := f.BlockFrequencies()
+		for i, block := range f.Blocks {
+			if got := freqs[block]; math.Abs(got-g.want[i]) > 1e-9 {
+				t.Errorf("%s: frequency mismatch of block %s; expected %v, got %v", f.Ident(), block.Ident(), g.want[i], got)
+			}
+		}
+	}
+	// The hotter successor has a higher frequency than the colder successor.
+	f := m.Funcs[0]
+	freqs := f.BlockFrequencies()
+	if hot, cold := freqs[f.Blocks[1]], freqs[f.Blocks[2]]; hot <= cold {
+		t.Errorf("expected hot block frequency (%v) to exceed cold block frequency (%v)", hot, cold)
+	}
+}
+
+func TestFuncEntryCount(t *testing.T) {
+	const src = `define i32 @f(i32 %x) !prof !0 {
+entry:
+	%c = icmp sgt i32 %x, 0, !annotation !1
+	br i1 %c, label %a, label %b, !prof !2, !misexpect !3
+
+a:
+	ret i32 1
+
+b:
+	ret i32 0
+}
+
+define void @g() !prof !0 {
+entry:
+	ret void
+}
+
+declare void @h()
+
+!0 = !{!"function_entry_count", i64 42}
+!1 = !{!"auto-init"}
+!2 = !{!"branch_weights", i32 2000, i32 1}
+!3 = !{!"misexpect", i64 0, i64 2000, i64 1}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f, g, h := m.Funcs[0], m.Funcs[1], m.Funcs[2]
+	if count, ok := f.EntryCount(); !ok || count != 42 {
+		t.Errorf("entry count mismatch; expected (42, true), got (%d, %v)", count, ok)
+	}
+	if count, ok := h.EntryCount(); ok {
+		t.Errorf("unexpected entry count %d of function without profile", count)
+	}
+	f.SetEntryCount(100)
+	h.SetEntryCount(7)
+	golden := []struct {
+		f    *ir.Func
+		want int64
+	}{
+		{f: f, want: 100},
+		// The metadata tuple shared with @f is not modified.
+		{f: g, want: 42},
+		{f: h, want: 7},
+	}
+	for _, gold := range golden {
+		if count, ok := gold.f.EntryCount(); !ok || count != gold.want {
+			t.Errorf("entry count mismatch of %q; expected (%d, true), got (%d, %v)", gold.f.Ident(), gold.want, count, ok)
+		}
+	}
+	// Other profile metadata attachments are preserved.
+	const want = `define i32 @f(i32 %x) !prof !{!"function_entry_count", i64 100} {
+entry:
+	%c = icmp sgt i32 %x, 0, !annotation !1
+	br i1 %c, label %a, label %b, !prof !2, !misexpect !3
+
+a:
+	ret i32 1
+
+b:
+	ret i32 0
+}`
+	if got := f.LLString(); got != want {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+	}
+	if got, want := h.LLString(), `declare !prof !{!"function_entry_count", i64 7} void @h()`; got != want {
+		t.Errorf("function mismatch; expected %q, got %q", want, got)
+	}
+}
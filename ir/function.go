@@ -2,6 +2,7 @@ package ir
 
 import (
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"sync"
@@ -49,9 +50,14 @@ type Func struct {
 	// (optional) Unnamed address.
 	UnnamedAddr enum.UnnamedAddr
 	// (optional) Function attributes.
+	//
+	// The alignment of the function is specified by an Align function
+	// attribute, which is printed following the comdat of the function.
 	FuncAttrs []FuncAttribute
 	// (optional) Section name; empty if not present.
 	Section string
+	// (optional) Partition name; empty if not present.
+	Partition string
 	// (optional) Comdat; nil if not present.
 	Comdat *ComdatDef
 	// (optional) Garbage collection; empty if not present.
@@ -66,6 +72,9 @@ type Func struct {
 	UseListOrders []*UseListOrder
 	// (optional) Metadata.
 	Metadata
+	// (optional) Provenance of the function in the parsed source input; nil if
+	// not present.
+	Provenance *Provenance
 
 	// mu prevents races on AssignIDs.
 	mu sync.Mutex
@@ -87,7 +96,8 @@ func NewFunc(name string, retType types.Type, params ...*Param) *Func {
 }
 
 // String returns the LLVM syntax representation of the function as a type-value
-// pair.
+// pair; e.g. `i32 (i32)* @f`, as used when the function is an operand. To print
+// the function definition or declaration, use LLString or WriteTo.
 func (f *Func) String() string {
 	return fmt.Sprintf("%s %s", f.Type(), f.Ident())
 }
@@ -104,6 +114,13 @@ func (f *Func) Type() types.Type {
 // LLString returns the LLVM syntax representation of the function definition or
 // declaration.
 func (f *Func) LLString() string {
+	return f.llString(false)
+}
+
+// llString returns the LLVM syntax representation of the function definition or
+// declaration. Basic blocks and function definitions are annotated with their
+// number of instructions as comments if instCounts is set.
+func (f *Func) llString(instCounts bool) string {
 	// Function declaration.
 	//
 	//    'declare' Metadata=MetadataAttachment* Header=FuncHeader
@@ -118,7 +135,7 @@ func (f *Func) LLString() string {
 		for _, md := range f.Metadata {
 			fmt.Fprintf(buf, " %s", md)
 		}
-		if f.Linkage != enum.LinkageNone {
+		if f.Linkage != enum.LinkageNone && f.Linkage != enum.LinkageExternal {
 			fmt.Fprintf(buf, " %s", f.Linkage)
 		}
 		buf.WriteString(headerString(f))
@@ -128,18 +145,38 @@ func (f *Func) LLString() string {
 	if err := f.AssignIDs(); err != nil {
 		panic(fmt.Errorf("unable to assign IDs of function %q; %v", f.Ident(), err))
 	}
+	if instCounts {
+		n := 0
+		for _, block := range f.Blocks {
+			n += blockInstCount(block)
+		}
+		fmt.Fprintf(buf, "%s\n", instCountComment(n))
+	}
 	buf.WriteString("define")
-	if f.Linkage != enum.LinkageNone {
+	// External linkage is the default, and is therefore omitted.
+	if f.Linkage != enum.LinkageNone && f.Linkage != enum.LinkageExternal {
 		fmt.Fprintf(buf, " %s", f.Linkage)
 	}
 	buf.WriteString(headerString(f))
 	for _, md := range f.Metadata {
 		fmt.Fprintf(buf, " %s", md)
 	}
-	fmt.Fprintf(buf, " %s", bodyString(f))
+	fmt.Fprintf(buf, " %s", bodyString(f, instCounts))
 	return buf.String()
 }
 
+// WriteTo writes the LLVM IR assembly of the function definition or declaration
+// to w (e.g. `define i32 @f(i32 %x) { ... }`), followed by a new line, without
+// the surrounding module. Unnamed local variables are assigned IDs before
+// writing.
+func (f *Func) WriteTo(w io.Writer) (n int64, err error) {
+	if err := f.AssignIDs(); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	nn, err := io.WriteString(w, f.LLString()+"\n")
+	return int64(nn), err
+}
+
 // AssignIDs assigns IDs to unnamed local variables.
 func (f *Func) AssignIDs() error {
 	if len(f.Blocks) == 0 {
@@ -201,6 +238,66 @@ func (f *Func) AssignIDs() error {
 	return nil
 }
 
+// ReplaceAllUsesWith replaces all uses of old with new in the instructions and
+// terminators of the function.
+func (f *Func) ReplaceAllUsesWith(old, new value.Value) {
+	replace := func(ops []*value.Value) {
+		for _, op := range ops {
+			if *op == old {
+				*op = new
+			}
+		}
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			replace(inst.Operands())
+		}
+		if block.Term != nil {
+			replace(block.Term.Operands())
+		}
+	}
+}
+
+// Callees returns the distinct callees of call and callbr instructions and
+// invoke terminators of the function, in order of first occurrence. Callees
+// include functions, as well as other values (e.g. function pointers of
+// indirect calls, constant expressions and inline assembly).
+func (f *Func) Callees() []value.Value {
+	var callees []value.Value
+	seen := make(map[value.Value]bool)
+	// Constant callees are identified by their LLVM syntax representation, as
+	// equal constants (e.g. bitcast expressions) may be distinct values.
+	seenConsts := make(map[string]bool)
+	add := func(callee value.Value) {
+		if c, ok := callee.(constant.Constant); ok {
+			if seenConsts[c.String()] {
+				return
+			}
+			seenConsts[c.String()] = true
+		} else {
+			if seen[callee] {
+				return
+			}
+			seen[callee] = true
+		}
+		callees = append(callees, callee)
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			if call, ok := inst.(*InstCall); ok {
+				add(call.Callee)
+			}
+		}
+		switch term := block.Term.(type) {
+		case *TermInvoke:
+			add(term.Invokee)
+		case *TermCallBr:
+			add(term.Callee)
+		}
+	}
+	return callees
+}
+
 // ### [ Helper functions ] ####################################################
 
 // headerString returns the string representation of the function header.
@@ -208,7 +305,8 @@ func headerString(f *Func) string {
 	// (Linkage | ExternLinkage)? Preemptionopt Visibilityopt DLLStorageClassopt
 	// CallingConvopt ReturnAttrs=ReturnAttribute* RetType=Type Name=GlobalIdent
 	// '(' Params ')' UnnamedAddropt AddrSpaceopt FuncAttrs=FuncAttribute*
-	// Sectionopt Comdatopt GCopt Prefixopt Prologueopt Personalityopt
+	// Sectionopt Partitionopt Comdatopt Alignopt GCopt Prefixopt Prologueopt
+	// Personalityopt
 	buf := &strings.Builder{}
 	if f.Preemption != enum.PreemptionNone {
 		fmt.Fprintf(buf, " %s", f.Preemption)
@@ -244,11 +342,18 @@ func headerString(f *Func) string {
 		fmt.Fprintf(buf, " %s", f.UnnamedAddr)
 	}
 	for _, attr := range f.FuncAttrs {
+		if _, ok := attr.(Align); ok {
+			// Printed following comdat.
+			continue
+		}
 		fmt.Fprintf(buf, " %s", attr)
 	}
 	if len(f.Section) > 0 {
 		fmt.Fprintf(buf, " section %s", quote(f.Section))
 	}
+	if len(f.Partition) > 0 {
+		fmt.Fprintf(buf, " partition %s", quote(f.Partition))
+	}
 	if f.Comdat != nil {
 		if f.Comdat.Name == f.Name() {
 			buf.WriteString(" comdat")
@@ -256,6 +361,11 @@ func headerString(f *Func) string {
 			fmt.Fprintf(buf, " %s", f.Comdat)
 		}
 	}
+	for _, attr := range f.FuncAttrs {
+		if align, ok := attr.(Align); ok {
+			fmt.Fprintf(buf, " %s", align)
+		}
+	}
 	if len(f.GC) > 0 {
 		fmt.Fprintf(buf, " gc %s", quote(f.GC))
 	}
@@ -271,8 +381,10 @@ func headerString(f *Func) string {
 	return buf.String()
 }
 
-// bodyString returns the string representation of the function body.
-func bodyString(body *Func) string {
+// bodyString returns the string representation of the function body. Basic
+// blocks are annotated with their number of instructions as comments if
+// instCounts is set.
+func bodyString(body *Func, instCounts bool) string {
 	// '{' Blocks=Block+ UseListOrders=UseListOrder* '}'
 	buf := &strings.Builder{}
 	buf.WriteString("{\n")
@@ -280,7 +392,13 @@ func bodyString(body *Func) string {
 		if i != 0 {
 			buf.WriteString("\n")
 		}
-		fmt.Fprintf(buf, "%s\n", block.LLString())
+		s := block.LLString()
+		if instCounts {
+			// Append comment to the label line of the basic block.
+			pos := strings.IndexByte(s, '\n')
+			s = fmt.Sprintf("%s %s%s", s[:pos], instCountComment(blockInstCount(block)), s[pos:])
+		}
+		fmt.Fprintf(buf, "%s\n", s)
 	}
 	if len(body.UseListOrders) > 0 {
 		buf.WriteString("\n")
@@ -292,11 +410,26 @@ func bodyString(body *Func) string {
 	return buf.String()
 }
 
+// blockInstCount returns the number of instructions of the given basic block,
+// including its terminator and excluding debug records.
+func blockInstCount(block *Block) int {
+	return len(withoutDbgRecords(block.Insts)) + 1
+}
+
+// instCountComment returns a comment of the given number of instructions (e.g.
+// `; 3 instructions`).
+func instCountComment(n int) string {
+	if n == 1 {
+		return "; 1 instruction"
+	}
+	return fmt.Sprintf("; %d instructions", n)
+}
+
 // isVoidValue reports whether the given named value is a non-value (i.e. a call
-// instruction or invoke terminator with void-return type).
+// instruction, invoke terminator or callbr terminator with void-return type).
 func isVoidValue(n value.Named) bool {
 	switch n.(type) {
-	case *InstCall, *TermInvoke:
+	case *InstCall, *TermInvoke, *TermCallBr:
 		return n.Type().Equal(types.Void)
 	}
 	return false
@@ -8,3 +8,12 @@ func (f *Func) NewBlock(name string) *Block {
 	f.Blocks = append(f.Blocks, block)
 	return block
 }
+
+// Entry returns the entry basic block of the function; i.e. the first basic
+// block of the function body, or nil if the function is a declaration.
+func (f *Func) Entry() *Block {
+	if len(f.Blocks) == 0 {
+		return nil
+	}
+	return f.Blocks[0]
+}
@@ -0,0 +1,193 @@
+package ir
+
+import (
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Debug line tables ] ===================================================
+
+// LineEntry is an entry of the line table of a function, mapping an
+// instruction or terminator to its source location.
+type LineEntry struct {
+	// Instruction or terminator.
+	Inst LLStringer
+	// (optional) Source file; nil if not present.
+	File *metadata.DIFile
+	// Source line; zero if not present.
+	Line int64
+	// Source column; zero if not present.
+	Column int64
+	// Scope of the source location (e.g. *metadata.DISubprogram).
+	Scope metadata.Field
+}
+
+// SourceLines returns the line table of the function; the source locations of
+// its instructions and terminators, as specified by their !dbg metadata
+// attachments (of type *metadata.DILocation), in the order of the instructions
+// and terminators of the function. Instructions and terminators without a !dbg
+// metadata attachment are not included.
+//
+// The source file of an entry is the file of the innermost scope of the source
+// location which specifies a file.
+func (f *Func) SourceLines() []LineEntry {
+	var entries []LineEntry
+	add := func(inst LLStringer) {
+		loc := dbgLocation(inst)
+		if loc == nil {
+			return
+		}
+		entry := LineEntry{
+			Inst:   inst,
+			File:   scopeFile(loc.Scope),
+			Line:   loc.Line,
+			Column: loc.Column,
+			Scope:  loc.Scope,
+		}
+		entries = append(entries, entry)
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			add(inst)
+		}
+		if block.Term != nil {
+			add(block.Term)
+		}
+	}
+	return entries
+}
+
+// === [ Debug local variables ] ===============================================
+
+// DebugLocal is a source-level local variable or parameter of a function, as
+// described by debug information.
+type DebugLocal struct {
+	// Debug information of the local variable.
+	Var *metadata.DILocalVariable
+	// Name of the local variable; empty if not present.
+	Name string
+	// (optional) Type of the local variable (e.g. *metadata.DIBasicType); nil if
+	// not present.
+	Type metadata.Field
+	// Scope of the local variable (e.g. *metadata.DISubprogram or
+	// *metadata.DILexicalBlock).
+	Scope metadata.Field
+	// Argument index (1-based) of function parameters; zero for local
+	// variables.
+	Arg uint64
+	// (optional) Address of the local variable, as specified by a #dbg_declare
+	// debug record or a call to @llvm.dbg.declare; nil if not present.
+	Addr value.Value
+}
+
+// DebugLocals returns the source-level local variables and parameters of the
+// function, as described by the retained nodes of its !dbg subprogram and by
+// the debug records and debug intrinsic calls (e.g. #dbg_declare and
+// @llvm.dbg.declare) of its basic blocks. Retained nodes are listed first,
+// followed by the remaining local variables in order of first occurrence in
+// the function body. Each local variable is listed once.
+func (f *Func) DebugLocals() []DebugLocal {
+	var locals []DebugLocal
+	// index maps from local variable to index in locals.
+	index := make(map[*metadata.DILocalVariable]int)
+	add := func(v *metadata.DILocalVariable) int {
+		if i, ok := index[v]; ok {
+			return i
+		}
+		local := DebugLocal{
+			Var:   v,
+			Name:  v.Name,
+			Type:  v.Type,
+			Scope: v.Scope,
+			Arg:   v.Arg,
+		}
+		index[v] = len(locals)
+		locals = append(locals, local)
+		return index[v]
+	}
+	// Retained nodes of subprogram.
+	for _, md := range f.Metadata {
+		sp, ok := md.Node.(*metadata.DISubprogram)
+		if !ok || md.Name != "dbg" || sp.RetainedNodes == nil {
+			continue
+		}
+		for _, field := range sp.RetainedNodes.Fields {
+			if v, ok := field.(*metadata.DILocalVariable); ok {
+				add(v)
+			}
+		}
+	}
+	// Debug records and debug intrinsic calls.
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			var record *DbgRecord
+			switch inst := inst.(type) {
+			case *DbgRecord:
+				record = inst
+			case *InstCall:
+				record = dbgRecordFromCall(inst)
+			}
+			if record == nil {
+				continue
+			}
+			v, ok := record.Variable.(*metadata.DILocalVariable)
+			if !ok {
+				continue
+			}
+			i := add(v)
+			if record.Kind == enum.DbgRecordKindDeclare && locals[i].Addr == nil {
+				locals[i].Addr = record.Value
+			}
+		}
+	}
+	return locals
+}
+
+// ### [ Helper functions ] ####################################################
+
+// dbgLocation returns the source location specified by the !dbg metadata
+// attachment of the given instruction or terminator, or nil if not present.
+func dbgLocation(inst LLStringer) *metadata.DILocation {
+	n, ok := inst.(interface {
+		MDAttachments() []*metadata.Attachment
+	})
+	if !ok {
+		return nil
+	}
+	for _, md := range n.MDAttachments() {
+		if loc, ok := md.Node.(*metadata.DILocation); ok && md.Name == "dbg" {
+			return loc
+		}
+	}
+	return nil
+}
+
+// scopeFile returns the source file of the innermost enclosing scope of the
+// given scope which specifies a file, or nil if not present.
+func scopeFile(scope metadata.Field) *metadata.DIFile {
+	for scope != nil {
+		switch s := scope.(type) {
+		case *metadata.DIFile:
+			return s
+		case *metadata.DISubprogram:
+			if s.File != nil {
+				return s.File
+			}
+			scope = s.Scope
+		case *metadata.DILexicalBlock:
+			if s.File != nil {
+				return s.File
+			}
+			scope = s.Scope
+		case *metadata.DILexicalBlockFile:
+			if s.File != nil {
+				return s.File
+			}
+			scope = s.Scope
+		default:
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package ir_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir/metadata"
+)
+
+func TestFuncSourceLines(t *testing.T) {
+	const src = `
+define i32 @f(i32 %x) !dbg !4 {
+entry:
+	%y = add i32 %x, 1, !dbg !7
+	%z = mul i32 %y, 2
+	%w = sub i32 %z, 3, !dbg !8
+	ret i32 %w, !dbg !10
+}
+
+!llvm.dbg.cu = !{!0}
+!llvm.module.flags = !{!3}
+
+!0 = distinct !DICompileUnit(language: DW_LANG_C99, file: !1, producer: "clang", isOptimized: false, runtimeVersion: 0, emissionKind: FullDebug, enums: !2)
+!1 = !DIFile(filename: "foo.c", directory: "/tmp")
+!2 = !{}
+!3 = !{i32 2, !"Debug Info Version", i32 3}
+!4 = distinct !DISubprogram(name: "f", scope: !1, file: !1, line: 1, type: !5, scopeLine: 1, spFlags: DISPFlagDefinition, unit: !0, retainedNodes: !2)
+!5 = !DISubroutineType(types: !6)
+!6 = !{null}
+!7 = !DILocation(line: 2, column: 10, scope: !4)
+!8 = !DILocation(line: 4, column: 3, scope: !9)
+!9 = distinct !DILexicalBlock(scope: !4, file: !11, line: 3, column: 5)
+!10 = !DILocation(line: 5, column: 3, scope: !4)
+!11 = !DIFile(filename: "bar.h", directory: "/tmp")
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	insts := f.Blocks[0].Insts
+	golden := []struct {
+		inst   interface{}
+		file   string
+		line   int64
+		column int64
+	}{
+		{inst: insts[0], file: "foo.c", line: 2, column: 10},
+		{inst: insts[2], file: "bar.h", line: 4, column: 3},
+		{inst: f.Blocks[0].Term, file: "foo.c", line: 5, column: 3},
+	}
+	entries := f.SourceLines()
+	if len(entries) != len(golden) {
+		t.Fatalf("line table length mismatch; expected %d, got %d", len(golden), len(entries))
+	}
+	for i, g := range golden {
+		entry := entries[i]
+		if entry.Inst != g.inst {
+			t.Errorf("entry %d instruction mismatch; expected %v, got %v", i, g.inst, entry.Inst)
+		}
+		if entry.File == nil || entry.File.Filename != g.file {
+			t.Errorf("entry %d file mismatch; expected %q, got %v", i, g.file, entry.File)
+		}
+		if entry.Line != g.line || entry.Column != g.column {
+			t.Errorf("entry %d location mismatch; expected %d:%d, got %d:%d", i, g.line, g.column, entry.Line, entry.Column)
+		}
+		if entry.Scope == nil {
+			t.Errorf("entry %d missing scope", i)
+		}
+	}
+}
+
+func TestFuncDebugLocals(t *testing.T) {
+	const src = `
+define i32 @f(i32 %x) !dbg !4 {
+entry:
+	%x.addr = alloca i32
+	%y = alloca i32
+	%z = alloca i32
+	call void @llvm.dbg.declare(metadata i32* %x.addr, metadata !9, metadata !DIExpression()), !dbg !12
+	call void @llvm.dbg.declare(metadata i32* %y, metadata !10, metadata !DIExpression()), !dbg !12
+	call void @llvm.dbg.declare(metadata i32* %z, metadata !13, metadata !DIExpression()), !dbg !12
+	ret i32 %x, !dbg !12
+}
+
+declare void @llvm.dbg.declare(metadata, metadata, metadata)
+
+!llvm.dbg.cu = !{!0}
+!llvm.module.flags = !{!3}
+
+!0 = distinct !DICompileUnit(language: DW_LANG_C99, file: !1, producer: "clang", isOptimized: false, runtimeVersion: 0, emissionKind: FullDebug, enums: !2)
+!1 = !DIFile(filename: "foo.c", directory: "/tmp")
+!2 = !{}
+!3 = !{i32 2, !"Debug Info Version", i32 3}
+!4 = distinct !DISubprogram(name: "f", scope: !1, file: !1, line: 1, type: !5, scopeLine: 1, spFlags: DISPFlagDefinition, unit: !0, retainedNodes: !8)
+!5 = !DISubroutineType(types: !6)
+!6 = !{!7, !7}
+!7 = !DIBasicType(name: "int", size: 32, encoding: DW_ATE_signed)
+!8 = !{!9, !10, !11}
+!9 = !DILocalVariable(name: "x", arg: 1, scope: !4, file: !1, line: 1, type: !7)
+!10 = !DILocalVariable(name: "y", scope: !4, file: !1, line: 2, type: !7)
+!11 = !DILocalVariable(name: "unused", scope: !4, file: !1, line: 3, type: !7)
+!12 = !DILocation(line: 4, column: 3, scope: !4)
+!13 = !DILocalVariable(name: "z", scope: !14, file: !1, line: 5, type: !7)
+!14 = distinct !DILexicalBlock(scope: !4, file: !1, line: 4, column: 5)
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	insts := f.Blocks[0].Insts
+	golden := []struct {
+		name  string
+		arg   uint64
+		scope string
+		addr  interface{}
+	}{
+		{name: "x", arg: 1, scope: "DISubprogram", addr: insts[0]},
+		{name: "y", scope: "DISubprogram", addr: insts[1]},
+		{name: "unused", scope: "DISubprogram"},
+		{name: "z", scope: "DILexicalBlock", addr: insts[2]},
+	}
+	locals := f.DebugLocals()
+	if len(locals) != len(golden) {
+		t.Fatalf("number of debug locals mismatch; expected %d, got %d", len(golden), len(locals))
+	}
+	for i, g := range golden {
+		local := locals[i]
+		if local.Name != g.name {
+			t.Errorf("local %d name mismatch; expected %q, got %q", i, g.name, local.Name)
+		}
+		if local.Arg != g.arg {
+			t.Errorf("local %q argument index mismatch; expected %d, got %d", g.name, g.arg, local.Arg)
+		}
+		if got := strings.TrimPrefix(fmt.Sprintf("%T", local.Scope), "*metadata."); got != g.scope {
+			t.Errorf("local %q scope mismatch; expected %s, got %s", g.name, g.scope, got)
+		}
+		if got := local.Type.(*metadata.DIBasicType).Name; got != "int" {
+			t.Errorf("local %q type mismatch; expected %q, got %q", g.name, "int", got)
+		}
+		if g.addr == nil {
+			if local.Addr != nil {
+				t.Errorf("local %q address mismatch; expected nil, got %v", g.name, local.Addr)
+			}
+		} else if local.Addr != g.addr {
+			t.Errorf("local %q address mismatch; expected %v, got %v", g.name, g.addr, local.Addr)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package ir_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestFuncCallees(t *testing.T) {
+	const src = `
+declare void @g()
+
+declare i32 @h(i32)
+
+declare i32 @__gxx_personality_v0(...)
+
+define void @f(void ()* %fp) personality i32 (...)* @__gxx_personality_v0 {
+entry:
+	call void @g()
+	call void %fp()
+	invoke void @g()
+			to label %cont unwind label %lpad
+
+cont:
+	%x = invoke i32 @h(i32 1)
+			to label %exit unwind label %lpad
+
+lpad:
+	%lp = landingpad { i8*, i32 }
+			cleanup
+	call void %fp()
+	resume { i8*, i32 } %lp
+
+exit:
+	ret void
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[3]
+	want := []string{"@g", "%fp", "@h"}
+	callees := f.Callees()
+	if len(callees) != len(want) {
+		t.Fatalf("callee count mismatch; expected %d, got %d", len(want), len(callees))
+	}
+	for i, callee := range callees {
+		if got := callee.Ident(); got != want[i] {
+			t.Errorf("callee %d mismatch; expected %q, got %q", i, want[i], got)
+		}
+	}
+	if callees[0] != m.Funcs[0] || callees[1] != f.Params[0] {
+		t.Errorf("callee value mismatch; expected @g and %%fp, got %v and %v", callees[0], callees[1])
+	}
+}
+
+func TestFuncSectionPartitionAlign(t *testing.T) {
+	m := ir.NewModule()
+	g := m.NewGlobalDef("g", constant.NewInt(types.I32, 0))
+	g.Section = ".data"
+	g.Partition = "part"
+	g.Align = 8
+	f := m.NewFunc("f", types.Void)
+	f.Section = ".text.hot"
+	f.Partition = "part"
+	f.FuncAttrs = append(f.FuncAttrs, ir.Align(16), enum.FuncAttrNoUnwind)
+	f.NewBlock("").NewRet(nil)
+	const want = `@g = global i32 0, section ".data", partition "part", align 8
+
+define void @f() nounwind section ".text.hot" partition "part" align 16 {
+; <label>:0
+	ret void
+}
+`
+	if got := m.String(); got != want {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", want, got)
+	}
+}
+
+func TestFuncWriteTo(t *testing.T) {
+	m := ir.NewModule()
+	m.NewGlobalDef("g", constant.NewInt(types.I32, 0))
+	f := m.NewFunc("f", types.I32, ir.NewParam("", types.I32))
+	entry := f.NewBlock("")
+	x := entry.NewAdd(f.Params[0], constant.NewInt(types.I32, 1))
+	exit := f.NewBlock("")
+	entry.NewBr(exit)
+	exit.NewRet(exit.NewMul(x, x))
+	const want = `define i32 @f(i32) {
+; <label>:1
+	%2 = add i32 %0, 1
+	br label %3
+
+; <label>:3
+	%4 = mul i32 %2, %2
+	ret i32 %4
+}
+`
+	buf := &strings.Builder{}
+	if _, err := f.WriteTo(buf); err != nil {
+		t.Fatalf("unable to write function; %+v", err)
+	}
+	got := buf.String()
+	if got != want {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+	}
+	// The output is a valid module consisting only of the function definition.
+	m2, err := asm.ParseString("<stdin>", got)
+	if err != nil {
+		t.Fatalf("unable to parse function definition; %+v", err)
+	}
+	if len(m2.Funcs) != 1 || len(m2.Globals) != 0 {
+		t.Errorf("expected module with one function, got %d functions and %d globals", len(m2.Funcs), len(m2.Globals))
+	}
+	// The string representation of the function is its type-value pair.
+	if got, want := f.String(), "i32 (i32)* @f"; got != want {
+		t.Errorf("function string mismatch; expected %q, got %q", want, got)
+	}
+}
@@ -44,6 +44,8 @@ type Global struct {
 	ExternallyInitialized bool
 	// (optional) Section name; empty if not present.
 	Section string
+	// (optional) Partition name; empty if not present.
+	Partition string
 	// (optional) Comdat; nil if not present.
 	Comdat *ComdatDef
 	// (optional) Alignment; zero if not present.
@@ -52,6 +54,9 @@ type Global struct {
 	FuncAttrs []FuncAttribute
 	// (optional) Metadata.
 	Metadata
+	// (optional) Provenance of the global variable in the parsed source input;
+	// nil if not present.
+	Provenance *Provenance
 }
 
 // NewGlobal returns a new global variable declaration based on the given global
@@ -97,19 +102,25 @@ func (g *Global) LLString() string {
 	//    Name=GlobalIdent '=' ExternLinkage Preemptionopt Visibilityopt
 	//    DLLStorageClassopt ThreadLocalopt UnnamedAddropt AddrSpaceopt
 	//    ExternallyInitializedopt Immutable ContentType=Type (',' Section)? (','
-	//    Comdat)? (',' Alignment)? Metadata=(',' MetadataAttachment)+?
-	//    FuncAttrs=(',' FuncAttribute)+?
+	//    Partition)? (',' Comdat)? (',' Alignment)? Metadata=(','
+	//    MetadataAttachment)+? FuncAttrs=(',' FuncAttribute)+?
 	//
 	// Global definition.
 	//
 	//    Name=GlobalIdent '=' Linkageopt Preemptionopt Visibilityopt
 	//    DLLStorageClassopt ThreadLocalopt UnnamedAddropt AddrSpaceopt
 	//    ExternallyInitializedopt Immutable ContentType=Type Init=Constant (','
-	//    Section)? (',' Comdat)? (',' Alignment)? Metadata=(','
+	//    Section)? (',' Partition)? (',' Comdat)? (',' Alignment)? Metadata=(','
 	//    MetadataAttachment)+? FuncAttrs=(',' FuncAttribute)+?
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "%s =", g.Ident())
-	if g.Linkage != enum.LinkageNone {
+	switch {
+	case g.Init == nil && (g.Linkage == enum.LinkageNone || g.Linkage == enum.LinkageExternal):
+		// Global declarations require linkage; external is the default.
+		buf.WriteString(" external")
+	case g.Linkage != enum.LinkageNone && g.Linkage != enum.LinkageExternal:
+		// External linkage is implied by global definitions, and is therefore
+		// omitted.
 		fmt.Fprintf(buf, " %s", g.Linkage)
 	}
 	if g.Preemption != enum.PreemptionNone {
@@ -148,6 +159,9 @@ func (g *Global) LLString() string {
 	if g.Section != "" {
 		fmt.Fprintf(buf, ", section %s", quote(g.Section))
 	}
+	if g.Partition != "" {
+		fmt.Fprintf(buf, ", partition %s", quote(g.Partition))
+	}
 	if g.Comdat != nil {
 		if g.Comdat.Name == g.Name() {
 			buf.WriteString(", comdat")
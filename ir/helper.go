@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/llir/llvm/internal/enc"
+	"github.com/llir/llvm/ir/constant"
 	"github.com/llir/llvm/ir/enum"
 	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
@@ -115,6 +116,127 @@ func (d Dereferenceable) String() string {
 	return fmt.Sprintf("dereferenceable(%d)", d.N)
 }
 
+// MemoryEffects is a memory effects attribute, specifying the kinds of memory
+// access of a function or call site for each memory location (e.g.
+// `memory(read)` or `memory(argmem: readwrite, inaccessiblemem: write)`).
+//
+// Every memory location has a memory access kind; use NewMemoryEffects to
+// create a memory effects attribute with the same kind of memory access for
+// all memory locations.
+type MemoryEffects struct {
+	// Memory access kind of argument memory (argmem); i.e. memory pointed to by
+	// pointer arguments.
+	ArgMem enum.ModRef
+	// Memory access kind of memory inaccessible to the current module
+	// (inaccessiblemem).
+	InaccessibleMem enum.ModRef
+	// Memory access kind of all other memory.
+	Other enum.ModRef
+}
+
+// NewMemoryEffects returns a new memory effects attribute with the given kind
+// of memory access for all memory locations.
+func NewMemoryEffects(mr enum.ModRef) MemoryEffects {
+	return MemoryEffects{ArgMem: mr, InaccessibleMem: mr, Other: mr}
+}
+
+// String returns the string representation of the memory effects attribute.
+func (a MemoryEffects) String() string {
+	// 'memory' '(' Default=ModRef? (Location ':' ModRef)* ')'
+	//
+	// The memory access kind of other memory is output as the default, and
+	// only memory locations with a different memory access kind are output
+	// explicitly.
+	var effects []string
+	if a.Other != enum.ModRefNone || (a.ArgMem == a.Other && a.InaccessibleMem == a.Other) {
+		effects = append(effects, a.Other.String())
+	}
+	if a.ArgMem != a.Other {
+		effects = append(effects, fmt.Sprintf("argmem: %s", a.ArgMem))
+	}
+	if a.InaccessibleMem != a.Other {
+		effects = append(effects, fmt.Sprintf("inaccessiblemem: %s", a.InaccessibleMem))
+	}
+	return fmt.Sprintf("memory(%s)", strings.Join(effects, ", "))
+}
+
+// NoFPClass is a nofpclass attribute, specifying floating-point classes which
+// the value is known not to belong to.
+type NoFPClass struct {
+	// Floating-point classes.
+	Classes enum.FPClass
+}
+
+// fpClassNames specifies the names of floating-point classes, in the order in
+// which they are output; compound classes precede their constituents.
+var fpClassNames = []struct {
+	class enum.FPClass
+	name  string
+}{
+	{class: enum.FPClassAll, name: "all"},
+	{class: enum.FPClassNaN, name: "nan"},
+	{class: enum.FPClassSNaN, name: "snan"},
+	{class: enum.FPClassQNaN, name: "qnan"},
+	{class: enum.FPClassInf, name: "inf"},
+	{class: enum.FPClassNegInf, name: "ninf"},
+	{class: enum.FPClassPosInf, name: "pinf"},
+	{class: enum.FPClassZero, name: "zero"},
+	{class: enum.FPClassNegZero, name: "nzero"},
+	{class: enum.FPClassPosZero, name: "pzero"},
+	{class: enum.FPClassSubnormal, name: "sub"},
+	{class: enum.FPClassNegSubnormal, name: "nsub"},
+	{class: enum.FPClassPosSubnormal, name: "psub"},
+	{class: enum.FPClassNormal, name: "norm"},
+	{class: enum.FPClassNegNormal, name: "nnorm"},
+	{class: enum.FPClassPosNormal, name: "pnorm"},
+}
+
+// String returns the string representation of the nofpclass attribute.
+func (a NoFPClass) String() string {
+	// 'nofpclass' '(' Classes=FPClass+ ')'
+	var names []string
+	classes := a.Classes
+	for _, c := range fpClassNames {
+		if classes&c.class == c.class {
+			names = append(names, c.name)
+			classes &^= c.class
+		}
+	}
+	if len(names) == 0 {
+		names = append(names, "none")
+	}
+	return fmt.Sprintf("nofpclass(%s)", strings.Join(names, " "))
+}
+
+// TypedParamAttr is a parameter attribute with a type operand (e.g.
+// byval(T)).
+type TypedParamAttr struct {
+	// Parameter attribute kind; one of byref, byval, elementtype, preallocated
+	// or sret.
+	Kind enum.ParamAttr
+	// Type operand.
+	Typ types.Type
+}
+
+// NewTypedParamAttr returns a new parameter attribute of the given kind with
+// the given type operand.
+func NewTypedParamAttr(kind enum.ParamAttr, typ types.Type) TypedParamAttr {
+	switch kind {
+	case enum.ParamAttrByRef, enum.ParamAttrByval, enum.ParamAttrElementType, enum.ParamAttrPreallocated, enum.ParamAttrSRet:
+		// valid type-carrying parameter attribute.
+	default:
+		panic(fmt.Errorf("invalid type-carrying parameter attribute %q", kind))
+	}
+	return TypedParamAttr{Kind: kind, Typ: typ}
+}
+
+// String returns the string representation of the type-carrying parameter
+// attribute.
+func (a TypedParamAttr) String() string {
+	// Kind=ParamAttr '(' Typ=Type ')'
+	return fmt.Sprintf("%s(%s)", a.Kind, a.Typ)
+}
+
 // TODO: figure out definition of ExceptionScope.
 
 // ExceptionScope is an exception scope.
@@ -133,6 +255,7 @@ type ExceptionScope interface {
 //    ir.Align
 //    ir.AlignStack
 //    ir.AllocSize
+//    ir.MemoryEffects
 //    enum.FuncAttr
 type FuncAttribute interface {
 	fmt.Stringer
@@ -261,6 +384,39 @@ func (mds Metadata) MDAttachments() []*metadata.Attachment {
 	return mds
 }
 
+// DebugLoc returns the debug location of the value, as specified by its !dbg
+// metadata attachment; or nil if not present.
+func (mds Metadata) DebugLoc() *metadata.DILocation {
+	for _, md := range mds {
+		if loc, ok := md.Node.(*metadata.DILocation); ok && md.Name == "dbg" {
+			return loc
+		}
+	}
+	return nil
+}
+
+// SetDebugLoc sets the debug location of the value, as specified by its !dbg
+// metadata attachment. A nil debug location removes the !dbg metadata
+// attachment.
+func (mds *Metadata) SetDebugLoc(loc *metadata.DILocation) {
+	for i, md := range *mds {
+		if md.Name != "dbg" {
+			continue
+		}
+		if loc == nil {
+			*mds = append((*mds)[:i:i], (*mds)[i+1:]...)
+			return
+		}
+		// Replace the attachment rather than updating it in place, as the
+		// attachment may be shared with cloned instructions.
+		(*mds)[i] = &metadata.Attachment{Name: "dbg", Node: loc}
+		return
+	}
+	if loc != nil {
+		*mds = append(*mds, &metadata.Attachment{Name: "dbg", Node: loc})
+	}
+}
+
 // OperandBundle is an operand bundle.
 type OperandBundle struct {
 	Tag    string
@@ -288,6 +444,19 @@ func (o *OperandBundle) String() string {
 	return buf.String()
 }
 
+// ConvergenceToken returns the convergence control token of the given operand
+// bundles, as specified by the "convergencectrl" operand bundle; or nil if not
+// present. The token is produced by a call to one of the
+// @llvm.experimental.convergence.* intrinsics.
+func ConvergenceToken(bundles []*OperandBundle) value.Value {
+	for _, bundle := range bundles {
+		if bundle.Tag == "convergencectrl" && len(bundle.Inputs) == 1 {
+			return bundle.Inputs[0]
+		}
+	}
+	return nil
+}
+
 // ParamAttribute is a parameter attribute.
 //
 // A ParamAttribute has one of the following underlying types.
@@ -296,6 +465,8 @@ func (o *OperandBundle) String() string {
 //    ir.AttrPair
 //    ir.Align
 //    ir.Dereferenceable
+//    ir.NoFPClass
+//    ir.TypedParamAttr
 //    enum.ParamAttr
 type ParamAttribute interface {
 	fmt.Stringer
@@ -304,6 +475,20 @@ type ParamAttribute interface {
 	IsParamAttribute()
 }
 
+// Provenance is the origin of a top-level entity in the LLVM IR assembly input
+// it was parsed from. Provenance is not part of the LLVM IR output.
+type Provenance struct {
+	// Path to the source file; empty if not present.
+	Path string
+	// Byte offset of the top-level entity in the source file.
+	Offset int
+}
+
+// String returns the string representation of the provenance.
+func (p *Provenance) String() string {
+	return fmt.Sprintf("%s:%d", p.Path, p.Offset)
+}
+
 // ReturnAttribute is a return attribute.
 //
 // A ReturnAttribute has one of the following underlying types.
@@ -312,6 +497,7 @@ type ParamAttribute interface {
 //    ir.AttrPair
 //    ir.Align
 //    ir.Dereferenceable
+//    ir.NoFPClass
 //    enum.ReturnAttr
 type ReturnAttribute interface {
 	fmt.Stringer
@@ -342,6 +528,47 @@ func (UnwindToCaller) String() string {
 
 // ___ [ Function parameter ] __________________________________________________
 
+// VScaleMultiple reports whether the given value is a constant multiple of
+// vscale, the runtime multiple of the vector length of scalable vector types,
+// and if so returns the multiplier. Calls to the @llvm.vscale.* intrinsics,
+// and mul and shl instructions of such values by integer constants are
+// recognized.
+func VScaleMultiple(v value.Value) (int64, bool) {
+	switch v := v.(type) {
+	case *InstCall:
+		callee, ok := v.Callee.(*Func)
+		if !ok || !strings.HasPrefix(callee.Name(), "llvm.vscale.") || len(v.Args) != 0 {
+			return 0, false
+		}
+		return 1, true
+	case *InstMul:
+		x, c := v.X, v.Y
+		if _, ok := x.(*constant.Int); ok {
+			x, c = c, x
+		}
+		n, ok := VScaleMultiple(x)
+		if !ok {
+			return 0, false
+		}
+		m, ok := c.(*constant.Int)
+		if !ok || !m.X.IsInt64() {
+			return 0, false
+		}
+		return n * m.X.Int64(), true
+	case *InstShl:
+		n, ok := VScaleMultiple(v.X)
+		if !ok {
+			return 0, false
+		}
+		m, ok := v.Y.(*constant.Int)
+		if !ok || !m.X.IsUint64() || m.X.Uint64() >= 63 {
+			return 0, false
+		}
+		return n << m.X.Uint64(), true
+	}
+	return 0, false
+}
+
 // Param is an LLVM IR function parameter.
 type Param struct {
 	// (optional) Parameter name (without '%' prefix).
@@ -0,0 +1,93 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestTypedParamAttr(t *testing.T) {
+	st := types.NewStruct(types.I32, types.I64)
+	st.SetName("struct.T")
+	ptr := types.NewPointer(st)
+	m := NewModule()
+	m.NewTypeDef("struct.T", st)
+	kinds := []enum.ParamAttr{enum.ParamAttrByval, enum.ParamAttrByRef, enum.ParamAttrSRet, enum.ParamAttrPreallocated}
+	var params []*Param
+	for _, kind := range kinds {
+		param := NewParam("", ptr)
+		param.Attrs = append(param.Attrs, NewTypedParamAttr(kind, st))
+		params = append(params, param)
+	}
+	g := m.NewFunc("g", types.Void, params...)
+	f := m.NewFunc("f", types.Void, NewParam("p", ptr))
+	entry := f.NewBlock("")
+	var args []value.Value
+	for _, kind := range kinds {
+		args = append(args, NewArg(f.Params[0], NewTypedParamAttr(kind, st)))
+	}
+	entry.NewCall(g, args...)
+	entry.NewRet(nil)
+	const want = `%struct.T = type { i32, i64 }
+
+declare void @g(%struct.T* byval(%struct.T), %struct.T* byref(%struct.T), %struct.T* sret(%struct.T), %struct.T* preallocated(%struct.T))
+
+define void @f(%struct.T* %p) {
+; <label>:0
+	call void @g(%struct.T* byval(%struct.T) %p, %struct.T* byref(%struct.T) %p, %struct.T* sret(%struct.T) %p, %struct.T* preallocated(%struct.T) %p)
+	ret void
+}
+`
+	if got := m.String(); got != want {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", want, got)
+	}
+	defer func() {
+		if e := recover(); e == nil {
+			t.Errorf("expected panic for invalid type-carrying parameter attribute %q", enum.ParamAttrInReg)
+		}
+	}()
+	NewTypedParamAttr(enum.ParamAttrInReg, st)
+}
+
+func TestParamAttrsNoUndefNoFPClass(t *testing.T) {
+	m := NewModule()
+	ptr := types.NewPointer(types.I32)
+	p := NewParam("", ptr)
+	p.Attrs = append(p.Attrs, NewTypedParamAttr(enum.ParamAttrElementType, types.I32), enum.ParamAttrNoCapture)
+	x := NewParam("", types.Double)
+	x.Attrs = append(x.Attrs, enum.ParamAttrNoUndef, NoFPClass{Classes: enum.FPClassNaN | enum.FPClassInf})
+	g := m.NewFunc("g", types.Double, p, x)
+	g.ReturnAttrs = append(g.ReturnAttrs, enum.ReturnAttrNoUndef, NoFPClass{Classes: enum.FPClassNegZero | enum.FPClassSubnormal})
+	f := m.NewFunc("f", types.Double, NewParam("p", ptr))
+	entry := f.NewBlock("")
+	call := entry.NewCall(g, NewArg(f.Params[0], NewTypedParamAttr(enum.ParamAttrElementType, types.I32)), NewArg(constant.NewFloat(types.Double, 1), enum.ParamAttrNoUndef))
+	call.ReturnAttrs = append(call.ReturnAttrs, enum.ReturnAttrNoUndef)
+	entry.NewRet(call)
+	const want = `declare noundef nofpclass(nzero sub) double @g(i32* elementtype(i32) nocapture, double noundef nofpclass(nan inf))
+
+define double @f(i32* %p) {
+; <label>:0
+	%1 = call noundef double @g(i32* elementtype(i32) %p, double noundef 1.0)
+	ret double %1
+}
+`
+	if got := m.String(); got != want {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", want, got)
+	}
+	golden := []struct {
+		classes enum.FPClass
+		want    string
+	}{
+		{classes: 0, want: "nofpclass(none)"},
+		{classes: enum.FPClassAll, want: "nofpclass(all)"},
+		{classes: enum.FPClassQNaN | enum.FPClassPosInf | enum.FPClassNormal, want: "nofpclass(qnan pinf norm)"},
+	}
+	for _, g := range golden {
+		if got := (NoFPClass{Classes: g.classes}).String(); got != g.want {
+			t.Errorf("nofpclass mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestValueIdent(t *testing.T) {
+	const src = `
+@g = global i32 0
+@0 = global [2 x i32] [i32 1, i32 2]
+
+define i32 @f(i32 %x, i32) {
+entry:
+	%1 = add i32 %x, %0
+	%sum = add i32 %1, ptrtoint (i32* @g to i32)
+	br label %2
+
+; <label>:2
+	ret i32 %sum
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	entry := f.Blocks[0]
+	add := entry.Insts[0].(*ir.InstAdd)
+	sum := entry.Insts[1].(*ir.InstAdd)
+	s := constant.NewStruct(types.NewStruct(types.I32, types.I32), constant.NewInt(types.I32, 1), constant.NewInt(types.I32, 2))
+	golden := []struct {
+		v    value.Value
+		want string
+	}{
+		// Globals.
+		{v: m.Globals[0], want: "@g"},
+		{v: m.Globals[1], want: "@0"},
+		{v: f, want: "@f"},
+		// Parameters.
+		{v: f.Params[0], want: "%x"},
+		{v: f.Params[1], want: "%0"},
+		// Instructions.
+		{v: add, want: "%1"},
+		{v: sum, want: "%sum"},
+		// Basic blocks.
+		{v: entry, want: "%entry"},
+		{v: f.Blocks[1], want: "%2"},
+		// Constants.
+		{v: constant.NewInt(types.I32, 42), want: "42"},
+		{v: s, want: "{ i32 1, i32 2 }"},
+		{v: m.Globals[1].Init, want: "[i32 1, i32 2]"},
+		{v: constant.NewNull(types.NewPointer(types.I8)), want: "null"},
+		// Constant expressions.
+		{v: sum.Y, want: "ptrtoint (i32* @g to i32)"},
+		// Nil value.
+		{v: nil, want: "<nil>"},
+	}
+	for _, g := range golden {
+		if got := value.Ident(g.v); got != g.want {
+			t.Errorf("identifier mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+}
@@ -69,7 +69,8 @@ func (i *IFunc) LLString() string {
 	// ThreadLocalopt UnnamedAddropt 'ifunc' Type ',' Type Constant
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "%s =", i.Ident())
-	if i.Linkage != enum.LinkageNone {
+	// External linkage is the default, and is therefore omitted.
+	if i.Linkage != enum.LinkageNone && i.Linkage != enum.LinkageExternal {
 		fmt.Fprintf(buf, " %s", i.Linkage)
 	}
 	if i.Preemption != enum.PreemptionNone {
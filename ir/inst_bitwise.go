@@ -253,6 +253,9 @@ type InstOr struct {
 
 	// Type of result produced by the instruction.
 	Typ types.Type
+	// (optional) The result is a poison value if any bit is set in both
+	// operands.
+	Disjoint bool
 	// (optional) Metadata.
 	Metadata
 }
@@ -282,10 +285,15 @@ func (inst *InstOr) Type() types.Type {
 
 // LLString returns the LLVM syntax representation of the instruction.
 func (inst *InstOr) LLString() string {
-	// 'or' X=TypeValue ',' Y=Value Metadata=(',' MetadataAttachment)+?
+	// 'or' Disjointopt X=TypeValue ',' Y=Value Metadata=(','
+	// MetadataAttachment)+?
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "%s = ", inst.Ident())
-	fmt.Fprintf(buf, "or %s, %s", inst.X, inst.Y.Ident())
+	buf.WriteString("or")
+	if inst.Disjoint {
+		buf.WriteString(" disjoint")
+	}
+	fmt.Fprintf(buf, " %s, %s", inst.X, inst.Y.Ident())
 	for _, md := range inst.Metadata {
 		fmt.Fprintf(buf, ", %s", md)
 	}
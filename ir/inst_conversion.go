@@ -96,6 +96,9 @@ type InstZExt struct {
 
 	// extra.
 
+	// (optional) Non-negative; the result is a poison value if the value before
+	// conversion is negative.
+	NNeg bool
 	// (optional) Metadata.
 	Metadata
 }
@@ -119,10 +122,15 @@ func (inst *InstZExt) Type() types.Type {
 
 // LLString returns the LLVM syntax representation of the instruction.
 func (inst *InstZExt) LLString() string {
-	// 'zext' From=TypeValue 'to' To=Type Metadata=(',' MetadataAttachment)+?
+	// 'zext' NNegopt From=TypeValue 'to' To=Type Metadata=(','
+	// MetadataAttachment)+?
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "%s = ", inst.Ident())
-	fmt.Fprintf(buf, "zext %s to %s", inst.From, inst.To)
+	buf.WriteString("zext")
+	if inst.NNeg {
+		buf.WriteString(" nneg")
+	}
+	fmt.Fprintf(buf, " %s to %s", inst.From, inst.To)
 	for _, md := range inst.Metadata {
 		fmt.Fprintf(buf, ", %s", md)
 	}
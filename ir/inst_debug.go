@@ -0,0 +1,136 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Debug records ] =======================================================
+
+// ~~~ [ #dbg_value, #dbg_declare, #dbg_assign, #dbg_label ] ~~~~~~~~~~~~~~~~~~~
+
+// DbgRecord is a debug record; a record of the location of a source variable or
+// label, which (as opposed to debug intrinsic calls) does not affect code
+// generation. A debug record is stored in the list of instructions of a basic
+// block, and is attached to the instruction following it.
+type DbgRecord struct {
+	// Debug record kind.
+	Kind enum.DbgRecordKind
+	// Location of source variable; nil for #dbg_label. The address of the
+	// source variable for #dbg_declare.
+	Value value.Value
+	// Source variable (e.g. *metadata.DILocalVariable), or label (e.g.
+	// *metadata.DILabel) for #dbg_label.
+	Variable metadata.Field
+	// DWARF expression of source variable location (e.g.
+	// *metadata.DIExpression); nil for #dbg_label.
+	Expr metadata.Field
+	// Assignment ID of #dbg_assign; linked to the store or alloca instruction
+	// of the assignment by a !DIAssignID metadata attachment.
+	AssignID *metadata.DIAssignID
+	// Address of assignment of #dbg_assign.
+	Address value.Value
+	// DWARF expression of address of assignment of #dbg_assign.
+	AddressExpr metadata.Field
+	// Debug location (e.g. *metadata.DILocation).
+	Loc metadata.Field
+}
+
+// NewDbgValue returns a new #dbg_value debug record based on the given source
+// variable location, source variable, DWARF expression and debug location.
+func NewDbgValue(v value.Value, variable, expr, loc metadata.Field) *DbgRecord {
+	return &DbgRecord{Kind: enum.DbgRecordKindValue, Value: v, Variable: variable, Expr: expr, Loc: loc}
+}
+
+// NewDbgDeclare returns a new #dbg_declare debug record based on the given
+// source variable address, source variable, DWARF expression and debug
+// location.
+func NewDbgDeclare(addr value.Value, variable, expr, loc metadata.Field) *DbgRecord {
+	return &DbgRecord{Kind: enum.DbgRecordKindDeclare, Value: addr, Variable: variable, Expr: expr, Loc: loc}
+}
+
+// NewDbgAssign returns a new #dbg_assign debug record based on the given
+// assigned value, source variable, DWARF expression, assignment ID, address of
+// assignment, DWARF expression of address and debug location.
+func NewDbgAssign(v value.Value, variable, expr metadata.Field, id *metadata.DIAssignID, addr value.Value, addrExpr, loc metadata.Field) *DbgRecord {
+	return &DbgRecord{Kind: enum.DbgRecordKindAssign, Value: v, Variable: variable, Expr: expr, AssignID: id, Address: addr, AddressExpr: addrExpr, Loc: loc}
+}
+
+// NewDbgLabel returns a new #dbg_label debug record based on the given label
+// and debug location.
+func NewDbgLabel(label, loc metadata.Field) *DbgRecord {
+	return &DbgRecord{Kind: enum.DbgRecordKindLabel, Variable: label, Loc: loc}
+}
+
+// LLString returns the LLVM syntax representation of the debug record.
+func (r *DbgRecord) LLString() string {
+	// '#dbg_value' '(' Value=Metadata ',' Variable=MDNode ',' Expr=MDNode ','
+	// Loc=MDNode ')'
+	//
+	// '#dbg_assign' '(' Value=Metadata ',' Variable=MDNode ',' Expr=MDNode ','
+	// AssignID=MDNode ',' Address=Metadata ',' AddressExpr=MDNode ','
+	// Loc=MDNode ')'
+	//
+	// '#dbg_label' '(' Label=MDNode ',' Loc=MDNode ')'
+	var args []string
+	switch r.Kind {
+	case enum.DbgRecordKindLabel:
+		args = append(args, r.Variable.String())
+	case enum.DbgRecordKindAssign:
+		args = append(args, r.Value.String(), r.Variable.String(), r.Expr.String(), r.AssignID.Ident(), r.Address.String(), r.AddressExpr.String())
+	default:
+		args = append(args, r.Value.String(), r.Variable.String(), r.Expr.String())
+	}
+	args = append(args, r.Loc.String())
+	return fmt.Sprintf("#%s(%s)", r.Kind, strings.Join(args, ", "))
+}
+
+// DebugLoc returns the debug location of the debug record, or nil if not of
+// type *metadata.DILocation.
+func (r *DbgRecord) DebugLoc() *metadata.DILocation {
+	loc, _ := r.Loc.(*metadata.DILocation)
+	return loc
+}
+
+// SetDebugLoc sets the debug location of the debug record. Note, debug records
+// require a debug location.
+func (r *DbgRecord) SetDebugLoc(loc *metadata.DILocation) {
+	r.Loc = loc
+}
+
+// ### [ Helper functions ] ####################################################
+
+// AssignID returns the assignment ID of the given instruction (typically store
+// or alloca), as specified by its !DIAssignID metadata attachment; or nil if
+// not present.
+func AssignID(inst Instruction) *metadata.DIAssignID {
+	n, ok := inst.(interface {
+		MDAttachments() []*metadata.Attachment
+	})
+	if !ok {
+		return nil
+	}
+	for _, md := range n.MDAttachments() {
+		if id, ok := md.Node.(*metadata.DIAssignID); ok && md.Name == "DIAssignID" {
+			return id
+		}
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// withoutDbgRecords returns the given instructions, excluding debug records.
+func withoutDbgRecords(insts []Instruction) []Instruction {
+	var filtered []Instruction
+	for _, inst := range insts {
+		if _, ok := inst.(*DbgRecord); !ok {
+			filtered = append(filtered, inst)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,126 @@
+package ir
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestDbgAssign(t *testing.T) {
+	m := NewModule()
+	f := m.NewFunc("f", types.Void, NewParam("x", types.I32))
+	file := &metadata.DIFile{MetadataID: -1, Filename: "foo.c"}
+	variable := &metadata.DILocalVariable{MetadataID: -1, Name: "v", Scope: file}
+	loc := &metadata.DILocation{MetadataID: -1, Line: 1, Scope: file}
+	expr, err := metadata.NewDIExpression()
+	if err != nil {
+		t.Fatalf("unable to create DIExpression; %v", err)
+	}
+	id := metadata.NewDIAssignID()
+	m.MetadataDefs = append(m.MetadataDefs, file, variable, loc, id)
+	if err := m.AssignMetadataIDs(); err != nil {
+		t.Fatalf("unable to assign metadata IDs; %v", err)
+	}
+	entry := f.NewBlock("entry")
+	p := entry.NewAlloca(types.I32)
+	p.SetName("p")
+	store := entry.NewStore(f.Params[0], p)
+	store.Metadata = append(store.Metadata, &metadata.Attachment{Name: "DIAssignID", Node: id})
+	record := NewDbgAssign(f.Params[0], variable, expr, id, p, expr, loc)
+	entry.Insts = append(entry.Insts, record)
+	entry.NewRet(nil)
+	// Assignment ID linkage.
+	if got := AssignID(store); got != record.AssignID {
+		t.Errorf("assignment ID mismatch; expected %v, got %v", record.AssignID, got)
+	}
+	if got := AssignID(p); got != nil {
+		t.Errorf("unexpected assignment ID of alloca; %v", got)
+	}
+	const want = `define void @f(i32 %x) {
+entry:
+	%p = alloca i32
+	store i32 %x, i32* %p, !DIAssignID !3
+	#dbg_assign(i32 %x, !1, !DIExpression(), !3, i32* %p, !DIExpression(), !2)
+	ret void
+}
+
+!0 = !DIFile(filename: "foo.c", directory: "")
+!1 = !DILocalVariable(name: "v", scope: !0)
+!2 = !DILocation(line: 1, scope: !0)
+!3 = distinct !DIAssignID()
+`
+	if got := m.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("module mismatch; expected suffix `%s`, got `%s`", want, got)
+	}
+	// Replacing the assigned value updates the debug record.
+	y := NewParam("y", types.I32)
+	f.ReplaceAllUsesWith(f.Params[0], y)
+	if record.Value != y {
+		t.Errorf("debug record value mismatch; expected %v, got %v", y, record.Value)
+	}
+}
+
+func TestInstDebugLoc(t *testing.T) {
+	m := NewModule()
+	f := m.NewFunc("f", types.I32, NewParam("x", types.I32))
+	entry := f.NewBlock("")
+	file := &metadata.DIFile{MetadataID: -1, Filename: "foo.c"}
+	loc := &metadata.DILocation{MetadataID: -1, Line: 3, Column: 7, Scope: file}
+	add := entry.NewAdd(f.Params[0], f.Params[0])
+	add.SetName("y")
+	entry.NewRet(add)
+	// New instructions have no debug location.
+	if got := add.DebugLoc(); got != nil {
+		t.Errorf("debug location mismatch; expected nil, got %v", got)
+	}
+	// Propagate debug location to instruction.
+	var inst Instruction = add
+	inst.SetDebugLoc(loc)
+	if got := inst.DebugLoc(); got != loc {
+		t.Errorf("debug location mismatch; expected %v, got %v", loc, got)
+	}
+	want := `%y = add i32 %x, %x, !dbg !DILocation(line: 3, column: 7, scope: !DIFile(filename: "foo.c", directory: ""))`
+	if got := add.LLString(); got != want {
+		t.Errorf("instruction mismatch; expected %q, got %q", want, got)
+	}
+	// Remove debug location.
+	inst.SetDebugLoc(nil)
+	if got := inst.DebugLoc(); got != nil {
+		t.Errorf("debug location mismatch; expected nil, got %v", got)
+	}
+	if len(add.Metadata) != 0 {
+		t.Errorf("metadata attachments mismatch; expected none, got %v", add.Metadata)
+	}
+}
+
+func TestDbgRecordInstWalks(t *testing.T) {
+	m := NewModule()
+	x := NewParam("x", types.I32)
+	f := m.NewFunc("f", types.I32, x)
+	entry := f.NewBlock("entry")
+	p := entry.NewAlloca(types.I32)
+	store := entry.NewStore(x, p)
+	dbg := NewDbgValue(x, nil, nil, nil)
+	entry.Insts = append(entry.Insts, dbg)
+	load := entry.NewLoad(p)
+	trailing := NewDbgValue(load, nil, nil, nil)
+	entry.Insts = append(entry.Insts, trailing)
+	entry.NewRet(load)
+	// Debug records are not counted as instructions.
+	if got, want := blockInstCount(entry), 4; got != want {
+		t.Errorf("instruction count mismatch; expected %d, got %d", want, got)
+	}
+	// Debug records stay attached to the instruction following them.
+	want := []Instruction{p, store, dbg, load, trailing}
+	got := entry.DataDependencyOrder()
+	if len(got) != len(want) {
+		t.Fatalf("length mismatch; expected %d, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("instruction %d mismatch; expected %v, got %v", i, want[i].LLString(), got[i].LLString())
+		}
+	}
+}
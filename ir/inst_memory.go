@@ -2,6 +2,7 @@ package ir
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/llir/llvm/ir/constant"
@@ -320,6 +321,8 @@ type InstCmpXchg struct {
 	Volatile bool
 	// (optional) Sync scope; empty if not present.
 	SyncScope string
+	// (optional) Alignment; zero if not present.
+	Align Align
 	// (optional) Metadata.
 	Metadata
 }
@@ -354,7 +357,8 @@ func (inst *InstCmpXchg) Type() types.Type {
 func (inst *InstCmpXchg) LLString() string {
 	// 'cmpxchg' Weakopt Volatileopt Ptr=TypeValue ',' Cmp=TypeValue ','
 	// New=TypeValue SyncScopeopt SuccessOrdering=AtomicOrdering
-	// FailureOrdering=AtomicOrdering Metadata=(',' MetadataAttachment)+?
+	// FailureOrdering=AtomicOrdering (',' Alignment)? Metadata=(','
+	// MetadataAttachment)+?
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "%s = ", inst.Ident())
 	buf.WriteString("cmpxchg")
@@ -370,6 +374,9 @@ func (inst *InstCmpXchg) LLString() string {
 	}
 	fmt.Fprintf(buf, " %s", inst.SuccessOrdering)
 	fmt.Fprintf(buf, " %s", inst.FailureOrdering)
+	if inst.Align != 0 {
+		fmt.Fprintf(buf, ", %s", inst.Align)
+	}
 	for _, md := range inst.Metadata {
 		fmt.Fprintf(buf, ", %s", md)
 	}
@@ -471,6 +478,8 @@ type InstGetElementPtr struct {
 	Typ types.Type // *types.PointerType or *types.VectorType (with elements of pointer type)
 	// (optional) In-bounds.
 	InBounds bool
+	// (optional) getelementptr flags (in addition to in-bounds).
+	Flags enum.GEPFlags
 	// (optional) Metadata.
 	Metadata
 }
@@ -516,14 +525,23 @@ func (inst *InstGetElementPtr) Type() types.Type {
 
 // LLString returns the LLVM syntax representation of the instruction.
 func (inst *InstGetElementPtr) LLString() string {
-	// 'getelementptr' InBoundsopt ElemType=Type ',' Src=TypeValue Indices=(','
-	// TypeValue)* Metadata=(',' MetadataAttachment)+?
+	// 'getelementptr' InBoundsopt Flags=GEPFlag* ElemType=Type ','
+	// Src=TypeValue Indices=(',' TypeValue)* Metadata=(','
+	// MetadataAttachment)+?
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "%s = ", inst.Ident())
 	buf.WriteString("getelementptr")
 	if inst.InBounds {
 		buf.WriteString(" inbounds")
 	}
+	flags := inst.Flags
+	if inst.InBounds {
+		// inbounds implies nusw.
+		flags = flags.Without(enum.GEPFlagNUSW)
+	}
+	for _, flag := range flags.List() {
+		fmt.Fprintf(buf, " %s", flag)
+	}
 	fmt.Fprintf(buf, " %s, %s", inst.ElemType, inst.Src)
 	for _, index := range inst.Indices {
 		fmt.Fprintf(buf, ", %s", index)
@@ -534,6 +552,53 @@ func (inst *InstGetElementPtr) LLString() string {
 	return buf.String()
 }
 
+// ConstantByteOffset returns the offset in bytes of the address computed by the
+// getelementptr instruction from its source address, based on the given data
+// layout. The boolean return value indicates whether the offset is computable;
+// i.e. whether all indices are constant integers.
+//
+// For byte addressing getelementptr instructions (e.g. `getelementptr i8, i8*
+// %p, i64 16`) the offset is the index itself.
+func (inst *InstGetElementPtr) ConstantByteOffset(dl *DataLayout) (int64, bool) {
+	// Cache element type if not present.
+	inst.Type()
+	var offset int64
+	t := inst.ElemType
+	for i, index := range inst.Indices {
+		c, ok := index.(*constant.Int)
+		if !ok || !c.X.IsInt64() {
+			return 0, false
+		}
+		idx := c.X.Int64()
+		if i == 0 {
+			// The first index steps over elements of the source address.
+			if idx != 0 && isScalableVector(t) {
+				// The size of scalable vectors is a runtime multiple of vscale.
+				return 0, false
+			}
+			offset += idx * int64(dl.TypeAllocSize(t))
+			continue
+		}
+		switch tt := t.(type) {
+		case *types.StructType:
+			if idx < 0 || idx >= int64(len(tt.Fields)) {
+				return 0, false
+			}
+			offset += int64(dl.StructFieldOffset(tt, int(idx)))
+			t = tt.Fields[idx]
+		case *types.ArrayType:
+			offset += idx * int64(dl.TypeAllocSize(tt.ElemType))
+			t = tt.ElemType
+		case *types.VectorType:
+			offset += idx * int64(dl.TypeAllocSize(tt.ElemType))
+			t = tt.ElemType
+		default:
+			return 0, false
+		}
+	}
+	return offset, true
+}
+
 // ### [ Helper functions ] ####################################################
 
 // gepType returns the pointer type or vector of pointers type to the element at
@@ -557,17 +622,20 @@ func gepType(elemType types.Type, indices []value.Value) types.Type {
 			e = t.ElemType
 		case *types.ArrayType:
 			e = t.ElemType
+		case *types.TargetExtType:
+			// Target extension types are opaque.
+			panic(fmt.Errorf("unable to index into element of target extension type `%s`; target extension types are opaque", e))
 		case *types.StructType:
 			switch index := index.(type) {
 			case *constant.Int:
-				e = t.Fields[index.X.Int64()]
+				e = t.Fields[structFieldIndex(t, index.X)]
 			case *constant.Vector:
 				// TODO: Validate how index vectors in gep are supposed to work.
 				idx, ok := index.Elems[0].(*constant.Int)
 				if !ok {
 					panic(fmt.Errorf("invalid index type for structure element; expected *constant.Int, got %T", index.Elems[0]))
 				}
-				i := idx.X.Int64()
+				i := idx.X
 				// Sanity check. All vector elements must be integers, and must have
 				// the same value.
 				for _, elem := range index.Elems {
@@ -575,12 +643,12 @@ func gepType(elemType types.Type, indices []value.Value) types.Type {
 					if !ok {
 						panic(fmt.Errorf("invalid index type for structure element; expected *constant.Int, got %T", elem))
 					}
-					j := idx.X.Int64()
-					if i != j {
+					j := idx.X
+					if i.Cmp(j) != 0 {
 						panic(fmt.Errorf("struct index mismatch; vector elements %d and %d differ", i, j))
 					}
 				}
-				e = t.Fields[i]
+				e = t.Fields[structFieldIndex(t, i)]
 			case *constant.ZeroInitializer:
 				e = t.Fields[0]
 			default:
@@ -598,8 +666,26 @@ func gepType(elemType types.Type, indices []value.Value) types.Type {
 	//    store <2 x %struct.fileinfo*> %113, <2 x %struct.fileinfo*>* %116, align 8, !dbg !4738, !tbaa !1793
 	if len(indices) > 0 {
 		if t, ok := indices[0].Type().(*types.VectorType); ok {
-			return types.NewVector(t.Len, types.NewPointer(e))
+			vt := types.NewVector(t.Len, types.NewPointer(e))
+			vt.Scalable = t.Scalable
+			return vt
 		}
 	}
 	return types.NewPointer(e)
 }
+
+// isScalableVector reports whether the given type is a scalable vector type.
+func isScalableVector(t types.Type) bool {
+	vt, ok := t.(*types.VectorType)
+	return ok && vt.Scalable
+}
+
+// structFieldIndex returns the field index of the given struct type, as
+// specified by the given integer. Integers of arbitrary width are handled
+// without overflow.
+func structFieldIndex(t *types.StructType, x *big.Int) int {
+	if x.Sign() < 0 || x.Cmp(big.NewInt(int64(len(t.Fields)))) >= 0 {
+		panic(fmt.Errorf("invalid struct index %v of struct type %v with %d fields", x, t, len(t.Fields)))
+	}
+	return int(x.Int64())
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
 	"github.com/llir/llvm/ir/types"
 	"github.com/pkg/errors"
 )
@@ -44,3 +45,168 @@ func TestTypeCheckStore(t *testing.T) {
 		})
 	}
 }
+
+func TestGetElementPtrFlags(t *testing.T) {
+	golden := []struct {
+		inBounds bool
+		flags    enum.GEPFlags
+		want     string
+	}{
+		{want: "getelementptr"},
+		{inBounds: true, want: "getelementptr inbounds"},
+		{flags: enum.NewGEPFlags(enum.GEPFlagNUSW), want: "getelementptr nusw"},
+		{flags: enum.NewGEPFlags(enum.GEPFlagNUW), want: "getelementptr nuw"},
+		{flags: enum.NewGEPFlags(enum.GEPFlagNUSW, enum.GEPFlagNUW), want: "getelementptr nusw nuw"},
+		{inBounds: true, flags: enum.NewGEPFlags(enum.GEPFlagNUW), want: "getelementptr inbounds nuw"},
+		// inbounds implies nusw.
+		{inBounds: true, flags: enum.NewGEPFlags(enum.GEPFlagNUSW, enum.GEPFlagNUW), want: "getelementptr inbounds nuw"},
+		// Flags are deduplicated and output in canonical order.
+		{flags: enum.NewGEPFlags(enum.GEPFlagNUW, enum.GEPFlagNUSW, enum.GEPFlagNUW), want: "getelementptr nusw nuw"},
+	}
+	g := NewGlobalDef("g", constant.NewZeroInitializer(types.NewArray(2, types.I32)))
+	zero := constant.NewInt(types.I64, 0)
+	one := constant.NewInt(types.I64, 1)
+	for _, gold := range golden {
+		// getelementptr instruction.
+		inst := NewGetElementPtr(g, zero, one)
+		inst.SetName("p")
+		inst.InBounds = gold.inBounds
+		inst.Flags = gold.flags
+		want := fmt.Sprintf("%%p = %s [2 x i32], [2 x i32]* @g, i64 0, i64 1", gold.want)
+		if got := inst.LLString(); got != want {
+			t.Errorf("getelementptr instruction mismatch; expected %q, got %q", want, got)
+		}
+		// getelementptr constant expression.
+		expr := constant.NewGetElementPtr(g, zero, one)
+		expr.InBounds = gold.inBounds
+		expr.Flags = gold.flags
+		want = fmt.Sprintf("%s ([2 x i32], [2 x i32]* @g, i64 0, i64 1)", gold.want)
+		if got := expr.Ident(); got != want {
+			t.Errorf("getelementptr expression mismatch; expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestConstantByteOffset(t *testing.T) {
+	dl, err := ParseDataLayout("e-m:e-i64:64-f80:128-n8:16:32:64-S128")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	// { i8, i32, [4 x i64] }
+	st := types.NewStruct(types.I8, types.I32, types.NewArray(4, types.I64))
+	p := NewParam("p", types.I8Ptr)
+	s := NewParam("s", types.NewPointer(st))
+	n := NewParam("n", types.I64)
+	i32 := func(x int64) *constant.Int { return constant.NewInt(types.I32, x) }
+	i64 := func(x int64) *constant.Int { return constant.NewInt(types.I64, x) }
+	golden := []struct {
+		inst *InstGetElementPtr
+		want int64
+		ok   bool
+	}{
+		// Byte addressing.
+		{inst: NewGetElementPtr(p, i64(16)), want: 16, ok: true},
+		{inst: NewGetElementPtr(p, i64(-3)), want: -3, ok: true},
+		{inst: NewGetElementPtr(p, n), ok: false},
+		// Struct and array indices.
+		{inst: NewGetElementPtr(s, i64(0), i32(1)), want: 4, ok: true},
+		{inst: NewGetElementPtr(s, i64(1), i32(2), i64(3)), want: 40 + 8 + 3*8, ok: true},
+		{inst: NewGetElementPtr(s, i64(0), i32(2), n), ok: false},
+	}
+	for _, g := range golden {
+		got, ok := g.inst.ConstantByteOffset(dl)
+		if ok != g.ok || got != g.want {
+			t.Errorf("byte offset mismatch of %q; expected (%d, %v), got (%d, %v)", g.inst.LLString(), g.want, g.ok, got, ok)
+		}
+	}
+}
+
+func TestGetElementPtrScalableVector(t *testing.T) {
+	// <vscale x 4 x i32>
+	vt := types.NewVector(4, types.I32)
+	vt.Scalable = true
+	p := NewParam("p", types.NewPointer(vt))
+	i := NewParam("i", types.I64)
+	// <vscale x 4 x i32*>
+	ps := types.NewVector(4, types.I32Ptr)
+	ps.Scalable = true
+	// <vscale x 4 x i64>
+	is := types.NewVector(4, types.I64)
+	is.Scalable = true
+	i64 := func(x int64) *constant.Int { return constant.NewInt(types.I64, x) }
+	golden := []struct {
+		inst     *InstGetElementPtr
+		want     string
+		wantType string
+	}{
+		{
+			inst:     NewGetElementPtr(p, i),
+			want:     "%0 = getelementptr <vscale x 4 x i32>, <vscale x 4 x i32>* %p, i64 %i",
+			wantType: "<vscale x 4 x i32>*",
+		},
+		{
+			inst:     NewGetElementPtr(p, i, i64(2)),
+			want:     "%0 = getelementptr <vscale x 4 x i32>, <vscale x 4 x i32>* %p, i64 %i, i64 2",
+			wantType: "i32*",
+		},
+		// Vector of pointers.
+		{
+			inst:     NewGetElementPtr(NewParam("ps", ps), NewParam("is", is)),
+			want:     "%0 = getelementptr i32, <vscale x 4 x i32*> %ps, <vscale x 4 x i64> %is",
+			wantType: "<vscale x 4 x i32*>",
+		},
+	}
+	for _, g := range golden {
+		if got := g.inst.LLString(); got != g.want {
+			t.Errorf("instruction mismatch; expected %q, got %q", g.want, got)
+		}
+		if got := g.inst.Type().String(); got != g.wantType {
+			t.Errorf("type mismatch of %q; expected %q, got %q", g.want, g.wantType, got)
+		}
+	}
+	// The size of scalable vectors is not known at compile time.
+	dl, err := ParseDataLayout("e-i64:64")
+	if err != nil {
+		t.Fatalf("unable to parse data layout; %v", err)
+	}
+	if off, ok := NewGetElementPtr(p, i64(1)).ConstantByteOffset(dl); ok {
+		t.Errorf("unexpected constant byte offset %d of scalable vector step", off)
+	}
+	if off, ok := NewGetElementPtr(p, i64(0), i64(2)).ConstantByteOffset(dl); !ok || off != 8 {
+		t.Errorf("byte offset mismatch; expected (8, true), got (%d, %v)", off, ok)
+	}
+}
+
+func TestTargetExtTypeMemory(t *testing.T) {
+	typ := types.NewTargetExt("spirv.Event", nil, nil)
+	f := NewFunc("f", types.Void, NewParam("x", typ))
+	entry := f.NewBlock("entry")
+	alloca := entry.NewAlloca(typ)
+	alloca.SetName("p")
+	entry.NewStore(f.Params[0], alloca)
+	load := entry.NewLoad(alloca)
+	load.SetName("v")
+	entry.NewRet(nil)
+	const want = `define void @f(target("spirv.Event") %x) {
+entry:
+	%p = alloca target("spirv.Event")
+	store target("spirv.Event") %x, target("spirv.Event")* %p
+	%v = load target("spirv.Event"), target("spirv.Event")* %p
+	ret void
+}`
+	if got := f.LLString(); got != want {
+		t.Errorf("function mismatch; expected %q, got %q", want, got)
+	}
+	// Target extension types are opaque, and may not be indexed into by
+	// getelementptr.
+	var panicErr error
+	func() {
+		defer func() { panicErr, _ = recover().(error) }()
+		zero := constant.NewInt(types.I64, 0)
+		NewGetElementPtr(alloca, zero, zero)
+	}()
+	const wantErr = "unable to index into element of target extension type `target(\"spirv.Event\")`; target extension types are opaque"
+	if panicErr == nil || panicErr.Error() != wantErr {
+		t.Errorf("getelementptr error mismatch; expected %q, got %v", wantErr, panicErr)
+	}
+}
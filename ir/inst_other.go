@@ -26,6 +26,9 @@ type InstICmp struct {
 
 	// Type of result produced by the instruction.
 	Typ types.Type // boolean or boolean vector
+	// (optional) Same sign; the result is a poison value if the operands have
+	// different sign bits.
+	SameSign bool
 	// (optional) Metadata.
 	Metadata
 }
@@ -53,7 +56,9 @@ func (inst *InstICmp) Type() types.Type {
 		case *types.IntType, *types.PointerType:
 			inst.Typ = types.I1
 		case *types.VectorType:
-			inst.Typ = types.NewVector(xType.Len, types.I1)
+			t := types.NewVector(xType.Len, types.I1)
+			t.Scalable = xType.Scalable
+			inst.Typ = t
 		default:
 			panic(fmt.Errorf("invalid icmp operand type; expected *types.IntType, *types.PointerType or *types.VectorType, got %T", xType))
 		}
@@ -63,11 +68,15 @@ func (inst *InstICmp) Type() types.Type {
 
 // LLString returns the LLVM syntax representation of the instruction.
 func (inst *InstICmp) LLString() string {
-	// 'icmp' Pred=IPred X=TypeValue ',' Y=Value Metadata=(','
+	// 'icmp' SameSignopt Pred=IPred X=TypeValue ',' Y=Value Metadata=(','
 	// MetadataAttachment)+?
 	buf := &strings.Builder{}
 	fmt.Fprintf(buf, "%s = ", inst.Ident())
-	fmt.Fprintf(buf, "icmp %s %s, %s", inst.Pred, inst.X, inst.Y.Ident())
+	buf.WriteString("icmp")
+	if inst.SameSign {
+		buf.WriteString(" samesign")
+	}
+	fmt.Fprintf(buf, " %s %s, %s", inst.Pred, inst.X, inst.Y.Ident())
 	for _, md := range inst.Metadata {
 		fmt.Fprintf(buf, ", %s", md)
 	}
@@ -118,7 +127,9 @@ func (inst *InstFCmp) Type() types.Type {
 		case *types.FloatType:
 			inst.Typ = types.I1
 		case *types.VectorType:
-			inst.Typ = types.NewVector(xType.Len, types.I1)
+			t := types.NewVector(xType.Len, types.I1)
+			t.Scalable = xType.Scalable
+			inst.Typ = t
 		default:
 			panic(fmt.Errorf("invalid fcmp operand type; expected *types.FloatType or *types.VectorType, got %T", xType))
 		}
@@ -161,13 +172,38 @@ type InstPhi struct {
 }
 
 // NewPhi returns a new phi instruction based on the given incoming values.
+// Further incoming values may be added using AddIncoming; e.g. when the
+// predecessor basic blocks are not yet present.
+//
+// NewPhi panics if the incoming values are not all of the same type.
 func NewPhi(incs ...*Incoming) *InstPhi {
-	inst := &InstPhi{Incs: incs}
-	// Compute type.
-	inst.Type()
+	inst := &InstPhi{}
+	for _, inc := range incs {
+		inst.addIncoming(inc)
+	}
 	return inst
 }
 
+// AddIncoming appends a new incoming value to the phi instruction based on the
+// given value and predecessor basic block.
+//
+// AddIncoming panics if the type of the incoming value differs from the type of
+// the phi instruction.
+func (inst *InstPhi) AddIncoming(x value.Value, pred *Block) {
+	inst.addIncoming(NewIncoming(x, pred))
+}
+
+// addIncoming appends the given incoming value to the phi instruction, after
+// validating its type.
+func (inst *InstPhi) addIncoming(inc *Incoming) {
+	if inst.Typ == nil {
+		inst.Typ = inc.X.Type()
+	} else if !inc.X.Type().Equal(inst.Typ) {
+		panic(fmt.Errorf("phi incoming value type mismatch of %q; expected %v, got %v", inc.Pred.Ident(), inst.Typ, inc.X.Type()))
+	}
+	inst.Incs = append(inst.Incs, inc)
+}
+
 // String returns the LLVM syntax representation of the instruction as a
 // type-value pair.
 func (inst *InstPhi) String() string {
@@ -280,6 +316,62 @@ func (inst *InstSelect) LLString() string {
 	return buf.String()
 }
 
+// ~~~ [ freeze ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+// InstFreeze is an LLVM IR freeze instruction.
+//
+// If the operand is undef or poison, each freeze instruction yields an
+// arbitrary but fixed value of the operand type; thus two freeze instructions
+// of the same operand may yield different values.
+type InstFreeze struct {
+	// Name of local variable associated with the result.
+	LocalIdent
+	// Operand.
+	X value.Value
+
+	// extra.
+
+	// Type of result produced by the instruction.
+	Typ types.Type
+	// (optional) Metadata.
+	Metadata
+}
+
+// NewFreeze returns a new freeze instruction based on the given operand.
+func NewFreeze(x value.Value) *InstFreeze {
+	inst := &InstFreeze{X: x}
+	// Compute type.
+	inst.Type()
+	return inst
+}
+
+// String returns the LLVM syntax representation of the instruction as a
+// type-value pair.
+func (inst *InstFreeze) String() string {
+	return fmt.Sprintf("%s %s", inst.Type(), inst.Ident())
+}
+
+// Type returns the type of the instruction.
+func (inst *InstFreeze) Type() types.Type {
+	// Cache type if not present.
+	if inst.Typ == nil {
+		inst.Typ = inst.X.Type()
+	}
+	return inst.Typ
+}
+
+// LLString returns the LLVM syntax representation of the instruction.
+func (inst *InstFreeze) LLString() string {
+	// 'freeze' X=TypeValue Metadata=(',' MetadataAttachment)+?
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "%s = ", inst.Ident())
+	fmt.Fprintf(buf, "freeze %s", inst.X)
+	for _, md := range inst.Metadata {
+		fmt.Fprintf(buf, ", %s", md)
+	}
+	return buf.String()
+}
+
 // ~~~ [ call ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // InstCall is an LLVM IR call instruction.
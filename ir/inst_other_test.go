@@ -0,0 +1,102 @@
+package ir
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestPhiAddIncoming(t *testing.T) {
+	m := NewModule()
+	x := NewParam("x", types.I32)
+	f := m.NewFunc("f", types.I32, x)
+	entry := f.NewBlock("entry")
+	then := f.NewBlock("then")
+	els := f.NewBlock("else")
+	exit := f.NewBlock("exit")
+	cond := entry.NewICmp(enum.IPredSLT, x, constant.NewInt(types.I32, 0))
+	cond.SetName("cond")
+	entry.NewCondBr(cond, then, els)
+	neg := then.NewSub(constant.NewInt(types.I32, 0), x)
+	neg.SetName("neg")
+	then.NewBr(exit)
+	els.NewBr(exit)
+	// Add incoming values after creation of the phi instruction.
+	phi := exit.NewPhi()
+	phi.SetName("abs")
+	phi.AddIncoming(neg, then)
+	phi.AddIncoming(x, els)
+	exit.NewRet(phi)
+	const want = `define i32 @f(i32 %x) {
+entry:
+	%cond = icmp slt i32 %x, 0
+	br i1 %cond, label %then, label %else
+
+then:
+	%neg = sub i32 0, %x
+	br label %exit
+
+else:
+	br label %exit
+
+exit:
+	%abs = phi i32 [ %neg, %then ], [ %x, %else ]
+	ret i32 %abs
+}`
+	if got := f.LLString(); got != want {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+	}
+	// Incoming value of mismatching type.
+	wantPanic := `phi incoming value type mismatch of "%else"; expected i32, got i64`
+	func() {
+		defer func() {
+			got := fmt.Sprint(recover())
+			if got != wantPanic {
+				t.Errorf("panic mismatch; expected %q, got %q", wantPanic, got)
+			}
+		}()
+		NewPhi(NewIncoming(neg, then), NewIncoming(constant.NewInt(types.I64, 0), els))
+	}()
+}
+
+func TestMemoryEffectsString(t *testing.T) {
+	golden := []struct {
+		attr MemoryEffects
+		want string
+	}{
+		{attr: NewMemoryEffects(enum.ModRefNone), want: "memory(none)"},
+		{attr: NewMemoryEffects(enum.ModRefRead), want: "memory(read)"},
+		{attr: NewMemoryEffects(enum.ModRefReadWrite), want: "memory(readwrite)"},
+		{attr: MemoryEffects{ArgMem: enum.ModRefReadWrite}, want: "memory(argmem: readwrite)"},
+		{attr: MemoryEffects{ArgMem: enum.ModRefReadWrite, InaccessibleMem: enum.ModRefWrite, Other: enum.ModRefRead}, want: "memory(read, argmem: readwrite, inaccessiblemem: write)"},
+		{attr: MemoryEffects{ArgMem: enum.ModRefRead, InaccessibleMem: enum.ModRefNone, Other: enum.ModRefRead}, want: "memory(read, inaccessiblemem: none)"},
+	}
+	for _, g := range golden {
+		if got := g.attr.String(); got != g.want {
+			t.Errorf("memory effects mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+}
+
+func TestCallSiteFuncAttrs(t *testing.T) {
+	m := NewModule()
+	f := m.NewFunc("f", types.Void)
+	f.FuncAttrs = append(f.FuncAttrs, enum.FuncAttrNoUnwind)
+	g := m.NewFunc("g", types.Void)
+	entry := g.NewBlock("entry")
+	call := entry.NewCall(f)
+	call.FuncAttrs = append(call.FuncAttrs, enum.FuncAttrNoBuiltin, NewMemoryEffects(enum.ModRefRead))
+	entry.NewRet(nil)
+	const want = "call void @f() nobuiltin memory(read)"
+	if got := call.LLString(); got != want {
+		t.Errorf("call instruction mismatch; expected %q, got %q", want, got)
+	}
+	// Call-site attributes are distinct from the attributes of the callee.
+	const wantDecl = "declare void @f() nounwind"
+	if got := f.LLString(); got != wantDecl {
+		t.Errorf("function declaration mismatch; expected %q, got %q", wantDecl, got)
+	}
+}
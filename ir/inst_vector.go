@@ -178,7 +178,9 @@ func (inst *InstShuffleVector) Type() types.Type {
 		if !ok {
 			panic(fmt.Errorf("invalid vector type; expected *types.VectorType, got %T", inst.Mask.Type()))
 		}
-		inst.Typ = types.NewVector(maskType.Len, xType.ElemType)
+		t := types.NewVector(maskType.Len, xType.ElemType)
+		t.Scalable = maskType.Scalable
+		inst.Typ = t
 	}
 	return inst.Typ
 }
@@ -1,5 +1,10 @@
 package ir
 
+import (
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/value"
+)
+
 // === [ Instructions ] ========================================================
 
 // Instruction is an LLVM IR instruction. All instructions (except store and
@@ -95,13 +100,38 @@ package ir
 //    *ir.InstFCmp         // https://godoc.org/github.com/llir/llvm/ir#InstFCmp
 //    *ir.InstPhi          // https://godoc.org/github.com/llir/llvm/ir#InstPhi
 //    *ir.InstSelect       // https://godoc.org/github.com/llir/llvm/ir#InstSelect
+//    *ir.InstFreeze       // https://godoc.org/github.com/llir/llvm/ir#InstFreeze
 //    *ir.InstCall         // https://godoc.org/github.com/llir/llvm/ir#InstCall
 //    *ir.InstVAArg        // https://godoc.org/github.com/llir/llvm/ir#InstVAArg
 //    *ir.InstLandingPad   // https://godoc.org/github.com/llir/llvm/ir#InstLandingPad
 //    *ir.InstCatchPad     // https://godoc.org/github.com/llir/llvm/ir#InstCatchPad
 //    *ir.InstCleanupPad   // https://godoc.org/github.com/llir/llvm/ir#InstCleanupPad
+//
+// Debug records
+//
+// https://llvm.org/docs/SourceLevelDebugging.html#debug-records
+//
+//    *ir.DbgRecord   // https://godoc.org/github.com/llir/llvm/ir#DbgRecord
 type Instruction interface {
 	LLStringer
+	// Operands returns a mutable list of operands of the given instruction.
+	//
+	// Operands of more specific types than value.Value (e.g. the predecessor
+	// basic blocks of phi instructions and the exception scope of catchpad
+	// instructions) are not included.
+	Operands() []*value.Value
+	// Clone returns a copy of the given instruction. The result of the copy is
+	// unnamed, and its operands, flags and types are those of the original
+	// instruction; operands may be remapped through Operands.
+	Clone() Instruction
+	// DebugLoc returns the debug location of the instruction, as specified by
+	// its !dbg metadata attachment (or the location of debug records); or nil
+	// if not present.
+	DebugLoc() *metadata.DILocation
+	// SetDebugLoc sets the debug location of the instruction. A nil debug
+	// location removes the !dbg metadata attachment. New instructions have no
+	// debug location.
+	SetDebugLoc(loc *metadata.DILocation)
 	// isInstruction ensures that only instructions can be assigned to the
 	// instruction.Instruction interface.
 	isInstruction()
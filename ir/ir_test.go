@@ -104,11 +104,14 @@ var (
 	_ Instruction = (*InstFCmp)(nil)
 	_ Instruction = (*InstPhi)(nil)
 	_ Instruction = (*InstSelect)(nil)
+	_ Instruction = (*InstFreeze)(nil)
 	_ Instruction = (*InstCall)(nil)
 	_ Instruction = (*InstVAArg)(nil)
 	_ Instruction = (*InstLandingPad)(nil)
 	_ Instruction = (*InstCatchPad)(nil)
 	_ Instruction = (*InstCleanupPad)(nil)
+	// Debug records.
+	_ Instruction = (*DbgRecord)(nil)
 )
 
 // Assert that each terminator implements the ir.Terminator interface.
@@ -120,6 +123,7 @@ var (
 	_ Terminator = (*TermSwitch)(nil)
 	_ Terminator = (*TermIndirectBr)(nil)
 	_ Terminator = (*TermInvoke)(nil)
+	_ Terminator = (*TermCallBr)(nil)
 	_ Terminator = (*TermResume)(nil)
 	_ Terminator = (*TermCatchSwitch)(nil)
 	_ Terminator = (*TermCatchRet)(nil)
@@ -202,6 +206,7 @@ var (
 	_ value.Named = (*InstFCmp)(nil)
 	_ value.Named = (*InstPhi)(nil)
 	_ value.Named = (*InstSelect)(nil)
+	_ value.Named = (*InstFreeze)(nil)
 	_ value.Named = (*InstCall)(nil)
 	_ value.Named = (*InstVAArg)(nil)
 	_ value.Named = (*InstLandingPad)(nil)
@@ -210,5 +215,6 @@ var (
 
 	// Terminators.
 	_ value.Named = (*TermInvoke)(nil)
+	_ value.Named = (*TermCallBr)(nil)
 	_ value.Named = (*TermCatchSwitch)(nil) // token result used by catchpad
 )
@@ -0,0 +1,325 @@
+package ir
+
+import "github.com/llir/llvm/ir/value"
+
+// === [ Value kinds ] =========================================================
+
+// --- [ Global values ] -------------------------------------------------------
+
+// ValueKind returns the kind of the value.
+func (*Global) ValueKind() value.Kind {
+	return value.KindGlobalVar
+}
+
+// ValueKind returns the kind of the value.
+func (*Func) ValueKind() value.Kind {
+	return value.KindGlobalFunc
+}
+
+// ValueKind returns the kind of the value.
+func (*Alias) ValueKind() value.Kind {
+	return value.KindAlias
+}
+
+// ValueKind returns the kind of the value.
+func (*IFunc) ValueKind() value.Kind {
+	return value.KindIFunc
+}
+
+// --- [ Local values ] --------------------------------------------------------
+
+// ValueKind returns the kind of the value.
+func (*Param) ValueKind() value.Kind {
+	return value.KindParam
+}
+
+// ValueKind returns the kind of the value.
+func (*Block) ValueKind() value.Kind {
+	return value.KindBlock
+}
+
+// --- [ Instructions ] --------------------------------------------------------
+
+// ValueKind returns the kind of the value.
+func (*InstFNeg) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstAdd) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFAdd) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstSub) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFSub) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstMul) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFMul) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstUDiv) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstSDiv) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFDiv) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstURem) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstSRem) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFRem) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstShl) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstLShr) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstAShr) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstAnd) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstOr) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstXor) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstExtractElement) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstInsertElement) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstShuffleVector) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstExtractValue) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstInsertValue) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstAlloca) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstLoad) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstCmpXchg) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstAtomicRMW) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstGetElementPtr) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstTrunc) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstZExt) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstSExt) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFPTrunc) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFPExt) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFPToUI) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFPToSI) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstUIToFP) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstSIToFP) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstPtrToInt) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstIntToPtr) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstBitCast) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstAddrSpaceCast) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstICmp) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFCmp) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstPhi) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstSelect) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstFreeze) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstCall) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstVAArg) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstLandingPad) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstCatchPad) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// ValueKind returns the kind of the value.
+func (*InstCleanupPad) ValueKind() value.Kind {
+	return value.KindInstruction
+}
+
+// --- [ Terminators ] ---------------------------------------------------------
+
+// ValueKind returns the kind of the value.
+func (*TermInvoke) ValueKind() value.Kind {
+	return value.KindTerminator
+}
+
+// ValueKind returns the kind of the value.
+func (*TermCallBr) ValueKind() value.Kind {
+	return value.KindTerminator
+}
+
+// ValueKind returns the kind of the value.
+func (*TermCatchSwitch) ValueKind() value.Kind {
+	return value.KindTerminator
+}
+
+// --- [ Other values ] --------------------------------------------------------
+
+// ValueKind returns the kind of the value.
+func (*InlineAsm) ValueKind() value.Kind {
+	return value.KindInlineAsm
+}
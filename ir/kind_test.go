@@ -0,0 +1,124 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestKindOf(t *testing.T) {
+	m := ir.NewModule()
+	g := m.NewGlobalDef("g", constant.NewInt(types.I32, 0))
+	f := m.NewFunc("f", types.I32, ir.NewParam("x", types.I32))
+	alias := m.NewAlias("a", g)
+	ifunc := m.NewIFunc("h", f)
+	entry := f.NewBlock("entry")
+	add := entry.NewAdd(f.Params[0], constant.NewInt(types.I32, 1))
+	normal := f.NewBlock("normal")
+	unwind := f.NewBlock("unwind")
+	invoke := entry.NewInvoke(f, []value.Value{add}, normal, unwind)
+	golden := []struct {
+		v    value.Value
+		want value.Kind
+	}{
+		{v: nil, want: value.KindUnknown},
+		// Simple constants.
+		{v: constant.NewInt(types.I32, 42), want: value.KindIntConstant},
+		{v: constant.NewFloat(types.Double, 1), want: value.KindFloatConstant},
+		{v: constant.NewNull(types.I32Ptr), want: value.KindNullConstant},
+		{v: constant.None, want: value.KindNoneToken},
+		{v: constant.NewStruct(types.NewStruct(types.I32), constant.NewInt(types.I32, 1)), want: value.KindStructConstant},
+		{v: constant.NewArray(types.NewArray(1, types.I32), constant.NewInt(types.I32, 1)), want: value.KindArrayConstant},
+		{v: constant.NewCharArrayFromString("foo"), want: value.KindArrayConstant},
+		{v: constant.NewVector(types.NewVector(1, types.I32), constant.NewInt(types.I32, 1)), want: value.KindVectorConstant},
+		{v: constant.NewZeroInitializer(types.NewArray(2, types.I32)), want: value.KindZeroInitializer},
+		{v: constant.NewUndef(types.I32), want: value.KindUndef},
+		{v: constant.NewPoison(types.I32), want: value.KindPoison},
+		{v: constant.NewBlockAddress(f, entry), want: value.KindBlockAddress},
+		{v: constant.NewPtrToInt(g, types.I64), want: value.KindConstantExpr},
+		{v: constant.NewAdd(constant.NewInt(types.I32, 1), constant.NewInt(types.I32, 2)), want: value.KindConstantExpr},
+		// Global values.
+		{v: g, want: value.KindGlobalVar},
+		{v: f, want: value.KindGlobalFunc},
+		{v: alias, want: value.KindAlias},
+		{v: ifunc, want: value.KindIFunc},
+		// Local values.
+		{v: f.Params[0], want: value.KindParam},
+		{v: entry, want: value.KindBlock},
+		{v: add, want: value.KindInstruction},
+		{v: invoke, want: value.KindTerminator},
+		// Other values.
+		{v: ir.NewInlineAsm(types.NewPointer(types.NewFunc(types.Void)), "nop", ""), want: value.KindInlineAsm},
+		{v: &metadata.Value{Value: &metadata.String{Value: "foo"}}, want: value.KindMetadata},
+	}
+	covered := make(map[value.Kind]bool)
+	for _, g := range golden {
+		got := value.KindOf(g.v)
+		if got != g.want {
+			t.Errorf("kind mismatch of %s; expected %v, got %v", value.Ident(g.v), g.want, got)
+		}
+		covered[g.want] = true
+	}
+	// Each value kind is covered.
+	for kind := value.KindUnknown; kind <= value.KindMetadata; kind++ {
+		if !covered[kind] {
+			t.Errorf("value kind %v not covered", kind)
+		}
+	}
+}
+
+func TestKindOfInsts(t *testing.T) {
+	// Each instruction and terminator producing a value, and each operand, has
+	// a known value kind.
+	paths := []string{
+		"../asm/testdata/inst_aggregate.ll",
+		"../asm/testdata/inst_binary.ll",
+		"../asm/testdata/inst_bitwise.ll",
+		"../asm/testdata/inst_conversion.ll",
+		"../asm/testdata/inst_memory.ll",
+		"../asm/testdata/inst_other.ll",
+		"../asm/testdata/inst_vector.ll",
+		"../asm/testdata/terminator.ll",
+	}
+	for _, path := range paths {
+		m, err := asm.ParseFile(path)
+		if err != nil {
+			t.Errorf("unable to parse %q; %+v", path, err)
+			continue
+		}
+		check := func(v value.Value, want value.Kind) {
+			if got := value.KindOf(v); got != want {
+				t.Errorf("%s: kind mismatch of %s (%T); expected %v, got %v", path, v.Ident(), v, want, got)
+			}
+		}
+		checkOperands := func(ops []*value.Value) {
+			for _, op := range ops {
+				if *op == nil {
+					continue
+				}
+				if got := value.KindOf(*op); got == value.KindUnknown {
+					t.Errorf("%s: unknown kind of operand %s (%T)", path, (*op).Ident(), *op)
+				}
+			}
+		}
+		for _, f := range m.Funcs {
+			for _, block := range f.Blocks {
+				for _, inst := range block.Insts {
+					if v, ok := inst.(value.Value); ok {
+						check(v, value.KindInstruction)
+					}
+					checkOperands(inst.Operands())
+				}
+				if v, ok := block.Term.(value.Value); ok {
+					check(v, value.KindTerminator)
+				}
+				checkOperands(block.Term.Operands())
+			}
+		}
+	}
+}
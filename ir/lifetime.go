@@ -0,0 +1,178 @@
+package ir
+
+import (
+	"strings"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Lifetimes ] ===========================================================
+
+// LifetimeRange is a live range of a stack slot, as bracketed by calls to the
+// @llvm.lifetime.start and @llvm.lifetime.end intrinsics.
+type LifetimeRange struct {
+	// Call to @llvm.lifetime.start which starts the live range.
+	Start *InstCall
+	// Parent basic block of Start.
+	StartBlock *Block
+	// (optional) Call to @llvm.lifetime.end which ends the live range; nil if
+	// the live range is not ended.
+	End *InstCall
+	// (optional) Parent basic block of End; nil if End is nil.
+	EndBlock *Block
+	// Size in bytes of the live object; -1 if unknown.
+	Size int64
+}
+
+// Lifetimes returns the live ranges of the stack slots of the function, as
+// specified by calls to the @llvm.lifetime.start and @llvm.lifetime.end
+// intrinsics. The pointer operand of the intrinsics is either an alloca
+// instruction or a bitcast of an alloca instruction.
+//
+// Each call to @llvm.lifetime.end is paired with the nearest call to
+// @llvm.lifetime.start on the same alloca instruction which dominates it; as
+// such, a start may be paired with several ends (e.g. one on each branch).
+// Starts not paired with any end have a live range with a nil End. Ends not
+// dominated by any start are ignored. The live ranges of each alloca
+// instruction are in the order of the instructions of the function.
+func (f *Func) Lifetimes() map[*InstAlloca][]LifetimeRange {
+	type lifetimeCall struct {
+		call  *InstCall
+		block *Block
+		// Index of call in block.
+		index int
+	}
+	starts := make(map[*InstAlloca][]lifetimeCall)
+	ends := make(map[*InstAlloca][]lifetimeCall)
+	var allocas []*InstAlloca
+	for _, block := range f.Blocks {
+		for i, inst := range block.Insts {
+			call, ok := inst.(*InstCall)
+			if !ok {
+				continue
+			}
+			start, ok := isLifetimeCall(call)
+			if !ok {
+				continue
+			}
+			alloca := lifetimeAlloca(call)
+			if alloca == nil {
+				continue
+			}
+			if _, ok := starts[alloca]; !ok {
+				if _, ok := ends[alloca]; !ok {
+					allocas = append(allocas, alloca)
+				}
+			}
+			c := lifetimeCall{call: call, block: block, index: i}
+			if start {
+				starts[alloca] = append(starts[alloca], c)
+			} else {
+				ends[alloca] = append(ends[alloca], c)
+			}
+		}
+	}
+	dt := f.DomTree()
+	ranges := make(map[*InstAlloca][]LifetimeRange)
+	for _, alloca := range allocas {
+		paired := make(map[*InstCall]bool)
+		// Live ranges of each start, in order of ends.
+		startRanges := make(map[*InstCall][]LifetimeRange)
+		for _, end := range ends[alloca] {
+			// Locate the nearest dominating start, by walking the dominator tree
+			// upwards from the basic block of the end.
+			var start *lifetimeCall
+			for block := end.block; block != nil && start == nil; block = dt.IDom[block] {
+				for i := len(starts[alloca]) - 1; i >= 0; i-- {
+					s := starts[alloca][i]
+					if s.block != block || (block == end.block && s.index > end.index) {
+						continue
+					}
+					start = &starts[alloca][i]
+					break
+				}
+			}
+			if start == nil {
+				continue
+			}
+			paired[start.call] = true
+			r := LifetimeRange{
+				Start:      start.call,
+				StartBlock: start.block,
+				End:        end.call,
+				EndBlock:   end.block,
+				Size:       lifetimeSize(start.call),
+			}
+			startRanges[start.call] = append(startRanges[start.call], r)
+		}
+		for _, start := range starts[alloca] {
+			if !paired[start.call] {
+				r := LifetimeRange{
+					Start:      start.call,
+					StartBlock: start.block,
+					Size:       lifetimeSize(start.call),
+				}
+				ranges[alloca] = append(ranges[alloca], r)
+				continue
+			}
+			ranges[alloca] = append(ranges[alloca], startRanges[start.call]...)
+		}
+	}
+	return ranges
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isLifetimeCall reports whether the given call is a call to a lifetime
+// intrinsic. The boolean return value start indicates whether the call is a
+// call to @llvm.lifetime.start (as opposed to @llvm.lifetime.end).
+func isLifetimeCall(call *InstCall) (start, ok bool) {
+	callee, ok := call.Callee.(*Func)
+	if !ok {
+		return false, false
+	}
+	// Overloaded intrinsics are suffixed by their pointer type (e.g.
+	// @llvm.lifetime.start.p0i8).
+	name := callee.Name()
+	switch {
+	case name == "llvm.lifetime.start" || strings.HasPrefix(name, "llvm.lifetime.start."):
+		return true, true
+	case name == "llvm.lifetime.end" || strings.HasPrefix(name, "llvm.lifetime.end."):
+		return false, true
+	}
+	return false, false
+}
+
+// lifetimeAlloca returns the alloca instruction of the pointer operand of the
+// given call to a lifetime intrinsic, or nil if the pointer operand is not an
+// alloca instruction (possibly through bitcasts).
+func lifetimeAlloca(call *InstCall) *InstAlloca {
+	if len(call.Args) != 2 {
+		return nil
+	}
+	var ptr value.Value = call.Args[1]
+	for {
+		switch p := ptr.(type) {
+		case *InstAlloca:
+			return p
+		case *InstBitCast:
+			ptr = p.From
+		default:
+			return nil
+		}
+	}
+}
+
+// lifetimeSize returns the size operand of the given call to a lifetime
+// intrinsic, or -1 if unknown.
+func lifetimeSize(call *InstCall) int64 {
+	if len(call.Args) != 2 {
+		return -1
+	}
+	size, ok := call.Args[0].(*constant.Int)
+	if !ok || !size.X.IsInt64() {
+		return -1
+	}
+	return size.X.Int64()
+}
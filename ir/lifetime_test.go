@@ -0,0 +1,85 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestFuncLifetimes(t *testing.T) {
+	const src = `
+declare void @llvm.lifetime.start.p0i8(i64, i8* nocapture)
+
+declare void @llvm.lifetime.end.p0i8(i64, i8* nocapture)
+
+declare void @use(i32*)
+
+define void @f(i1 %cond) {
+entry:
+	%a = alloca i32
+	%b = alloca [16 x i8]
+	%a8 = bitcast i32* %a to i8*
+	call void @llvm.lifetime.start.p0i8(i64 4, i8* %a8)
+	call void @use(i32* %a)
+	call void @llvm.lifetime.end.p0i8(i64 4, i8* %a8)
+	%b8 = bitcast [16 x i8]* %b to i8*
+	call void @llvm.lifetime.start.p0i8(i64 -1, i8* %b8)
+	br i1 %cond, label %left, label %right
+
+left:
+	call void @llvm.lifetime.end.p0i8(i64 -1, i8* %b8)
+	call void @llvm.lifetime.start.p0i8(i64 4, i8* %a8)
+	ret void
+
+right:
+	call void @llvm.lifetime.end.p0i8(i64 -1, i8* %b8)
+	ret void
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[3]
+	entry, left, right := f.Blocks[0], f.Blocks[1], f.Blocks[2]
+	a := entry.Insts[0].(*ir.InstAlloca)
+	b := entry.Insts[1].(*ir.InstAlloca)
+	golden := []struct {
+		alloca *ir.InstAlloca
+		want   []ir.LifetimeRange
+	}{
+		{
+			alloca: a,
+			want: []ir.LifetimeRange{
+				{Start: entry.Insts[3].(*ir.InstCall), StartBlock: entry, End: entry.Insts[5].(*ir.InstCall), EndBlock: entry, Size: 4},
+				// Start not paired with any end.
+				{Start: left.Insts[1].(*ir.InstCall), StartBlock: left, Size: 4},
+			},
+		},
+		{
+			alloca: b,
+			want: []ir.LifetimeRange{
+				// Start paired with an end on each branch.
+				{Start: entry.Insts[7].(*ir.InstCall), StartBlock: entry, End: left.Insts[0].(*ir.InstCall), EndBlock: left, Size: -1},
+				{Start: entry.Insts[7].(*ir.InstCall), StartBlock: entry, End: right.Insts[0].(*ir.InstCall), EndBlock: right, Size: -1},
+			},
+		},
+	}
+	lifetimes := f.Lifetimes()
+	if len(lifetimes) != len(golden) {
+		t.Fatalf("number of allocas mismatch; expected %d, got %d", len(golden), len(lifetimes))
+	}
+	for _, g := range golden {
+		got := lifetimes[g.alloca]
+		if len(got) != len(g.want) {
+			t.Errorf("%s: number of live ranges mismatch; expected %d, got %d", g.alloca.Ident(), len(g.want), len(got))
+			continue
+		}
+		for i := range g.want {
+			if got[i] != g.want[i] {
+				t.Errorf("%s: live range %d mismatch; expected %+v, got %+v", g.alloca.Ident(), i, g.want[i], got[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,538 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/pkg/errors"
+)
+
+// === [ Module linking ] ======================================================
+
+// Link links the source module into the destination module. The source module
+// is consumed by Link, and should not be used after linking.
+//
+// Global variables, functions, aliases and IFuncs of the source module are
+// added to the destination module. Symbols of the same name are resolved
+// according to the linkage rules of LLVM:
+//
+//    * declarations (and available_externally definitions) are resolved to
+//      the definition of the other module;
+//    * strong definitions take precedence over weak definitions (weak,
+//      weak_odr, linkonce, linkonce_odr and common);
+//    * weak definitions take precedence over linkonce definitions;
+//    * common definitions take precedence over weak and linkonce definitions,
+//      and the larger of two common definitions is kept;
+//    * otherwise, the definition of the destination module is kept.
+//
+// The losing symbol is dropped, and references to it are redirected to the
+// winning symbol (bitcast to the type of the losing symbol if the types
+// differ). The array initializers of global variables with appending linkage
+// (e.g. @llvm.global_ctors) are concatenated. Symbols with private or internal
+// linkage do not conflict with symbols of the other module; they are renamed
+// on name collision. Linking two strong definitions of the same symbol is an
+// error.
+//
+// Type definitions, comdats, attribute groups, metadata and module-level
+// inline assembly of the source module are added to the destination module.
+// Type definitions of the same name are merged if their bodies are isomorphic
+// (i.e. structurally identical, where nested identified types are identical by
+// name), and an opaque type definition is resolved to the body of the other
+// module. Otherwise, the type definition of the source module is renamed (e.g.
+// %T.1), as by llvm-link. Comdats already present in the destination module (by
+// name) are not duplicated, and the named metadata nodes of named metadata
+// definitions present in both modules are concatenated.
+func Link(dst, src *Module) error {
+	l := newLinker(dst, src)
+	// Type definitions.
+	l.linkTypeDefs(src)
+	// Comdat definitions.
+	comdatNames := make(map[string]bool)
+	for _, c := range dst.ComdatDefs {
+		comdatNames[c.Name] = true
+	}
+	for _, c := range src.ComdatDefs {
+		if !comdatNames[c.Name] {
+			dst.ComdatDefs = append(dst.ComdatDefs, c)
+		}
+	}
+	// Global symbols.
+	var syms []constant.Constant
+	for _, g := range src.Globals {
+		syms = append(syms, g)
+	}
+	for _, alias := range src.Aliases {
+		syms = append(syms, alias)
+	}
+	for _, ifunc := range src.IFuncs {
+		syms = append(syms, ifunc)
+	}
+	for _, f := range src.Funcs {
+		syms = append(syms, f)
+	}
+	for _, sym := range syms {
+		if err := l.linkSymbol(sym); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	// Redirect references to dropped symbols.
+	if len(l.repl) > 0 {
+		dst.WalkConstants(func(c constant.Constant) constant.Constant {
+			if r, ok := l.repl[c]; ok {
+				return r
+			}
+			return c
+		})
+	}
+	// Attribute group definitions; renumbered after the attribute group IDs of
+	// the destination module.
+	offset := int64(0)
+	for _, def := range dst.AttrGroupDefs {
+		if def.ID >= offset {
+			offset = def.ID + 1
+		}
+	}
+	for _, def := range src.AttrGroupDefs {
+		def.ID += offset
+		dst.AttrGroupDefs = append(dst.AttrGroupDefs, def)
+	}
+	// Metadata definitions; renumbered after the metadata IDs of the
+	// destination module.
+	for _, md := range src.MetadataDefs {
+		md.SetID(-1)
+		dst.MetadataDefs = append(dst.MetadataDefs, md)
+	}
+	if err := dst.AssignMetadataIDs(); err != nil {
+		return errors.WithStack(err)
+	}
+	for name, md := range src.NamedMetadataDefs {
+		if prev, ok := dst.NamedMetadataDefs[name]; ok {
+			prev.Nodes = append(prev.Nodes, md.Nodes...)
+			continue
+		}
+		dst.NamedMetadataDefs[name] = md
+	}
+	// Module-level inline assembly and use-list orders.
+	dst.ModuleAsms = append(dst.ModuleAsms, src.ModuleAsms...)
+	dst.UseListOrders = append(dst.UseListOrders, src.UseListOrders...)
+	dst.UseListOrderBBs = append(dst.UseListOrderBBs, src.UseListOrderBBs...)
+	// Renumber unnamed global symbols.
+	dst.AssignGlobalIDs()
+	return nil
+}
+
+// linker links a source module into a destination module.
+type linker struct {
+	// Destination module.
+	dst *Module
+	// Data layout of the destination module.
+	dl *DataLayout
+	// syms maps from global name to symbol of the destination module.
+	syms map[string]constant.Constant
+	// Global names used by the destination or source module.
+	used map[string]bool
+	// repl maps from dropped symbol to replacement of references to the symbol.
+	repl map[constant.Constant]constant.Constant
+}
+
+// newLinker returns a new linker of the given source module into the given
+// destination module.
+func newLinker(dst, src *Module) *linker {
+	dl, err := ParseDataLayout(dst.DataLayout)
+	if err != nil {
+		// Fall back to the default data layout.
+		dl, _ = ParseDataLayout("")
+	}
+	l := &linker{
+		dst:  dst,
+		dl:   dl,
+		syms: make(map[string]constant.Constant),
+		used: make(map[string]bool),
+		repl: make(map[constant.Constant]constant.Constant),
+	}
+	for _, m := range []*Module{dst, src} {
+		for _, g := range m.Globals {
+			l.used[g.GlobalName] = true
+		}
+		for _, alias := range m.Aliases {
+			l.used[alias.GlobalName] = true
+		}
+		for _, ifunc := range m.IFuncs {
+			l.used[ifunc.GlobalName] = true
+		}
+		for _, f := range m.Funcs {
+			l.used[f.GlobalName] = true
+		}
+	}
+	for _, g := range dst.Globals {
+		l.addName(g)
+	}
+	for _, alias := range dst.Aliases {
+		l.addName(alias)
+	}
+	for _, ifunc := range dst.IFuncs {
+		l.addName(ifunc)
+	}
+	for _, f := range dst.Funcs {
+		l.addName(f)
+	}
+	return l
+}
+
+// linkTypeDefs links the type definitions of the given source module into the
+// destination module.
+func (l *linker) linkTypeDefs(src *Module) {
+	dstTypes := make(map[string]types.Type)
+	used := make(map[string]bool)
+	for _, t := range l.dst.TypeDefs {
+		dstTypes[t.Name()] = t
+		used[t.Name()] = true
+	}
+	for _, t := range src.TypeDefs {
+		used[t.Name()] = true
+	}
+	// Rename conflicting type definitions of the source module. As the bodies
+	// of type definitions refer to nested type definitions by name, renaming a
+	// type definition may cause type definitions referring to it to conflict;
+	// thus repeat until no more type definitions conflict.
+	renamed := make(map[types.Type]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, t := range src.TypeDefs {
+			if renamed[t] {
+				continue
+			}
+			prev, ok := dstTypes[t.Name()]
+			if !ok || isOpaqueType(prev) || isOpaqueType(t) || prev.LLString() == t.LLString() {
+				continue
+			}
+			t.SetName(uniqueTypeName(used, t.Name()))
+			renamed[t] = true
+			changed = true
+		}
+	}
+	for _, t := range src.TypeDefs {
+		prev, ok := dstTypes[t.Name()]
+		if !ok {
+			l.dst.TypeDefs = append(l.dst.TypeDefs, t)
+			continue
+		}
+		// Resolve opaque type definition of the destination module to the body
+		// of the source module.
+		if isOpaqueType(prev) && !isOpaqueType(t) {
+			p, s := prev.(*types.StructType), t.(*types.StructType)
+			p.Opaque, p.Packed, p.Fields = false, s.Packed, s.Fields
+		}
+	}
+}
+
+// linkSymbol links the given global symbol of the source module into the
+// destination module.
+func (l *linker) linkSymbol(sym constant.Constant) error {
+	ident := symbolIdent(sym)
+	if ident.IsUnnamed() {
+		l.add(sym)
+		return nil
+	}
+	name := ident.GlobalName
+	prev, ok := l.syms[name]
+	switch {
+	case !ok:
+		l.add(sym)
+		l.syms[name] = sym
+		return nil
+	case isLocalLinkage(symbolLinkage(sym)):
+		// Rename local symbol of source module.
+		ident.SetName(l.uniqueName(name))
+		l.add(sym)
+		l.syms[ident.GlobalName] = sym
+		return nil
+	case isLocalLinkage(symbolLinkage(prev)):
+		// Rename local symbol of destination module.
+		prevIdent := symbolIdent(prev)
+		prevIdent.SetName(l.uniqueName(name))
+		l.syms[prevIdent.GlobalName] = prev
+		l.add(sym)
+		l.syms[name] = sym
+		return nil
+	case symbolLinkage(prev) == enum.LinkageAppending && symbolLinkage(sym) == enum.LinkageAppending:
+		return l.appendGlobals(prev, sym)
+	}
+	fromSrc, err := l.linkFromSource(prev, sym)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if fromSrc {
+		l.replace(prev, sym)
+		l.syms[name] = sym
+		l.repl[prev] = castSymbol(sym, prev.Type())
+		return nil
+	}
+	l.repl[sym] = castSymbol(prev, sym.Type())
+	return nil
+}
+
+// linkFromSource reports whether the symbol of the source module takes
+// precedence over the symbol of the same name of the destination module.
+func (l *linker) linkFromSource(dst, src constant.Constant) (bool, error) {
+	srcLinkage, dstLinkage := symbolLinkage(src), symbolLinkage(dst)
+	switch {
+	case isDeclForLinker(src):
+		// Link available_externally definitions of the source module only if
+		// the destination module has a declaration.
+		return srcLinkage == enum.LinkageAvailableExternally && isDecl(dst), nil
+	case isDeclForLinker(dst):
+		return true, nil
+	case srcLinkage == enum.LinkageCommon:
+		switch dstLinkage {
+		case enum.LinkageLinkOnce, enum.LinkageLinkOnceODR, enum.LinkageWeak, enum.LinkageWeakODR:
+			return true, nil
+		case enum.LinkageCommon:
+			// Keep the larger of the common symbols.
+			return l.symbolSize(src) > l.symbolSize(dst), nil
+		}
+		return false, nil
+	case isWeakForLinker(srcLinkage):
+		if isLinkOnce(dstLinkage) && (srcLinkage == enum.LinkageWeak || srcLinkage == enum.LinkageWeakODR) {
+			return true, nil
+		}
+		return false, nil
+	case isWeakForLinker(dstLinkage):
+		return true, nil
+	}
+	return false, errors.Errorf("linking globals named %s: symbol multiply defined", symbolIdent(src).Ident())
+}
+
+// appendGlobals appends the array initializer of the given global variable of
+// the source module to the array initializer of the given global variable of
+// the destination module, both with appending linkage (e.g. @llvm.used).
+func (l *linker) appendGlobals(dst, src constant.Constant) error {
+	dstGlobal, ok1 := dst.(*Global)
+	srcGlobal, ok2 := src.(*Global)
+	if !ok1 || !ok2 {
+		return errors.Errorf("linking globals named %s: appending linkage of non-global variable", symbolIdent(src).Ident())
+	}
+	dstInit, ok1 := dstGlobal.Init.(*constant.Array)
+	srcInit, ok2 := srcGlobal.Init.(*constant.Array)
+	if !ok1 || !ok2 || !dstInit.Typ.ElemType.Equal(srcInit.Typ.ElemType) {
+		return errors.Errorf("linking globals named %s: appending linkage of incompatible initializers", symbolIdent(src).Ident())
+	}
+	elems := append(dstInit.Elems, srcInit.Elems...)
+	init := constant.NewArray(types.NewArray(uint64(len(elems)), dstInit.Typ.ElemType), elems...)
+	dstGlobal.Init = init
+	dstGlobal.ContentType = init.Typ
+	dstGlobal.Typ = nil
+	dstGlobal.Type()
+	l.repl[src] = castSymbol(dst, src.Type())
+	return nil
+}
+
+// add adds the given symbol of the source module to the destination module.
+func (l *linker) add(sym constant.Constant) {
+	switch sym := sym.(type) {
+	case *Global:
+		l.dst.Globals = append(l.dst.Globals, sym)
+	case *Alias:
+		l.dst.Aliases = append(l.dst.Aliases, sym)
+	case *IFunc:
+		l.dst.IFuncs = append(l.dst.IFuncs, sym)
+	case *Func:
+		l.dst.Funcs = append(l.dst.Funcs, sym)
+	default:
+		panic(fmt.Errorf("support for global symbol %T not yet implemented", sym))
+	}
+}
+
+// replace replaces the given symbol of the destination module with the given
+// symbol of the source module. The symbol of the source module takes the
+// position of the replaced symbol if both are of the same kind.
+func (l *linker) replace(old, new constant.Constant) {
+	dst := l.dst
+	switch old := old.(type) {
+	case *Global:
+		for i, g := range dst.Globals {
+			if g != old {
+				continue
+			}
+			if new, ok := new.(*Global); ok {
+				dst.Globals[i] = new
+				return
+			}
+			dst.Globals = append(dst.Globals[:i], dst.Globals[i+1:]...)
+			break
+		}
+	case *Alias:
+		for i, alias := range dst.Aliases {
+			if alias != old {
+				continue
+			}
+			if new, ok := new.(*Alias); ok {
+				dst.Aliases[i] = new
+				return
+			}
+			dst.Aliases = append(dst.Aliases[:i], dst.Aliases[i+1:]...)
+			break
+		}
+	case *IFunc:
+		for i, ifunc := range dst.IFuncs {
+			if ifunc != old {
+				continue
+			}
+			if new, ok := new.(*IFunc); ok {
+				dst.IFuncs[i] = new
+				return
+			}
+			dst.IFuncs = append(dst.IFuncs[:i], dst.IFuncs[i+1:]...)
+			break
+		}
+	case *Func:
+		for i, f := range dst.Funcs {
+			if f != old {
+				continue
+			}
+			if new, ok := new.(*Func); ok {
+				dst.Funcs[i] = new
+				return
+			}
+			dst.Funcs = append(dst.Funcs[:i], dst.Funcs[i+1:]...)
+			break
+		}
+	default:
+		panic(fmt.Errorf("support for global symbol %T not yet implemented", old))
+	}
+	l.add(new)
+}
+
+// addName indexes the given named symbol of the destination module.
+func (l *linker) addName(sym constant.Constant) {
+	if ident := symbolIdent(sym); !ident.IsUnnamed() {
+		l.syms[ident.GlobalName] = sym
+	}
+}
+
+// uniqueName returns a unique global name based on the given name (e.g.
+// "foo.1").
+func (l *linker) uniqueName(name string) string {
+	for i := 1; ; i++ {
+		newName := fmt.Sprintf("%s.%d", name, i)
+		if !l.used[newName] {
+			l.used[newName] = true
+			return newName
+		}
+	}
+}
+
+// symbolSize returns the size in bytes of the content of the given symbol.
+func (l *linker) symbolSize(sym constant.Constant) uint64 {
+	if g, ok := sym.(*Global); ok {
+		return l.dl.TypeAllocSize(g.ContentType)
+	}
+	return 0
+}
+
+// ### [ Helper functions ] ####################################################
+
+// symbolIdent returns the global identifier of the given global symbol.
+func symbolIdent(sym constant.Constant) *GlobalIdent {
+	switch sym := sym.(type) {
+	case *Global:
+		return &sym.GlobalIdent
+	case *Alias:
+		return &sym.GlobalIdent
+	case *IFunc:
+		return &sym.GlobalIdent
+	case *Func:
+		return &sym.GlobalIdent
+	default:
+		panic(fmt.Errorf("support for global symbol %T not yet implemented", sym))
+	}
+}
+
+// symbolLinkage returns the linkage of the given global symbol.
+func symbolLinkage(sym constant.Constant) enum.Linkage {
+	switch sym := sym.(type) {
+	case *Global:
+		return sym.Linkage
+	case *Alias:
+		return sym.Linkage
+	case *IFunc:
+		return sym.Linkage
+	case *Func:
+		return sym.Linkage
+	default:
+		panic(fmt.Errorf("support for global symbol %T not yet implemented", sym))
+	}
+}
+
+// isDecl reports whether the given global symbol is a declaration.
+func isDecl(sym constant.Constant) bool {
+	switch sym := sym.(type) {
+	case *Global:
+		return sym.Init == nil
+	case *Func:
+		return len(sym.Blocks) == 0
+	}
+	// Aliases and IFuncs are definitions.
+	return false
+}
+
+// isDeclForLinker reports whether the given global symbol is a declaration from
+// the perspective of the linker; i.e. a declaration, an available_externally
+// definition or an extern_weak declaration.
+func isDeclForLinker(sym constant.Constant) bool {
+	switch symbolLinkage(sym) {
+	case enum.LinkageAvailableExternally, enum.LinkageExternWeak:
+		return true
+	}
+	return isDecl(sym)
+}
+
+// isLocalLinkage reports whether the given linkage is local to its module
+// (private or internal).
+func isLocalLinkage(linkage enum.Linkage) bool {
+	return linkage == enum.LinkagePrivate || linkage == enum.LinkageInternal
+}
+
+// isLinkOnce reports whether the given linkage is linkonce or linkonce_odr.
+func isLinkOnce(linkage enum.Linkage) bool {
+	return linkage == enum.LinkageLinkOnce || linkage == enum.LinkageLinkOnceODR
+}
+
+// isWeakForLinker reports whether symbols of the given linkage may be replaced
+// by other definitions of the same symbol at link time.
+func isWeakForLinker(linkage enum.Linkage) bool {
+	switch linkage {
+	case enum.LinkageWeak, enum.LinkageWeakODR, enum.LinkageLinkOnce, enum.LinkageLinkOnceODR, enum.LinkageCommon, enum.LinkageExternWeak:
+		return true
+	}
+	return false
+}
+
+// isOpaqueType reports whether the given type is an opaque struct type.
+func isOpaqueType(t types.Type) bool {
+	s, ok := t.(*types.StructType)
+	return ok && s.Opaque
+}
+
+// uniqueTypeName returns a type name based on the given name (e.g. "T.1") not
+// present in used, and marks it as used.
+func uniqueTypeName(used map[string]bool, name string) string {
+	for i := 1; ; i++ {
+		newName := fmt.Sprintf("%s.%d", name, i)
+		if !used[newName] {
+			used[newName] = true
+			return newName
+		}
+	}
+}
+
+// castSymbol returns the given symbol, bitcast to the given type if of a
+// different type.
+func castSymbol(sym constant.Constant, typ types.Type) constant.Constant {
+	if sym.Type().Equal(typ) {
+		return sym
+	}
+	return constant.NewBitCast(sym, typ)
+}
@@ -0,0 +1,234 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestLink(t *testing.T) {
+	golden := []struct {
+		dst, src string
+		want     string
+	}{
+		// Weak definitions in both modules; the destination definition wins.
+		{
+			dst: `
+define weak i32 @f() {
+	ret i32 1
+}
+
+define i32 @main() {
+	%1 = call i32 @f()
+	ret i32 %1
+}
+`,
+			src: `
+define weak i32 @f() {
+	ret i32 2
+}
+
+define i32 @g() {
+	%1 = call i32 @f()
+	ret i32 %1
+}
+`,
+			want: `
+define weak i32 @f() {
+; <label>:0
+	ret i32 1
+}
+
+define i32 @main() {
+; <label>:0
+	%1 = call i32 @f()
+	ret i32 %1
+}
+
+define i32 @g() {
+; <label>:0
+	%1 = call i32 @f()
+	ret i32 %1
+}
+`,
+		},
+		// Strong definition takes precedence over weak definition.
+		{
+			dst: `
+define linkonce_odr i32 @f() {
+	ret i32 1
+}
+
+define i32 @main() {
+	%1 = call i32 @f()
+	ret i32 %1
+}
+`,
+			src: `
+define i32 @f() {
+	ret i32 2
+}
+`,
+			want: `
+define i32 @f() {
+; <label>:0
+	ret i32 2
+}
+
+define i32 @main() {
+; <label>:0
+	%1 = call i32 @f()
+	ret i32 %1
+}
+`,
+		},
+		// Larger common definition wins, and declarations resolve to
+		// definitions.
+		{
+			dst: `
+@x = common global i32 0
+@y = external global i32
+
+define i32* @main() {
+	ret i32* @x
+}
+`,
+			src: `
+@x = common global i64 0
+@y = global i32 42
+@z = internal global i32 1
+
+define i32* @g() {
+	ret i32* @y
+}
+`,
+			want: `
+@x = common global i64 0
+@y = global i32 42
+@z = internal global i32 1
+
+define i32* @main() {
+; <label>:0
+	ret i32* bitcast (i64* @x to i32*)
+}
+
+define i32* @g() {
+; <label>:0
+	ret i32* @y
+}
+`,
+		},
+		// Local symbols are renamed on collision.
+		{
+			dst: `
+define internal void @f() {
+	ret void
+}
+`,
+			src: `
+define internal void @f() {
+	ret void
+}
+
+define void @g() {
+	call void @f()
+	ret void
+}
+`,
+			want: `
+define internal void @f() {
+; <label>:0
+	ret void
+}
+
+define internal void @f.1() {
+; <label>:0
+	ret void
+}
+
+define void @g() {
+; <label>:0
+	call void @f.1()
+	ret void
+}
+`,
+		},
+		// Type definitions with isomorphic bodies are merged, and conflicting
+		// type definitions of the source module are renamed; including type
+		// definitions referring to renamed type definitions.
+		{
+			dst: `
+%S = type { i8 }
+%T = type { i32 }
+%U = type opaque
+%W = type { %T* }
+
+@a = global %T zeroinitializer
+@w = global %W* null
+`,
+			src: `
+%S = type { i8 }
+%T = type { i64 }
+%U = type { i16 }
+%V = type { %S, %T* }
+%W = type { %T* }
+
+@b = global %T zeroinitializer
+@s = global %S zeroinitializer
+@v = global %V zeroinitializer
+@x = global %W* null
+`,
+			want: `
+%S = type { i8 }
+%T = type { i32 }
+%U = type { i16 }
+%W = type { %T* }
+%T.1 = type { i64 }
+%V = type { %S, %T.1* }
+%W.1 = type { %T.1* }
+
+@a = global %T zeroinitializer
+@w = global %W* null
+@b = global %T.1 zeroinitializer
+@s = global %S zeroinitializer
+@v = global %V zeroinitializer
+@x = global %W.1* null
+`,
+		},
+	}
+	for i, g := range golden {
+		dst, err := asm.ParseString("<dst>", g.dst)
+		if err != nil {
+			t.Errorf("test %d: unable to parse destination module; %+v", i, err)
+			continue
+		}
+		src, err := asm.ParseString("<src>", g.src)
+		if err != nil {
+			t.Errorf("test %d: unable to parse source module; %+v", i, err)
+			continue
+		}
+		if err := ir.Link(dst, src); err != nil {
+			t.Errorf("test %d: unable to link modules; %+v", i, err)
+			continue
+		}
+		if got := dst.String(); got != g.want[1:] {
+			t.Errorf("test %d: module mismatch; expected `%s`, got `%s`", i, g.want[1:], got)
+		}
+	}
+}
+
+func TestLinkMultiplyDefined(t *testing.T) {
+	dst, err := asm.ParseString("<dst>", "define void @f() {\n\tret void\n}\n")
+	if err != nil {
+		t.Fatalf("unable to parse destination module; %+v", err)
+	}
+	src, err := asm.ParseString("<src>", "define void @f() {\n\tret void\n}\n")
+	if err != nil {
+		t.Fatalf("unable to parse source module; %+v", err)
+	}
+	const want = "linking globals named @f: symbol multiply defined"
+	if err := ir.Link(dst, src); err == nil || err.Error() != want {
+		t.Errorf("error mismatch; expected %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,119 @@
+package ir
+
+import "sort"
+
+// === [ Natural loops ] =======================================================
+
+// Loop is a natural loop of a function; a strongly connected set of basic
+// blocks with a single entry basic block (the loop header) which dominates all
+// basic blocks of the loop.
+type Loop struct {
+	// Loop header; the single entry basic block of the loop.
+	Header *Block
+	// Basic blocks of the loop, in the order of the basic blocks of the
+	// function.
+	Blocks []*Block
+	// Latch basic blocks of the loop; the sources of back edges to the loop
+	// header, in the order of the basic blocks of the function.
+	Latches []*Block
+	// (optional) Innermost loop containing the loop; nil if outermost.
+	Parent *Loop
+
+	// extra.
+
+	// Basic blocks of the loop.
+	contains map[*Block]bool
+}
+
+// Loops returns the natural loops of the function, in reverse post-order of
+// their loop headers; thus, outer loops precede the loops nested within them.
+// Back edges to the same loop header form a single loop.
+func (f *Func) Loops() []*Loop {
+	dt := f.DomTree()
+	preds := f.Predecessors()
+	index := make(map[*Block]int)
+	for i, block := range f.Blocks {
+		index[block] = i
+	}
+	var loops []*Loop
+	for _, header := range f.ReversePostOrder() {
+		var latches []*Block
+		for _, pred := range preds[header] {
+			if dt.Dominates(header, pred) && !containsBlock(latches, pred) {
+				latches = append(latches, pred)
+			}
+		}
+		if len(latches) == 0 {
+			continue
+		}
+		loop := &Loop{
+			Header:   header,
+			Latches:  latches,
+			contains: map[*Block]bool{header: true},
+		}
+		// Collect basic blocks which reach a latch without passing through the
+		// loop header.
+		stack := append([]*Block(nil), latches...)
+		for len(stack) > 0 {
+			block := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if loop.contains[block] || !dt.Reachable(block) {
+				continue
+			}
+			loop.contains[block] = true
+			stack = append(stack, preds[block]...)
+		}
+		for _, block := range f.Blocks {
+			if loop.contains[block] {
+				loop.Blocks = append(loop.Blocks, block)
+			}
+		}
+		sort.Slice(loop.Latches, func(i, j int) bool {
+			return index[loop.Latches[i]] < index[loop.Latches[j]]
+		})
+		// The innermost enclosing loop is the smallest preceding loop containing
+		// the loop header.
+		for _, outer := range loops {
+			if !outer.Contains(header) {
+				continue
+			}
+			if loop.Parent == nil || len(outer.Blocks) < len(loop.Parent.Blocks) {
+				loop.Parent = outer
+			}
+		}
+		loops = append(loops, loop)
+	}
+	return loops
+}
+
+// Contains reports whether the given basic block is part of the loop.
+func (l *Loop) Contains(block *Block) bool {
+	return l.contains[block]
+}
+
+// Exits returns the exit basic blocks of the loop; the basic blocks outside of
+// the loop which are successors of basic blocks of the loop, in order of first
+// occurrence.
+func (l *Loop) Exits() []*Block {
+	var exits []*Block
+	for _, block := range l.Blocks {
+		for _, succ := range succs(block) {
+			if !l.Contains(succ) && !containsBlock(exits, succ) {
+				exits = append(exits, succ)
+			}
+		}
+	}
+	return exits
+}
+
+// ### [ Helper functions ] ####################################################
+
+// containsBlock reports whether the given basic blocks contain the basic block.
+func containsBlock(blocks []*Block, block *Block) bool {
+	for _, b := range blocks {
+		if b == block {
+			return true
+		}
+	}
+	return false
+}
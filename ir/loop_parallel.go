@@ -0,0 +1,162 @@
+package ir
+
+import (
+	"github.com/llir/llvm/ir/metadata"
+)
+
+// === [ Loop metadata ] =======================================================
+
+// LoopID returns the loop metadata of the loop (e.g. !0 of `br label %loop,
+// !llvm.loop !0`), as specified by the !llvm.loop metadata attachment of the
+// terminators of its latch basic blocks; or nil if not present or if the latch
+// basic blocks specify different loop metadata.
+func (l *Loop) LoopID() *metadata.Tuple {
+	var id *metadata.Tuple
+	for _, latch := range l.Latches {
+		md, ok := latch.Term.(interface {
+			MDAttachments() []*metadata.Attachment
+		})
+		if !ok {
+			return nil
+		}
+		var latchID *metadata.Tuple
+		for _, attachment := range md.MDAttachments() {
+			if attachment.Name == "llvm.loop" {
+				latchID, _ = attachment.Node.(*metadata.Tuple)
+				break
+			}
+		}
+		if latchID == nil || (id != nil && latchID != id) {
+			return nil
+		}
+		id = latchID
+	}
+	return id
+}
+
+// LoopProperty returns the first loop property of the given name (e.g.
+// `!{!"llvm.loop.unroll.count", i32 4}` of "llvm.loop.unroll.count") of the
+// loop metadata of the loop; or nil if not present.
+func (l *Loop) LoopProperty(name string) *metadata.Tuple {
+	props := loopProperties(l.LoopID(), name)
+	if len(props) == 0 {
+		return nil
+	}
+	return props[0]
+}
+
+// ParallelAccessGroups returns the access groups of the loop, as specified by
+// the llvm.loop.parallel_accesses properties of its loop metadata (e.g. !1 of
+// `!{!"llvm.loop.parallel_accesses", !1}`); or nil if not present. Memory
+// accesses of the access groups carry no loop-carried dependencies within the
+// loop.
+func (l *Loop) ParallelAccessGroups() []*metadata.Tuple {
+	var groups []*metadata.Tuple
+	for _, prop := range loopProperties(l.LoopID(), "llvm.loop.parallel_accesses") {
+		for _, field := range prop.Fields[1:] {
+			if group, ok := field.(*metadata.Tuple); ok {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups
+}
+
+// IsParallel reports whether the loop is annotated as parallel; i.e. whether
+// every instruction of the loop which may access memory belongs to an access
+// group of the parallel accesses of the loop (see ParallelAccessGroups and
+// AccessGroups).
+func (l *Loop) IsParallel() bool {
+	groups := make(map[*metadata.Tuple]bool)
+	for _, group := range l.ParallelAccessGroups() {
+		groups[group] = true
+	}
+	if len(groups) == 0 {
+		return false
+	}
+	for _, block := range l.Blocks {
+		for _, inst := range block.Insts {
+			if !mayAccessMemory(inst) {
+				continue
+			}
+			parallel := false
+			for _, group := range AccessGroups(inst) {
+				if groups[group] {
+					parallel = true
+					break
+				}
+			}
+			if !parallel {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AccessGroups returns the access groups of the given instruction, as specified
+// by its !llvm.access.group metadata attachment; or nil if not present. The
+// metadata attachment is either a single access group (a distinct metadata
+// tuple without fields, e.g. `distinct !{}`) or a list of access groups.
+func AccessGroups(inst Instruction) []*metadata.Tuple {
+	md, ok := inst.(interface {
+		MDAttachments() []*metadata.Attachment
+	})
+	if !ok {
+		return nil
+	}
+	for _, attachment := range md.MDAttachments() {
+		if attachment.Name != "llvm.access.group" {
+			continue
+		}
+		node, ok := attachment.Node.(*metadata.Tuple)
+		if !ok {
+			return nil
+		}
+		if len(node.Fields) == 0 {
+			// Single access group.
+			return []*metadata.Tuple{node}
+		}
+		var groups []*metadata.Tuple
+		for _, field := range node.Fields {
+			if group, ok := field.(*metadata.Tuple); ok {
+				groups = append(groups, group)
+			}
+		}
+		return groups
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// loopProperties returns the loop properties of the given name of the given
+// loop metadata, or nil if not present. Loop properties are metadata tuples
+// with a metadata string of the property name as first field.
+func loopProperties(id *metadata.Tuple, name string) []*metadata.Tuple {
+	if id == nil {
+		return nil
+	}
+	var props []*metadata.Tuple
+	for _, field := range id.Fields {
+		prop, ok := field.(*metadata.Tuple)
+		if !ok || prop == id || len(prop.Fields) == 0 {
+			// Skip self-reference of loop metadata.
+			continue
+		}
+		if s, ok := prop.Fields[0].(*metadata.String); ok && s.Value == name {
+			props = append(props, prop)
+		}
+	}
+	return props
+}
+
+// mayAccessMemory reports whether the given instruction may read or write
+// memory.
+func mayAccessMemory(inst Instruction) bool {
+	switch inst.(type) {
+	case *InstLoad, *InstStore, *InstFence, *InstCmpXchg, *InstAtomicRMW, *InstCall, *InstVAArg:
+		return true
+	}
+	return false
+}
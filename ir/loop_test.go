@@ -0,0 +1,179 @@
+package ir_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestFuncLoops(t *testing.T) {
+	const src = `
+define void @f(i1 %c) {
+entry:
+	br label %outer
+
+outer:
+	br label %inner
+
+inner:
+	br i1 %c, label %inner, label %inner.latch
+
+inner.latch:
+	br i1 %c, label %inner, label %outer.latch
+
+outer.latch:
+	br i1 %c, label %outer, label %exit
+
+exit:
+	ret void
+}`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	loops := m.Funcs[0].Loops()
+	golden := []struct {
+		header  string
+		blocks  []string
+		latches []string
+		exits   []string
+		parent  string
+	}{
+		{
+			header:  "%outer",
+			blocks:  []string{"%outer", "%inner", "%inner.latch", "%outer.latch"},
+			latches: []string{"%outer.latch"},
+			exits:   []string{"%exit"},
+		},
+		{
+			header:  "%inner",
+			blocks:  []string{"%inner", "%inner.latch"},
+			latches: []string{"%inner", "%inner.latch"},
+			exits:   []string{"%outer.latch"},
+			parent:  "%outer",
+		},
+	}
+	if len(loops) != len(golden) {
+		t.Fatalf("loop count mismatch; expected %d, got %d", len(golden), len(loops))
+	}
+	for i, g := range golden {
+		loop := loops[i]
+		if got := loop.Header.Ident(); got != g.header {
+			t.Errorf("loop %d header mismatch; expected %q, got %q", i, g.header, got)
+		}
+		if got := idents(loop.Blocks); !reflect.DeepEqual(got, g.blocks) {
+			t.Errorf("loop %s blocks mismatch; expected %v, got %v", g.header, g.blocks, got)
+		}
+		if got := idents(loop.Latches); !reflect.DeepEqual(got, g.latches) {
+			t.Errorf("loop %s latches mismatch; expected %v, got %v", g.header, g.latches, got)
+		}
+		if got := idents(loop.Exits()); !reflect.DeepEqual(got, g.exits) {
+			t.Errorf("loop %s exits mismatch; expected %v, got %v", g.header, g.exits, got)
+		}
+		parent := ""
+		if loop.Parent != nil {
+			parent = loop.Parent.Header.Ident()
+		}
+		if parent != g.parent {
+			t.Errorf("loop %s parent mismatch; expected %q, got %q", g.header, g.parent, parent)
+		}
+	}
+}
+
+func TestLoopIsParallel(t *testing.T) {
+	const src = `
+define void @f(i32* %a, i32* %b, i64 %n) {
+entry:
+	br label %loop
+
+loop:
+	%i = phi i64 [ 0, %entry ], [ %next, %loop ]
+	%src = getelementptr inbounds i32, i32* %b, i64 %i
+	%x = load i32, i32* %src, !llvm.access.group !1
+	%dst = getelementptr inbounds i32, i32* %a, i64 %i
+	store i32 %x, i32* %dst, !llvm.access.group !4
+	%next = add i64 %i, 1
+	%cond = icmp ult i64 %next, %n
+	br i1 %cond, label %loop, label %exit, !llvm.loop !0
+
+exit:
+	ret void
+}
+
+define void @g(i32* %a, i64 %n) {
+entry:
+	br label %loop
+
+loop:
+	%i = phi i64 [ 0, %entry ], [ %next, %loop ]
+	%dst = getelementptr inbounds i32, i32* %a, i64 %i
+	store i32 0, i32* %dst
+	%next = add i64 %i, 1
+	%cond = icmp ult i64 %next, %n
+	br i1 %cond, label %loop, label %exit, !llvm.loop !0
+
+exit:
+	ret void
+}
+
+!0 = distinct !{!0, !2, !3}
+!1 = distinct !{}
+!2 = !{!"llvm.loop.parallel_accesses", !1, !5}
+!3 = !{!"llvm.loop.vectorize.enable", i1 true}
+!4 = !{!1, !5}
+!5 = distinct !{}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	golden := []struct {
+		parallel bool
+		groups   int
+	}{
+		// All memory accesses in parallel access groups.
+		{parallel: true, groups: 2},
+		// Memory access without access group.
+		{parallel: false, groups: 2},
+	}
+	for i, g := range golden {
+		f := m.Funcs[i]
+		loops := f.Loops()
+		if len(loops) != 1 {
+			t.Errorf("loop count mismatch of function %s; expected 1, got %d", f.Ident(), len(loops))
+			continue
+		}
+		loop := loops[0]
+		if loop.LoopID() == nil {
+			t.Errorf("missing loop metadata of function %s", f.Ident())
+		}
+		if loop.LoopProperty("llvm.loop.vectorize.enable") == nil {
+			t.Errorf("missing llvm.loop.vectorize.enable property of function %s", f.Ident())
+		}
+		if got := len(loop.ParallelAccessGroups()); got != g.groups {
+			t.Errorf("access group count mismatch of function %s; expected %d, got %d", f.Ident(), g.groups, got)
+		}
+		if got := loop.IsParallel(); got != g.parallel {
+			t.Errorf("parallel mismatch of function %s; expected %v, got %v", f.Ident(), g.parallel, got)
+		}
+	}
+	// Single access group and list of access groups.
+	insts := m.Funcs[0].Blocks[1].Insts
+	if got := len(ir.AccessGroups(insts[2])); got != 1 {
+		t.Errorf("access group count mismatch of load; expected 1, got %d", got)
+	}
+	if got := len(ir.AccessGroups(insts[4])); got != 2 {
+		t.Errorf("access group count mismatch of store; expected 2, got %d", got)
+	}
+}
+
+// idents returns the identifiers of the given basic blocks.
+func idents(blocks []*ir.Block) []string {
+	var ss []string
+	for _, block := range blocks {
+		ss = append(ss, block.Ident())
+	}
+	return ss
+}
@@ -56,3 +56,49 @@ func dwarfTagString(tag enum.DwarfTag) string {
 func quote(s string) string {
 	return enc.Quote([]byte(s))
 }
+
+// dwarfOpArity maps from DWARF operators to the number of operands they take
+// in DIExpression metadata nodes. DWARF operators not present in the map are of
+// unknown arity.
+var dwarfOpArity = map[enum.DwarfOp]int{
+	// Operators without operands.
+	enum.DwarfOpDeref:      0,
+	enum.DwarfOpDup:        0,
+	enum.DwarfOpDrop:       0,
+	enum.DwarfOpOver:       0,
+	enum.DwarfOpSwap:       0,
+	enum.DwarfOpRot:        0,
+	enum.DwarfOpAbs:        0,
+	enum.DwarfOpAnd:        0,
+	enum.DwarfOpDiv:        0,
+	enum.DwarfOpMinus:      0,
+	enum.DwarfOpMod:        0,
+	enum.DwarfOpMul:        0,
+	enum.DwarfOpNeg:        0,
+	enum.DwarfOpNot:        0,
+	enum.DwarfOpOr:         0,
+	enum.DwarfOpPlus:       0,
+	enum.DwarfOpShl:        0,
+	enum.DwarfOpShr:        0,
+	enum.DwarfOpShra:       0,
+	enum.DwarfOpXor:        0,
+	enum.DwarfOpEq:         0,
+	enum.DwarfOpGe:         0,
+	enum.DwarfOpGt:         0,
+	enum.DwarfOpLe:         0,
+	enum.DwarfOpLt:         0,
+	enum.DwarfOpNe:         0,
+	enum.DwarfOpStackValue: 0,
+	// Operators with one operand.
+	enum.DwarfOpConstu:     1,
+	enum.DwarfOpConsts:     1,
+	enum.DwarfOpPick:       1,
+	enum.DwarfOpPlusUconst: 1,
+	enum.DwarfOpDerefSize:  1,
+	enum.DwarfOpPiece:      1,
+	enum.DwarfOpEntryValue: 1,
+	// Operators with two operands.
+	enum.DwarfOpBregx:        2,
+	enum.DwarfOpBitPiece:     2,
+	enum.DwarfOpLLVMFragment: 2,
+}
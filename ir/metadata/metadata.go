@@ -8,6 +8,7 @@ import (
 
 	"github.com/llir/llvm/internal/enc"
 	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
 )
 
 // TODO: remove Null if possible.
@@ -127,6 +128,11 @@ func (md *Value) Ident() string {
 	return md.Value.String()
 }
 
+// ValueKind returns the kind of the value.
+func (*Value) ValueKind() value.Kind {
+	return value.KindMetadata
+}
+
 // --- [ Metadata string ] -----------------------------------------------------
 
 // String is a metadata string.
@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"testing"
+
 	"github.com/llir/llvm/ir/enum"
 	"github.com/llir/llvm/ir/value"
 )
@@ -27,6 +29,7 @@ var (
 // Assert that each specialized metadata node implements the
 // metadata.SpecializedNode interface.
 var (
+	_ SpecializedNode = (*DIAssignID)(nil)
 	_ SpecializedNode = (*DIBasicType)(nil)
 	_ SpecializedNode = (*DICompileUnit)(nil)
 	_ SpecializedNode = (*DICompositeType)(nil)
@@ -77,3 +80,48 @@ var (
 	_ Metadata = (Definition)(nil)
 	_ Metadata = (SpecializedNode)(nil)
 )
+
+func TestNewDIExpression(t *testing.T) {
+	golden := []struct {
+		fields []DIExpressionField
+		want   string
+		err    string
+	}{
+		{
+			fields: []DIExpressionField{enum.DwarfOpPlusUconst, UintLit(8), enum.DwarfOpDeref},
+			want:   "!DIExpression(DW_OP_plus_uconst, 8, DW_OP_deref)",
+		},
+		{
+			fields: []DIExpressionField{enum.DwarfOpLLVMFragment, UintLit(0), UintLit(32)},
+			want:   "!DIExpression(DW_OP_LLVM_fragment, 0, 32)",
+		},
+		{
+			fields: nil,
+			want:   "!DIExpression()",
+		},
+		{
+			fields: []DIExpressionField{enum.DwarfOpPlusUconst},
+			err:    "invalid number of operands for DWARF operator DW_OP_plus_uconst; expected 1, got 0",
+		},
+		{
+			fields: []DIExpressionField{enum.DwarfOpDeref, UintLit(8)},
+			err:    "invalid number of operands for DWARF operator DW_OP_deref; expected 0, got more",
+		},
+	}
+	for _, g := range golden {
+		expr, err := NewDIExpression(g.fields...)
+		if len(g.err) > 0 {
+			if err == nil || err.Error() != g.err {
+				t.Errorf("error mismatch; expected %q, got %v", g.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error; %v", err)
+			continue
+		}
+		if got := expr.String(); got != g.want {
+			t.Errorf("DIExpression mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+}
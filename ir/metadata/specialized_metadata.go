@@ -5,8 +5,61 @@ import (
 	"strings"
 
 	"github.com/llir/llvm/ir/enum"
+	"github.com/pkg/errors"
 )
 
+// ~~~ [ DIAssignID ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+
+// DIAssignID is a specialized metadata node, which identifies an assignment of
+// assignment tracking debug info; linking a store or alloca instruction (by
+// !DIAssignID metadata attachment) to the #dbg_assign debug records describing
+// the assignment. DIAssignID nodes are always distinct, and have no fields.
+type DIAssignID struct {
+	// Metadata ID associated with the specialized metadata node; -1 if not
+	// present.
+	MetadataID
+	// (optional) Distinct.
+	Distinct bool
+}
+
+// NewDIAssignID returns a new distinct DIAssignID specialized metadata node.
+func NewDIAssignID() *DIAssignID {
+	return &DIAssignID{MetadataID: -1, Distinct: true}
+}
+
+// String returns the LLVM syntax representation of the specialized metadata node.
+func (md *DIAssignID) String() string {
+	return md.Ident()
+}
+
+// Ident returns the identifier associated with the specialized metadata node.
+func (md *DIAssignID) Ident() string {
+	if md == nil {
+		return "null"
+	}
+	if md.MetadataID != -1 {
+		return md.MetadataID.Ident()
+	}
+	return md.LLString()
+}
+
+// LLString returns the LLVM syntax representation of the specialized metadata
+// node.
+func (md *DIAssignID) LLString() string {
+	// 'distinct' '!DIAssignID' '(' ')'
+	buf := &strings.Builder{}
+	if md.Distinct {
+		buf.WriteString("distinct ")
+	}
+	buf.WriteString("!DIAssignID()")
+	return buf.String()
+}
+
+// SetDistinct specifies whether the metadata definition is dinstict.
+func (md *DIAssignID) SetDistinct(distinct bool) {
+	md.Distinct = distinct
+}
+
 // ~~~ [ DIBasicType ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
 
 // DIBasicType is a specialized metadata node.
@@ -516,6 +569,45 @@ type DIExpression struct {
 	Fields []DIExpressionField
 }
 
+// NewDIExpression returns a new DIExpression specialized metadata node based on
+// the given DWARF expression fields; each DWARF operator is followed by its
+// operands.
+//
+// An error is returned if a DWARF operator of known arity is followed by an
+// invalid number of operands.
+func NewDIExpression(fields ...DIExpressionField) (*DIExpression, error) {
+	for i := 0; i < len(fields); {
+		op, ok := fields[i].(enum.DwarfOp)
+		if !ok {
+			// Operands without preceding DWARF operator (e.g. in DIExpression(42))
+			// are accepted as is.
+			i++
+			continue
+		}
+		i++
+		n, ok := dwarfOpArity[op]
+		if !ok {
+			// Unknown arity; accept any operands following the operator.
+			continue
+		}
+		for j := 0; j < n; j++ {
+			if i >= len(fields) {
+				return nil, errors.Errorf("invalid number of operands for DWARF operator %v; expected %d, got %d", op, n, j)
+			}
+			if _, ok := fields[i].(UintLit); !ok {
+				return nil, errors.Errorf("invalid operand type of DWARF operator %v; expected metadata.UintLit, got %T", op, fields[i])
+			}
+			i++
+		}
+		if i < len(fields) {
+			if _, ok := fields[i].(UintLit); ok {
+				return nil, errors.Errorf("invalid number of operands for DWARF operator %v; expected %d, got more", op, n)
+			}
+		}
+	}
+	return &DIExpression{MetadataID: -1, Fields: fields}, nil
+}
+
 // String returns the LLVM syntax representation of the specialized metadata node.
 func (md *DIExpression) String() string {
 	return md.Ident()
@@ -66,6 +66,7 @@ type Field interface {
 //
 // A SpecializedNode has one of the following underlying types.
 //
+//    *metadata.DIAssignID                   // https://godoc.org/github.com/llir/llvm/ir/metadata#DIAssignID
 //    *metadata.DIBasicType                  // https://godoc.org/github.com/llir/llvm/ir/metadata#DIBasicType
 //    *metadata.DICompileUnit                // https://godoc.org/github.com/llir/llvm/ir/metadata#DICompileUnit
 //    *metadata.DICompositeType              // https://godoc.org/github.com/llir/llvm/ir/metadata#DICompositeType
@@ -1,7 +1,9 @@
 package ir
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/llir/llvm/internal/enc"
@@ -51,6 +53,8 @@ type Module struct {
 	UseListOrders []*UseListOrder
 	// (optional) Basic block specific use-list order directives.
 	UseListOrderBBs []*UseListOrderBB
+	// (optional) Module summary index entries (e.g. as used by ThinLTO).
+	SummaryEntries []*SummaryEntry
 }
 
 // NewModule returns a new LLVM IR module.
@@ -64,6 +68,22 @@ func NewModule() *Module {
 // syntax.
 func (m *Module) String() string {
 	buf := &strings.Builder{}
+	m.write(buf, &writeConfig{})
+	return buf.String()
+}
+
+// write writes the LLVM IR assembly of the module to w, one top-level entity at
+// a time, based on the given write configuration. The first error of w is
+// returned.
+func (m *Module) write(w io.Writer, cfg *writeConfig) error {
+	buf := &countWriter{w: w}
+	// Assign type names of hoisted literal struct types.
+	if cfg.hoist != nil {
+		cfg.hoist.nameTypes()
+		defer cfg.hoist.unnameTypes()
+	}
+	// Assign global IDs.
+	m.AssignGlobalIDs()
 	// Assign metadata IDs.
 	if err := m.AssignMetadataIDs(); err != nil {
 		panic(fmt.Errorf("unable to assign metadata IDs of module; %v", err))
@@ -84,64 +104,70 @@ func (m *Module) String() string {
 		fmt.Fprintf(buf, "target triple = %s\n", quote(m.TargetTriple))
 	}
 	// Module-level inline assembly.
-	if len(m.ModuleAsms) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.ModuleAsms) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, asm := range m.ModuleAsms {
 		// 'module' 'asm' Asm=StringLit
 		fmt.Fprintf(buf, "module asm %s\n", quote(asm))
 	}
 	// Type definitions.
-	if len(m.TypeDefs) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if (len(m.TypeDefs) > 0 || cfg.hoist != nil) && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
-	for _, t := range m.TypeDefs {
+	for i, t := range m.TypeDefs {
+		if cfg.hoist != nil && i == cfg.hoist.pos {
+			cfg.hoist.writeTypeDefs(buf)
+		}
 		// Alias=LocalIdent '=' 'type' Typ=OpaqueType
 		//
 		// Alias=LocalIdent '=' 'type' Typ=Type
 		fmt.Fprintf(buf, "%s = type %s\n", t, t.LLString())
 	}
+	if cfg.hoist != nil && cfg.hoist.pos == len(m.TypeDefs) {
+		cfg.hoist.writeTypeDefs(buf)
+	}
 	// Comdat definitions.
-	if len(m.ComdatDefs) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.ComdatDefs) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, def := range m.ComdatDefs {
 		fmt.Fprintln(buf, def.LLString())
 	}
 	// Global declarations and definitions.
-	if len(m.Globals) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.Globals) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, g := range m.Globals {
 		fmt.Fprintln(buf, g.LLString())
 	}
 	// Aliases.
-	if len(m.Aliases) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.Aliases) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, alias := range m.Aliases {
 		fmt.Fprintln(buf, alias.LLString())
 	}
 	// IFuncs.
-	if len(m.IFuncs) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.IFuncs) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, ifunc := range m.IFuncs {
 		fmt.Fprintln(buf, ifunc.LLString())
 	}
 	// Function declarations and definitions.
-	if len(m.Funcs) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.Funcs) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for i, f := range m.Funcs {
 		if i != 0 {
-			buf.WriteString("\n")
+			io.WriteString(buf, "\n")
 		}
-		fmt.Fprintln(buf, f.LLString())
+		fmt.Fprintln(buf, f.llString(cfg.instCounts))
 	}
 	// Attribute group definitions.
-	if len(m.AttrGroupDefs) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.AttrGroupDefs) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, a := range m.AttrGroupDefs {
 		fmt.Fprintln(buf, a.LLString())
@@ -152,8 +178,8 @@ func (m *Module) String() string {
 		mdNames = append(mdNames, mdName)
 	}
 	natsort.Strings(mdNames)
-	if len(m.NamedMetadataDefs) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.NamedMetadataDefs) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, mdName := range mdNames {
 		// Name=MetadataName '=' '!' '{' MDNodes=(MetadataNode separator ',')* '}'
@@ -161,8 +187,8 @@ func (m *Module) String() string {
 		fmt.Fprintf(buf, "%s = %s\n", md.Ident(), md.LLString())
 	}
 	// Metadata definitions.
-	if len(m.MetadataDefs) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.MetadataDefs) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, md := range m.MetadataDefs {
 		// ID=MetadataID '=' Distinctopt MDNode=MDTuple
@@ -171,20 +197,162 @@ func (m *Module) String() string {
 		fmt.Fprintf(buf, "%s = %s\n", md.Ident(), md.LLString())
 	}
 	// Use-list orders.
-	if len(m.UseListOrders) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.UseListOrders) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, u := range m.UseListOrders {
 		fmt.Fprintln(buf, u)
 	}
 	// Basic block specific use-list orders.
-	if len(m.UseListOrderBBs) > 0 && buf.Len() > 0 {
-		buf.WriteString("\n")
+	if len(m.UseListOrderBBs) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
 	}
 	for _, u := range m.UseListOrderBBs {
 		fmt.Fprintln(buf, u)
 	}
-	return buf.String()
+	// Module summary index entries.
+	if len(m.SummaryEntries) > 0 && buf.n > 0 {
+		io.WriteString(buf, "\n")
+	}
+	for _, entry := range m.SummaryEntries {
+		fmt.Fprintln(buf, entry)
+	}
+	return buf.err
+}
+
+// WriteTo writes the LLVM IR assembly of the module to w.
+func (m *Module) WriteTo(w io.Writer) (n int64, err error) {
+	return m.WriteToWithOptions(w)
+}
+
+// WriteToWithOptions writes the LLVM IR assembly of the module to w, based on
+// the given write options.
+//
+// If the VerifyBeforeWrite option is specified and the module fails to verify,
+// nothing is written to w and the verification errors are returned.
+func (m *Module) WriteToWithOptions(w io.Writer, opts ...WriteOption) (n int64, err error) {
+	cfg := &writeConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.verify {
+		if err := m.Verify(); err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	if cfg.hoistMinUses > 0 {
+		cfg.hoist = m.hoistStructTypes(cfg.hoistMinUses)
+	}
+	cw := &countWriter{w: w}
+	if cfg.gzip {
+		zw := gzip.NewWriter(cw)
+		if err := m.write(zw, cfg); err != nil {
+			return cw.n, errors.WithStack(err)
+		}
+		if err := zw.Close(); err != nil {
+			return cw.n, errors.WithStack(err)
+		}
+		return cw.n, nil
+	}
+	if err := m.write(cw, cfg); err != nil {
+		return cw.n, errors.WithStack(err)
+	}
+	return cw.n, nil
+}
+
+// WriteOption is an option of the module writer.
+type WriteOption func(cfg *writeConfig)
+
+// writeConfig is the configuration of the module writer.
+type writeConfig struct {
+	// Verify the module before writing.
+	verify bool
+	// Minimum number of uses of literal struct types to hoist into type
+	// definitions; or 0 to not hoist.
+	hoistMinUses int
+	// Literal struct types hoisted into type definitions; or nil if none.
+	hoist *structHoist
+	// Gzip-compress the output.
+	gzip bool
+	// Annotate basic blocks and functions with instruction counts.
+	instCounts bool
+}
+
+// VerifyBeforeWrite returns a write option which verifies the module before
+// writing. If verification fails, nothing is written and the verification
+// errors are returned instead.
+func VerifyBeforeWrite() WriteOption {
+	return func(cfg *writeConfig) {
+		cfg.verify = true
+	}
+}
+
+// HoistStructTypes returns a write option which hoists literal struct types
+// used at least minUses times into numbered type definitions (e.g. `%0 = type
+// { i32, i8* }`), which are referenced in place of the literal struct types to
+// reduce the size of the output. The module is left unchanged.
+//
+// Hoisted type definitions are numbered in order of first use in the output,
+// so that the output is reproducible.
+//
+// Literal struct types of intrinsic function signatures and cmpxchg results are
+// not hoisted, as LLVM requires these to be literal struct types.
+func HoistStructTypes(minUses int) WriteOption {
+	return func(cfg *writeConfig) {
+		if minUses < 1 {
+			minUses = 1
+		}
+		cfg.hoistMinUses = minUses
+	}
+}
+
+// GzipOutput returns a write option which gzip-compresses the output. The
+// number of bytes written is the size of the compressed output. Compressed
+// output may be parsed using asm.ParseFile, which transparently decompresses
+// gzip-compressed input.
+func GzipOutput() WriteOption {
+	return func(cfg *writeConfig) {
+		cfg.gzip = true
+	}
+}
+
+// AnnotateInstCounts returns a write option which annotates each basic block
+// and function definition with its number of instructions (including
+// terminators, and excluding debug records), as comments; e.g.
+//
+//    ; 3 instructions
+//    define i32 @f(i32 %x) {
+//    entry: ; 3 instructions
+//       ...
+//    }
+//
+// The output remains valid LLVM IR assembly.
+func AnnotateInstCounts() WriteOption {
+	return func(cfg *writeConfig) {
+		cfg.instCounts = true
+	}
+}
+
+// countWriter is a writer which counts the number of bytes written to the
+// underlying writer, and records the first error of the underlying writer.
+type countWriter struct {
+	// Underlying writer.
+	w io.Writer
+	// Number of bytes written.
+	n int64
+	// First error of the underlying writer; subsequent writes are skipped.
+	err error
+}
+
+// Write writes p to the underlying writer.
+func (cw *countWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
 }
 
 // ~~~ [ Comdat Definition ] ~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
@@ -302,8 +470,56 @@ func (u *UseListOrderBB) String() string {
 	return buf.String()
 }
 
+// SummaryEntry is an entry of the module summary index, as used by ThinLTO
+// (e.g. `^0 = module: (path: "foo.o", hash: (0, 0, 0, 0, 0))`).
+//
+// Summary entries are stored opaquely; the body of the entry is stored as is,
+// and may refer to other summary entries by ID (e.g. ^0).
+type SummaryEntry struct {
+	// Summary entry ID (without '^' prefix).
+	ID int64
+	// Body of the summary entry (e.g. `gv: (name: "foo")`).
+	Body string
+}
+
+// String returns the string representation of the module summary index entry.
+func (e *SummaryEntry) String() string {
+	// ID=SummaryID '=' Body
+	return fmt.Sprintf("^%d = %s", e.ID, e.Body)
+}
+
 // ### [ Helper functions ] ####################################################
 
+// AssignGlobalIDs assigns global IDs to the unnamed global variables, aliases,
+// IFuncs and functions of the module.
+//
+// Unnamed global identifiers are assigned consecutive IDs (e.g. @0, @1) in the
+// order in which they are output; global variables first, followed by aliases,
+// IFuncs and functions. Any previously assigned IDs are replaced, as LLVM
+// requires the IDs of unnamed global identifiers to be numbered in order of
+// definition.
+func (m *Module) AssignGlobalIDs() {
+	id := int64(0)
+	setID := func(ident *GlobalIdent) {
+		if ident.IsUnnamed() {
+			ident.SetID(id)
+			id++
+		}
+	}
+	for _, g := range m.Globals {
+		setID(&g.GlobalIdent)
+	}
+	for _, alias := range m.Aliases {
+		setID(&alias.GlobalIdent)
+	}
+	for _, ifunc := range m.IFuncs {
+		setID(&ifunc.GlobalIdent)
+	}
+	for _, f := range m.Funcs {
+		setID(&f.GlobalIdent)
+	}
+}
+
 // AssignMetadataIDs assigns metadata IDs to the unnamed metadata definitions of
 // the module.
 func (m *Module) AssignMetadataIDs() error {
@@ -0,0 +1,18 @@
+package ir
+
+// --- [ Attribute group definitions ] -----------------------------------------
+
+// NewAttrGroupDef appends a new attribute group definition to the module based
+// on the given function attributes. The attribute group ID is one past the
+// largest attribute group ID of the module.
+func (m *Module) NewAttrGroupDef(funcAttrs ...FuncAttribute) *AttrGroupDef {
+	var id int64
+	for _, def := range m.AttrGroupDefs {
+		if def.ID >= id {
+			id = def.ID + 1
+		}
+	}
+	def := &AttrGroupDef{ID: id, FuncAttrs: funcAttrs}
+	m.AttrGroupDefs = append(m.AttrGroupDefs, def)
+	return def
+}
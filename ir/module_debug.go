@@ -0,0 +1,164 @@
+package ir
+
+import (
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// --- [ Debug intrinsics and debug records ] ----------------------------------
+
+// dbgIntrinsicNames maps from debug record kind to the name of the
+// corresponding debug intrinsic function.
+var dbgIntrinsicNames = map[enum.DbgRecordKind]string{
+	enum.DbgRecordKindValue:   "llvm.dbg.value",
+	enum.DbgRecordKindDeclare: "llvm.dbg.declare",
+	enum.DbgRecordKindAssign:  "llvm.dbg.assign",
+	enum.DbgRecordKindLabel:   "llvm.dbg.label",
+}
+
+// ConvertDebugIntrinsicsToRecords replaces the calls to debug intrinsics (e.g.
+// @llvm.dbg.value) of the module with the corresponding debug records (e.g.
+// #dbg_value), as used by LLVM 19 and later.
+//
+// Calls without a !dbg attachment, and calls with operands not representable
+// by debug records, are left unchanged. The declarations of the debug
+// intrinsics are kept, for use by ConvertDebugRecordsToIntrinsics.
+func (m *Module) ConvertDebugIntrinsicsToRecords() {
+	for _, f := range m.Funcs {
+		for _, block := range f.Blocks {
+			for i, inst := range block.Insts {
+				call, ok := inst.(*InstCall)
+				if !ok {
+					continue
+				}
+				if record := dbgRecordFromCall(call); record != nil {
+					block.Insts[i] = record
+				}
+			}
+		}
+	}
+}
+
+// ConvertDebugRecordsToIntrinsics replaces the debug records (e.g. #dbg_value)
+// of the module with calls to the corresponding debug intrinsics (e.g.
+// @llvm.dbg.value), as used by LLVM 18 and earlier.
+//
+// Declarations of the debug intrinsics are added to the module as needed.
+func (m *Module) ConvertDebugRecordsToIntrinsics() {
+	for _, f := range m.Funcs {
+		for _, block := range f.Blocks {
+			for i, inst := range block.Insts {
+				record, ok := inst.(*DbgRecord)
+				if !ok {
+					continue
+				}
+				callee := m.dbgIntrinsic(record.Kind)
+				var args []value.Value
+				switch record.Kind {
+				case enum.DbgRecordKindLabel:
+					args = append(args, mdArg(record.Variable))
+				case enum.DbgRecordKindAssign:
+					args = append(args, mdArg(record.Value), mdArg(record.Variable), mdArg(record.Expr), mdArg(record.AssignID), mdArg(record.Address), mdArg(record.AddressExpr))
+				default:
+					args = append(args, mdArg(record.Value), mdArg(record.Variable), mdArg(record.Expr))
+				}
+				call := NewCall(callee, args...)
+				if loc, ok := record.Loc.(metadata.MDNode); ok {
+					call.Metadata = append(call.Metadata, &metadata.Attachment{Name: "dbg", Node: loc})
+				}
+				block.Insts[i] = call
+			}
+		}
+	}
+}
+
+// dbgIntrinsic returns the declaration of the debug intrinsic of the given
+// debug record kind, adding it to the module if not present.
+func (m *Module) dbgIntrinsic(kind enum.DbgRecordKind) *Func {
+	name := dbgIntrinsicNames[kind]
+	for _, f := range m.Funcs {
+		if f.Name() == name {
+			return f
+		}
+	}
+	var params []*Param
+	nparams := 3
+	switch kind {
+	case enum.DbgRecordKindLabel:
+		nparams = 1
+	case enum.DbgRecordKindAssign:
+		nparams = 6
+	}
+	for i := 0; i < nparams; i++ {
+		params = append(params, NewParam("", types.Metadata))
+	}
+	return m.NewFunc(name, types.Void, params...)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// dbgRecordFromCall returns the debug record corresponding to the given call to
+// a debug intrinsic, or nil if the call is not a call to a debug intrinsic
+// representable by a debug record.
+func dbgRecordFromCall(call *InstCall) *DbgRecord {
+	callee, ok := call.Callee.(*Func)
+	if !ok {
+		return nil
+	}
+	var loc metadata.Field
+	for _, md := range call.Metadata {
+		if node, ok := md.Node.(metadata.Field); ok && md.Name == "dbg" {
+			loc = node
+		}
+	}
+	if loc == nil {
+		return nil
+	}
+	// Metadata arguments of the call.
+	var mds []metadata.Metadata
+	for _, arg := range call.Args {
+		md, ok := arg.(*metadata.Value)
+		if !ok {
+			return nil
+		}
+		mds = append(mds, md.Value)
+	}
+	switch callee.Name() {
+	case dbgIntrinsicNames[enum.DbgRecordKindValue], dbgIntrinsicNames[enum.DbgRecordKindDeclare]:
+		if len(mds) != 3 {
+			return nil
+		}
+		v, ok := mds[0].(value.Value)
+		if !ok {
+			return nil
+		}
+		if callee.Name() == dbgIntrinsicNames[enum.DbgRecordKindDeclare] {
+			return NewDbgDeclare(v, mds[1], mds[2], loc)
+		}
+		return NewDbgValue(v, mds[1], mds[2], loc)
+	case dbgIntrinsicNames[enum.DbgRecordKindAssign]:
+		if len(mds) != 6 {
+			return nil
+		}
+		v, ok1 := mds[0].(value.Value)
+		id, ok2 := mds[3].(*metadata.DIAssignID)
+		addr, ok3 := mds[4].(value.Value)
+		if !ok1 || !ok2 || !ok3 {
+			return nil
+		}
+		return NewDbgAssign(v, mds[1], mds[2], id, addr, mds[5], loc)
+	case dbgIntrinsicNames[enum.DbgRecordKindLabel]:
+		if len(mds) != 1 {
+			return nil
+		}
+		return NewDbgLabel(mds[0], loc)
+	}
+	return nil
+}
+
+// mdArg returns the given metadata as a metadata argument of a call.
+func mdArg(md metadata.Metadata) value.Value {
+	return &metadata.Value{Value: md}
+}
@@ -0,0 +1,57 @@
+package ir_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestConvertDebugIntrinsicsToRecords(t *testing.T) {
+	const src = `define void @f(i32 %x) {
+entry:
+	%p = alloca i32
+	call void @llvm.dbg.declare(metadata i32* %p, metadata !1, metadata !DIExpression()), !dbg !2
+	store i32 %x, i32* %p
+	call void @llvm.dbg.value(metadata i32 %x, metadata !1, metadata !DIExpression()), !dbg !2
+	call void @llvm.dbg.label(metadata !3), !dbg !2
+	call void @llvm.dbg.value(metadata i32 %x, metadata !1, metadata !DIExpression())
+	ret void
+}
+
+declare void @llvm.dbg.declare(metadata, metadata, metadata)
+
+declare void @llvm.dbg.value(metadata, metadata, metadata)
+
+declare void @llvm.dbg.label(metadata)
+
+!0 = !DIFile(filename: "foo.c", directory: "")
+!1 = !DILocalVariable(name: "v", scope: !0)
+!2 = !DILocation(line: 1, scope: !0)
+!3 = !DILabel(scope: !0, name: "L", file: !0, line: 1)
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	m.ConvertDebugIntrinsicsToRecords()
+	// The call without a !dbg attachment is left unchanged.
+	const want = `define void @f(i32 %x) {
+entry:
+	%p = alloca i32
+	#dbg_declare(i32* %p, !1, !DIExpression(), !2)
+	store i32 %x, i32* %p
+	#dbg_value(i32 %x, !1, !DIExpression(), !2)
+	#dbg_label(!3, !2)
+	call void @llvm.dbg.value(metadata i32 %x, metadata !1, metadata !DIExpression())
+	ret void
+}
+`
+	if got := m.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("module mismatch; expected prefix `%s`, got `%s`", want, got)
+	}
+	m.ConvertDebugRecordsToIntrinsics()
+	if got := m.String(); got != src {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", src, got)
+	}
+}
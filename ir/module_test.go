@@ -0,0 +1,109 @@
+package ir_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestModuleAssignGlobalIDs(t *testing.T) {
+	// Function @0 precedes global variable @1 in the input, but is output after
+	// the global variables.
+	const src = `
+declare void @0()
+
+@1 = global void ()* @0
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	// Unnamed global variable created through the API.
+	m.NewGlobalDef("", constant.NewInt(types.I32, 42))
+	const want = `@0 = global void ()* @2
+@1 = global i32 42
+
+declare void @2()
+`
+	if got := m.String(); got != want {
+		t.Errorf("module mismatch; expected `%s`, got `%s`", want, got)
+	}
+}
+
+func TestModuleTypes(t *testing.T) {
+	m := ir.NewModule()
+	list := m.NewTypeDef("list", types.NewStruct(types.I32))
+	list.(*types.StructType).Fields = append(list.(*types.StructType).Fields, types.NewPointer(list))
+	m.NewTypeDef("opaque", &types.StructType{Opaque: true})
+	pair := types.NewStruct(types.I8, list)
+	pair.Packed = true
+	m.NewTypeDef("pair", pair)
+	m.NewTypeDef("int", types.NewInt(32))
+	golden := []struct {
+		name string
+		want string
+	}{
+		{name: "list", want: "%list = type { i32, %list* }"},
+		{name: "opaque", want: "%opaque = type opaque"},
+		{name: "pair", want: "%pair = type <{ i8, %list }>"},
+		{name: "int", want: "%int = type i32"},
+	}
+	defs := m.Types()
+	if len(defs) != len(golden) {
+		t.Fatalf("number of type definitions mismatch; expected %d, got %d", len(golden), len(defs))
+	}
+	for i, g := range golden {
+		def := defs[i]
+		if def.Name != g.name {
+			t.Errorf("type name mismatch; expected %q, got %q", g.name, def.Name)
+		}
+		if got := def.LLString(); got != g.want {
+			t.Errorf("type definition mismatch; expected %q, got %q", g.want, got)
+		}
+	}
+}
+
+// Assert that *ir.Module implements io.WriterTo.
+var _ io.WriterTo = (*ir.Module)(nil)
+
+func TestModuleWriteTo(t *testing.T) {
+	m := ir.NewModule()
+	m.NewGlobalDef("x", constant.NewInt(types.I32, 1))
+	m.NewGlobalDef("y", constant.NewInt(types.I32, 2))
+	m.NewFunc("f", types.Void)
+	buf := &strings.Builder{}
+	n, err := m.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("unexpected error; %v", err)
+	}
+	if want := m.String(); buf.String() != want || n != int64(len(want)) {
+		t.Errorf("output mismatch; expected %q (%d bytes), got %q (%d bytes)", want, len(want), buf.String(), n)
+	}
+	// Writing stops at the first error of the underlying writer.
+	w := &failWriter{}
+	n, err = m.WriteTo(w)
+	if err == nil {
+		t.Fatalf("expected write error, got nil")
+	}
+	if n != 0 || w.calls != 1 {
+		t.Errorf("expected writing to stop after the first failed write; got %d bytes written in %d writes", n, w.calls)
+	}
+}
+
+// failWriter is a writer which fails all writes.
+type failWriter struct {
+	// Number of writes.
+	calls int
+}
+
+// Write records the write and returns an error.
+func (w *failWriter) Write(p []byte) (int, error) {
+	w.calls++
+	return 0, errors.New("write error")
+}
@@ -1,6 +1,10 @@
 package ir
 
-import "github.com/llir/llvm/ir/types"
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir/types"
+)
 
 // --- [ Type definitions ] ----------------------------------------------------
 
@@ -11,3 +15,29 @@ func (m *Module) NewTypeDef(name string, typ types.Type) types.Type {
 	m.TypeDefs = append(m.TypeDefs, typ)
 	return typ
 }
+
+// NamedTypeDef is a named type definition of a module.
+type NamedTypeDef struct {
+	// Type name (without '%' prefix).
+	Name string
+	// Underlying type of the type definition.
+	Def types.Type
+}
+
+// LLString returns the LLVM syntax representation of the type definition.
+func (def NamedTypeDef) LLString() string {
+	// Alias=LocalIdent '=' 'type' Typ=Type
+	return fmt.Sprintf("%s = type %s", def.Def, def.Def.LLString())
+}
+
+// Types returns the named type definitions of the module, in order of
+// declaration; i.e. the order of m.TypeDefs. Note, the type definitions of
+// modules parsed by the asm package are in natural sorting order of type
+// names.
+func (m *Module) Types() []NamedTypeDef {
+	defs := make([]NamedTypeDef, len(m.TypeDefs))
+	for i, t := range m.TypeDefs {
+		defs[i] = NamedTypeDef{Name: t.Name(), Def: t}
+	}
+	return defs
+}
@@ -0,0 +1,443 @@
+package ir
+
+import (
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Operands ] ============================================================
+
+// --- [ Unary instructions ] --------------------------------------------------
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFNeg) Operands() []*value.Value {
+	return []*value.Value{&inst.X}
+}
+
+// --- [ Binary instructions ] -------------------------------------------------
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstAdd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFAdd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstSub) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFSub) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstMul) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFMul) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstUDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstSDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFDiv) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstURem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstSRem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFRem) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// --- [ Bitwise instructions ] ------------------------------------------------
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstShl) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstLShr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstAShr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstAnd) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstOr) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstXor) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// --- [ Vector instructions ] -------------------------------------------------
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstExtractElement) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Index}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstInsertElement) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Elem, &inst.Index}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstShuffleVector) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y, &inst.Mask}
+}
+
+// --- [ Aggregate instructions ] ----------------------------------------------
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstExtractValue) Operands() []*value.Value {
+	return []*value.Value{&inst.X}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstInsertValue) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Elem}
+}
+
+// --- [ Memory instructions ] -------------------------------------------------
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstAlloca) Operands() []*value.Value {
+	if inst.NElems != nil {
+		return []*value.Value{&inst.NElems}
+	}
+	return nil
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstLoad) Operands() []*value.Value {
+	return []*value.Value{&inst.Src}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstStore) Operands() []*value.Value {
+	return []*value.Value{&inst.Src, &inst.Dst}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFence) Operands() []*value.Value {
+	return nil
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstCmpXchg) Operands() []*value.Value {
+	return []*value.Value{&inst.Ptr, &inst.Cmp, &inst.New}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstAtomicRMW) Operands() []*value.Value {
+	return []*value.Value{&inst.Dst, &inst.X}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstGetElementPtr) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, 1+len(inst.Indices))
+	ops = append(ops, &inst.Src)
+	for i := range inst.Indices {
+		ops = append(ops, &inst.Indices[i])
+	}
+	return ops
+}
+
+// --- [ Conversion instructions ] ---------------------------------------------
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstTrunc) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstZExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstSExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFPTrunc) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFPExt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFPToUI) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFPToSI) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstUIToFP) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstSIToFP) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstPtrToInt) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstIntToPtr) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstBitCast) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstAddrSpaceCast) Operands() []*value.Value {
+	return []*value.Value{&inst.From}
+}
+
+// --- [ Other instructions ] --------------------------------------------------
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstICmp) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFCmp) Operands() []*value.Value {
+	return []*value.Value{&inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+//
+// Note, the predecessor basic blocks of incoming values are not included.
+func (inst *InstPhi) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, len(inst.Incs))
+	for _, inc := range inst.Incs {
+		ops = append(ops, &inc.X)
+	}
+	return ops
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstSelect) Operands() []*value.Value {
+	return []*value.Value{&inst.Cond, &inst.X, &inst.Y}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstFreeze) Operands() []*value.Value {
+	return []*value.Value{&inst.X}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+//
+// The callee is the first operand, followed by the function arguments and the
+// inputs of operand bundles.
+func (inst *InstCall) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, 1+len(inst.Args))
+	ops = append(ops, &inst.Callee)
+	ops = appendArgOperands(ops, inst.Args)
+	for _, bundle := range inst.OperandBundles {
+		for i := range bundle.Inputs {
+			ops = append(ops, &bundle.Inputs[i])
+		}
+	}
+	return ops
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstVAArg) Operands() []*value.Value {
+	return []*value.Value{&inst.ArgList}
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+func (inst *InstLandingPad) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, len(inst.Clauses))
+	for _, clause := range inst.Clauses {
+		ops = append(ops, &clause.X)
+	}
+	return ops
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+//
+// Note, the exception scope is not included.
+func (inst *InstCatchPad) Operands() []*value.Value {
+	return appendArgOperands(nil, inst.Args)
+}
+
+// Operands returns a mutable list of operands of the given instruction.
+//
+// Note, the exception scope is not included.
+func (inst *InstCleanupPad) Operands() []*value.Value {
+	return appendArgOperands(nil, inst.Args)
+}
+
+// --- [ Debug records ] -------------------------------------------------------
+
+// Operands returns a mutable list of operands of the given debug record.
+func (r *DbgRecord) Operands() []*value.Value {
+	var ops []*value.Value
+	if r.Value != nil {
+		ops = append(ops, &r.Value)
+	}
+	if r.Address != nil {
+		ops = append(ops, &r.Address)
+	}
+	return ops
+}
+
+// --- [ Terminators ] ---------------------------------------------------------
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermRet) Operands() []*value.Value {
+	if term.X != nil {
+		return []*value.Value{&term.X}
+	}
+	return nil
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermBr) Operands() []*value.Value {
+	return nil
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermCondBr) Operands() []*value.Value {
+	return []*value.Value{&term.Cond}
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermSwitch) Operands() []*value.Value {
+	return []*value.Value{&term.X}
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermIndirectBr) Operands() []*value.Value {
+	return []*value.Value{&term.Addr}
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+//
+// The invokee is the first operand, followed by the function arguments and the
+// inputs of operand bundles.
+func (term *TermInvoke) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, 1+len(term.Args))
+	ops = append(ops, &term.Invokee)
+	ops = appendArgOperands(ops, term.Args)
+	for _, bundle := range term.OperandBundles {
+		for i := range bundle.Inputs {
+			ops = append(ops, &bundle.Inputs[i])
+		}
+	}
+	return ops
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+//
+// The callee is the first operand, followed by the function arguments and the
+// inputs of operand bundles.
+func (term *TermCallBr) Operands() []*value.Value {
+	ops := make([]*value.Value, 0, 1+len(term.Args))
+	ops = append(ops, &term.Callee)
+	ops = appendArgOperands(ops, term.Args)
+	for _, bundle := range term.OperandBundles {
+		for i := range bundle.Inputs {
+			ops = append(ops, &bundle.Inputs[i])
+		}
+	}
+	return ops
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermResume) Operands() []*value.Value {
+	return []*value.Value{&term.X}
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermCatchSwitch) Operands() []*value.Value {
+	return nil
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermCatchRet) Operands() []*value.Value {
+	return nil
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermCleanupRet) Operands() []*value.Value {
+	return nil
+}
+
+// Operands returns a mutable list of operands of the given terminator.
+func (term *TermUnreachable) Operands() []*value.Value {
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// appendArgOperands appends the operands of the given function arguments to
+// ops. The value of arguments with parameter attributes (i.e. *Arg) is
+// appended rather than the *Arg itself.
+func appendArgOperands(ops []*value.Value, args []value.Value) []*value.Value {
+	for i := range args {
+		if arg, ok := args[i].(*Arg); ok {
+			ops = append(ops, &arg.Value)
+			continue
+		}
+		ops = append(ops, &args[i])
+	}
+	return ops
+}
@@ -0,0 +1,125 @@
+package ir
+
+import (
+	"math/big"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Range inference ] =====================================================
+
+// InferRange returns a conservative range [lo, hi] (both inclusive) of the
+// unsigned interpretation of the given integer value. The boolean return value
+// indicates whether a range is known.
+//
+// The range is derived from integer constants, !range metadata of load and call
+// instructions, and instructions computing the and of a value with an integer
+// mask (bounded above by the mask), zero extension (bounded above by the
+// largest value of the source type) and truncation (the range of the source
+// value, if within the range of the destination type).
+func InferRange(v value.Value) (lo, hi *big.Int, ok bool) {
+	typ, ok := v.Type().(*types.IntType)
+	if !ok {
+		return nil, nil, false
+	}
+	switch v := v.(type) {
+	case *constant.Int:
+		x := unsignedInt(v.X, typ.BitSize)
+		return x, x, true
+	case *InstLoad:
+		return rangeMetadata(v.Metadata, typ.BitSize)
+	case *InstCall:
+		return rangeMetadata(v.Metadata, typ.BitSize)
+	case *InstAnd:
+		// The result is bounded above by the upper bounds of both operands.
+		_, xhi, xok := InferRange(v.X)
+		_, yhi, yok := InferRange(v.Y)
+		switch {
+		case xok && yok:
+			if yhi.Cmp(xhi) < 0 {
+				xhi = yhi
+			}
+			return new(big.Int), xhi, true
+		case xok:
+			return new(big.Int), xhi, true
+		case yok:
+			return new(big.Int), yhi, true
+		}
+		return nil, nil, false
+	case *InstZExt:
+		if lo, hi, ok := InferRange(v.From); ok {
+			return lo, hi, true
+		}
+		from, ok := v.From.Type().(*types.IntType)
+		if !ok {
+			return nil, nil, false
+		}
+		return new(big.Int), maxUint(from.BitSize), true
+	case *InstTrunc:
+		lo, hi, ok := InferRange(v.From)
+		if !ok || hi.Cmp(maxUint(typ.BitSize)) > 0 {
+			return nil, nil, false
+		}
+		return lo, hi, true
+	}
+	return nil, nil, false
+}
+
+// ### [ Helper functions ] ####################################################
+
+// rangeMetadata returns the range [lo, hi] (both inclusive) specified by the
+// !range metadata attachment of the given metadata attachments, for an integer
+// type of the given bit size. The boolean return value indicates whether a
+// range is specified.
+//
+// The !range metadata node is a list of half-open ranges [a, b); ranges which
+// wrap around are not handled.
+func rangeMetadata(mds Metadata, bitSize uint64) (lo, hi *big.Int, ok bool) {
+	for _, md := range mds {
+		if md.Name != "range" {
+			continue
+		}
+		tuple, ok := md.Node.(*metadata.Tuple)
+		if !ok || len(tuple.Fields) == 0 || len(tuple.Fields)%2 != 0 {
+			return nil, nil, false
+		}
+		for i := 0; i < len(tuple.Fields); i += 2 {
+			a, ok1 := tuple.Fields[i].(*constant.Int)
+			b, ok2 := tuple.Fields[i+1].(*constant.Int)
+			if !ok1 || !ok2 {
+				return nil, nil, false
+			}
+			start := unsignedInt(a.X, bitSize)
+			end := unsignedInt(b.X, bitSize)
+			if start.Cmp(end) >= 0 {
+				// Wrapped range.
+				return nil, nil, false
+			}
+			end.Sub(end, big.NewInt(1))
+			if lo == nil || start.Cmp(lo) < 0 {
+				lo = start
+			}
+			if hi == nil || end.Cmp(hi) > 0 {
+				hi = end
+			}
+		}
+		return lo, hi, true
+	}
+	return nil, nil, false
+}
+
+// unsignedInt returns the unsigned interpretation of the given integer of the
+// specified bit size.
+func unsignedInt(x *big.Int, bitSize uint64) *big.Int {
+	mod := new(big.Int).Lsh(big.NewInt(1), uint(bitSize))
+	return new(big.Int).Mod(x, mod)
+}
+
+// maxUint returns the largest unsigned integer of the specified bit size.
+func maxUint(bitSize uint64) *big.Int {
+	x := new(big.Int).Lsh(big.NewInt(1), uint(bitSize))
+	return x.Sub(x, big.NewInt(1))
+}
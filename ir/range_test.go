@@ -0,0 +1,67 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestInferRange(t *testing.T) {
+	const src = `
+define void @f(i32 %x, i8 %y, i32* %p) {
+entry:
+	%mask = and i32 %x, 255
+	%narrow = and i32 %mask, %x
+	%load = load i32, i32* %p, !range !0
+	%ext = zext i8 %y to i32
+	%trunc1 = trunc i32 %mask to i8
+	%trunc2 = trunc i32 %x to i8
+	ret void
+}
+
+!0 = !{i32 0, i32 10, i32 20, i32 30}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	insts := f.Blocks[0].Insts
+	golden := []struct {
+		v      value.Value
+		lo, hi int64
+		ok     bool
+	}{
+		// and with mask.
+		{v: insts[0].(value.Value), lo: 0, hi: 255, ok: true},
+		{v: insts[1].(value.Value), lo: 0, hi: 255, ok: true},
+		// !range metadata.
+		{v: insts[2].(value.Value), lo: 0, hi: 29, ok: true},
+		// zext.
+		{v: insts[3].(value.Value), lo: 0, hi: 255, ok: true},
+		// trunc.
+		{v: insts[4].(value.Value), lo: 0, hi: 255, ok: true},
+		{v: insts[5].(value.Value), ok: false},
+		// Constants.
+		{v: constant.NewInt(types.I8, -1), lo: 255, hi: 255, ok: true},
+		// Unknown.
+		{v: f.Params[0], ok: false},
+	}
+	for _, g := range golden {
+		lo, hi, ok := ir.InferRange(g.v)
+		if ok != g.ok {
+			t.Errorf("range of %v; expected ok=%v, got ok=%v", g.v.Ident(), g.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if lo.Int64() != g.lo || hi.Int64() != g.hi {
+			t.Errorf("range of %v mismatch; expected [%d, %d], got [%v, %v]", g.v.Ident(), g.lo, g.hi, lo, hi)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package ir
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+// === [ S-expressions ] =======================================================
+
+// WriteSExpr writes the given module to w as nested s-expressions, for
+// consumption by Lisp-based analysis tools. Unnamed global and local
+// identifiers are assigned IDs before writing.
+//
+// The module is represented as follows, where names are string literals, and
+// types and the identifiers of values are atoms (or string literals if they
+// contain whitespace or delimiters).
+//
+//    (module
+//       (typedef %T "{ i32, i8* }")
+//       (global "g" i32 (const i32 0))
+//       (alias "a" i32* @g)
+//       (ifunc "h" "void ()*" @resolver)
+//       (function "f" i32 (param %x i32)
+//          (block "entry"
+//             (add %1 i32 %x (const i32 42))
+//             (ret %1))))
+//
+// Instructions producing a value are represented by their opcode, result and
+// result type, followed by their operands. Other instructions and terminators
+// are represented by their opcode followed by their operands, where the
+// operands of terminators are followed by their successor basic blocks.
+// Constant operands are represented as (const type value). The predicates of
+// comparison instructions precede the operands, the incoming values of phi
+// instructions are represented as (value pred) pairs, and the cases of switch
+// terminators as (case x target) triples. Other attributes, flags and metadata
+// are not represented.
+func WriteSExpr(w io.Writer, m *Module) error {
+	buf := &strings.Builder{}
+	m.AssignGlobalIDs()
+	buf.WriteString("(module")
+	for _, t := range m.TypeDefs {
+		fmt.Fprintf(buf, "\n\t(typedef %s %s)", sexprAtom(t.String()), sexprString(t.LLString()))
+	}
+	for _, g := range m.Globals {
+		fmt.Fprintf(buf, "\n\t(global %s %s", sexprString(g.Name()), sexprType(g.ContentType))
+		if g.Init != nil {
+			fmt.Fprintf(buf, " %s", sexprOperand(g.Init))
+		}
+		buf.WriteString(")")
+	}
+	for _, alias := range m.Aliases {
+		fmt.Fprintf(buf, "\n\t(alias %s %s %s)", sexprString(alias.Name()), sexprType(alias.Type()), sexprOperand(alias.Aliasee))
+	}
+	for _, ifunc := range m.IFuncs {
+		fmt.Fprintf(buf, "\n\t(ifunc %s %s %s)", sexprString(ifunc.Name()), sexprType(ifunc.Type()), sexprOperand(ifunc.Resolver))
+	}
+	for _, f := range m.Funcs {
+		if err := f.AssignIDs(); err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Fprintf(buf, "\n\t(function %s %s", sexprString(f.Name()), sexprType(f.Sig.RetType))
+		for _, param := range f.Params {
+			fmt.Fprintf(buf, " (param %s %s)", sexprAtom(param.Ident()), sexprType(param.Typ))
+		}
+		for _, block := range f.Blocks {
+			fmt.Fprintf(buf, "\n\t\t(block %s", sexprString(block.Name()))
+			for _, inst := range block.Insts {
+				fmt.Fprintf(buf, "\n\t\t\t%s", sexprInst(inst))
+			}
+			if block.Term != nil {
+				fmt.Fprintf(buf, "\n\t\t\t%s", sexprTerm(block.Term))
+			}
+			buf.WriteString(")")
+		}
+		buf.WriteString(")")
+	}
+	buf.WriteString(")\n")
+	if _, err := io.WriteString(w, buf.String()); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// sexprInst returns the s-expression of the given instruction.
+func sexprInst(inst Instruction) string {
+	var elems []string
+	elems = append(elems, instOpcode(inst))
+	if n, ok := inst.(value.Named); ok && !n.Type().Equal(types.Void) {
+		elems = append(elems, sexprAtom(n.Ident()), sexprType(n.Type()))
+	}
+	switch inst := inst.(type) {
+	case *InstICmp:
+		elems = append(elems, inst.Pred.String())
+	case *InstFCmp:
+		elems = append(elems, inst.Pred.String())
+	case *InstPhi:
+		for _, inc := range inst.Incs {
+			elems = append(elems, fmt.Sprintf("(%s %s)", sexprOperand(inc.X), sexprAtom(inc.Pred.Ident())))
+		}
+		return sexprList(elems)
+	}
+	for _, use := range inst.Operands() {
+		elems = append(elems, sexprOperand(*use))
+	}
+	return sexprList(elems)
+}
+
+// sexprTerm returns the s-expression of the given terminator.
+func sexprTerm(term Terminator) string {
+	var elems []string
+	elems = append(elems, termOpcode(term))
+	if n, ok := term.(value.Named); ok && !n.Type().Equal(types.Void) {
+		elems = append(elems, sexprAtom(n.Ident()), sexprType(n.Type()))
+	}
+	for _, use := range term.Operands() {
+		elems = append(elems, sexprOperand(*use))
+	}
+	if term, ok := term.(*TermSwitch); ok {
+		elems = append(elems, sexprAtom(term.TargetDefault.Ident()))
+		for _, c := range term.Cases {
+			elems = append(elems, fmt.Sprintf("(case %s %s)", sexprOperand(c.X), sexprAtom(c.Target.Ident())))
+		}
+		return sexprList(elems)
+	}
+	for _, succ := range term.Succs() {
+		elems = append(elems, sexprAtom(succ.Ident()))
+	}
+	return sexprList(elems)
+}
+
+// instOpcode returns the opcode of the given instruction.
+func instOpcode(inst Instruction) string {
+	switch inst := inst.(type) {
+	// Unary instructions.
+	case *InstFNeg:
+		return "fneg"
+	// Binary instructions.
+	case *InstAdd:
+		return "add"
+	case *InstFAdd:
+		return "fadd"
+	case *InstSub:
+		return "sub"
+	case *InstFSub:
+		return "fsub"
+	case *InstMul:
+		return "mul"
+	case *InstFMul:
+		return "fmul"
+	case *InstUDiv:
+		return "udiv"
+	case *InstSDiv:
+		return "sdiv"
+	case *InstFDiv:
+		return "fdiv"
+	case *InstURem:
+		return "urem"
+	case *InstSRem:
+		return "srem"
+	case *InstFRem:
+		return "frem"
+	// Bitwise instructions.
+	case *InstShl:
+		return "shl"
+	case *InstLShr:
+		return "lshr"
+	case *InstAShr:
+		return "ashr"
+	case *InstAnd:
+		return "and"
+	case *InstOr:
+		return "or"
+	case *InstXor:
+		return "xor"
+	// Vector instructions.
+	case *InstExtractElement:
+		return "extractelement"
+	case *InstInsertElement:
+		return "insertelement"
+	case *InstShuffleVector:
+		return "shufflevector"
+	// Aggregate instructions.
+	case *InstExtractValue:
+		return "extractvalue"
+	case *InstInsertValue:
+		return "insertvalue"
+	// Memory instructions.
+	case *InstAlloca:
+		return "alloca"
+	case *InstLoad:
+		return "load"
+	case *InstStore:
+		return "store"
+	case *InstFence:
+		return "fence"
+	case *InstCmpXchg:
+		return "cmpxchg"
+	case *InstAtomicRMW:
+		return "atomicrmw"
+	case *InstGetElementPtr:
+		return "getelementptr"
+	// Conversion instructions.
+	case *InstTrunc:
+		return "trunc"
+	case *InstZExt:
+		return "zext"
+	case *InstSExt:
+		return "sext"
+	case *InstFPTrunc:
+		return "fptrunc"
+	case *InstFPExt:
+		return "fpext"
+	case *InstFPToUI:
+		return "fptoui"
+	case *InstFPToSI:
+		return "fptosi"
+	case *InstUIToFP:
+		return "uitofp"
+	case *InstSIToFP:
+		return "sitofp"
+	case *InstPtrToInt:
+		return "ptrtoint"
+	case *InstIntToPtr:
+		return "inttoptr"
+	case *InstBitCast:
+		return "bitcast"
+	case *InstAddrSpaceCast:
+		return "addrspacecast"
+	// Other instructions.
+	case *InstICmp:
+		return "icmp"
+	case *InstFCmp:
+		return "fcmp"
+	case *InstPhi:
+		return "phi"
+	case *InstSelect:
+		return "select"
+	case *InstFreeze:
+		return "freeze"
+	case *InstCall:
+		return "call"
+	case *InstVAArg:
+		return "va_arg"
+	case *InstLandingPad:
+		return "landingpad"
+	case *InstCatchPad:
+		return "catchpad"
+	case *InstCleanupPad:
+		return "cleanuppad"
+	// Debug records.
+	case *DbgRecord:
+		return inst.Kind.String()
+	default:
+		panic(fmt.Errorf("support for instruction %T not yet implemented", inst))
+	}
+}
+
+// termOpcode returns the opcode of the given terminator.
+func termOpcode(term Terminator) string {
+	switch term := term.(type) {
+	case *TermRet:
+		return "ret"
+	case *TermBr, *TermCondBr:
+		return "br"
+	case *TermSwitch:
+		return "switch"
+	case *TermIndirectBr:
+		return "indirectbr"
+	case *TermInvoke:
+		return "invoke"
+	case *TermCallBr:
+		return "callbr"
+	case *TermResume:
+		return "resume"
+	case *TermCatchSwitch:
+		return "catchswitch"
+	case *TermCatchRet:
+		return "catchret"
+	case *TermCleanupRet:
+		return "cleanupret"
+	case *TermUnreachable:
+		return "unreachable"
+	default:
+		panic(fmt.Errorf("support for terminator %T not yet implemented", term))
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// sexprList returns the s-expression list of the given elements.
+func sexprList(elems []string) string {
+	return "(" + strings.Join(elems, " ") + ")"
+}
+
+// sexprOperand returns the s-expression of the given operand. Constants are
+// represented as (const type value).
+func sexprOperand(v value.Value) string {
+	switch v := v.(type) {
+	case *Global, *Func, *Alias, *IFunc:
+		return sexprAtom(v.Ident())
+	case constant.Constant:
+		return fmt.Sprintf("(const %s %s)", sexprType(v.Type()), sexprAtom(v.Ident()))
+	default:
+		return sexprAtom(v.Ident())
+	}
+}
+
+// sexprType returns the s-expression of the given type.
+func sexprType(t types.Type) string {
+	return sexprAtom(t.String())
+}
+
+// sexprAtom returns s as an s-expression atom, or as a string literal if s
+// contains whitespace, delimiters or quotes.
+func sexprAtom(s string) string {
+	if len(s) == 0 || strings.ContainsAny(s, " \t\n()\";'") {
+		return sexprString(s)
+	}
+	return s
+}
+
+// sexprString returns s as an s-expression string literal.
+func sexprString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
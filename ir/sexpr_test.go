@@ -0,0 +1,72 @@
+package ir_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestWriteSExpr(t *testing.T) {
+	const src = `
+%T = type { i32, i8* }
+
+@g = global i32 42
+
+declare i32 @h(i32)
+
+define i32 @f(i32 %x, i32) {
+entry:
+	%1 = add i32 %x, %0
+	%cmp = icmp slt i32 %1, 10
+	br i1 %cmp, label %then, label %exit
+
+then:
+	%y = call i32 @h(i32 %1)
+	store i32 %y, i32* @g
+	switch i32 %y, label %exit [
+		i32 1, label %entry.ret
+	]
+
+entry.ret:
+	ret i32 0
+
+exit:
+	%z = phi i32 [ %1, %entry ], [ %y, %then ]
+	%s = insertvalue %T undef, i32 %z, 0
+	ret i32 %z
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	const want = `(module
+	(typedef %T "{ i32, i8* }")
+	(global "g" i32 (const i32 42))
+	(function "h" i32 (param %0 i32))
+	(function "f" i32 (param %x i32) (param %0 i32)
+		(block "entry"
+			(add %1 i32 %x %0)
+			(icmp %cmp i1 slt %1 (const i32 10))
+			(br %cmp %then %exit))
+		(block "then"
+			(call %y i32 @h %1)
+			(store %y @g)
+			(switch %y %exit (case (const i32 1) %entry.ret)))
+		(block "entry.ret"
+			(ret (const i32 0)))
+		(block "exit"
+			(phi %z i32 (%1 %entry) (%y %then))
+			(insertvalue %s %T (const %T undef) %z)
+			(ret %z))))
+`
+	buf := &strings.Builder{}
+	if err := ir.WriteSExpr(buf, m); err != nil {
+		t.Fatalf("unable to write s-expressions; %+v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("s-expression mismatch; expected `%s`, got `%s`", want, got)
+	}
+}
@@ -44,6 +44,10 @@ func (AlignStack) IsFuncAttribute() {}
 // ir.FuncAttribute interface.
 func (AllocSize) IsFuncAttribute() {}
 
+// IsFuncAttribute ensures that only function attributes can be assigned to the
+// ir.FuncAttribute interface.
+func (MemoryEffects) IsFuncAttribute() {}
+
 // === [ ir.Instruction ] ======================================================
 
 // Binary instructions.
@@ -109,12 +113,16 @@ func (*InstICmp) isInstruction()       {}
 func (*InstFCmp) isInstruction()       {}
 func (*InstPhi) isInstruction()        {}
 func (*InstSelect) isInstruction()     {}
+func (*InstFreeze) isInstruction()     {}
 func (*InstCall) isInstruction()       {}
 func (*InstVAArg) isInstruction()      {}
 func (*InstLandingPad) isInstruction() {}
 func (*InstCatchPad) isInstruction()   {}
 func (*InstCleanupPad) isInstruction() {}
 
+// Debug records.
+func (*DbgRecord) isInstruction() {}
+
 // === [ ir.ParamAttribute ] ===================================================
 
 // IsParamAttribute ensures that only parameter attributes can be assigned to
@@ -133,6 +141,14 @@ func (Align) IsParamAttribute() {}
 // the ir.ParamAttribute interface.
 func (Dereferenceable) IsParamAttribute() {}
 
+// IsParamAttribute ensures that only parameter attributes can be assigned to
+// the ir.ParamAttribute interface.
+func (NoFPClass) IsParamAttribute() {}
+
+// IsParamAttribute ensures that only parameter attributes can be assigned to
+// the ir.ParamAttribute interface.
+func (TypedParamAttr) IsParamAttribute() {}
+
 // === [ ir.ReturnAttribute ] ==================================================
 
 // IsReturnAttribute ensures that only return attributes can be assigned to
@@ -151,6 +167,10 @@ func (Align) IsReturnAttribute() {}
 // the ir.ReturnAttribute interface.
 func (Dereferenceable) IsReturnAttribute() {}
 
+// IsReturnAttribute ensures that only return attributes can be assigned to the
+// ir.ReturnAttribute interface.
+func (NoFPClass) IsReturnAttribute() {}
+
 // === [ ir.UnwindTarget ] =====================================================
 
 // isUnwindTarget ensures that only unwind targets can be assigned to the
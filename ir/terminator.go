@@ -26,6 +26,7 @@ import (
 //    *ir.TermSwitch        // https://godoc.org/github.com/llir/llvm/ir#TermSwitch
 //    *ir.TermIndirectBr    // https://godoc.org/github.com/llir/llvm/ir#TermIndirectBr
 //    *ir.TermInvoke        // https://godoc.org/github.com/llir/llvm/ir#TermInvoke
+//    *ir.TermCallBr        // https://godoc.org/github.com/llir/llvm/ir#TermCallBr
 //    *ir.TermResume        // https://godoc.org/github.com/llir/llvm/ir#TermResume
 //    *ir.TermCatchSwitch   // https://godoc.org/github.com/llir/llvm/ir#TermCatchSwitch
 //    *ir.TermCatchRet      // https://godoc.org/github.com/llir/llvm/ir#TermCatchRet
@@ -35,6 +36,11 @@ type Terminator interface {
 	LLStringer
 	// Succs returns the successor basic blocks of the terminator.
 	Succs() []*Block
+	// Operands returns a mutable list of operands of the given terminator.
+	//
+	// Operands of more specific types than value.Value (e.g. successor basic
+	// blocks and the constant comparands of switch cases) are not included.
+	Operands() []*value.Value
 }
 
 // --- [ ret ] -----------------------------------------------------------------
@@ -209,6 +215,49 @@ func (term *TermSwitch) Succs() []*Block {
 	return term.Successors
 }
 
+// AddCase adds a switch case to the terminator, based on the given case
+// comparand and target basic block. The case comparand must have the same type
+// as the control variable, and be unique among the switch cases. The cached
+// successors of the terminator are invalidated.
+func (term *TermSwitch) AddCase(x *constant.Int, target *Block) *Case {
+	if !x.Typ.Equal(term.X.Type()) {
+		panic(fmt.Errorf("switch case comparand type mismatch of %q; expected %v, got %v", x.Ident(), term.X.Type(), x.Typ))
+	}
+	if term.caseIndex(x) != -1 {
+		panic(fmt.Errorf("duplicate switch case comparand %q", x.Ident()))
+	}
+	c := NewCase(x, target)
+	term.Cases = append(term.Cases, c)
+	// Invalidate cached successors.
+	term.Successors = nil
+	return c
+}
+
+// RemoveCase removes the switch case with the given case comparand from the
+// terminator, and reports whether such a case was present. The cached
+// successors of the terminator are invalidated.
+func (term *TermSwitch) RemoveCase(x *constant.Int) bool {
+	i := term.caseIndex(x)
+	if i == -1 {
+		return false
+	}
+	term.Cases = append(term.Cases[:i], term.Cases[i+1:]...)
+	// Invalidate cached successors.
+	term.Successors = nil
+	return true
+}
+
+// caseIndex returns the index of the switch case with the given case
+// comparand, or -1 if not present.
+func (term *TermSwitch) caseIndex(x *constant.Int) int {
+	for i, c := range term.Cases {
+		if c.X.Type().Equal(x.Typ) && c.X.Ident() == x.Ident() {
+			return i
+		}
+	}
+	return -1
+}
+
 // LLString returns the LLVM syntax representation of the terminator.
 func (term *TermSwitch) LLString() string {
 	// 'switch' X=TypeValue ',' Default=Label '[' Cases=Case* ']' Metadata=(','
@@ -437,6 +486,162 @@ func (term *TermInvoke) LLString() string {
 	return buf.String()
 }
 
+// --- [ callbr ] --------------------------------------------------------------
+
+// TermCallBr is an LLVM IR callbr terminator.
+type TermCallBr struct {
+	// Name of local variable associated with the result.
+	LocalIdent
+	// Callee (inline assembly).
+	// TODO: specify the set of underlying types of Callee.
+	Callee value.Value
+	// Function arguments.
+	//
+	// Arg has one of the following underlying types:
+	//    value.Value
+	Args []value.Value
+	// Default (fallthrough) control flow return point.
+	Normal *Block
+	// Indirect control flow return points.
+	Others []*Block
+
+	// extra.
+
+	// Type of result produced by the terminator, or function signature of the
+	// callee (as used when callee is variadic).
+	Typ types.Type
+	// Successor basic blocks of the terminator; cached by Succs. The cache must
+	// be invalidated (by setting Successors to nil) when Normal or Others is
+	// modified.
+	Successors []*Block
+	// (optional) Calling convention; zero if not present.
+	CallingConv enum.CallingConv
+	// (optional) Return attributes.
+	ReturnAttrs []ReturnAttribute
+	// (optional) Address space; zero if not present.
+	AddrSpace types.AddrSpace
+	// (optional) Function attributes.
+	FuncAttrs []FuncAttribute
+	// (optional) Operand bundles.
+	OperandBundles []*OperandBundle
+	// (optional) Metadata.
+	Metadata
+}
+
+// NewCallBr returns a new callbr terminator based on the given callee, function
+// arguments and control flow return points for default and indirect execution.
+//
+// TODO: specify the set of underlying types of callee.
+func NewCallBr(callee value.Value, args []value.Value, normal *Block, others ...*Block) *TermCallBr {
+	term := &TermCallBr{Callee: callee, Args: args, Normal: normal, Others: others}
+	// Compute type.
+	term.Type()
+	return term
+}
+
+// String returns the LLVM syntax representation of the terminator as a type-
+// value pair.
+func (term *TermCallBr) String() string {
+	return fmt.Sprintf("%s %s", term.Type(), term.Ident())
+}
+
+// Type returns the type of the terminator.
+func (term *TermCallBr) Type() types.Type {
+	// Cache type if not present.
+	if term.Typ == nil {
+		t, ok := term.Callee.Type().(*types.PointerType)
+		if !ok {
+			panic(fmt.Errorf("invalid callee type; expected *types.PointerType, got %T", term.Callee.Type()))
+		}
+		sig, ok := t.ElemType.(*types.FuncType)
+		if !ok {
+			panic(fmt.Errorf("invalid callee type; expected *types.FuncType, got %T", t.ElemType))
+		}
+		if sig.Variadic {
+			term.Typ = sig
+		} else {
+			term.Typ = sig.RetType
+		}
+	}
+	if t, ok := term.Typ.(*types.FuncType); ok {
+		return t.RetType
+	}
+	return term.Typ
+}
+
+// Succs returns the successor basic blocks of the terminator; i.e. the default
+// destination followed by the indirect destinations. The successors are cached
+// in Successors, which must be reset to nil when Normal or Others is modified.
+func (term *TermCallBr) Succs() []*Block {
+	// Cache successors if not present.
+	if term.Successors == nil {
+		succs := make([]*Block, 0, 1+len(term.Others))
+		succs = append(succs, term.Normal)
+		succs = append(succs, term.Others...)
+		term.Successors = succs
+	}
+	return term.Successors
+}
+
+// LLString returns the LLVM syntax representation of the terminator.
+func (term *TermCallBr) LLString() string {
+	// 'callbr' CallingConvopt ReturnAttrs=ReturnAttribute* AddrSpaceopt Typ=Type
+	// Callee=Value '(' Args ')' FuncAttrs=FuncAttribute* OperandBundles=('['
+	// (OperandBundle separator ',')+ ']')? 'to' Normal=Label '[' Others=(Label
+	// separator ',')* ']' Metadata=(',' MetadataAttachment)+?
+	buf := &strings.Builder{}
+	if !term.Type().Equal(types.Void) {
+		fmt.Fprintf(buf, "%s = ", term.Ident())
+	}
+	buf.WriteString("callbr")
+	if term.CallingConv != enum.CallingConvNone {
+		fmt.Fprintf(buf, " %s", callingConvString(term.CallingConv))
+	}
+	for _, attr := range term.ReturnAttrs {
+		fmt.Fprintf(buf, " %s", attr)
+	}
+	// Use function signature instead of return type for variadic functions.
+	typ := term.Type()
+	if t, ok := term.Typ.(*types.FuncType); ok {
+		if t.Variadic {
+			typ = t
+		}
+	}
+	fmt.Fprintf(buf, " %s %s(", typ, term.Callee.Ident())
+	for i, arg := range term.Args {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(arg.String())
+	}
+	buf.WriteString(")")
+	for _, attr := range term.FuncAttrs {
+		fmt.Fprintf(buf, " %s", attr)
+	}
+	if len(term.OperandBundles) > 0 {
+		buf.WriteString(" [ ")
+		for i, operandBundle := range term.OperandBundles {
+			if i != 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(operandBundle.String())
+		}
+		buf.WriteString(" ]")
+	}
+	fmt.Fprintf(buf, "\n\t\tto %s [", term.Normal)
+	for i, other := range term.Others {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(other.String())
+	}
+	buf.WriteString("]")
+	for _, md := range term.Metadata {
+		fmt.Fprintf(buf, ", %s", md)
+	}
+	return buf.String()
+}
+
 // --- [ resume ] --------------------------------------------------------------
 
 // TermResume is an LLVM IR resume terminator.
@@ -515,11 +720,14 @@ func (term *TermCatchSwitch) Type() types.Type {
 func (term *TermCatchSwitch) Succs() []*Block {
 	// Cache successors if not present.
 	if term.Successors == nil {
+		// Copy handlers to prevent the unwind target from being appended to the
+		// backing array of term.Handlers.
+		succs := make([]*Block, 0, len(term.Handlers)+1)
+		succs = append(succs, term.Handlers...)
 		if unwindTarget, ok := term.UnwindTarget.(*Block); ok {
-			term.Successors = append(term.Handlers, unwindTarget)
-		} else {
-			term.Successors = term.Handlers
+			succs = append(succs, unwindTarget)
 		}
+		term.Successors = succs
 	}
 	return term.Successors
 }
@@ -0,0 +1,121 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestSwitchCases(t *testing.T) {
+	m := NewModule()
+	f := m.NewFunc("f", types.I32, NewParam("x", types.I32))
+	entry := f.NewBlock("entry")
+	one := f.NewBlock("one")
+	two := f.NewBlock("two")
+	def := f.NewBlock("default")
+	term := entry.NewSwitch(f.Params[0], def)
+	term.AddCase(constant.NewInt(types.I32, 1), one)
+	term.AddCase(constant.NewInt(types.I32, 2), two)
+	term.AddCase(constant.NewInt(types.I32, 3), two)
+	if succs := term.Succs(); len(succs) != 4 {
+		t.Errorf("successor count mismatch; expected 4, got %d", len(succs))
+	}
+	if !term.RemoveCase(constant.NewInt(types.I32, 2)) {
+		t.Errorf("unable to remove switch case with comparand 2")
+	}
+	if term.RemoveCase(constant.NewInt(types.I32, 4)) {
+		t.Errorf("removed non-existent switch case with comparand 4")
+	}
+	if succs := term.Succs(); len(succs) != 3 {
+		t.Errorf("successor count mismatch; expected 3, got %d", len(succs))
+	}
+	for _, block := range []*Block{one, two, def} {
+		block.NewRet(constant.NewInt(types.I32, 0))
+	}
+	const want = `define i32 @f(i32 %x) {
+entry:
+	switch i32 %x, label %default [
+		i32 1, label %one
+		i32 3, label %two
+	]
+
+one:
+	ret i32 0
+
+two:
+	ret i32 0
+
+default:
+	ret i32 0
+}`
+	if got := f.LLString(); got != want {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+	}
+	if err := m.Verify(); err != nil {
+		t.Errorf("unable to verify module; %v", err)
+	}
+	// Invalid switch cases.
+	golden := []struct {
+		x    *constant.Int
+		desc string
+	}{
+		{x: constant.NewInt(types.I64, 5), desc: "type mismatch"},
+		{x: constant.NewInt(types.I32, 1), desc: "duplicate"},
+	}
+	for _, g := range golden {
+		func() {
+			defer func() {
+				if e := recover(); e == nil {
+					t.Errorf("expected panic for %s switch case %v", g.desc, g.x)
+				}
+			}()
+			term.AddCase(g.x, one)
+		}()
+	}
+}
+
+func TestBlockNewInvoke(t *testing.T) {
+	m := NewModule()
+	personality := m.NewFunc("__gxx_personality_v0", types.I32)
+	personality.Sig.Variadic = true
+	g := m.NewFunc("g", types.I32, NewParam("", types.I32))
+	f := m.NewFunc("f", types.I32)
+	f.Personality = personality
+	entry := f.NewBlock("entry")
+	normal := f.NewBlock("normal")
+	unwind := f.NewBlock("unwind")
+	term := entry.NewInvoke(g, []value.Value{constant.NewInt(types.I32, 42)}, normal, unwind)
+	term.SetName("x")
+	if !term.Type().Equal(types.I32) {
+		t.Errorf("invoke type mismatch; expected i32, got %v", term.Type())
+	}
+	if succs := term.Succs(); len(succs) != 2 || succs[0] != normal || succs[1] != unwind {
+		t.Errorf("successor mismatch; expected [%%normal %%unwind], got %v", succs)
+	}
+	normal.NewRet(term)
+	lp := unwind.NewLandingPad(types.NewStruct(types.I8Ptr, types.I32))
+	lp.Cleanup = true
+	lp.SetName("lp")
+	unwind.NewResume(lp)
+	const want = `define i32 @f() personality i32 (...)* @__gxx_personality_v0 {
+entry:
+	%x = invoke i32 @g(i32 42)
+		to label %normal unwind label %unwind
+
+normal:
+	ret i32 %x
+
+unwind:
+	%lp = landingpad { i8*, i32 }
+		cleanup
+	resume { i8*, i32 } %lp
+}`
+	if got := f.LLString(); got != want {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+	}
+	if err := m.Verify(); err != nil {
+		t.Errorf("unable to verify module; %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package ir
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Hoisting of literal struct types ] ====================================
+
+// structHoist records the literal struct types of a module hoisted into
+// numbered type definitions when writing the module. The hoisted literal struct
+// types are assigned their type names only for the duration of the write (see
+// nameTypes and unnameTypes), so that the type printer refers to them by name;
+// the module is left unchanged.
+type structHoist struct {
+	// Type names of hoisted literal struct types, in order of type definition.
+	names []string
+	// Hoisted literal struct types, in order of type definition; each is the
+	// list of structurally identical literal struct types of the module.
+	structs [][]*types.StructType
+	// Index into the type definitions of the module before which the hoisted
+	// type definitions are output.
+	pos int
+}
+
+// hoistStructTypes returns the literal struct types of the module used at least
+// minUses times, to be hoisted into numbered type definitions when writing the
+// module; or nil if no literal struct types are to be hoisted.
+//
+// Structurally identical literal struct types are hoisted into the same type
+// definition, as literal struct types are uniqued by structural identity. Uses
+// are counted per occurrence in the LLVM IR assembly of the module (e.g. the
+// type of each operand).
+func (m *Module) hoistStructTypes(minUses int) *structHoist {
+	h := &structHoister{
+		structs:  make(map[string][]*types.StructType),
+		uses:     make(map[string]int),
+		excluded: make(map[string]bool),
+		visited:  make(map[*types.StructType]bool),
+	}
+	h.collectModule(m)
+	// Assign type names, starting after the numbered type definitions of the
+	// module.
+	hoist := &structHoist{}
+	id := 0
+	for i, t := range m.TypeDefs {
+		if !isDecimal(t.Name()) {
+			continue
+		}
+		if n, err := strconv.Atoi(t.Name()); err == nil && n >= id {
+			id = n + 1
+		}
+		// Output hoisted type definitions after the numbered type definitions
+		// of the module, in accordance with the natural sorting order of type
+		// definitions used by the parser.
+		hoist.pos = i + 1
+	}
+	for _, key := range h.keys {
+		if h.uses[key] < minUses || h.excluded[key] {
+			continue
+		}
+		hoist.names = append(hoist.names, strconv.Itoa(id))
+		hoist.structs = append(hoist.structs, h.structs[key])
+		id++
+	}
+	if len(hoist.names) == 0 {
+		return nil
+	}
+	return hoist
+}
+
+// nameTypes assigns the type names of the hoisted literal struct types.
+func (hoist *structHoist) nameTypes() {
+	for i, ts := range hoist.structs {
+		for _, t := range ts {
+			t.TypeName = hoist.names[i]
+		}
+	}
+}
+
+// unnameTypes reverts the hoisted literal struct types to literal struct types.
+func (hoist *structHoist) unnameTypes() {
+	for _, ts := range hoist.structs {
+		for _, t := range ts {
+			t.TypeName = ""
+		}
+	}
+}
+
+// writeTypeDefs writes the hoisted type definitions to w. The type names of
+// the hoisted literal struct types must be assigned (see nameTypes).
+func (hoist *structHoist) writeTypeDefs(w io.Writer) {
+	for _, ts := range hoist.structs {
+		t := ts[0]
+		fmt.Fprintf(w, "%s = type %s\n", t, t.LLString())
+	}
+}
+
+// structHoister collects the literal struct types of a module.
+type structHoister struct {
+	// Literal struct types, keyed by their LLVM syntax representation.
+	structs map[string][]*types.StructType
+	// Keys of literal struct types in order of first use.
+	keys []string
+	// Number of uses of literal struct types, keyed by their LLVM syntax
+	// representation.
+	uses map[string]int
+	// Literal struct types which must not be hoisted, keyed by their LLVM syntax
+	// representation.
+	excluded map[string]bool
+	// Visited struct types.
+	visited map[*types.StructType]bool
+}
+
+// collectModule collects the literal struct types of the given module, in
+// order of first use in the LLVM IR assembly of the module. Constants are
+// collected along with the global variables, functions and instructions using
+// them, so that the assigned type names are independent of the order in which
+// the parts of the module are traversed.
+func (h *structHoister) collectModule(m *Module) {
+	w := &constWalker{fn: h.collectConst, visited: make(map[*metadata.Tuple]bool)}
+	for _, t := range m.TypeDefs {
+		h.collect(t, false)
+	}
+	for _, g := range m.Globals {
+		h.collect(g.Typ, false)
+		h.collect(g.ContentType, false)
+		if g.Init != nil {
+			w.walk(g.Init)
+		}
+	}
+	for _, alias := range m.Aliases {
+		h.collect(alias.Typ, false)
+		w.walk(alias.Aliasee)
+	}
+	for _, ifunc := range m.IFuncs {
+		h.collect(ifunc.Typ, false)
+		w.walk(ifunc.Resolver)
+	}
+	for _, f := range m.Funcs {
+		// LLVM requires the signatures of intrinsic functions to use literal
+		// struct types.
+		intrinsic := strings.HasPrefix(f.Name(), "llvm.")
+		h.collect(f.Typ, intrinsic)
+		for _, param := range f.Params {
+			h.collect(param.Typ, intrinsic)
+		}
+		for _, c := range []constant.Constant{f.Prefix, f.Prologue, f.Personality} {
+			if c != nil {
+				w.walk(c)
+			}
+		}
+		for _, block := range f.Blocks {
+			for _, inst := range block.Insts {
+				h.collectInst(inst)
+				w.walkOperands(inst.Operands())
+			}
+			if block.Term != nil {
+				if v, ok := block.Term.(value.Value); ok {
+					h.collect(v.Type(), false)
+				}
+				h.collectOperands(block.Term.Operands())
+				w.walkOperands(block.Term.Operands())
+				if term, ok := block.Term.(*TermSwitch); ok {
+					for _, c := range term.Cases {
+						w.walk(c.X)
+					}
+				}
+			}
+		}
+	}
+	for _, md := range m.MetadataDefs {
+		if tuple, ok := md.(*metadata.Tuple); ok {
+			w.walkTuple(tuple)
+		}
+	}
+}
+
+// collectConst collects the literal struct types of the given constant, and
+// returns the constant unchanged.
+func (h *structHoister) collectConst(c constant.Constant) constant.Constant {
+	h.collect(c.Type(), false)
+	if expr, ok := c.(*constant.ExprGetElementPtr); ok {
+		h.collect(expr.ElemType, false)
+	}
+	return c
+}
+
+// collectInst collects the literal struct types of the given instruction.
+func (h *structHoister) collectInst(inst Instruction) {
+	switch inst := inst.(type) {
+	case *InstAlloca:
+		h.collect(inst.ElemType, false)
+	case *InstGetElementPtr:
+		h.collect(inst.ElemType, false)
+	case *InstVAArg:
+		h.collect(inst.ArgType, false)
+	case *InstLandingPad:
+		h.collect(inst.ResultType, false)
+	case *InstCmpXchg:
+		// The result type of cmpxchg is implied by its operands, and must
+		// therefore remain a literal struct type.
+		h.collect(inst.Type(), true)
+	}
+	if v, ok := inst.(value.Value); ok {
+		h.collect(v.Type(), false)
+	}
+	h.collectOperands(inst.Operands())
+}
+
+// collectOperands collects the literal struct types of the given operands.
+func (h *structHoister) collectOperands(ops []*value.Value) {
+	for _, op := range ops {
+		if *op != nil {
+			h.collect((*op).Type(), false)
+		}
+	}
+}
+
+// collect collects the literal struct types of the given type. If exclude is
+// true, the literal struct types are excluded from hoisting.
+func (h *structHoister) collect(t types.Type, exclude bool) {
+	switch t := t.(type) {
+	case *types.PointerType:
+		h.collect(t.ElemType, exclude)
+	case *types.VectorType:
+		h.collect(t.ElemType, exclude)
+	case *types.ArrayType:
+		h.collect(t.ElemType, exclude)
+	case *types.FuncType:
+		h.collect(t.RetType, exclude)
+		for _, param := range t.Params {
+			h.collect(param, exclude)
+		}
+	case *types.TargetExtType:
+		for _, param := range t.TypeParams {
+			h.collect(param, exclude)
+		}
+	case *types.StructType:
+		if len(t.TypeName) > 0 {
+			// Identified struct type.
+			if h.visited[t] {
+				return
+			}
+			h.visited[t] = true
+		} else {
+			// Literal struct type.
+			key := t.LLString()
+			if _, ok := h.structs[key]; !ok {
+				h.keys = append(h.keys, key)
+			}
+			if !h.visited[t] {
+				h.visited[t] = true
+				h.structs[key] = append(h.structs[key], t)
+			}
+			h.uses[key]++
+			if exclude {
+				h.excluded[key] = true
+			}
+		}
+		for _, field := range t.Fields {
+			h.collect(field, exclude)
+		}
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isDecimal reports whether the given string consists only of decimal digits.
+func isDecimal(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
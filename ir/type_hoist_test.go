@@ -0,0 +1,203 @@
+package ir_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestHoistStructTypes(t *testing.T) {
+	const src = `%T = type { i64 }
+
+@g = global { i32, i8* } zeroinitializer
+
+declare { i32, i1 } @llvm.sadd.with.overflow.i32(i32, i32)
+
+define { i32, i8* } @f({ i32, i8* } %x, { i64 } %unique) {
+; <label>:0
+	%p = alloca { i32, i8* }
+	store { i32, i8* } %x, { i32, i8* }* %p
+	%y = load { i32, i8* }, { i32, i8* }* %p
+	%sum = call { i32, i1 } @llvm.sadd.with.overflow.i32(i32 1, i32 2)
+	%overflow = extractvalue { i32, i1 } %sum, 1
+	ret { i32, i8* } %y
+}
+`
+	const want = `%0 = type { i32, i8* }
+%T = type { i64 }
+
+@g = global %0 zeroinitializer
+
+declare { i32, i1 } @llvm.sadd.with.overflow.i32(i32, i32)
+
+define %0 @f(%0 %x, { i64 } %unique) {
+; <label>:0
+	%p = alloca %0
+	store %0 %x, %0* %p
+	%y = load %0, %0* %p
+	%sum = call { i32, i1 } @llvm.sadd.with.overflow.i32(i32 1, i32 2)
+	%overflow = extractvalue { i32, i1 } %sum, 1
+	ret %0 %y
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	buf := &strings.Builder{}
+	if _, err := m.WriteToWithOptions(buf, ir.HoistStructTypes(3)); err != nil {
+		t.Fatalf("unable to write module; %+v", err)
+	}
+	got := buf.String()
+	if got != want {
+		t.Errorf("hoisted module mismatch; expected `%s`, got `%s`", want, got)
+	}
+	// The module is left unchanged.
+	if s := m.String(); s != src {
+		t.Errorf("module changed by hoisting; expected `%s`, got `%s`", src, s)
+	}
+	// The hoisted module is re-parseable and equivalent.
+	m2, err := asm.ParseString("<stdin>", got)
+	if err != nil {
+		t.Fatalf("unable to parse hoisted module; %+v", err)
+	}
+	if s := m2.String(); s != want {
+		t.Errorf("re-parsed module mismatch; expected `%s`, got `%s`", want, s)
+	}
+}
+
+func TestHoistStructTypesDeterministic(t *testing.T) {
+	// The literal struct type { i16, i16 } is first used in the initializer of
+	// @size, and is therefore numbered before the literal struct types first
+	// used in the body of @f.
+	const src = `@size = global i64 ptrtoint ({ i16, i16 }* getelementptr ({ i16, i16 }, { i16, i16 }* null, i64 1) to i64)
+
+define void @f({ i8, i8 }* %p, { i16, i16 }* %q) {
+entry:
+	%x = alloca { i32, i32 }
+	%y = load { i8, i8 }, { i8, i8 }* %p
+	%z = load { i16, i16 }, { i16, i16 }* %q
+	ret void
+}
+`
+	const want = `%0 = type { i16, i16 }
+%1 = type { i8, i8 }
+%2 = type { i32, i32 }
+
+@size = global i64 ptrtoint (%0* getelementptr (%0, %0* null, i64 1) to i64)
+
+define void @f(%1* %p, %0* %q) {
+entry:
+	%x = alloca %2
+	%y = load %1, %1* %p
+	%z = load %0, %0* %q
+	ret void
+}
+`
+	// Emit the module twice, and from two separately parsed copies.
+	var outputs []string
+	for i := 0; i < 2; i++ {
+		m, err := asm.ParseString("<stdin>", src)
+		if err != nil {
+			t.Fatalf("unable to parse module; %+v", err)
+		}
+		for j := 0; j < 2; j++ {
+			buf := &strings.Builder{}
+			if _, err := m.WriteToWithOptions(buf, ir.HoistStructTypes(1)); err != nil {
+				t.Fatalf("unable to write module; %+v", err)
+			}
+			outputs = append(outputs, buf.String())
+		}
+	}
+	for _, got := range outputs {
+		if got != want {
+			t.Errorf("hoisted module mismatch; expected `%s`, got `%s`", want, got)
+		}
+	}
+}
+
+func TestHoistStructTypesNested(t *testing.T) {
+	// Literal struct types nested within hoisted literal struct types and type
+	// definitions are replaced; string literals are left unchanged.
+	const src = `%T = type { i32, { i8 } }
+
+@s = global [9 x i8] c"{ i8 }*\00\00"
+@g = global { { i8 }, i64 } zeroinitializer
+
+define void @f(%T* %p, { i8 }* %q, { { i8 }, i64 }* %r) {
+entry:
+	%x = load { i8 }, { i8 }* %q
+	ret void
+}
+`
+	const want = `%0 = type { i8 }
+%1 = type { %0, i64 }
+%T = type { i32, %0 }
+
+@s = global [9 x i8] c"{ i8 }*\00\00"
+@g = global %1 zeroinitializer
+
+define void @f(%T* %p, %0* %q, %1* %r) {
+entry:
+	%x = load %0, %0* %q
+	ret void
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	buf := &strings.Builder{}
+	if _, err := m.WriteToWithOptions(buf, ir.HoistStructTypes(1)); err != nil {
+		t.Fatalf("unable to write module; %+v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("hoisted module mismatch; expected `%s`, got `%s`", want, got)
+	}
+	if s := m.String(); s != src {
+		t.Errorf("module changed by hoisting; expected `%s`, got `%s`", src, s)
+	}
+}
+
+func TestHoistStructTypesConstants(t *testing.T) {
+	// Struct constants are left unchanged; only their types are replaced.
+	const src = `@g = global {} {}
+@h = global { {}, i32 } { {} {}, i32 1 }
+@z = global { {}, i32 } zeroinitializer
+@e = global <{}> <{}>
+`
+	const want = `%0 = type {}
+%1 = type { %0, i32 }
+%2 = type <{}>
+
+@g = global %0 {}
+@h = global %1 { %0 {}, i32 1 }
+@z = global %1 zeroinitializer
+@e = global %2 <{}>
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	buf := &strings.Builder{}
+	if _, err := m.WriteToWithOptions(buf, ir.HoistStructTypes(1)); err != nil {
+		t.Fatalf("unable to write module; %+v", err)
+	}
+	got := buf.String()
+	if got != want {
+		t.Errorf("hoisted module mismatch; expected `%s`, got `%s`", want, got)
+	}
+	if s := m.String(); s != src {
+		t.Errorf("module changed by hoisting; expected `%s`, got `%s`", src, s)
+	}
+	// The hoisted module is re-parseable and equivalent.
+	m2, err := asm.ParseString("<stdin>", got)
+	if err != nil {
+		t.Fatalf("unable to parse hoisted module; %+v", err)
+	}
+	if s := m2.String(); s != want {
+		t.Errorf("re-parsed module mismatch; expected `%s`, got `%s`", want, s)
+	}
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+)
+
+// Hash returns a deterministic hash of the given type, consistent with Equal;
+// i.e. equal types have equal hashes. Hash is intended for use as a map key,
+// e.g. for caching per-type computations, with types of equal hash compared
+// using Equal to resolve collisions.
+//
+// Identified (named) struct types are hashed by type name, and literal struct
+// types by structure; as such, recursive struct types are hashed without
+// looping. Pointer types are hashed by their string representation, as used
+// by Equal.
+func Hash(t Type) uint64 {
+	h := &typeHasher{h: fnv.New64a()}
+	h.hashType(t)
+	return h.h.Sum64()
+}
+
+// typeHasher is a recursive type hasher.
+type typeHasher struct {
+	// Underlying hash function.
+	h hash.Hash64
+	// Scratch buffer used to encode integers.
+	buf [binary.MaxVarintLen64]byte
+}
+
+// Type kind tags, written before the constituents of each type.
+const (
+	hashVoid byte = iota
+	hashFunc
+	hashInt
+	hashFloat
+	hashMMX
+	hashPointer
+	hashVector
+	hashLabel
+	hashToken
+	hashMetadata
+	hashArray
+	hashNamedStruct
+	hashStruct
+	hashTargetExt
+)
+
+// hashType writes the hash of t to the underlying hash function.
+func (h *typeHasher) hashType(t Type) {
+	switch t := t.(type) {
+	case *VoidType:
+		h.writeByte(hashVoid)
+	case *FuncType:
+		h.writeByte(hashFunc)
+		h.hashType(t.RetType)
+		h.writeUint(uint64(len(t.Params)))
+		for _, param := range t.Params {
+			h.hashType(param)
+		}
+		h.writeBool(t.Variadic)
+	case *IntType:
+		h.writeByte(hashInt)
+		h.writeUint(t.BitSize)
+	case *FloatType:
+		h.writeByte(hashFloat)
+		h.writeUint(uint64(t.Kind))
+	case *MMXType:
+		h.writeByte(hashMMX)
+	case *PointerType:
+		// Pointer types are compared by string representation; see
+		// PointerType.Equal.
+		h.writeByte(hashPointer)
+		h.writeString(t.String())
+	case *VectorType:
+		h.writeByte(hashVector)
+		h.writeUint(t.Len)
+		h.writeBool(t.Scalable)
+		h.hashType(t.ElemType)
+	case *LabelType:
+		h.writeByte(hashLabel)
+	case *TokenType:
+		h.writeByte(hashToken)
+	case *MetadataType:
+		h.writeByte(hashMetadata)
+	case *ArrayType:
+		h.writeByte(hashArray)
+		h.writeUint(t.Len)
+		h.hashType(t.ElemType)
+	case *StructType:
+		if len(t.TypeName) > 0 {
+			// Identified struct types are uniqued by type names.
+			h.writeByte(hashNamedStruct)
+			h.writeString(t.TypeName)
+			return
+		}
+		h.writeByte(hashStruct)
+		h.writeBool(t.Packed)
+		h.writeUint(uint64(len(t.Fields)))
+		for _, field := range t.Fields {
+			h.hashType(field)
+		}
+	case *TargetExtType:
+		h.writeByte(hashTargetExt)
+		h.writeString(t.ExtName)
+		h.writeUint(uint64(len(t.TypeParams)))
+		for _, param := range t.TypeParams {
+			h.hashType(param)
+		}
+		h.writeUint(uint64(len(t.IntParams)))
+		for _, param := range t.IntParams {
+			h.writeUint(param)
+		}
+	default:
+		panic(fmt.Errorf("support for type %T not yet implemented", t))
+	}
+}
+
+// writeByte writes b to the underlying hash function.
+func (h *typeHasher) writeByte(b byte) {
+	h.h.Write([]byte{b})
+}
+
+// writeBool writes b to the underlying hash function.
+func (h *typeHasher) writeBool(b bool) {
+	if b {
+		h.writeByte(1)
+	} else {
+		h.writeByte(0)
+	}
+}
+
+// writeUint writes x to the underlying hash function.
+func (h *typeHasher) writeUint(x uint64) {
+	n := binary.PutUvarint(h.buf[:], x)
+	h.h.Write(h.buf[:n])
+}
+
+// writeString writes s, prefixed by its length, to the underlying hash
+// function.
+func (h *typeHasher) writeString(s string) {
+	h.writeUint(uint64(len(s)))
+	h.h.Write([]byte(s))
+}
@@ -0,0 +1,227 @@
+package types
+
+// Map returns the type obtained by recursively applying fn to t and its
+// constituent types; i.e. pointer element types, array and vector element
+// types, struct field types, function return and parameter types, and target
+// extension type parameters.
+//
+// Types are rewritten bottom-up; fn is applied to the constituent types of a
+// type before it is applied to the type itself, which has been updated to
+// reference the rewritten constituent types. Types whose constituent types are
+// unchanged are not copied, and fn should return its argument to leave a type
+// unchanged. fn is applied exactly once to each type reachable from t.
+//
+// Recursive struct types are rewritten without looping; the types of a cycle
+// (e.g. %list and %list* of `%list = type { i32, %list* }`) are passed to fn
+// with references to types of the same cycle left unchanged. If any type of
+// the cycle is rewritten, every type of the cycle is copied, and references
+// within the cycle are updated to refer to the copies. Type definitions of
+// modules are not updated; use the returned types to update them.
+func Map(t Type, fn func(Type) Type) Type {
+	m := &typeMapper{
+		fn:      fn,
+		done:    make(map[Type]Type),
+		index:   make(map[Type]int),
+		lowlink: make(map[Type]int),
+		onStack: make(map[Type]bool),
+	}
+	m.visit(t)
+	return m.done[t]
+}
+
+// typeMapper is a recursive type mapper.
+//
+// Types are visited in depth-first order to locate the strongly connected
+// components (i.e. cycles) of the type graph using Tarjan's algorithm, and each
+// strongly connected component is rewritten once its constituent types outside
+// of the component have been rewritten.
+type typeMapper struct {
+	// Map function; applied bottom-up.
+	fn func(Type) Type
+	// done maps from original type to rewritten type.
+	done map[Type]Type
+	// Depth-first visitation index of types.
+	index map[Type]int
+	// Smallest index of types reachable from each type on the stack.
+	lowlink map[Type]int
+	// Stack of visited types not yet part of a strongly connected component.
+	stack []Type
+	// Types on the stack.
+	onStack map[Type]bool
+}
+
+// visit visits t and its constituent types, and rewrites the strongly
+// connected components of types rooted at t.
+func (m *typeMapper) visit(t Type) {
+	if _, ok := m.index[t]; ok {
+		return
+	}
+	m.index[t] = len(m.index)
+	m.lowlink[t] = m.index[t]
+	m.stack = append(m.stack, t)
+	m.onStack[t] = true
+	for _, c := range constituents(t) {
+		if _, ok := m.index[c]; !ok {
+			m.visit(c)
+			if m.lowlink[c] < m.lowlink[t] {
+				m.lowlink[t] = m.lowlink[c]
+			}
+		} else if m.onStack[c] && m.index[c] < m.lowlink[t] {
+			m.lowlink[t] = m.index[c]
+		}
+	}
+	if m.lowlink[t] != m.index[t] {
+		return
+	}
+	// t is the root of a strongly connected component.
+	var scc []Type
+	for {
+		u := m.stack[len(m.stack)-1]
+		m.stack = m.stack[:len(m.stack)-1]
+		m.onStack[u] = false
+		scc = append(scc, u)
+		if u == t {
+			break
+		}
+	}
+	m.mapSCC(scc)
+}
+
+// mapSCC rewrites the types of the given strongly connected component, the
+// constituent types of which outside of the component have been rewritten.
+func (m *typeMapper) mapSCC(scc []Type) {
+	inSCC := make(map[Type]bool)
+	for _, t := range scc {
+		inSCC[t] = true
+	}
+	// Apply fn once to each type, with references to types of the component
+	// left unchanged.
+	results := make([]Type, len(scc))
+	// copies records the types copied by the mapper, which may be updated.
+	copies := make(map[Type]bool)
+	changed := false
+	for i, t := range scc {
+		cs := constituents(t)
+		us := make([]Type, len(cs))
+		diff := false
+		for j, c := range cs {
+			us[j] = c
+			if !inSCC[c] {
+				us[j] = m.done[c]
+				if us[j] != c {
+					diff = true
+				}
+			}
+		}
+		u := t
+		if diff {
+			u = withConstituents(t, us)
+			copies[u] = true
+		}
+		results[i] = m.fn(u)
+		if results[i] != t {
+			changed = true
+		}
+	}
+	if !changed {
+		for _, t := range scc {
+			m.done[t] = t
+		}
+		return
+	}
+	// Copy every type of the component, as each type of the component
+	// (transitively) references the rewritten types.
+	for i, t := range scc {
+		u := results[i]
+		if u == t && len(constituents(t)) > 0 {
+			u = withConstituents(t, constituents(t))
+			copies[u] = true
+		}
+		m.done[t] = u
+	}
+	// Update references within the component to refer to the copies.
+	for _, t := range scc {
+		u := m.done[t]
+		if !copies[u] {
+			// Replaced by fn.
+			continue
+		}
+		cs := constituents(u)
+		for j, c := range cs {
+			if inSCC[c] {
+				cs[j] = m.done[c]
+			}
+		}
+		setConstituents(u, cs)
+	}
+}
+
+// ### [ Helper functions ] ####################################################
+
+// constituents returns the constituent types of t, in a newly allocated slice.
+func constituents(t Type) []Type {
+	switch t := t.(type) {
+	case *FuncType:
+		return append([]Type{t.RetType}, t.Params...)
+	case *PointerType:
+		return []Type{t.ElemType}
+	case *VectorType:
+		return []Type{t.ElemType}
+	case *ArrayType:
+		return []Type{t.ElemType}
+	case *StructType:
+		return append([]Type(nil), t.Fields...)
+	case *TargetExtType:
+		return append([]Type(nil), t.TypeParams...)
+	}
+	// Types without constituent types.
+	return nil
+}
+
+// withConstituents returns a copy of t with the given constituent types.
+func withConstituents(t Type, cs []Type) Type {
+	var u Type
+	switch t := t.(type) {
+	case *FuncType:
+		v := *t
+		u = &v
+	case *PointerType:
+		v := *t
+		u = &v
+	case *VectorType:
+		v := *t
+		u = &v
+	case *ArrayType:
+		v := *t
+		u = &v
+	case *StructType:
+		v := *t
+		u = &v
+	case *TargetExtType:
+		v := *t
+		u = &v
+	default:
+		return t
+	}
+	setConstituents(u, append([]Type(nil), cs...))
+	return u
+}
+
+// setConstituents sets the constituent types of t to cs.
+func setConstituents(t Type, cs []Type) {
+	switch t := t.(type) {
+	case *FuncType:
+		t.RetType = cs[0]
+		t.Params = cs[1:]
+	case *PointerType:
+		t.ElemType = cs[0]
+	case *VectorType:
+		t.ElemType = cs[0]
+	case *ArrayType:
+		t.ElemType = cs[0]
+	case *StructType:
+		t.Fields = cs
+	case *TargetExtType:
+		t.TypeParams = cs
+	}
+}
@@ -32,6 +32,7 @@ var (
 	X86_FP80  = &FloatType{Kind: FloatKindX86_FP80}  // x86_fp80
 	FP128     = &FloatType{Kind: FloatKindFP128}     // fp128
 	PPC_FP128 = &FloatType{Kind: FloatKindPPC_FP128} // ppc_fp128
+	BFloat    = &FloatType{Kind: FloatKindBFloat}    // bfloat
 	// Integer pointer types.
 	I1Ptr   = &PointerType{ElemType: I1}   // i1*
 	I8Ptr   = &PointerType{ElemType: I8}   // i8*
@@ -115,6 +116,12 @@ func IsStruct(t Type) bool {
 	return ok
 }
 
+// IsTargetExt reports whether the given type is a target extension type.
+func IsTargetExt(t Type) bool {
+	_, ok := t.(*TargetExtType)
+	return ok
+}
+
 // Equal reports whether t and u are of equal type.
 func Equal(t, u Type) bool {
 	return t.Equal(u)
@@ -136,6 +143,7 @@ func Equal(t, u Type) bool {
 //    *types.MetadataType   // https://godoc.org/github.com/llir/llvm/ir/types#MetadataType
 //    *types.ArrayType      // https://godoc.org/github.com/llir/llvm/ir/types#ArrayType
 //    *types.StructType     // https://godoc.org/github.com/llir/llvm/ir/types#StructType
+//    *types.TargetExtType  // https://godoc.org/github.com/llir/llvm/ir/types#TargetExtType
 type Type interface {
 	fmt.Stringer
 	// LLString returns the LLVM syntax representation of the definition of the
@@ -384,6 +392,8 @@ const (
 	FloatKindX86_FP80 // x86_fp80
 	// 128-bit floating point type (IBM extended double).
 	FloatKindPPC_FP128 // ppc_fp128
+	// 16-bit floating-point type (brain floating-point format).
+	FloatKindBFloat // bfloat
 )
 
 // --- [ MMX types ] -----------------------------------------------------------
@@ -499,10 +509,13 @@ func (a AddrSpace) String() string {
 type VectorType struct {
 	// Type name; or empty if not present.
 	TypeName string
-	// Vector length.
+	// Vector length; the minimum vector length of scalable vectors.
 	Len uint64
 	// Element type.
 	ElemType Type
+	// Scalable vector type; the vector length is a runtime multiple (vscale) of
+	// Len.
+	Scalable bool
 }
 
 // NewVector returns a new vector type based on the given vector length and
@@ -517,7 +530,7 @@ func NewVector(len uint64, elemType Type) *VectorType {
 // Equal reports whether t and u are of equal type.
 func (t *VectorType) Equal(u Type) bool {
 	if u, ok := u.(*VectorType); ok {
-		if t.Len != u.Len {
+		if t.Len != u.Len || t.Scalable != u.Scalable {
 			return false
 		}
 		return t.ElemType.Equal(u.ElemType)
@@ -537,6 +550,10 @@ func (t *VectorType) String() string {
 // type.
 func (t *VectorType) LLString() string {
 	// '<' Len=UintLit 'x' Elem=Type '>'
+	// '<' 'vscale' 'x' Len=UintLit 'x' Elem=Type '>'
+	if t.Scalable {
+		return fmt.Sprintf("<vscale x %d x %s>", t.Len, t.ElemType)
+	}
 	return fmt.Sprintf("<%d x %s>", t.Len, t.ElemType)
 }
 
@@ -837,3 +854,87 @@ func (t *StructType) Name() string {
 func (t *StructType) SetName(name string) {
 	t.TypeName = name
 }
+
+// --- [ Target extension types ] ----------------------------------------------
+
+// TargetExtType is an LLVM IR target extension type; an opaque type with
+// target-specific semantics (e.g. `target("spirv.Image", void, 1)`).
+type TargetExtType struct {
+	// Type name; or empty if not present.
+	TypeName string
+	// Target extension type name (e.g. "spirv.Image").
+	ExtName string
+	// Type parameters.
+	TypeParams []Type
+	// Integer parameters.
+	IntParams []uint64
+}
+
+// NewTargetExt returns a new target extension type based on the given target
+// extension type name, type parameters and integer parameters.
+func NewTargetExt(extName string, typeParams []Type, intParams []uint64) *TargetExtType {
+	return &TargetExtType{
+		ExtName:    extName,
+		TypeParams: typeParams,
+		IntParams:  intParams,
+	}
+}
+
+// Equal reports whether t and u are of equal type.
+func (t *TargetExtType) Equal(u Type) bool {
+	if u, ok := u.(*TargetExtType); ok {
+		if t.ExtName != u.ExtName {
+			return false
+		}
+		if len(t.TypeParams) != len(u.TypeParams) || len(t.IntParams) != len(u.IntParams) {
+			return false
+		}
+		for i := range t.TypeParams {
+			if !t.TypeParams[i].Equal(u.TypeParams[i]) {
+				return false
+			}
+		}
+		for i := range t.IntParams {
+			if t.IntParams[i] != u.IntParams[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// String returns the string representation of the target extension type.
+func (t *TargetExtType) String() string {
+	if len(t.TypeName) > 0 {
+		return enc.Local(t.TypeName)
+	}
+	return t.LLString()
+}
+
+// LLString returns the LLVM syntax representation of the definition of the
+// type.
+func (t *TargetExtType) LLString() string {
+	// 'target' '(' ExtName=StringLit TypeParams=(',' Type)* IntParams=(','
+	// UintLit)* ')'
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "target(%s", enc.Quote([]byte(t.ExtName)))
+	for _, param := range t.TypeParams {
+		fmt.Fprintf(buf, ", %s", param)
+	}
+	for _, param := range t.IntParams {
+		fmt.Fprintf(buf, ", %d", param)
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// Name returns the type name of the type.
+func (t *TargetExtType) Name() string {
+	return t.TypeName
+}
+
+// SetName sets the type name of the type.
+func (t *TargetExtType) SetName(name string) {
+	t.TypeName = name
+}
@@ -1,6 +1,9 @@
 package types
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestIntTypeEqual(t *testing.T) {
 	golden := []struct {
@@ -302,6 +305,10 @@ func TestEqual(t *testing.T) {
 		{t: NewArray(5, I8), u: &ArrayType{Len: 5, ElemType: I8}, want: true},
 		{t: NewArray(5, I8), u: NewArray(3, I8), want: false},
 		{t: NewArray(5, I8), u: I8, want: false},
+		{t: NewTargetExt("spirv.Image", []Type{Void}, []uint64{1}), u: &TargetExtType{ExtName: "spirv.Image", TypeParams: []Type{Void}, IntParams: []uint64{1}}, want: true},
+		{t: NewTargetExt("spirv.Image", []Type{Void}, []uint64{1}), u: NewTargetExt("spirv.Image", []Type{Void}, []uint64{0}), want: false},
+		{t: NewTargetExt("spirv.Image", []Type{Void}, nil), u: NewTargetExt("spirv.Event", []Type{Void}, nil), want: false},
+		{t: NewTargetExt("spirv.Event", nil, nil), u: I8, want: false},
 	}
 	for _, g := range golden {
 		got := Equal(g.t, g.u)
@@ -394,4 +401,156 @@ var (
 	_ Type = (*MetadataType)(nil)
 	_ Type = (*ArrayType)(nil)
 	_ Type = (*StructType)(nil)
+	_ Type = (*TargetExtType)(nil)
 )
+
+func TestMap(t *testing.T) {
+	// Replace i1 with i8.
+	fn := func(t Type) Type {
+		if t.Equal(I1) {
+			return I8
+		}
+		return t
+	}
+	// {i32, [4 x {i1, i1*}]}
+	inner := NewStruct(I1, NewPointer(I1))
+	outer := NewStruct(I32, NewArray(4, inner))
+	got := Map(outer, fn)
+	const want = "{ i32, [4 x { i8, i8* }] }"
+	if got.String() != want {
+		t.Errorf("type mismatch; expected %q, got %q", want, got)
+	}
+	if outer.String() != "{ i32, [4 x { i1, i1* }] }" {
+		t.Errorf("original type modified; got %q", outer)
+	}
+	// Unchanged types are not copied.
+	if u := Map(outer, func(t Type) Type { return t }); u != outer {
+		t.Errorf("unchanged type copied")
+	}
+	// %list = type { i1, %list* }
+	list := &StructType{TypeName: "list"}
+	list.Fields = []Type{I1, NewPointer(list)}
+	u, ok := Map(list, fn).(*StructType)
+	if !ok {
+		t.Fatalf("type mismatch; expected *StructType, got %T", u)
+	}
+	const wantList = "{ i8, %list* }"
+	if got := u.LLString(); got != wantList {
+		t.Errorf("type mismatch; expected %q, got %q", wantList, got)
+	}
+	if elem := u.Fields[1].(*PointerType).ElemType; elem != u {
+		t.Errorf("recursive reference mismatch; expected %p, got %p", u, elem)
+	}
+	// Unchanged recursive types are not copied.
+	if u := Map(list, func(t Type) Type { return t }); u != list {
+		t.Errorf("unchanged recursive type copied")
+	}
+	// Deeply nested named struct types; fn is applied once per type.
+	//
+	//    %s0 = type { i1 }
+	//    %s1 = type { %s0, %s0*, %s1* }
+	//    ...
+	const depth = 64
+	var nested Type = &StructType{TypeName: "s0", Fields: []Type{I1}}
+	for i := 1; i <= depth; i++ {
+		s := &StructType{TypeName: fmt.Sprintf("s%d", i)}
+		s.Fields = []Type{nested, NewPointer(nested), NewPointer(s)}
+		nested = s
+	}
+	calls := make(map[Type]int)
+	counter := func(t Type) Type {
+		calls[t]++
+		return fn(t)
+	}
+	got2, ok := Map(nested, counter).(*StructType)
+	if !ok || got2 == nested {
+		t.Fatalf("nested type not rewritten; got %v", got2)
+	}
+	if elem := got2.Fields[2].(*PointerType).ElemType; elem != got2 {
+		t.Errorf("recursive reference mismatch; expected %p, got %p", got2, elem)
+	}
+	if elem := got2.Fields[1].(*PointerType).ElemType; elem != got2.Fields[0] {
+		t.Errorf("nested reference mismatch; expected %p, got %p", got2.Fields[0], elem)
+	}
+	// i1, %s0, and for each %sN; %sN, %sN-1* and %sN*.
+	if want := 2 + 3*depth; len(calls) != want {
+		t.Errorf("number of types mismatch; expected %d, got %d", want, len(calls))
+	}
+	for t2, n := range calls {
+		if n != 1 {
+			t.Errorf("fn applied %d times to %s; expected once", n, t2)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	// %list = type { i32, %list* }
+	list := &StructType{TypeName: "list"}
+	list.Fields = []Type{I32, NewPointer(list)}
+	// Equal types have equal hashes.
+	equal := []struct {
+		t Type
+		u Type
+	}{
+		{t: I32, u: NewInt(32)},
+		{t: &IntType{TypeName: "foo", BitSize: 32}, u: I32},
+		{t: NewFunc(Void, I32), u: NewFunc(Void, NewInt(32))},
+		{t: NewPointer(I8), u: &PointerType{ElemType: I8}},
+		{t: NewVector(4, Float), u: &VectorType{Len: 4, ElemType: Float}},
+		{t: NewArray(4, NewStruct(I8, Double)), u: NewArray(4, NewStruct(I8, Double))},
+		{t: list, u: &StructType{TypeName: "list"}},
+		{t: NewStruct(I32, NewPointer(list)), u: NewStruct(I32, NewPointer(list))},
+		{t: NewTargetExt("spirv.Image", []Type{Void}, []uint64{1}), u: NewTargetExt("spirv.Image", []Type{Void}, []uint64{1})},
+	}
+	for _, g := range equal {
+		if !Equal(g.t, g.u) {
+			t.Errorf("expected `%s` and `%s` to be equal", g.t.LLString(), g.u.LLString())
+			continue
+		}
+		if Hash(g.t) != Hash(g.u) {
+			t.Errorf("hash mismatch between equal types `%s` and `%s`", g.t.LLString(), g.u.LLString())
+		}
+	}
+	// Distinct types have distinct hashes.
+	distinct := []Type{
+		Void,
+		NewFunc(Void),
+		NewFunc(Void, I32),
+		&FuncType{RetType: Void, Params: []Type{I32}, Variadic: true},
+		I1,
+		I8,
+		I32,
+		Float,
+		Double,
+		MMX,
+		NewPointer(I8),
+		NewPointer(I32),
+		&PointerType{ElemType: I8, AddrSpace: 1},
+		NewVector(4, I32),
+		NewVector(8, I32),
+		&VectorType{Len: 4, ElemType: I32, Scalable: true},
+		Label,
+		Token,
+		Metadata,
+		NewArray(4, I32),
+		NewArray(4, I8),
+		NewStruct(),
+		NewStruct(I32),
+		NewStruct(I32, I32),
+		&StructType{Packed: true, Fields: []Type{I32}},
+		list,
+		&StructType{TypeName: "foo"},
+		NewTargetExt("spirv.Event", nil, nil),
+		NewTargetExt("spirv.Image", []Type{Void}, []uint64{1}),
+		NewTargetExt("spirv.Image", []Type{Void}, []uint64{0}),
+	}
+	hashes := make(map[uint64]Type)
+	for _, typ := range distinct {
+		h := Hash(typ)
+		if prev, ok := hashes[h]; ok {
+			t.Errorf("hash collision between distinct types `%s` and `%s`", prev.LLString(), typ.LLString())
+			continue
+		}
+		hashes[h] = typ
+	}
+}
@@ -0,0 +1,50 @@
+package value
+
+//go:generate stringer -type Kind -trimprefix Kind
+
+// Kind is the kind of an LLVM IR value.
+type Kind uint8
+
+// Value kinds.
+const (
+	// Values of unknown kind (e.g. nil or values of user-defined types).
+	KindUnknown Kind = iota
+	// Simple constants.
+	KindIntConstant     // *constant.Int
+	KindFloatConstant   // *constant.Float
+	KindNullConstant    // *constant.Null
+	KindNoneToken       // *constant.NoneToken
+	KindStructConstant  // *constant.Struct
+	KindArrayConstant   // *constant.Array, *constant.CharArray
+	KindVectorConstant  // *constant.Vector
+	KindZeroInitializer // *constant.ZeroInitializer
+	KindUndef           // *constant.Undef
+	KindPoison          // *constant.Poison
+	KindBlockAddress    // *constant.BlockAddress
+	KindConstantExpr    // constant.Expression
+	// Global values.
+	KindGlobalVar  // *ir.Global
+	KindGlobalFunc // *ir.Func
+	KindAlias      // *ir.Alias
+	KindIFunc      // *ir.IFunc
+	// Local values.
+	KindParam       // *ir.Param
+	KindBlock       // *ir.Block
+	KindInstruction // ir.Instruction
+	KindTerminator  // *ir.TermInvoke, *ir.TermCallBr, *ir.TermCatchSwitch
+	// Other values.
+	KindInlineAsm // *ir.InlineAsm
+	KindMetadata  // *metadata.Value
+)
+
+// KindOf returns the kind of the given value, as specified by the ValueKind
+// method of the value. KindOf returns KindUnknown for nil values and values
+// without a ValueKind method.
+func KindOf(v Value) Kind {
+	if v, ok := v.(interface {
+		ValueKind() Kind
+	}); ok {
+		return v.ValueKind()
+	}
+	return KindUnknown
+}
@@ -36,6 +36,7 @@ type Value interface {
 //    TODO: add named metadata value?
 //    ir.Instruction        // https://godoc.org/github.com/llir/llvm/ir#Instruction (except store and fence)
 //    *ir.TermInvoke        // https://godoc.org/github.com/llir/llvm/ir#TermInvoke
+//    *ir.TermCallBr        // https://godoc.org/github.com/llir/llvm/ir#TermCallBr
 //    *ir.TermCatchSwitch   // https://godoc.org/github.com/llir/llvm/ir#TermCatchSwitch (token result used by catchpad)
 type Named interface {
 	Value
@@ -44,3 +45,14 @@ type Named interface {
 	// SetName sets the name of the value.
 	SetName(name string)
 }
+
+// Ident returns the identifier of the given value, as printed when the value is
+// used as an operand; e.g. "%5", "@g", "42" or "{ i32 1, i32 2 }". The
+// identifiers of unnamed local values are only valid after local IDs have been
+// assigned (see ir.Func.AssignIDs). Ident returns "<nil>" for a nil value.
+func Ident(v Value) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return v.Ident()
+}
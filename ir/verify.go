@@ -0,0 +1,529 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+// === [ Verifier ] ============================================================
+
+// Verify verifies the module, and returns the verification errors encountered,
+// if any. The returned error is of type VerifyErrors.
+func (m *Module) Verify() error {
+	var errs VerifyErrors
+	for _, f := range m.Funcs {
+		// Skip function declarations.
+		if len(f.Blocks) == 0 {
+			continue
+		}
+		for _, check := range funcChecks {
+			if err := check(f); err != nil {
+				errs = appendErr(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// funcChecks specifies the verification checks run on each function definition
+// by (*Module).Verify.
+var funcChecks = []func(f *Func) error{
+	CheckTerminators,
+	CheckEntryBlock,
+	CheckReturns,
+	CheckCallBr,
+	CheckMustTail,
+}
+
+// VerifyErrors is a list of verification errors.
+type VerifyErrors []error
+
+// Error returns the error message of the verification errors, one per line.
+func (errs VerifyErrors) Error() string {
+	var ss []string
+	for _, err := range errs {
+		ss = append(ss, err.Error())
+	}
+	return strings.Join(ss, "\n")
+}
+
+// --- [ Terminators ] ---------------------------------------------------------
+
+// CheckTerminators verifies that each basic block of the given function ends
+// with a terminator.
+//
+// Terminators are stored separately from the instructions of basic blocks, and
+// are always output last; thus, a basic block may not contain a terminator in
+// a non-final position.
+func CheckTerminators(f *Func) error {
+	var errs VerifyErrors
+	for _, block := range f.Blocks {
+		if block.Term == nil {
+			errs = append(errs, errors.Errorf("missing terminator in function %s; in block %s", f.Ident(), block.Ident()))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// --- [ Entry block ] --------------------------------------------------------
+
+// CheckEntryBlock verifies that the entry basic block of the given function is
+// not the target of any terminator, as LLVM requires the entry basic block to
+// have no predecessors.
+func CheckEntryBlock(f *Func) error {
+	entry := f.Entry()
+	if entry == nil {
+		return nil
+	}
+	var errs VerifyErrors
+	for _, block := range f.Blocks {
+		for _, succ := range succs(block) {
+			if succ == entry {
+				errs = append(errs, errors.Errorf("branch to entry block %s in function %s; in block %s", entry.Ident(), f.Ident(), block.Ident()))
+				break
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// --- [ Return ] --------------------------------------------------------------
+
+// CheckReturns verifies that the return values of ret terminators in the given
+// function match the return type of the function.
+func CheckReturns(f *Func) error {
+	var errs VerifyErrors
+	retType := f.Sig.RetType
+	for _, block := range f.Blocks {
+		term, ok := block.Term.(*TermRet)
+		if !ok {
+			continue
+		}
+		switch {
+		case term.X == nil:
+			if !types.IsVoid(retType) {
+				errs = append(errs, errors.Errorf("invalid void return in function %s with return type %v; in block %s", f.Ident(), retType, block.Ident()))
+			}
+		case !types.Equal(term.X.Type(), retType):
+			errs = append(errs, errors.Errorf("return value type mismatch in function %s; expected %v, got %v; in block %s", f.Ident(), retType, term.X.Type(), block.Ident()))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// --- [ callbr ] --------------------------------------------------------------
+
+// CheckCallBr verifies that callbr terminators in the given function have a
+// default (fallthrough) destination, and that their default and indirect
+// destinations are basic blocks of the function.
+func CheckCallBr(f *Func) error {
+	var errs VerifyErrors
+	blocks := make(map[*Block]bool)
+	for _, block := range f.Blocks {
+		blocks[block] = true
+	}
+	for _, block := range f.Blocks {
+		term, ok := block.Term.(*TermCallBr)
+		if !ok {
+			continue
+		}
+		switch {
+		case term.Normal == nil:
+			errs = append(errs, errors.Errorf("missing default destination of callbr terminator in function %s; in block %s", f.Ident(), block.Ident()))
+		case !blocks[term.Normal]:
+			errs = append(errs, errors.Errorf("invalid default destination %s of callbr terminator in function %s; not a basic block of the function; in block %s", term.Normal.Ident(), f.Ident(), block.Ident()))
+		}
+		for i, other := range term.Others {
+			switch {
+			case other == nil:
+				errs = append(errs, errors.Errorf("missing indirect destination %d of callbr terminator in function %s; in block %s", i, f.Ident(), block.Ident()))
+			case !blocks[other]:
+				errs = append(errs, errors.Errorf("invalid indirect destination %s of callbr terminator in function %s; not a basic block of the function; in block %s", other.Ident(), f.Ident(), block.Ident()))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// --- [ musttail ] ------------------------------------------------------------
+
+// CheckMustTail verifies that musttail calls in the given function are
+// immediately followed by a ret terminator (with an optional bitcast in
+// between) which returns the result of the call, and that the signature and
+// calling convention of the callee match those of the caller.
+func CheckMustTail(f *Func) error {
+	var errs VerifyErrors
+	for _, block := range f.Blocks {
+		for i, inst := range block.Insts {
+			call, ok := inst.(*InstCall)
+			if !ok || call.Tail != enum.TailMustTail {
+				continue
+			}
+			if err := checkMustTail(f, block, call, block.Insts[i+1:]); err != nil {
+				errs = append(errs, errors.Errorf("%v; musttail call %s in function %s; in block %s", err, call.LLString(), f.Ident(), block.Ident()))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkMustTail verifies the given musttail call of block, which is followed
+// by the instructions rest. Debug records in rest are ignored.
+func checkMustTail(f *Func, block *Block, call *InstCall, rest []Instruction) error {
+	if _, ok := call.Callee.(*InlineAsm); ok {
+		return errors.New("invalid inline assembly callee")
+	}
+	// Signature and calling convention.
+	sig := calleeSig(call)
+	if sig == nil {
+		return errors.Errorf("invalid callee type %v", call.Callee.Type())
+	}
+	if sig.Variadic != f.Sig.Variadic {
+		return errors.New("variadic mismatch between caller and callee")
+	}
+	if len(sig.Params) != len(f.Sig.Params) {
+		return errors.Errorf("parameter count mismatch between caller and callee; expected %d, got %d", len(f.Sig.Params), len(sig.Params))
+	}
+	for i := range sig.Params {
+		if !isCongruentType(sig.Params[i], f.Sig.Params[i]) {
+			return errors.Errorf("parameter %d type mismatch between caller and callee; expected %v, got %v", i, f.Sig.Params[i], sig.Params[i])
+		}
+	}
+	if !isCongruentType(sig.RetType, f.Sig.RetType) {
+		return errors.Errorf("return type mismatch between caller and callee; expected %v, got %v", f.Sig.RetType, sig.RetType)
+	}
+	if call.CallingConv != f.CallingConv {
+		return errors.Errorf("calling convention mismatch between caller and callee; expected %v, got %v", f.CallingConv, call.CallingConv)
+	}
+	// Following ret terminator, with an optional bitcast in between.
+	rest = withoutDbgRecords(rest)
+	var result value.Value = call
+	if len(rest) > 0 {
+		if cast, ok := rest[0].(*InstBitCast); ok && cast.From == call {
+			result = cast
+			rest = rest[1:]
+		}
+	}
+	ret, ok := block.Term.(*TermRet)
+	if len(rest) > 0 || !ok {
+		return errors.New("not immediately followed by ret")
+	}
+	if ret.X != nil && ret.X != result {
+		return errors.Errorf("result not returned; ret returns %v", ret.X.Ident())
+	}
+	return nil
+}
+
+// --- [ Debug locations ] -----------------------------------------------------
+
+// CheckDebugLocations verifies that the instructions and terminators of the
+// given function have a debug location (a !dbg metadata attachment of type
+// *metadata.DILocation), if the function has debug info (a !dbg metadata
+// attachment of type *metadata.DISubprogram). Functions without debug info are
+// not checked.
+//
+// Alloca and phi instructions are not checked, as they have no meaningful
+// source location and are commonly emitted without one; neither are debug
+// records, which specify their location separately.
+//
+// CheckDebugLocations is an opt-in diagnostic, and is not run by
+// (*Module).Verify, as LLVM permits instructions without debug locations; it
+// is intended to confirm that transformation passes propagate debug locations
+// to the instructions they insert.
+func CheckDebugLocations(f *Func) error {
+	hasDebugInfo := false
+	for _, md := range f.Metadata {
+		if _, ok := md.Node.(*metadata.DISubprogram); ok && md.Name == "dbg" {
+			hasDebugInfo = true
+			break
+		}
+	}
+	if !hasDebugInfo {
+		return nil
+	}
+	var errs VerifyErrors
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			switch inst.(type) {
+			case *InstAlloca, *InstPhi, *DbgRecord:
+				continue
+			}
+			if inst.DebugLoc() == nil {
+				errs = append(errs, errors.Errorf("missing debug location of instruction %s in function %s; in block %s", inst.LLString(), f.Ident(), block.Ident()))
+			}
+		}
+		if block.Term != nil && dbgLocation(block.Term) == nil {
+			errs = append(errs, errors.Errorf("missing debug location of terminator %s in function %s; in block %s", block.Term.LLString(), f.Ident(), block.Ident()))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// --- [ Arity ] ---------------------------------------------------------------
+
+// CheckArity verifies that the instructions and terminators of the given
+// function have the number of operands required by their kind; e.g. two value
+// operands of binary instructions, three of select instructions, and a source
+// address (and any number of indices) of getelementptr instructions.
+//
+// Furthermore, CheckArity verifies that the arguments of calls match the
+// parameters of the callee signature (at least as many arguments as parameters
+// for variadic callees), that extractvalue and insertvalue instructions have at
+// least one index, and that phi instructions have one incoming value for each
+// control flow edge to their parent basic block.
+//
+// CheckArity is an opt-in diagnostic, and is not run by (*Module).Verify, as
+// the operand count of parsed instructions is guaranteed by the grammar; it is
+// intended to catch invalid operands of instructions constructed
+// programmatically.
+func CheckArity(f *Func) error {
+	var errs VerifyErrors
+	preds := f.Predecessors()
+	check := func(v interface{ Operands() []*value.Value }, block *Block) {
+		if err := checkArity(v, len(preds[block])); err != nil {
+			errs = append(errs, errors.Errorf("%v; in function %s; in block %s", err, f.Ident(), block.Ident()))
+		}
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			check(inst, block)
+		}
+		if block.Term != nil {
+			check(block.Term, block)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkArity verifies the number of operands of the given instruction or
+// terminator, with a parent basic block of npreds predecessors.
+func checkArity(v interface{ Operands() []*value.Value }, npreds int) error {
+	// Optional operands are only included by Operands if present; thus, every
+	// operand is required to be non-nil.
+	ops := v.Operands()
+	got := 0
+	for _, op := range ops {
+		if *op != nil {
+			got++
+		}
+	}
+	if got != len(ops) {
+		return errors.Errorf("invalid operand count of %s; expected %d, got %d", opcodeName(v), len(ops), got)
+	}
+	switch v := v.(type) {
+	case *InstCall:
+		return checkArgCount(v.Callee, v.Args, opcodeName(v))
+	case *TermInvoke:
+		return checkArgCount(v.Invokee, v.Args, opcodeName(v))
+	case *TermCallBr:
+		return checkArgCount(v.Callee, v.Args, opcodeName(v))
+	case *InstExtractValue:
+		if len(v.Indices) == 0 {
+			return errors.Errorf("invalid index count of %s; expected at least 1, got 0", opcodeName(v))
+		}
+	case *InstInsertValue:
+		if len(v.Indices) == 0 {
+			return errors.Errorf("invalid index count of %s; expected at least 1, got 0", opcodeName(v))
+		}
+	case *InstPhi:
+		if len(v.Incs) != npreds {
+			return errors.Errorf("invalid incoming value count of %s; expected %d, got %d", opcodeName(v), npreds, len(v.Incs))
+		}
+	}
+	return nil
+}
+
+// checkArgCount verifies that the number of arguments of a call to the given
+// callee matches the number of parameters of the callee signature.
+func checkArgCount(callee value.Value, args []value.Value, desc string) error {
+	t, ok := callee.Type().(*types.PointerType)
+	if !ok {
+		return nil
+	}
+	sig, ok := t.ElemType.(*types.FuncType)
+	if !ok {
+		return nil
+	}
+	switch {
+	case sig.Variadic && len(args) < len(sig.Params):
+		return errors.Errorf("invalid argument count of %s; expected at least %d, got %d", desc, len(sig.Params), len(args))
+	case !sig.Variadic && len(args) != len(sig.Params):
+		return errors.Errorf("invalid argument count of %s; expected %d, got %d", desc, len(sig.Params), len(args))
+	}
+	return nil
+}
+
+// --- [ getelementptr ] -------------------------------------------------------
+
+// CheckGEPBounds verifies that the constant array, vector and struct indices of
+// getelementptr instructions in the given function, and of getelementptr
+// constant expressions used directly as their operands, are within the bounds
+// of the indexed types. The first index, which steps over elements of the
+// source address, is not checked. To allow the computation of end pointers,
+// the last index may be one past the end of an array or vector.
+//
+// CheckGEPBounds is an opt-in diagnostic, and is not run by (*Module).Verify,
+// as LLVM permits getelementptr instructions with out of bounds indices.
+func CheckGEPBounds(f *Func) error {
+	var errs VerifyErrors
+	check := func(elemType types.Type, indices []value.Value, desc string, block *Block) {
+		if err := checkGEPBounds(elemType, indices); err != nil {
+			errs = append(errs, errors.Errorf("%v; %s in function %s; in block %s", err, desc, f.Ident(), block.Ident()))
+		}
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			if gep, ok := inst.(*InstGetElementPtr); ok {
+				// Cache element type if not present.
+				gep.Type()
+				check(gep.ElemType, gep.Indices, "getelementptr instruction "+gep.Ident(), block)
+			}
+			for _, use := range inst.Operands() {
+				expr, ok := (*use).(*constant.ExprGetElementPtr)
+				if !ok {
+					continue
+				}
+				// Cache element type if not present.
+				expr.Type()
+				indices := make([]value.Value, len(expr.Indices))
+				for i, index := range expr.Indices {
+					indices[i] = index
+				}
+				check(expr.ElemType, indices, "getelementptr expression "+expr.Ident(), block)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// checkGEPBounds verifies that the constant indices (except the first) of a
+// getelementptr with the given element type are within bounds.
+func checkGEPBounds(elemType types.Type, indices []value.Value) error {
+	e := elemType
+	for i := 1; i < len(indices); i++ {
+		last := i == len(indices)-1
+		idx, isConst := indices[i].(*constant.Int)
+		switch t := e.(type) {
+		case *types.ArrayType:
+			if isConst && !inBounds(idx, t.Len, last) {
+				return errors.Errorf("out of bounds index %v of array type %v with length %d", idx.X, t, t.Len)
+			}
+			e = t.ElemType
+		case *types.VectorType:
+			if isConst && !t.Scalable && !inBounds(idx, t.Len, last) {
+				return errors.Errorf("out of bounds index %v of vector type %v with length %d", idx.X, t, t.Len)
+			}
+			e = t.ElemType
+		case *types.StructType:
+			if !isConst {
+				// Struct indices are either integer constants or (splat) vectors
+				// of integer constants; the latter are not checked.
+				return nil
+			}
+			if !inBounds(idx, uint64(len(t.Fields)), false) {
+				return errors.Errorf("out of bounds index %v of struct type %v with %d fields", idx.X, t, len(t.Fields))
+			}
+			e = t.Fields[idx.X.Int64()]
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// inBounds reports whether the given index is within the bounds of a type with
+// the specified number of elements. If end is true, the index may be one past
+// the end.
+func inBounds(index *constant.Int, n uint64, end bool) bool {
+	if index.X.Sign() < 0 || !index.X.IsUint64() {
+		return false
+	}
+	x := index.X.Uint64()
+	return x < n || (end && x == n)
+}
+
+// opcodeName returns a description of the given instruction or terminator
+// based on its kind and identifier, if any (e.g. "select instruction %x"), for
+// use in error messages.
+func opcodeName(v interface{}) string {
+	name := fmt.Sprintf("%T", v)
+	switch {
+	case strings.HasPrefix(name, "*ir.Inst"):
+		name = strings.ToLower(strings.TrimPrefix(name, "*ir.Inst")) + " instruction"
+	case strings.HasPrefix(name, "*ir.Term"):
+		name = strings.ToLower(strings.TrimPrefix(name, "*ir.Term")) + " terminator"
+	default:
+		name = strings.TrimPrefix(name, "*ir.")
+	}
+	if n, ok := v.(value.Named); ok && !types.IsVoid(n.Type()) {
+		name += " " + n.Ident()
+	}
+	return name
+}
+
+// calleeSig returns the function signature of the callee of the given call
+// instruction, or nil if the callee is not of pointer to function type.
+func calleeSig(call *InstCall) *types.FuncType {
+	t, ok := call.Callee.Type().(*types.PointerType)
+	if !ok {
+		return nil
+	}
+	sig, _ := t.ElemType.(*types.FuncType)
+	return sig
+}
+
+// isCongruentType reports whether the given types are congruent; i.e. equal or
+// both pointer types in the same address space.
+func isCongruentType(t, u types.Type) bool {
+	if types.Equal(t, u) {
+		return true
+	}
+	p, ok1 := t.(*types.PointerType)
+	q, ok2 := u.(*types.PointerType)
+	return ok1 && ok2 && p.AddrSpace == q.AddrSpace
+}
+
+// appendErr appends the given error to the list of verification errors,
+// flattening nested verification errors.
+func appendErr(errs VerifyErrors, err error) VerifyErrors {
+	if es, ok := err.(VerifyErrors); ok {
+		return append(errs, es...)
+	}
+	return append(errs, err)
+}
@@ -0,0 +1,319 @@
+package ir
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+func TestVerifyBeforeWrite(t *testing.T) {
+	m := NewModule()
+	f := m.NewFunc("f", types.I32)
+	entry := f.NewBlock("entry")
+	// Invalid return value type.
+	entry.NewRet(constant.NewInt(types.I64, 42))
+	buf := &strings.Builder{}
+	n, err := m.WriteToWithOptions(buf, VerifyBeforeWrite())
+	if err == nil {
+		t.Fatalf("expected verification error, got nil")
+	}
+	if _, ok := errors.Cause(err).(VerifyErrors); !ok {
+		t.Errorf("error type mismatch; expected VerifyErrors, got %T", errors.Cause(err))
+	}
+	const want = "return value type mismatch in function @f; expected i32, got i64; in block %entry"
+	if got := err.Error(); got != want {
+		t.Errorf("error mismatch; expected %q, got %q", want, got)
+	}
+	if n != 0 || buf.Len() != 0 {
+		t.Errorf("expected nothing to be written; got %d bytes", buf.Len())
+	}
+	// Write without verification.
+	buf.Reset()
+	if _, err := m.WriteTo(buf); err != nil {
+		t.Fatalf("unexpected error; %v", err)
+	}
+	if buf.String() != m.String() {
+		t.Errorf("output mismatch; expected %q, got %q", m.String(), buf.String())
+	}
+}
+
+func TestCheckTerminators(t *testing.T) {
+	m := NewModule()
+	x := NewParam("x", types.I32)
+	f := m.NewFunc("f", types.I32, x)
+	entry := f.NewBlock("entry")
+	exit := f.NewBlock("exit")
+	entry.NewBr(exit)
+	// Missing terminator.
+	exit.NewAdd(x, x)
+	const want = "missing terminator in function @f; in block %exit"
+	if err := m.Verify(); err == nil || err.Error() != want {
+		t.Errorf("error mismatch; expected %q, got %v", want, err)
+	}
+	exit.NewRet(x)
+	if err := m.Verify(); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+}
+
+func TestCheckEntryBlock(t *testing.T) {
+	m := NewModule()
+	cond := NewParam("cond", types.I1)
+	f := m.NewFunc("f", types.Void, cond)
+	entry := f.NewBlock("entry")
+	loop := f.NewBlock("loop")
+	exit := f.NewBlock("exit")
+	if got := f.Entry(); got != entry {
+		t.Errorf("entry block mismatch; expected %v, got %v", entry.Ident(), got)
+	}
+	entry.NewBr(loop)
+	// Branch back to the entry block.
+	loop.NewCondBr(cond, entry, exit)
+	exit.NewRet(nil)
+	const want = "branch to entry block %entry in function @f; in block %loop"
+	if err := m.Verify(); err == nil || err.Error() != want {
+		t.Errorf("error mismatch; expected %q, got %v", want, err)
+	}
+	loop.Term = NewCondBr(cond, loop, exit)
+	if err := m.Verify(); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	// Declarations have no entry block.
+	if got := m.NewFunc("g", types.Void).Entry(); got != nil {
+		t.Errorf("entry block mismatch; expected nil, got %v", got.Ident())
+	}
+}
+
+func TestCheckCallBr(t *testing.T) {
+	f := NewFunc("f", types.Void)
+	entry := f.NewBlock("entry")
+	normal := f.NewBlock("normal")
+	indirect := f.NewBlock("indirect")
+	normal.NewRet(nil)
+	indirect.NewRet(nil)
+	asm := NewInlineAsm(types.NewPointer(types.NewFunc(types.Void)), "", "")
+	term := entry.NewCallBr(asm, nil, normal, indirect)
+	if err := CheckCallBr(f); err != nil {
+		t.Fatalf("unexpected error; %v", err)
+	}
+	const wantLL = "callbr void asm \"\", \"\"()\n\t\tto label %normal [label %indirect]"
+	if got := term.LLString(); got != wantLL {
+		t.Errorf("callbr mismatch; expected %q, got %q", wantLL, got)
+	}
+	// Missing default destination.
+	term.Normal = nil
+	err := CheckCallBr(f)
+	const want = "missing default destination of callbr terminator in function @f; in block %entry"
+	if err == nil || err.Error() != want {
+		t.Errorf("error mismatch; expected %q, got %v", want, err)
+	}
+}
+
+func TestCheckMustTail(t *testing.T) {
+	m := NewModule()
+	g := m.NewFunc("g", types.I32, NewParam("x", types.I32))
+	x := NewParam("x", types.I32)
+	f := m.NewFunc("f", types.I32, x)
+	entry := f.NewBlock("entry")
+	call := entry.NewCall(g, x)
+	call.SetName("r")
+	call.Tail = enum.TailMustTail
+	ret := entry.NewRet(call)
+	if err := CheckMustTail(f); err != nil {
+		t.Fatalf("unexpected error; %v", err)
+	}
+	// Debug records between call and ret are ignored.
+	entry.Insts = append(entry.Insts, NewDbgValue(call, nil, nil, nil))
+	if err := CheckMustTail(f); err != nil {
+		t.Fatalf("unexpected error with debug record; %v", err)
+	}
+	entry.Insts = entry.Insts[:1]
+	// Call not immediately followed by ret.
+	add := NewAdd(call, constant.NewInt(types.I32, 1))
+	add.SetName("y")
+	entry.Insts = append(entry.Insts, add)
+	ret.X = add
+	const want = "not immediately followed by ret; musttail call %r = musttail call i32 @g(i32 %x) in function @f; in block %entry"
+	err := CheckMustTail(f)
+	if err == nil || err.Error() != want {
+		t.Errorf("error mismatch; expected %q, got %v", want, err)
+	}
+	// Result of call not returned.
+	entry.Insts = entry.Insts[:1]
+	ret.X = x
+	const want2 = "result not returned; ret returns %x; musttail call %r = musttail call i32 @g(i32 %x) in function @f; in block %entry"
+	err = CheckMustTail(f)
+	if err == nil || err.Error() != want2 {
+		t.Errorf("error mismatch; expected %q, got %v", want2, err)
+	}
+	// Signature mismatch between caller and callee.
+	h := m.NewFunc("h", types.I32, NewParam("x", types.I64))
+	call.Callee = h
+	call.Args = []value.Value{constant.NewInt(types.I64, 0)}
+	ret.X = call
+	const want3 = "parameter 0 type mismatch between caller and callee; expected i32, got i64; musttail call %r = musttail call i32 @h(i64 0) in function @f; in block %entry"
+	if err := m.Verify(); err == nil || err.Error() != want3 {
+		t.Errorf("error mismatch; expected %q, got %v", want3, err)
+	}
+}
+
+func TestCheckGEPBounds(t *testing.T) {
+	m := NewModule()
+	arr := types.NewArray(4, types.I32)
+	st := types.NewStruct(types.I32, types.NewArray(2, types.NewArray(3, types.I32)))
+	a := m.NewGlobalDef("a", constant.NewZeroInitializer(arr))
+	s := m.NewGlobalDef("s", constant.NewZeroInitializer(st))
+	f := m.NewFunc("f", types.Void, NewParam("i", types.I64))
+	entry := f.NewBlock("entry")
+	idx := func(x int64) value.Value { return constant.NewInt(types.I64, x) }
+	i32 := func(x int64) value.Value { return constant.NewInt(types.I32, x) }
+	geps := []*InstGetElementPtr{
+		// In bounds.
+		entry.NewGetElementPtr(a, idx(0), idx(3)),
+		// One past the end.
+		entry.NewGetElementPtr(a, idx(0), idx(4)),
+		// First index is not checked.
+		entry.NewGetElementPtr(a, idx(10), idx(0)),
+		// Non-constant index.
+		entry.NewGetElementPtr(a, idx(0), f.Params[0]),
+		// Out of bounds.
+		entry.NewGetElementPtr(a, idx(0), idx(5)),
+		entry.NewGetElementPtr(a, idx(0), idx(-1)),
+		// In bounds struct and array indices.
+		entry.NewGetElementPtr(s, idx(0), i32(1), idx(1)),
+		// Out of bounds array index of struct field (not the last index).
+		entry.NewGetElementPtr(s, idx(0), i32(1), idx(2), idx(0)),
+	}
+	for i, gep := range geps {
+		gep.SetName(fmt.Sprintf("p%d", i))
+	}
+	// Out of bounds getelementptr constant expression operand.
+	expr := constant.NewGetElementPtr(a, constant.NewInt(types.I64, 0), constant.NewInt(types.I64, 7))
+	entry.NewLoad(expr)
+	entry.NewRet(nil)
+	// Out of bounds indices are permitted by default.
+	if err := m.Verify(); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	want := []string{
+		"out of bounds index 5 of array type [4 x i32] with length 4; getelementptr instruction %p4 in function @f; in block %entry",
+		"out of bounds index -1 of array type [4 x i32] with length 4; getelementptr instruction %p5 in function @f; in block %entry",
+		"out of bounds index 2 of array type [2 x [3 x i32]] with length 2; getelementptr instruction %p7 in function @f; in block %entry",
+		"out of bounds index 7 of array type [4 x i32] with length 4; getelementptr expression getelementptr ([4 x i32], [4 x i32]* @a, i64 0, i64 7) in function @f; in block %entry",
+	}
+	err := CheckGEPBounds(f)
+	errs, ok := err.(VerifyErrors)
+	if !ok {
+		t.Fatalf("error type mismatch; expected VerifyErrors, got %T", err)
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("error count mismatch; expected %d, got %d (%v)", len(want), len(errs), errs)
+	}
+	for i := range want {
+		if got := errs[i].Error(); got != want[i] {
+			t.Errorf("error mismatch; expected %q, got %q", want[i], got)
+		}
+	}
+}
+
+func TestCheckDebugLocations(t *testing.T) {
+	m := NewModule()
+	f := m.NewFunc("f", types.I32, NewParam("x", types.I32))
+	file := &metadata.DIFile{MetadataID: -1, Filename: "foo.c"}
+	sp := &metadata.DISubprogram{MetadataID: -1, Name: "f", File: file}
+	loc := &metadata.DILocation{MetadataID: -1, Line: 1, Scope: sp}
+	entry := f.NewBlock("entry")
+	entry.NewAlloca(types.I32)
+	y := entry.NewAdd(f.Params[0], constant.NewInt(types.I32, 1))
+	y.SetName("y")
+	y.SetDebugLoc(loc)
+	// Newly inserted instruction without debug location.
+	z := entry.NewMul(y, constant.NewInt(types.I32, 2))
+	z.SetName("z")
+	ret := entry.NewRet(z)
+	ret.SetDebugLoc(loc)
+	// Functions without debug info are not checked.
+	if err := CheckDebugLocations(f); err != nil {
+		t.Fatalf("unexpected error for function without debug info; %v", err)
+	}
+	f.Metadata = append(f.Metadata, &metadata.Attachment{Name: "dbg", Node: sp})
+	want := []string{
+		"missing debug location of instruction %z = mul i32 %y, 2 in function @f; in block %entry",
+	}
+	err := CheckDebugLocations(f)
+	errs, ok := err.(VerifyErrors)
+	if !ok {
+		t.Fatalf("error type mismatch; expected VerifyErrors, got %T", err)
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("error count mismatch; expected %d, got %d (%v)", len(want), len(errs), errs)
+	}
+	for i := range want {
+		if got := errs[i].Error(); got != want[i] {
+			t.Errorf("error mismatch; expected %q, got %q", want[i], got)
+		}
+	}
+	// Propagate debug location to the new instruction.
+	z.SetDebugLoc(loc)
+	if err := CheckDebugLocations(f); err != nil {
+		t.Errorf("unexpected error after propagating debug location; %v", err)
+	}
+}
+
+func TestCheckArity(t *testing.T) {
+	m := NewModule()
+	g := m.NewFunc("g", types.I32, NewParam("x", types.I32))
+	f := m.NewFunc("f", types.I32, NewParam("c", types.I1), NewParam("x", types.I32))
+	c, x := f.Params[0], f.Params[1]
+	entry := f.NewBlock("entry")
+	exit := f.NewBlock("exit")
+	add := entry.NewAdd(x, x)
+	add.SetName("add")
+	// Malformed select without false value.
+	sel := entry.NewSelect(c, add, x)
+	sel.SetName("sel")
+	sel.Y = nil
+	// Call with too few arguments.
+	call := entry.NewCall(g)
+	call.SetName("call")
+	entry.NewBr(exit)
+	phi := exit.NewPhi(NewIncoming(sel, entry))
+	phi.SetName("phi")
+	exit.NewRet(phi)
+	want := []string{
+		"invalid operand count of select instruction %sel; expected 3, got 2; in function @f; in block %entry",
+		"invalid argument count of call instruction %call; expected 1, got 0; in function @f; in block %entry",
+	}
+	err := CheckArity(f)
+	errs, ok := err.(VerifyErrors)
+	if !ok {
+		t.Fatalf("error type mismatch; expected VerifyErrors, got %T", err)
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("error count mismatch; expected %d, got %d (%v)", len(want), len(errs), errs)
+	}
+	for i := range want {
+		if got := errs[i].Error(); got != want[i] {
+			t.Errorf("error mismatch; expected %q, got %q", want[i], got)
+		}
+	}
+	// Well-formed instructions.
+	sel.Y = x
+	call.Args = append(call.Args, x)
+	if err := CheckArity(f); err != nil {
+		t.Errorf("unexpected error; %v", err)
+	}
+	// Phi with an extra incoming value.
+	phi.Incs = append(phi.Incs, NewIncoming(x, entry))
+	wantErr := "invalid incoming value count of phi instruction %phi; expected 1, got 2; in function @f; in block %exit"
+	if err := CheckArity(f); err == nil || err.Error() != wantErr {
+		t.Errorf("error mismatch; expected %q, got %v", wantErr, err)
+	}
+}
@@ -0,0 +1,104 @@
+package ir
+
+import (
+	"strings"
+
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Vector predication intrinsics ] =======================================
+
+// IsVPIntrinsic reports whether the given call is a call to a vector
+// predication intrinsic (e.g. @llvm.vp.add.v4i32 or @llvm.vp.load.v4i32.p0).
+//
+// Vector predication intrinsics take an explicit mask operand of type <N x i1>
+// and an explicit vector length (EVL) operand of type i32, as their last two
+// operands; except for @llvm.vp.select and @llvm.vp.merge, which take no mask
+// operand.
+func IsVPIntrinsic(call *InstCall) bool {
+	_, ok := vpIntrinsicName(call)
+	return ok
+}
+
+// VPMask returns the mask operand of the given call to a vector predication
+// intrinsic, or nil if the call is not a call to a vector predication intrinsic
+// or the intrinsic takes no mask operand.
+func VPMask(call *InstCall) value.Value {
+	name, ok := vpIntrinsicName(call)
+	if !ok || len(call.Args) < 2 {
+		return nil
+	}
+	if name == "select" || name == "merge" {
+		return nil
+	}
+	mask := call.Args[len(call.Args)-2]
+	if !isMaskType(mask.Type()) {
+		return nil
+	}
+	return mask
+}
+
+// VPEVL returns the explicit vector length operand of the given call to a
+// vector predication intrinsic, or nil if the call is not a call to a vector
+// predication intrinsic.
+func VPEVL(call *InstCall) value.Value {
+	if !IsVPIntrinsic(call) || len(call.Args) == 0 {
+		return nil
+	}
+	evl := call.Args[len(call.Args)-1]
+	if !types.Equal(evl.Type(), types.I32) {
+		return nil
+	}
+	return evl
+}
+
+// ### [ Helper functions ] ####################################################
+
+// vpIntrinsicName returns the base name of the vector predication intrinsic
+// called by the given call, without the "llvm.vp." prefix and type suffixes
+// (e.g. "add" for @llvm.vp.add.v4i32, and "reduce.add" for
+// @llvm.vp.reduce.add.v4i32). The boolean return value indicates success.
+func vpIntrinsicName(call *InstCall) (string, bool) {
+	callee, ok := call.Callee.(*Func)
+	if !ok {
+		return "", false
+	}
+	const prefix = "llvm.vp."
+	name := callee.Name()
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	var parts []string
+	for _, part := range strings.Split(name[len(prefix):], ".") {
+		if isOverloadSuffix(part) {
+			break
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return "", false
+	}
+	return strings.Join(parts, "."), true
+}
+
+// isOverloadSuffix reports whether the given component of an intrinsic name is
+// a type suffix of an overloaded intrinsic (e.g. "v4i32", "nxv2f64" or "p0").
+func isOverloadSuffix(s string) bool {
+	s = strings.TrimPrefix(s, "nx")
+	if len(s) < 2 {
+		return false
+	}
+	switch s[0] {
+	case 'v', 'p', 'i', 'f':
+		return s[1] >= '0' && s[1] <= '9'
+	}
+	return false
+}
+
+// isMaskType reports whether the given type is a vector mask type; i.e. a
+// vector of i1 elements.
+func isMaskType(t types.Type) bool {
+	vt, ok := t.(*types.VectorType)
+	return ok && types.Equal(vt.ElemType, types.I1)
+}
@@ -0,0 +1,62 @@
+package ir_test
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestVPIntrinsic(t *testing.T) {
+	const src = `
+declare <4 x i32> @llvm.vp.add.v4i32(<4 x i32>, <4 x i32>, <4 x i1>, i32)
+
+declare <4 x i32> @llvm.vp.select.v4i32(<4 x i1>, <4 x i32>, <4 x i32>, i32)
+
+declare <4 x i32> @llvm.masked.load.v4i32.p0v4i32(<4 x i32>*, i32, <4 x i1>, <4 x i32>)
+
+define void @f(<4 x i32> %a, <4 x i32> %b, <4 x i1> %m, i32 %evl, <4 x i32>* %p) {
+	%1 = call <4 x i32> @llvm.vp.add.v4i32(<4 x i32> %a, <4 x i32> %b, <4 x i1> %m, i32 %evl)
+	%2 = call <4 x i32> @llvm.vp.select.v4i32(<4 x i1> %m, <4 x i32> %a, <4 x i32> %b, i32 %evl)
+	%3 = call <4 x i32> @llvm.masked.load.v4i32.p0v4i32(<4 x i32>* %p, i32 4, <4 x i1> %m, <4 x i32> %a)
+	ret void
+}
+`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[3]
+	mask, evl := f.Params[2], f.Params[3]
+	insts := f.Blocks[0].Insts
+	add := insts[0].(*ir.InstCall)
+	if !ir.IsVPIntrinsic(add) {
+		t.Errorf("%q: expected VP intrinsic", add.LLString())
+	}
+	if got := ir.VPMask(add); got != mask {
+		t.Errorf("%q: mask mismatch; expected %v, got %v", add.LLString(), mask, got)
+	}
+	if got := ir.VPEVL(add); got != evl {
+		t.Errorf("%q: evl mismatch; expected %v, got %v", add.LLString(), evl, got)
+	}
+	sel := insts[1].(*ir.InstCall)
+	if !ir.IsVPIntrinsic(sel) {
+		t.Errorf("%q: expected VP intrinsic", sel.LLString())
+	}
+	if got := ir.VPMask(sel); got != nil {
+		t.Errorf("%q: expected no mask, got %v", sel.LLString(), got)
+	}
+	if got := ir.VPEVL(sel); got != evl {
+		t.Errorf("%q: evl mismatch; expected %v, got %v", sel.LLString(), evl, got)
+	}
+	load := insts[2].(*ir.InstCall)
+	if ir.IsVPIntrinsic(load) {
+		t.Errorf("%q: unexpected VP intrinsic", load.LLString())
+	}
+	if got := ir.VPMask(load); got != nil {
+		t.Errorf("%q: expected no mask, got %v", load.LLString(), got)
+	}
+	if got := ir.VPEVL(load); got != nil {
+		t.Errorf("%q: expected no evl, got %v", load.LLString(), got)
+	}
+}
@@ -0,0 +1,272 @@
+package ir
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Constant walker ] =====================================================
+
+// WalkConstants visits the constants reachable from the module in depth-first
+// order; this includes global variable initializers, aliasees, resolvers,
+// function prefix, prologue and personality constants, operands of
+// instructions and terminators, switch case comparands, and constants used as
+// fields of metadata tuples or as metadata arguments.
+//
+// fn is invoked for each visited constant c. If fn returns a constant different
+// from c, c is replaced in place by the returned constant and the operands of
+// c are not visited. Otherwise, the operands of c (e.g. the operands of
+// constant expressions and the elements of aggregate constants) are visited
+// recursively.
+//
+// Global variables, functions, aliases and IFuncs used as constants are leaves
+// of the walk; their initializers and bodies are visited as part of the module
+// rather than through their uses.
+func (m *Module) WalkConstants(fn func(c constant.Constant) constant.Constant) {
+	w := &constWalker{fn: fn, visited: make(map[*metadata.Tuple]bool)}
+	for _, g := range m.Globals {
+		if g.Init != nil {
+			g.Init = w.walk(g.Init)
+		}
+	}
+	for _, alias := range m.Aliases {
+		alias.Aliasee = w.walk(alias.Aliasee)
+	}
+	for _, ifunc := range m.IFuncs {
+		ifunc.Resolver = w.walk(ifunc.Resolver)
+	}
+	for _, f := range m.Funcs {
+		w.walkFunc(f)
+	}
+	for _, md := range m.MetadataDefs {
+		if tuple, ok := md.(*metadata.Tuple); ok {
+			w.walkTuple(tuple)
+		}
+	}
+}
+
+// ReferencedGlobals returns the distinct global variables, functions, aliases
+// and IFuncs referenced by the function, in order of first reference; this
+// includes references through constant expressions (e.g. bitcast of a global
+// variable) and aggregate constants in operands of instructions and
+// terminators, as well as the prefix, prologue and personality constants of
+// the function. Recursive functions reference themselves.
+func (f *Func) ReferencedGlobals() []value.Value {
+	var globals []value.Value
+	seen := make(map[value.Value]bool)
+	fn := func(c constant.Constant) constant.Constant {
+		switch c.(type) {
+		case *Global, *Func, *Alias, *IFunc:
+			if !seen[c] {
+				seen[c] = true
+				globals = append(globals, c)
+			}
+		}
+		return c
+	}
+	w := &constWalker{fn: fn, visited: make(map[*metadata.Tuple]bool)}
+	w.walkFunc(f)
+	return globals
+}
+
+// constWalker is a walker of constants.
+type constWalker struct {
+	// Visit function; returns the replacement of the given constant.
+	fn func(c constant.Constant) constant.Constant
+	// Visited metadata tuples, to prevent infinite recursion on cyclic metadata.
+	visited map[*metadata.Tuple]bool
+}
+
+// walkFunc visits the constants of the given function.
+func (w *constWalker) walkFunc(f *Func) {
+	if f.Prefix != nil {
+		f.Prefix = w.walk(f.Prefix)
+	}
+	if f.Prologue != nil {
+		f.Prologue = w.walk(f.Prologue)
+	}
+	if f.Personality != nil {
+		f.Personality = w.walk(f.Personality)
+	}
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			w.walkOperands(inst.Operands())
+		}
+		if block.Term == nil {
+			continue
+		}
+		w.walkOperands(block.Term.Operands())
+		if term, ok := block.Term.(*TermSwitch); ok {
+			for _, c := range term.Cases {
+				c.X = w.walk(c.X)
+			}
+		}
+	}
+}
+
+// walkOperands visits the constant operands of an instruction or terminator.
+func (w *constWalker) walkOperands(ops []*value.Value) {
+	for _, op := range ops {
+		switch v := (*op).(type) {
+		case constant.Constant:
+			*op = w.walk(v)
+		case *metadata.Value:
+			w.walkMetadata(v)
+		}
+	}
+}
+
+// walkMetadata visits the constants of the given metadata value.
+func (w *constWalker) walkMetadata(md *metadata.Value) {
+	switch v := md.Value.(type) {
+	case constant.Constant:
+		md.Value = w.walk(v)
+	case *metadata.Tuple:
+		w.walkTuple(v)
+	}
+}
+
+// walkTuple visits the constants used as fields of the given metadata tuple.
+func (w *constWalker) walkTuple(tuple *metadata.Tuple) {
+	if tuple == nil || w.visited[tuple] {
+		return
+	}
+	w.visited[tuple] = true
+	for i, field := range tuple.Fields {
+		switch field := field.(type) {
+		case constant.Constant:
+			tuple.Fields[i] = w.walk(field)
+		case *metadata.Tuple:
+			w.walkTuple(field)
+		}
+	}
+}
+
+// walk visits the given constant and its operands, and returns the replacement
+// of c.
+func (w *constWalker) walk(c constant.Constant) constant.Constant {
+	if new := w.fn(c); new != c {
+		return new
+	}
+	switch c := c.(type) {
+	// Simple constants and global identifiers are leaves.
+	case *constant.Int, *constant.Float, *constant.Null, *constant.NoneToken:
+	case *constant.Undef, *constant.Poison, *constant.ZeroInitializer, *constant.CharArray:
+	case *Global, *Func, *Alias, *IFunc:
+	// Complex constants.
+	case *constant.Struct:
+		w.walkList(c.Fields)
+	case *constant.Array:
+		w.walkList(c.Elems)
+	case *constant.Vector:
+		w.walkList(c.Elems)
+	case *constant.BlockAddress:
+		c.Func = w.walk(c.Func)
+	// Unary expressions.
+	case *constant.ExprFNeg:
+		c.X = w.walk(c.X)
+	// Binary expressions.
+	case *constant.ExprAdd:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprFAdd:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprSub:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprFSub:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprMul:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprFMul:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprUDiv:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprSDiv:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprFDiv:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprURem:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprSRem:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprFRem:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	// Bitwise expressions.
+	case *constant.ExprShl:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprLShr:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprAShr:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprAnd:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprOr:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprXor:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	// Vector expressions.
+	case *constant.ExprExtractElement:
+		c.X, c.Index = w.walk(c.X), w.walk(c.Index)
+	case *constant.ExprInsertElement:
+		c.X, c.Elem, c.Index = w.walk(c.X), w.walk(c.Elem), w.walk(c.Index)
+	case *constant.ExprShuffleVector:
+		c.X, c.Y, c.Mask = w.walk(c.X), w.walk(c.Y), w.walk(c.Mask)
+	// Aggregate expressions.
+	case *constant.ExprExtractValue:
+		c.X = w.walk(c.X)
+	case *constant.ExprInsertValue:
+		c.X, c.Elem = w.walk(c.X), w.walk(c.Elem)
+	// Memory expressions.
+	case *constant.ExprGetElementPtr:
+		c.Src = w.walk(c.Src)
+		w.walkList(c.Indices)
+	case *constant.Index:
+		c.Constant = w.walk(c.Constant)
+	// Conversion expressions.
+	case *constant.ExprTrunc:
+		c.From = w.walk(c.From)
+	case *constant.ExprZExt:
+		c.From = w.walk(c.From)
+	case *constant.ExprSExt:
+		c.From = w.walk(c.From)
+	case *constant.ExprFPTrunc:
+		c.From = w.walk(c.From)
+	case *constant.ExprFPExt:
+		c.From = w.walk(c.From)
+	case *constant.ExprFPToUI:
+		c.From = w.walk(c.From)
+	case *constant.ExprFPToSI:
+		c.From = w.walk(c.From)
+	case *constant.ExprUIToFP:
+		c.From = w.walk(c.From)
+	case *constant.ExprSIToFP:
+		c.From = w.walk(c.From)
+	case *constant.ExprPtrToInt:
+		c.From = w.walk(c.From)
+	case *constant.ExprIntToPtr:
+		c.From = w.walk(c.From)
+	case *constant.ExprBitCast:
+		c.From = w.walk(c.From)
+	case *constant.ExprAddrSpaceCast:
+		c.From = w.walk(c.From)
+	// Other expressions.
+	case *constant.ExprICmp:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprFCmp:
+		c.X, c.Y = w.walk(c.X), w.walk(c.Y)
+	case *constant.ExprSelect:
+		c.Cond, c.X, c.Y = w.walk(c.Cond), w.walk(c.X), w.walk(c.Y)
+	default:
+		panic(fmt.Errorf("support for constant %T not yet implemented", c))
+	}
+	return c
+}
+
+// walkList visits the given list of constants, replacing elements in place.
+func (w *constWalker) walkList(cs []constant.Constant) {
+	for i, c := range cs {
+		cs[i] = w.walk(c)
+	}
+}
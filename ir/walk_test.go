@@ -0,0 +1,65 @@
+package ir
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestWalkConstants(t *testing.T) {
+	m := NewModule()
+	g := m.NewGlobalDef("g", constant.NewArray(types.NewArray(2, types.I32), constant.NewInt(types.I32, 1), constant.NewInt(types.I32, 2)))
+	h := m.NewGlobalDef("h", constant.NewArray(types.NewArray(2, types.I32), constant.NewInt(types.I32, 3), constant.NewInt(types.I32, 4)))
+	zero := constant.NewInt(types.I64, 0)
+	one := constant.NewInt(types.I64, 1)
+	gep := constant.NewGetElementPtr(g, zero, one)
+	p := m.NewGlobalDef("p", constant.NewBitCast(gep, types.I8Ptr))
+	f := m.NewFunc("f", types.I8Ptr)
+	entry := f.NewBlock("")
+	entry.NewRet(constant.NewBitCast(constant.NewGetElementPtr(g, zero, zero), types.I8Ptr))
+	// Replace @g by @h.
+	var visited int
+	m.WalkConstants(func(c constant.Constant) constant.Constant {
+		visited++
+		if c == g {
+			return h
+		}
+		return c
+	})
+	const wantInit = "bitcast (i32* getelementptr ([2 x i32], [2 x i32]* @h, i64 0, i64 1) to i8*)"
+	if got := p.Init.Ident(); got != wantInit {
+		t.Errorf("initializer mismatch; expected %q, got %q", wantInit, got)
+	}
+	const wantRet = "ret i8* bitcast (i32* getelementptr ([2 x i32], [2 x i32]* @h, i64 0, i64 0) to i8*)"
+	if got := entry.Term.LLString(); got != wantRet {
+		t.Errorf("terminator mismatch; expected %q, got %q", wantRet, got)
+	}
+	if visited == 0 {
+		t.Errorf("no constants visited")
+	}
+}
+
+func TestFuncReferencedGlobals(t *testing.T) {
+	m := NewModule()
+	g := m.NewGlobalDef("g", constant.NewInt(types.I32, 1))
+	h := m.NewFunc("h", types.Void, NewParam("", types.I8Ptr))
+	a := m.NewAlias("a", g)
+	f := m.NewFunc("f", types.I32)
+	entry := f.NewBlock("")
+	// @g referenced through a bitcast constant expression.
+	entry.NewCall(h, constant.NewBitCast(g, types.I8Ptr))
+	entry.NewCall(h, constant.NewBitCast(g, types.I8Ptr))
+	x := entry.NewLoad(a)
+	entry.NewRet(x)
+	got := f.ReferencedGlobals()
+	want := []string{"@h", "@g", "@a"}
+	if len(got) != len(want) {
+		t.Fatalf("number of referenced globals mismatch; expected %d, got %d", len(want), len(got))
+	}
+	for i, v := range got {
+		if v.Ident() != want[i] {
+			t.Errorf("referenced global mismatch at index %d; expected %q, got %q", i, want[i], v.Ident())
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package pass
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/value"
+)
+
+// === [ Alias analysis ] ======================================================
+
+// AliasResult is the result of an alias query.
+type AliasResult uint8
+
+// Alias query results.
+const (
+	// The memory locations may or may not overlap.
+	MayAlias AliasResult = iota
+	// The memory locations never overlap.
+	NoAlias
+	// The memory locations always start at the same address.
+	MustAlias
+)
+
+// Alias reports whether the memory locations pointed to by the pointer values a
+// and b may overlap.
+//
+// The analysis is conservative; pointers derived from distinct identified
+// objects (i.e. stack allocations and global variables) through bitcasts and
+// getelementptr do not alias, identical pointers must alias, and any other
+// pair of pointers may alias.
+//
+// Pointers derived from a noalias parameter (e.g. restrict pointers of C) do
+// not alias pointers derived from other parameters or identified objects, as
+// memory accessed through a noalias parameter is not accessed through pointers
+// not based on the parameter. Pointers with other base objects (e.g. loaded
+// pointers, which may have been based on the noalias parameter through memory)
+// may alias.
+func Alias(a, b value.Value) AliasResult {
+	if a == b {
+		return MustAlias
+	}
+	objA, objB := underlyingObject(a), underlyingObject(b)
+	if objA == objB {
+		return MayAlias
+	}
+	if isIdentifiedObject(objA) && isIdentifiedObject(objB) {
+		return NoAlias
+	}
+	if isNoAliasParam(objA) && isNonDerivedObject(objB) {
+		return NoAlias
+	}
+	if isNoAliasParam(objB) && isNonDerivedObject(objA) {
+		return NoAlias
+	}
+	return MayAlias
+}
+
+// ### [ Helper functions ] ####################################################
+
+// underlyingObject returns the base object of the given pointer value, as
+// traced through bitcasts and getelementptr instructions and constant
+// expressions.
+func underlyingObject(v value.Value) value.Value {
+	for {
+		switch x := v.(type) {
+		case *ir.InstBitCast:
+			v = x.From
+		case *ir.InstAddrSpaceCast:
+			v = x.From
+		case *ir.InstGetElementPtr:
+			v = x.Src
+		case *constant.ExprBitCast:
+			v = x.From
+		case *constant.ExprAddrSpaceCast:
+			v = x.From
+		case *constant.ExprGetElementPtr:
+			v = x.Src
+		default:
+			return v
+		}
+	}
+}
+
+// isIdentifiedObject reports whether the given value is an identified object;
+// i.e. an object which occupies memory distinct from every other identified
+// object.
+func isIdentifiedObject(v value.Value) bool {
+	switch v.(type) {
+	case *ir.InstAlloca, *ir.Global:
+		return true
+	}
+	return false
+}
+
+// isNoAliasParam reports whether the given value is a function parameter with
+// the noalias attribute.
+func isNoAliasParam(v value.Value) bool {
+	param, ok := v.(*ir.Param)
+	if !ok {
+		return false
+	}
+	for _, attr := range param.Attrs {
+		if attr == enum.ParamAttrNoAlias {
+			return true
+		}
+	}
+	return false
+}
+
+// isNonDerivedObject reports whether pointers with the given base object are
+// never based on a noalias parameter other than the base object itself; i.e.
+// whether the base object is an identified object or a function parameter.
+func isNonDerivedObject(v value.Value) bool {
+	if _, ok := v.(*ir.Param); ok {
+		return true
+	}
+	return isIdentifiedObject(v)
+}
@@ -0,0 +1,70 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestAliasNoAliasParam(t *testing.T) {
+	const src = `
+@g = global [4 x i32] zeroinitializer
+
+define void @f(i32* noalias %a, i32* noalias %b, i32* %c, i32** %pp) {
+entry:
+	%x = alloca i32
+	%a1 = getelementptr inbounds i32, i32* %a, i64 1
+	%b1 = getelementptr inbounds i32, i32* %b, i64 1
+	%b8 = bitcast i32* %b1 to i8*
+	%a8 = bitcast i32* %a to i8*
+	%c1 = getelementptr inbounds i32, i32* %c, i64 1
+	%g1 = getelementptr inbounds [4 x i32], [4 x i32]* @g, i64 0, i64 1
+	%p = load i32*, i32** %pp
+	ret void
+}`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	locals := make(map[string]value.Value)
+	for _, param := range f.Params {
+		locals[param.Name()] = param
+	}
+	for _, inst := range f.Blocks[0].Insts {
+		if v, ok := inst.(value.Named); ok {
+			locals[v.Name()] = v
+		}
+	}
+	golden := []struct {
+		a, b string
+		want AliasResult
+	}{
+		// Pointers derived from distinct noalias parameters.
+		{a: "a1", b: "b1", want: NoAlias},
+		{a: "a8", b: "b8", want: NoAlias},
+		// Noalias parameter and pointer derived from other parameter.
+		{a: "a1", b: "c1", want: NoAlias},
+		{a: "c", b: "b8", want: NoAlias},
+		// Noalias parameter and identified objects.
+		{a: "a", b: "x", want: NoAlias},
+		{a: "g1", b: "b1", want: NoAlias},
+		// Pointers derived from the same noalias parameter.
+		{a: "a", b: "a1", want: MayAlias},
+		{a: "a", b: "a8", want: MayAlias},
+		// Loaded pointer, which may be based on a noalias parameter.
+		{a: "a1", b: "p", want: MayAlias},
+		// Parameters without noalias attribute.
+		{a: "c", b: "pp", want: MayAlias},
+	}
+	for _, g := range golden {
+		a, b := locals[g.a], locals[g.b]
+		if got := Alias(a, b); got != g.want {
+			t.Errorf("alias result mismatch of %s and %s; expected %v, got %v", a.Ident(), b.Ident(), g.want, got)
+		}
+		if got := Alias(b, a); got != g.want {
+			t.Errorf("alias result mismatch of %s and %s; expected %v, got %v", b.Ident(), a.Ident(), g.want, got)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package pass
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/value"
+)
+
+// CanonicalizeCompares swaps the operands of the icmp and fcmp instructions of
+// the given function into a canonical order, and reports whether the function
+// was changed. Structurally equivalent comparisons thus have identical operands
+// and predicates, which improves the effectiveness of GVN and CSE.
+//
+// Operands are ordered by rank, with operands of higher rank on the left-hand
+// side; constants (including global variables and functions) have the lowest
+// rank, followed by function parameters, and all other values (e.g.
+// instructions). Operands of equal rank are left unchanged. When the operands
+// are swapped, the predicate is swapped accordingly, preserving the signedness
+// of integer predicates and the orderedness of floating-point predicates.
+//
+//    icmp slt i32 5, %x      -> icmp sgt i32 %x, 5
+//    fcmp uge double 1.0, %x -> fcmp ule double %x, 1.0
+//    icmp ult i32 %param, %y -> icmp ugt i32 %y, %param (for instruction %y)
+func CanonicalizeCompares(f *ir.Func) bool {
+	changed := false
+	for _, block := range f.Blocks {
+		for _, inst := range block.Insts {
+			switch inst := inst.(type) {
+			case *ir.InstICmp:
+				if operandRank(inst.X) < operandRank(inst.Y) {
+					inst.X, inst.Y = inst.Y, inst.X
+					inst.Pred = inst.Pred.Swapped()
+					changed = true
+				}
+			case *ir.InstFCmp:
+				if operandRank(inst.X) < operandRank(inst.Y) {
+					inst.X, inst.Y = inst.Y, inst.X
+					inst.Pred = inst.Pred.Swapped()
+					changed = true
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// operandRank returns the rank of the given operand in canonical operand order;
+// operands of higher rank are placed on the left-hand side.
+func operandRank(v value.Value) int {
+	switch v.(type) {
+	case constant.Constant:
+		return 0
+	case *ir.Param:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,88 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestCanonicalizeCompares(t *testing.T) {
+	golden := []struct {
+		in   string
+		want string
+	}{
+		// Constant on the left-hand side.
+		{
+			in: `
+define i1 @f(i32 %x) {
+entry:
+	%c = icmp slt i32 5, %x
+	ret i1 %c
+}`,
+			want: `define i1 @f(i32 %x) {
+entry:
+	%c = icmp sgt i32 %x, 5
+	ret i1 %c
+}`,
+		},
+		// Unsigned and floating-point predicates; parameter on the left-hand side
+		// of an instruction.
+		{
+			in: `
+define i1 @f(i32 %x, double %d) {
+entry:
+	%y = add i32 %x, 1
+	%c1 = icmp ule i32 %x, %y
+	%c2 = fcmp ugt double 1.0, %d
+	%c3 = fcmp olt double 1.0, %d
+	%c4 = fcmp une double 1.0, %d
+	%c5 = and i1 %c1, %c2
+	%c6 = and i1 %c3, %c4
+	%c7 = and i1 %c5, %c6
+	ret i1 %c7
+}`,
+			want: `define i1 @f(i32 %x, double %d) {
+entry:
+	%y = add i32 %x, 1
+	%c1 = icmp uge i32 %y, %x
+	%c2 = fcmp ult double %d, 1.0
+	%c3 = fcmp ogt double %d, 1.0
+	%c4 = fcmp une double %d, 1.0
+	%c5 = and i1 %c1, %c2
+	%c6 = and i1 %c3, %c4
+	%c7 = and i1 %c5, %c6
+	ret i1 %c7
+}`,
+		},
+		// Already canonical.
+		{
+			in: `
+define i1 @f(i32 %x, i32 %y) {
+entry:
+	%c1 = icmp eq i32 %x, 0
+	%c2 = icmp sgt i32 %y, %x
+	%c3 = and i1 %c1, %c2
+	ret i1 %c3
+}`,
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString("<stdin>", g.in)
+		if err != nil {
+			t.Errorf("unable to parse module; %v", err)
+			continue
+		}
+		f := m.Funcs[0]
+		want := g.want
+		if len(want) == 0 {
+			want = g.in[1:]
+		}
+		wantChanged := len(g.want) > 0
+		if changed := CanonicalizeCompares(f); changed != wantChanged {
+			t.Errorf("change mismatch of function %s; expected %v, got %v", f.Ident(), wantChanged, changed)
+		}
+		if got := f.LLString(); got != want {
+			t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package pass
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir"
+)
+
+// SplitCriticalEdges splits the critical edges of the given function, and
+// reports whether the function was changed. A critical edge is a control flow
+// edge from a basic block with multiple successors to a basic block with
+// multiple predecessors.
+//
+// A critical edge is split by inserting a new basic block on the edge, which
+// unconditionally branches to the target of the edge. The new basic block is
+// inserted after the source of the edge, and is named
+// "<source>.<target>_crit_edge" if both the source and the target are named.
+// Multiple control flow edges between the same source and target (e.g. switch
+// cases with the same target) are split by a single new basic block.
+//
+// The incoming values of phi instructions of the target from the source are
+// updated to be incoming from the new basic block.
+//
+// Only the edges of br and switch terminators are split, as the edges of other
+// terminators (e.g. indirectbr and invoke) may not be redirected to a new
+// basic block.
+func SplitCriticalEdges(f *ir.Func) bool {
+	preds := f.Predecessors()
+	changed := false
+	for i := 0; i < len(f.Blocks); i++ {
+		block := f.Blocks[i]
+		switch block.Term.(type) {
+		case *ir.TermCondBr, *ir.TermSwitch:
+			// Terminators with redirectable edges.
+		default:
+			continue
+		}
+		succs := uniqueBlocks(block.Term.Succs())
+		if len(succs) < 2 {
+			continue
+		}
+		var edges []*ir.Block
+		for _, succ := range succs {
+			if len(uniqueBlocks(preds[succ])) < 2 {
+				continue
+			}
+			edge := ir.NewBlock(critEdgeName(block, succ))
+			edge.Parent = f
+			edge.NewBr(succ)
+			block.Term = cloneTerm(block.Term, map[*ir.Block]*ir.Block{succ: edge})
+			replacePhiPred(succ, block, edge)
+			edges = append(edges, edge)
+		}
+		if len(edges) == 0 {
+			continue
+		}
+		// Insert new basic blocks after the source basic block.
+		blocks := make([]*ir.Block, 0, len(f.Blocks)+len(edges))
+		blocks = append(blocks, f.Blocks[:i+1]...)
+		blocks = append(blocks, edges...)
+		f.Blocks = append(blocks, f.Blocks[i+1:]...)
+		i += len(edges)
+		changed = true
+	}
+	return changed
+}
+
+// ### [ Helper functions ] ####################################################
+
+// replacePhiPred replaces the incoming values from the predecessor basic block
+// old of the phi instructions of the given basic block with a single incoming
+// value from the predecessor basic block new.
+func replacePhiPred(block, old, new *ir.Block) {
+	for _, inst := range block.Insts {
+		if _, ok := inst.(*ir.DbgRecord); ok {
+			// Debug records may be interleaved with phi instructions.
+			continue
+		}
+		phi, ok := inst.(*ir.InstPhi)
+		if !ok {
+			// Phi instructions are grouped at the start of basic blocks.
+			break
+		}
+		var incs []*ir.Incoming
+		replaced := false
+		for _, inc := range phi.Incs {
+			if inc.Pred == old {
+				if replaced {
+					continue
+				}
+				inc = ir.NewIncoming(inc.X, new)
+				replaced = true
+			}
+			incs = append(incs, inc)
+		}
+		phi.Incs = incs
+	}
+}
+
+// uniqueBlocks returns the given basic blocks with duplicates removed, in order
+// of first occurrence.
+func uniqueBlocks(blocks []*ir.Block) []*ir.Block {
+	var unique []*ir.Block
+	seen := make(map[*ir.Block]bool)
+	for _, block := range blocks {
+		if !seen[block] {
+			seen[block] = true
+			unique = append(unique, block)
+		}
+	}
+	return unique
+}
+
+// critEdgeName returns the name of the new basic block splitting the critical
+// edge from the source basic block to the target basic block. An empty name is
+// returned if either basic block is unnamed.
+func critEdgeName(source, target *ir.Block) string {
+	if source.IsUnnamed() || target.IsUnnamed() {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s_crit_edge", source.Name(), target.Name())
+}
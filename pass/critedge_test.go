@@ -0,0 +1,152 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestSplitCriticalEdges(t *testing.T) {
+	golden := []struct {
+		in   string
+		want string
+	}{
+		// Diamond with a critical edge from entry to exit.
+		{
+			in: `
+define i32 @f(i1 %cond) {
+entry:
+	br i1 %cond, label %then, label %exit
+
+then:
+	br label %exit
+
+exit:
+	%x = phi i32 [ 0, %entry ], [ 1, %then ]
+	ret i32 %x
+}`,
+			want: `define i32 @f(i1 %cond) {
+entry:
+	br i1 %cond, label %then, label %entry.exit_crit_edge
+
+entry.exit_crit_edge:
+	br label %exit
+
+then:
+	br label %exit
+
+exit:
+	%x = phi i32 [ 0, %entry.exit_crit_edge ], [ 1, %then ]
+	ret i32 %x
+}`,
+		},
+		// Critical edge to a basic block with a debug record preceding phi
+		// instructions.
+		{
+			in: `
+define i32 @f(i1 %cond) {
+entry:
+	br i1 %cond, label %then, label %exit
+
+then:
+	br label %exit
+
+exit:
+	#dbg_value(i32 0, !0, !DIExpression(), !1)
+	%x = phi i32 [ 0, %entry ], [ 1, %then ]
+	ret i32 %x
+}
+
+!0 = !DILocalVariable(name: "x", scope: !2)
+!1 = !DILocation(line: 1, scope: !2)
+!2 = distinct !DISubprogram(name: "f")`,
+			want: `define i32 @f(i1 %cond) {
+entry:
+	br i1 %cond, label %then, label %entry.exit_crit_edge
+
+entry.exit_crit_edge:
+	br label %exit
+
+then:
+	br label %exit
+
+exit:
+	#dbg_value(i32 0, !0, !DIExpression(), !1)
+	%x = phi i32 [ 0, %entry.exit_crit_edge ], [ 1, %then ]
+	ret i32 %x
+}`,
+		},
+		// Multiple switch cases sharing a critical edge.
+		{
+			in: `
+define i32 @f(i32 %y) {
+entry:
+	switch i32 %y, label %a [
+		i32 0, label %exit
+		i32 1, label %exit
+	]
+
+a:
+	br label %exit
+
+exit:
+	%x = phi i32 [ 0, %entry ], [ 0, %entry ], [ 1, %a ]
+	ret i32 %x
+}`,
+			want: `define i32 @f(i32 %y) {
+entry:
+	switch i32 %y, label %a [
+		i32 0, label %entry.exit_crit_edge
+		i32 1, label %entry.exit_crit_edge
+	]
+
+entry.exit_crit_edge:
+	br label %exit
+
+a:
+	br label %exit
+
+exit:
+	%x = phi i32 [ 0, %entry.exit_crit_edge ], [ 1, %a ]
+	ret i32 %x
+}`,
+		},
+		// No critical edges.
+		{
+			in: `
+define i32 @f(i1 %cond) {
+entry:
+	br i1 %cond, label %then, label %else
+
+then:
+	br label %exit
+
+else:
+	br label %exit
+
+exit:
+	%x = phi i32 [ 0, %then ], [ 1, %else ]
+	ret i32 %x
+}`,
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString("<stdin>", g.in)
+		if err != nil {
+			t.Errorf("unable to parse module; %v", err)
+			continue
+		}
+		f := m.Funcs[0]
+		want := g.want
+		if len(want) == 0 {
+			want = g.in[1:]
+		}
+		wantChanged := len(g.want) > 0
+		if changed := SplitCriticalEdges(f); changed != wantChanged {
+			t.Errorf("change mismatch of function %s; expected %v, got %v", f.Ident(), wantChanged, changed)
+		}
+		if got := f.LLString(); got != want {
+			t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package pass
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// ForwardStoreToLoad replaces loads of the given function with the value of a
+// preceding store to the same memory location, and reports whether the function
+// was changed.
+//
+// A load is replaced with the stored value of a store if the store dominates
+// the load, the store destination must alias the load source (as determined by
+// Alias), the stored value has the type of the load, and no instruction on any
+// path from the store to the load may write to the memory location. Calls,
+// fences and atomic instructions are assumed to write to all memory. Volatile
+// and atomic loads and stores are never forwarded.
+//
+// Uses of forwarded loads are replaced using ReplaceAllUsesWith, after which
+// the forwarded loads are removed.
+func ForwardStoreToLoad(f *ir.Func) bool {
+	if len(f.Blocks) == 0 {
+		return false
+	}
+	dt := f.DomTree()
+	preds := f.Predecessors()
+	// Stores visited so far; since basic blocks are visited in reverse
+	// post-order, the stores of dominating basic blocks are visited before the
+	// loads they dominate.
+	var stores []storeInst
+	changed := false
+	for _, block := range f.ReversePostOrder() {
+		for i := 0; i < len(block.Insts); i++ {
+			switch inst := block.Insts[i].(type) {
+			case *ir.InstStore:
+				if !inst.Atomic && !inst.Volatile {
+					stores = append(stores, storeInst{inst: inst, block: block})
+				}
+			case *ir.InstLoad:
+				if inst.Atomic || inst.Volatile {
+					continue
+				}
+				x := forwardedValue(dt, preds, stores, inst, block)
+				if x == nil {
+					continue
+				}
+				f.ReplaceAllUsesWith(inst, x)
+				block.Insts = append(block.Insts[:i], block.Insts[i+1:]...)
+				i--
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// storeInst is a store instruction and its parent basic block.
+type storeInst struct {
+	// Store instruction.
+	inst *ir.InstStore
+	// Parent basic block of the store instruction.
+	block *ir.Block
+}
+
+// forwardedValue returns the stored value of the nearest of the given stores
+// which may be forwarded to the load of block, or nil if not present.
+func forwardedValue(dt *ir.DomTree, preds map[*ir.Block][]*ir.Block, stores []storeInst, load *ir.InstLoad, block *ir.Block) value.Value {
+	for i := len(stores) - 1; i >= 0; i-- {
+		s := stores[i]
+		store := s.inst
+		if Alias(store.Dst, load.Src) != MustAlias {
+			continue
+		}
+		if !dt.Dominates(s.block, block) || !types.Equal(store.Src.Type(), load.Type()) {
+			continue
+		}
+		if isClobbered(preds, load.Src, store, s.block, load, block) {
+			continue
+		}
+		return store.Src
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestForwardStoreToLoad(t *testing.T) {
+	golden := []struct {
+		in   string
+		want string
+	}{
+		// Store forwarded to a load within a straight-line basic block, across a
+		// store to a non-aliasing pointer.
+		{
+			in: `
+define i32 @f(i32 %x, i32 %y) {
+entry:
+	%p = alloca i32
+	%q = alloca i32
+	store i32 %x, i32* %p
+	store i32 %y, i32* %q
+	%a = load i32, i32* %p
+	%b = load i32, i32* %q
+	%sum = add i32 %a, %b
+	ret i32 %sum
+}`,
+			want: `define i32 @f(i32 %x, i32 %y) {
+entry:
+	%p = alloca i32
+	%q = alloca i32
+	store i32 %x, i32* %p
+	store i32 %y, i32* %q
+	%sum = add i32 %x, %y
+	ret i32 %sum
+}`,
+		},
+		// Store forwarded to a load of a dominated basic block.
+		{
+			in: `
+define i32 @f(i32 %x, i1 %cond) {
+entry:
+	%p = alloca i32
+	store i32 %x, i32* %p
+	br i1 %cond, label %then, label %exit
+
+then:
+	%a = load i32, i32* %p
+	ret i32 %a
+
+exit:
+	ret i32 0
+}`,
+			want: `define i32 @f(i32 %x, i1 %cond) {
+entry:
+	%p = alloca i32
+	store i32 %x, i32* %p
+	br i1 %cond, label %then, label %exit
+
+then:
+	ret i32 %x
+
+exit:
+	ret i32 0
+}`,
+		},
+		// Store clobbered by an aliasing store on one path, store clobbered by a
+		// call, and volatile load.
+		{
+			in: `
+declare void @g()
+
+define i32 @f(i32 %x, i32* %q, i1 %cond) {
+entry:
+	%p = alloca i32
+	store i32 %x, i32* %p
+	br i1 %cond, label %then, label %exit
+
+then:
+	store i32 0, i32* %q
+	br label %exit
+
+exit:
+	%a = load i32, i32* %p
+	store i32 %x, i32* %p
+	call void @g()
+	%b = load i32, i32* %p
+	store i32 %x, i32* %p
+	%c = load volatile i32, i32* %p
+	%sum1 = add i32 %a, %b
+	%sum2 = add i32 %sum1, %c
+	ret i32 %sum2
+}`,
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString("<stdin>", g.in)
+		if err != nil {
+			t.Errorf("unable to parse module; %v", err)
+			continue
+		}
+		f := m.Funcs[len(m.Funcs)-1]
+		want := g.want
+		if len(want) == 0 {
+			want = f.LLString()
+		}
+		wantChanged := len(g.want) > 0
+		if changed := ForwardStoreToLoad(f); changed != wantChanged {
+			t.Errorf("change mismatch of function %s; expected %v, got %v", f.Ident(), wantChanged, changed)
+		}
+		if got := f.LLString(); got != want {
+			t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+		}
+	}
+}
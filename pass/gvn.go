@@ -0,0 +1,341 @@
+package pass
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/value"
+)
+
+// GVN eliminates redundant computations of the given function based on global
+// value numbering, and reports whether the function was changed.
+//
+// Basic blocks are visited in reverse post-order, and instructions computing
+// the same value as an earlier instruction (the leader) are replaced with the
+// leader, provided that the leader dominates the instruction. Two instructions
+// compute the same value if they have the same opcode, flags and type, and
+// their operands have the same value numbers; operands of commutative
+// instructions are compared irrespective of order.
+//
+// Non-volatile, non-atomic loads are redundant if the leader load reads from a
+// pointer with the same value number, and no instruction on any path from the
+// leader to the load may write to the memory location of the pointer, as
+// determined by Alias. Calls, fences and atomic instructions are assumed to
+// write to all memory.
+//
+// Freeze instructions are never redundant, as two freeze instructions of the
+// same undef or poison operand may yield different values.
+//
+// Uses of redundant instructions are replaced using ReplaceAllUsesWith, after
+// which the redundant instructions are removed.
+func GVN(f *ir.Func) bool {
+	if len(f.Blocks) == 0 {
+		return false
+	}
+	g := &gvn{
+		dt:      f.DomTree(),
+		preds:   f.Predecessors(),
+		nums:    make(map[value.Value]int),
+		consts:  make(map[string]int),
+		leaders: make(map[string][]leader),
+	}
+	changed := false
+	for _, block := range f.ReversePostOrder() {
+		for i := 0; i < len(block.Insts); i++ {
+			inst := block.Insts[i]
+			key, ok := g.key(inst)
+			if !ok {
+				continue
+			}
+			if l := g.findLeader(key, inst, block); l != nil {
+				f.ReplaceAllUsesWith(inst.(value.Value), l)
+				block.Insts = append(block.Insts[:i], block.Insts[i+1:]...)
+				i--
+				changed = true
+				continue
+			}
+			g.leaders[key] = append(g.leaders[key], leader{inst: inst, block: block})
+		}
+	}
+	return changed
+}
+
+// gvn is a global value numbering pass over a function.
+type gvn struct {
+	// Dominator tree of the function.
+	dt *ir.DomTree
+	// Predecessor basic blocks of each basic block.
+	preds map[*ir.Block][]*ir.Block
+	// Value numbers of non-constant values.
+	nums map[value.Value]int
+	// Value numbers of constants, keyed by their LLVM syntax representation.
+	consts map[string]int
+	// Leader instructions, keyed by expression.
+	leaders map[string][]leader
+}
+
+// leader is a leader instruction; i.e. the first instruction computing a given
+// value.
+type leader struct {
+	// Leader instruction.
+	inst ir.Instruction
+	// Parent basic block of the leader instruction.
+	block *ir.Block
+}
+
+// findLeader returns a leader instruction of the given expression key which
+// dominates inst of block and computes the same value, or nil if not present.
+func (g *gvn) findLeader(key string, inst ir.Instruction, block *ir.Block) value.Value {
+	for _, l := range g.leaders[key] {
+		if !g.dt.Dominates(l.block, block) {
+			continue
+		}
+		if load, ok := inst.(*ir.InstLoad); ok && isClobbered(g.preds, load.Src, l.inst, l.block, inst, block) {
+			continue
+		}
+		return l.inst.(value.Value)
+	}
+	return nil
+}
+
+// key returns the expression key of the given instruction, based on its
+// opcode, flags, type and the value numbers of its operands. The boolean
+// return value indicates whether the instruction is subject to value numbering.
+func (g *gvn) key(inst ir.Instruction) (string, bool) {
+	op, ok := opcode(inst)
+	if !ok {
+		return "", false
+	}
+	var nums []int
+	for _, use := range inst.Operands() {
+		nums = append(nums, g.valueNum(*use))
+	}
+	if isCommutative(inst) {
+		sort.Ints(nums)
+	}
+	return fmt.Sprintf("%s %v %v", op, inst.(value.Value).Type(), nums), true
+}
+
+// valueNum returns the value number of the given value. Equal constants have
+// the same value number.
+func (g *gvn) valueNum(v value.Value) int {
+	if c, ok := v.(constant.Constant); ok {
+		s := c.String()
+		if num, ok := g.consts[s]; ok {
+			return num
+		}
+		num := len(g.nums) + len(g.consts)
+		g.consts[s] = num
+		return num
+	}
+	if num, ok := g.nums[v]; ok {
+		return num
+	}
+	num := len(g.nums) + len(g.consts)
+	g.nums[v] = num
+	return num
+}
+
+// isClobbered reports whether the memory location pointed to by ptr may be
+// written to by an instruction on any path from the instruction from of block
+// fromBlock to the instruction to of block toBlock, where fromBlock dominates
+// toBlock. The predecessor basic blocks of each basic block are given by preds.
+func isClobbered(preds map[*ir.Block][]*ir.Block, ptr value.Value, from ir.Instruction, fromBlock *ir.Block, to ir.Instruction, toBlock *ir.Block) bool {
+	if fromBlock == toBlock {
+		return clobbers(instsBetween(fromBlock, from, to), nil, ptr)
+	}
+	// Instructions after from in fromBlock, and before to in toBlock.
+	if clobbers(instsBetween(fromBlock, from, nil), fromBlock.Term, ptr) {
+		return true
+	}
+	if clobbers(instsBetween(toBlock, nil, to), nil, ptr) {
+		return true
+	}
+	// Basic blocks on paths from fromBlock to toBlock; found by traversing
+	// predecessors backwards from toBlock, stopping at fromBlock. Any path
+	// re-entering fromBlock passes through from, which reloads the value.
+	visited := make(map[*ir.Block]bool)
+	queue := append([]*ir.Block(nil), preds[toBlock]...)
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+		if block == fromBlock || visited[block] {
+			continue
+		}
+		visited[block] = true
+		if clobbers(block.Insts, block.Term, ptr) {
+			return true
+		}
+		queue = append(queue, preds[block]...)
+	}
+	return false
+}
+
+// clobbers reports whether any of the given instructions or the terminator may
+// write to the memory location pointed to by ptr.
+func clobbers(insts []ir.Instruction, term ir.Terminator, ptr value.Value) bool {
+	for _, inst := range insts {
+		switch inst := inst.(type) {
+		case *ir.InstStore:
+			if Alias(inst.Dst, ptr) != NoAlias {
+				return true
+			}
+		case *ir.InstLoad:
+			if inst.Atomic || inst.Volatile {
+				return true
+			}
+		case *ir.InstCall, *ir.InstFence, *ir.InstCmpXchg, *ir.InstAtomicRMW, *ir.InstVAArg:
+			return true
+		}
+	}
+	switch term.(type) {
+	case *ir.TermInvoke, *ir.TermCallBr:
+		return true
+	}
+	return false
+}
+
+// ### [ Helper functions ] ####################################################
+
+// instsBetween returns the instructions of the given basic block strictly
+// between from and to. A nil from denotes the start of the basic block, and a
+// nil to denotes the end of the basic block.
+func instsBetween(block *ir.Block, from, to ir.Instruction) []ir.Instruction {
+	start, end := 0, len(block.Insts)
+	for i, inst := range block.Insts {
+		switch inst {
+		case from:
+			start = i + 1
+		case to:
+			end = i
+		}
+	}
+	if start > end {
+		return nil
+	}
+	return block.Insts[start:end]
+}
+
+// opcode returns the opcode of the given instruction, including flags and
+// other non-operand properties which affect the computed value. The boolean
+// return value indicates whether the instruction is subject to value
+// numbering; i.e. whether it is free of side effects and computes a value
+// solely based on its operands (and memory, in the case of loads).
+func opcode(inst ir.Instruction) (string, bool) {
+	switch inst := inst.(type) {
+	// Unary instructions.
+	case *ir.InstFNeg:
+		return fmt.Sprintf("fneg %v", inst.FastMathFlags), true
+	// Binary instructions.
+	case *ir.InstAdd:
+		return fmt.Sprintf("add %v", inst.OverflowFlags), true
+	case *ir.InstFAdd:
+		return fmt.Sprintf("fadd %v", inst.FastMathFlags), true
+	case *ir.InstSub:
+		return fmt.Sprintf("sub %v", inst.OverflowFlags), true
+	case *ir.InstFSub:
+		return fmt.Sprintf("fsub %v", inst.FastMathFlags), true
+	case *ir.InstMul:
+		return fmt.Sprintf("mul %v", inst.OverflowFlags), true
+	case *ir.InstFMul:
+		return fmt.Sprintf("fmul %v", inst.FastMathFlags), true
+	case *ir.InstUDiv:
+		return fmt.Sprintf("udiv %v", inst.Exact), true
+	case *ir.InstSDiv:
+		return fmt.Sprintf("sdiv %v", inst.Exact), true
+	case *ir.InstFDiv:
+		return fmt.Sprintf("fdiv %v", inst.FastMathFlags), true
+	case *ir.InstURem:
+		return "urem", true
+	case *ir.InstSRem:
+		return "srem", true
+	case *ir.InstFRem:
+		return fmt.Sprintf("frem %v", inst.FastMathFlags), true
+	// Bitwise instructions.
+	case *ir.InstShl:
+		return fmt.Sprintf("shl %v", inst.OverflowFlags), true
+	case *ir.InstLShr:
+		return fmt.Sprintf("lshr %v", inst.Exact), true
+	case *ir.InstAShr:
+		return fmt.Sprintf("ashr %v", inst.Exact), true
+	case *ir.InstAnd:
+		return "and", true
+	case *ir.InstOr:
+		return fmt.Sprintf("or %v", inst.Disjoint), true
+	case *ir.InstXor:
+		return "xor", true
+	// Vector instructions.
+	case *ir.InstExtractElement:
+		return "extractelement", true
+	case *ir.InstInsertElement:
+		return "insertelement", true
+	case *ir.InstShuffleVector:
+		return "shufflevector", true
+	// Aggregate instructions.
+	case *ir.InstExtractValue:
+		return fmt.Sprintf("extractvalue %v", inst.Indices), true
+	case *ir.InstInsertValue:
+		return fmt.Sprintf("insertvalue %v", inst.Indices), true
+	// Memory instructions.
+	case *ir.InstLoad:
+		if inst.Atomic || inst.Volatile {
+			return "", false
+		}
+		return "load", true
+	case *ir.InstGetElementPtr:
+		return fmt.Sprintf("getelementptr %v %v %v", inst.InBounds, inst.Flags, inst.ElemType), true
+	// Conversion instructions; the destination type is the instruction type.
+	case *ir.InstTrunc:
+		return "trunc", true
+	case *ir.InstZExt:
+		return fmt.Sprintf("zext %v", inst.NNeg), true
+	case *ir.InstSExt:
+		return "sext", true
+	case *ir.InstFPTrunc:
+		return "fptrunc", true
+	case *ir.InstFPExt:
+		return "fpext", true
+	case *ir.InstFPToUI:
+		return "fptoui", true
+	case *ir.InstFPToSI:
+		return "fptosi", true
+	case *ir.InstUIToFP:
+		return "uitofp", true
+	case *ir.InstSIToFP:
+		return "sitofp", true
+	case *ir.InstPtrToInt:
+		return "ptrtoint", true
+	case *ir.InstIntToPtr:
+		return "inttoptr", true
+	case *ir.InstBitCast:
+		return "bitcast", true
+	case *ir.InstAddrSpaceCast:
+		return "addrspacecast", true
+	// Other instructions.
+	case *ir.InstICmp:
+		return fmt.Sprintf("icmp %v %v", inst.Pred, inst.SameSign), true
+	case *ir.InstFCmp:
+		return fmt.Sprintf("fcmp %v %v", inst.Pred, inst.FastMathFlags), true
+	case *ir.InstSelect:
+		return "select", true
+	case *ir.InstFreeze:
+		// Each freeze of undef or poison yields a distinct arbitrary value.
+		return "", false
+	}
+	return "", false
+}
+
+// isCommutative reports whether the operands of the given instruction may be
+// reordered without changing the computed value.
+func isCommutative(inst ir.Instruction) bool {
+	switch inst := inst.(type) {
+	case *ir.InstAdd, *ir.InstFAdd, *ir.InstMul, *ir.InstFMul, *ir.InstAnd, *ir.InstOr, *ir.InstXor:
+		return true
+	case *ir.InstICmp:
+		return inst.Pred == enum.IPredEQ || inst.Pred == enum.IPredNE
+	}
+	return false
+}
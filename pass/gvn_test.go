@@ -0,0 +1,139 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/types"
+)
+
+func TestGVN(t *testing.T) {
+	golden := []struct {
+		in   string
+		want string
+	}{
+		// Redundant load across a dominating store to a non-aliasing pointer, and
+		// redundant commutative computation in a dominated block.
+		{
+			in: `
+define i32 @f(i32 %x, i32 %y, i1 %cond) {
+entry:
+	%p = alloca i32
+	%q = alloca i32
+	%a = load i32, i32* %p
+	store i32 %x, i32* %q
+	%sum1 = add i32 %x, %y
+	br i1 %cond, label %then, label %exit
+
+then:
+	%b = load i32, i32* %p
+	%sum2 = add i32 %y, %x
+	%res = add i32 %b, %sum2
+	ret i32 %res
+
+exit:
+	ret i32 %a
+}`,
+			want: `define i32 @f(i32 %x, i32 %y, i1 %cond) {
+entry:
+	%p = alloca i32
+	%q = alloca i32
+	%a = load i32, i32* %p
+	store i32 %x, i32* %q
+	%sum1 = add i32 %x, %y
+	br i1 %cond, label %then, label %exit
+
+then:
+	%res = add i32 %a, %sum1
+	ret i32 %res
+
+exit:
+	ret i32 %a
+}`,
+		},
+		// Load clobbered by an aliasing store on one path, and computation not
+		// dominated by the leader.
+		{
+			in: `
+define i32 @f(i32 %x, i32 %y, i1 %cond) {
+entry:
+	%p = alloca i32
+	%a = load i32, i32* %p
+	br i1 %cond, label %then, label %exit
+
+then:
+	store i32 %x, i32* %p
+	%sum1 = add i32 %x, %y
+	br label %exit
+
+exit:
+	%b = load i32, i32* %p
+	%sum2 = add i32 %x, %y
+	%res = add i32 %b, %sum2
+	ret i32 %res
+}`,
+			want: `define i32 @f(i32 %x, i32 %y, i1 %cond) {
+entry:
+	%p = alloca i32
+	%a = load i32, i32* %p
+	br i1 %cond, label %then, label %exit
+
+then:
+	store i32 %x, i32* %p
+	%sum1 = add i32 %x, %y
+	br label %exit
+
+exit:
+	%b = load i32, i32* %p
+	%sum2 = add i32 %x, %y
+	%res = add i32 %b, %sum2
+	ret i32 %res
+}`,
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString("<stdin>", g.in)
+		if err != nil {
+			t.Errorf("unable to parse module; %v", err)
+			continue
+		}
+		f := m.Funcs[0]
+		wantChanged := g.in[1:] != g.want
+		if changed := GVN(f); changed != wantChanged {
+			t.Errorf("change mismatch of function %s; expected %v, got %v", f.Ident(), wantChanged, changed)
+		}
+		if got := f.LLString(); got != g.want {
+			t.Errorf("function mismatch; expected `%s`, got `%s`", g.want, got)
+		}
+	}
+}
+
+func TestGVNFreeze(t *testing.T) {
+	// Two freeze instructions of the same operand may yield different values,
+	// and are therefore not merged.
+	m := ir.NewModule()
+	x := ir.NewParam("x", types.I32)
+	f := m.NewFunc("f", types.I32, x)
+	entry := f.NewBlock("entry")
+	a := entry.NewFreeze(x)
+	a.SetName("a")
+	b := entry.NewFreeze(x)
+	b.SetName("b")
+	sum := entry.NewAdd(a, b)
+	sum.SetName("sum")
+	entry.NewRet(sum)
+	const want = `define i32 @f(i32 %x) {
+entry:
+	%a = freeze i32 %x
+	%b = freeze i32 %x
+	%sum = add i32 %a, %b
+	ret i32 %sum
+}`
+	if GVN(f) {
+		t.Errorf("unexpected change of function %s", f.Ident())
+	}
+	if got := f.LLString(); got != want {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+	}
+}
@@ -0,0 +1,83 @@
+package pass
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+)
+
+// HoistStaticAllocas moves the static alloca instructions of the given function
+// to the entry basic block, and reports whether the function was changed. An
+// alloca instruction is static if it allocates a constant number of elements
+// (i.e. its element count is either absent or an integer constant).
+//
+// The hoisted alloca instructions are inserted after the leading alloca
+// instructions of the entry basic block, in the order of the basic blocks of
+// the function.
+//
+// Variable-sized and inalloca alloca instructions, and alloca instructions
+// within loops are not hoisted, as hoisting would change their semantics; an
+// alloca instruction within a loop allocates new stack memory on each
+// iteration.
+func HoistStaticAllocas(f *ir.Func) bool {
+	entry := f.Entry()
+	if entry == nil {
+		return false
+	}
+	inLoop := make(map[*ir.Block]bool)
+	for _, loop := range f.Loops() {
+		for _, block := range loop.Blocks {
+			inLoop[block] = true
+		}
+	}
+	var hoisted []ir.Instruction
+	for _, block := range f.Blocks {
+		if block == entry || inLoop[block] {
+			continue
+		}
+		insts := block.Insts[:0]
+		for _, inst := range block.Insts {
+			if alloca, ok := inst.(*ir.InstAlloca); ok && isStaticAlloca(alloca) {
+				hoisted = append(hoisted, alloca)
+				continue
+			}
+			insts = append(insts, inst)
+		}
+		block.Insts = insts
+	}
+	if len(hoisted) == 0 {
+		return false
+	}
+	// Insert after the leading alloca instructions of the entry basic block,
+	// and the debug records in between.
+	i := 0
+	for j, inst := range entry.Insts {
+		if _, ok := inst.(*ir.DbgRecord); ok {
+			continue
+		}
+		if _, ok := inst.(*ir.InstAlloca); !ok {
+			break
+		}
+		i = j + 1
+	}
+	insts := make([]ir.Instruction, 0, len(entry.Insts)+len(hoisted))
+	insts = append(insts, entry.Insts[:i]...)
+	insts = append(insts, hoisted...)
+	insts = append(insts, entry.Insts[i:]...)
+	entry.Insts = insts
+	return true
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isStaticAlloca reports whether the given alloca instruction allocates a
+// constant number of elements.
+func isStaticAlloca(alloca *ir.InstAlloca) bool {
+	if alloca.InAlloca {
+		return false
+	}
+	if alloca.NElems == nil {
+		return true
+	}
+	_, ok := alloca.NElems.(*constant.Int)
+	return ok
+}
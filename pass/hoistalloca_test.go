@@ -0,0 +1,86 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestHoistStaticAllocas(t *testing.T) {
+	golden := []struct {
+		in   string
+		want string
+	}{
+		// Static alloca after a call in a non-entry block; dynamic alloca left
+		// in place.
+		{
+			in: `
+define void @f(i1 %cond, i32 %n) {
+entry:
+	%a = alloca i32
+	store i32 0, i32* %a
+	br i1 %cond, label %then, label %exit
+
+then:
+	%b = alloca [4 x i8], align 4
+	%c = alloca i32, i32 %n
+	%d = alloca i64, i32 2
+	store i32 1, i32* %c
+	br label %exit
+
+exit:
+	ret void
+}`,
+			want: `define void @f(i1 %cond, i32 %n) {
+entry:
+	%a = alloca i32
+	%b = alloca [4 x i8], align 4
+	%d = alloca i64, i32 2
+	store i32 0, i32* %a
+	br i1 %cond, label %then, label %exit
+
+then:
+	%c = alloca i32, i32 %n
+	store i32 1, i32* %c
+	br label %exit
+
+exit:
+	ret void
+}`,
+		},
+		// Static alloca within a loop.
+		{
+			in: `
+define void @g(i1 %cond) {
+entry:
+	br label %loop
+
+loop:
+	%a = alloca i32
+	br i1 %cond, label %loop, label %exit
+
+exit:
+	ret void
+}`,
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString("<stdin>", g.in)
+		if err != nil {
+			t.Errorf("unable to parse module; %v", err)
+			continue
+		}
+		f := m.Funcs[0]
+		want := g.want
+		if len(want) == 0 {
+			want = g.in[1:]
+		}
+		wantChanged := len(g.want) > 0
+		if changed := HoistStaticAllocas(f); changed != wantChanged {
+			t.Errorf("change mismatch of function %s; expected %v, got %v", f.Ident(), wantChanged, changed)
+		}
+		if got := f.LLString(); got != want {
+			t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package pass
+
+import (
+	"strings"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/pkg/errors"
+)
+
+// CanInline checks the preconditions of inlining the callee of the given call
+// into the caller, and returns an error describing why the callee cannot be
+// inlined, if any. Inliners should invoke CanInline before inlining a call, to
+// refuse calls which cannot be correctly inlined rather than producing invalid
+// IR.
+//
+// A callee may be inlined if:
+//
+//    * the callee is a function definition called directly (not through a
+//      function pointer, bitcast or alias);
+//    * the arguments of the call match the parameters of the callee;
+//    * the callee contains no musttail calls, as these must remain immediately
+//      followed by the ret of the callee;
+//    * the callee does not access its variable arguments (through va_arg
+//      instructions or calls to @llvm.va_start), as these refer to the
+//      arguments of the function in which they are executed;
+//    * the callee contains no indirectbr terminators, as the block addresses of
+//      the callee may not be redirected to the inlined basic blocks.
+func CanInline(call *ir.InstCall) error {
+	callee, ok := call.Callee.(*ir.Func)
+	if !ok {
+		return errors.Errorf("unable to inline indirect call %s", call.LLString())
+	}
+	if len(callee.Blocks) == 0 {
+		return errors.Errorf("unable to inline call to function declaration %s", callee.Ident())
+	}
+	switch {
+	case callee.Sig.Variadic && len(call.Args) < len(callee.Params):
+		return errors.Errorf("unable to inline call to %s; expected at least %d arguments, got %d", callee.Ident(), len(callee.Params), len(call.Args))
+	case !callee.Sig.Variadic && len(call.Args) != len(callee.Params):
+		return errors.Errorf("unable to inline call to %s; expected %d arguments, got %d", callee.Ident(), len(callee.Params), len(call.Args))
+	}
+	for _, block := range callee.Blocks {
+		for _, inst := range block.Insts {
+			switch inst := inst.(type) {
+			case *ir.InstCall:
+				if inst.Tail == enum.TailMustTail {
+					return errors.Errorf("unable to inline call to %s containing musttail call %s; in block %s", callee.Ident(), inst.LLString(), block.Ident())
+				}
+				if isVAStart(inst) {
+					return errors.Errorf("unable to inline call to variadic function %s calling @llvm.va_start; in block %s", callee.Ident(), block.Ident())
+				}
+			case *ir.InstVAArg:
+				return errors.Errorf("unable to inline call to variadic function %s containing va_arg instruction %s; in block %s", callee.Ident(), inst.Ident(), block.Ident())
+			}
+		}
+		if _, ok := block.Term.(*ir.TermIndirectBr); ok {
+			return errors.Errorf("unable to inline call to %s containing indirectbr terminator; in block %s", callee.Ident(), block.Ident())
+		}
+	}
+	return nil
+}
+
+// isVAStart reports whether the given call is a call to the @llvm.va_start
+// intrinsic.
+func isVAStart(call *ir.InstCall) bool {
+	callee, ok := call.Callee.(*ir.Func)
+	if !ok {
+		return false
+	}
+	// Overloaded intrinsics are suffixed by their pointer type (e.g.
+	// @llvm.va_start.p0 of later versions of LLVM).
+	name := callee.Name()
+	return name == "llvm.va_start" || strings.HasPrefix(name, "llvm.va_start.")
+}
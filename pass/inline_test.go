@@ -0,0 +1,95 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+	"github.com/llir/llvm/ir"
+)
+
+func TestCanInline(t *testing.T) {
+	const src = `
+declare void @llvm.va_start(i8*)
+
+declare i32 @decl(i32)
+
+define i32 @add(i32 %x, i32 %y) {
+entry:
+	%z = add i32 %x, %y
+	ret i32 %z
+}
+
+define i32 @sum(i32 %n, ...) {
+entry:
+	%ap = alloca i8
+	call void @llvm.va_start(i8* %ap)
+	%x = va_arg i8* %ap, i32
+	ret i32 %x
+}
+
+define i32 @first(i8* %ap, ...) {
+entry:
+	%x = va_arg i8* %ap, i32
+	ret i32 %x
+}
+
+define i32 @ignore(i32 %n, ...) {
+entry:
+	ret i32 %n
+}
+
+define i32 @tail(i32 %x) {
+entry:
+	%y = musttail call i32 @add(i32 %x, i32 1)
+	ret i32 %y
+}
+
+define void @main(i8* %ap) {
+entry:
+	%a = call i32 @add(i32 1, i32 2)
+	%b = call i32 (i32, ...) @sum(i32 1, i32 2)
+	%c = call i32 (i8*, ...) @first(i8* %ap)
+	%d = call i32 (i32, ...) @ignore(i32 1, i32 2)
+	%e = call i32 @tail(i32 1)
+	%f = call i32 @decl(i32 1)
+	ret void
+}`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	golden := []struct {
+		err string
+	}{
+		// Direct call to function definition.
+		{},
+		// Variadic callee calling @llvm.va_start.
+		{err: "unable to inline call to variadic function @sum calling @llvm.va_start; in block %entry"},
+		// Variadic callee containing va_arg instruction.
+		{err: "unable to inline call to variadic function @first containing va_arg instruction %x; in block %entry"},
+		// Variadic callee not accessing its variable arguments.
+		{},
+		// Callee containing musttail call.
+		{err: "unable to inline call to @tail containing musttail call %y = musttail call i32 @add(i32 %x, i32 1); in block %entry"},
+		// Function declaration.
+		{err: "unable to inline call to function declaration @decl"},
+	}
+	main := m.Funcs[len(m.Funcs)-1]
+	insts := main.Blocks[0].Insts
+	if len(insts) != len(golden) {
+		t.Fatalf("call count mismatch; expected %d, got %d", len(golden), len(insts))
+	}
+	for i, g := range golden {
+		call := insts[i].(*ir.InstCall)
+		err := CanInline(call)
+		if len(g.err) == 0 {
+			if err != nil {
+				t.Errorf("unexpected error for call %s; %v", call.Ident(), err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != g.err {
+			t.Errorf("error mismatch for call %s; expected %q, got %v", call.Ident(), g.err, err)
+		}
+	}
+}
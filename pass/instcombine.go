@@ -0,0 +1,501 @@
+package pass
+
+import (
+	"math/big"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// InstCombineLite simplifies instructions of the given function based on
+// algebraic identities, and reports whether the function was changed.
+//
+// The following identities are applied (commuted variants included).
+//
+//    add x, 0          -> x
+//    sub x, 0          -> x
+//    sub x, x          -> 0
+//    sub 0, (sub 0, x) -> x
+//    mul x, 1          -> x
+//    mul x, 0          -> 0
+//    and x, x          -> x
+//    and x, 0          -> 0
+//    and x, -1         -> x
+//    or x, x           -> x
+//    or x, 0           -> x
+//    or x, -1          -> -1
+//    xor x, x          -> 0
+//    xor x, 0          -> x
+//    xor (xor x, -1), -1 -> x
+//    fneg (fneg x)     -> x
+//    bitcast (bitcast x to T) to typeof(x) -> x
+//    freeze (freeze x) -> freeze x
+//    freeze x          -> x (if x is never undef or poison)
+//    trunc (zext x to T) to typeof(x) -> x
+//    trunc (sext x to T) to typeof(x) -> x
+//    select true, x, y -> x
+//    select false, x, y -> y
+//    select c, x, x    -> x
+//    select c, true, false -> c
+//    getelementptr x, 0, ..., 0 -> x (if of the same type as x)
+//
+// Furthermore, chains of integer conversion instructions are combined into a
+// single instruction, replacing the outermost conversion instruction of the
+// chain.
+//
+//    trunc (trunc x)             -> trunc x
+//    trunc (zext x to T) to U    -> trunc x to U (if U is narrower than x)
+//    trunc (zext x to T) to U    -> zext x to U  (if U is wider than x)
+//    trunc (sext x to T) to U    -> trunc x to U (if U is narrower than x)
+//    trunc (sext x to T) to U    -> sext x to U  (if U is wider than x)
+//    zext (zext x)               -> zext x
+//    sext (sext x)               -> sext x
+//    sext (zext x)               -> zext x
+//    zext (trunc x to iN) to typeof(x) -> and x, 2^N-1
+//
+// Getelementptr instructions with all-zero indices which change the pointer
+// type are rewritten as bitcast instructions.
+//
+//    getelementptr x, 0, ..., 0 -> bitcast x (if of different pointer type)
+//
+// Select instructions with i1 operands are rewritten as logical operations, if
+// the operand which is not selected by the condition in the original
+// instruction is never undef or poison; as the logical operation would
+// otherwise propagate poison not propagated by the select instruction.
+//
+//    select c, true, y -> or c, y  (if y is never undef or poison)
+//    select c, x, false -> and c, x (if x is never undef or poison)
+//
+// Chains which lose bits in an intermediate conversion are not combined unless
+// the lost bits are restored by the combined instruction; e.g. sext (trunc x
+// to i8) to typeof(x) is not simplified to x, as the upper bits of x are
+// replaced by copies of the sign bit of the truncated value.
+//
+// Rewrites are conservative with regards to overflow flags (nsw and nuw); an
+// identity is only applied if the replacement value is defined whenever the
+// original instruction is. As such, the presence of overflow flags never
+// changes the result of a rewrite. Floating-point identities which depend on
+// the sign of zero or NaN values (e.g. fadd x, 0.0) are not applied. A value is
+// only considered to never be undef or poison if it is a constant without
+// undef, poison or constant expression elements, the address of a global
+// variable or function, a parameter with the noundef attribute, or the result
+// of a call with the noundef return attribute.
+//
+// Uses of simplified instructions are replaced using ReplaceAllUsesWith, after
+// which the simplified instructions are removed.
+func InstCombineLite(f *ir.Func) bool {
+	changed := false
+	for {
+		progress := false
+		for _, block := range f.Blocks {
+			for i := 0; i < len(block.Insts); i++ {
+				inst := block.Insts[i]
+				v := simplifyInst(inst)
+				if v == nil {
+					if combined := combineInst(inst); combined != nil {
+						f.ReplaceAllUsesWith(inst.(value.Value), combined.(value.Value))
+						block.Insts[i] = combined
+						progress = true
+					}
+					continue
+				}
+				f.ReplaceAllUsesWith(inst.(value.Value), v)
+				block.Insts = append(block.Insts[:i], block.Insts[i+1:]...)
+				i--
+				progress = true
+			}
+		}
+		if !progress {
+			break
+		}
+		changed = true
+	}
+	return changed
+}
+
+// simplifyInst returns a simplified value equivalent to the given instruction,
+// or nil if no simplification applies.
+func simplifyInst(inst ir.Instruction) value.Value {
+	switch inst := inst.(type) {
+	case *ir.InstAdd:
+		switch {
+		case isZero(inst.Y):
+			return inst.X
+		case isZero(inst.X):
+			return inst.Y
+		}
+	case *ir.InstSub:
+		switch {
+		case isZero(inst.Y):
+			return inst.X
+		case inst.X == inst.Y:
+			return zeroValue(inst.Type())
+		case isZero(inst.X):
+			// sub 0, (sub 0, x) -> x
+			if neg, ok := inst.Y.(*ir.InstSub); ok && isZero(neg.X) {
+				return neg.Y
+			}
+		}
+	case *ir.InstMul:
+		switch {
+		case isOne(inst.Y):
+			return inst.X
+		case isOne(inst.X):
+			return inst.Y
+		case isZero(inst.Y):
+			return inst.Y
+		case isZero(inst.X):
+			return inst.X
+		}
+	case *ir.InstAnd:
+		switch {
+		case inst.X == inst.Y:
+			return inst.X
+		case isZero(inst.Y):
+			return inst.Y
+		case isZero(inst.X):
+			return inst.X
+		case isAllOnes(inst.Y):
+			return inst.X
+		case isAllOnes(inst.X):
+			return inst.Y
+		}
+	case *ir.InstOr:
+		switch {
+		case inst.X == inst.Y:
+			return inst.X
+		case isZero(inst.Y):
+			return inst.X
+		case isZero(inst.X):
+			return inst.Y
+		case isAllOnes(inst.Y):
+			return inst.Y
+		case isAllOnes(inst.X):
+			return inst.X
+		}
+	case *ir.InstXor:
+		switch {
+		case inst.X == inst.Y:
+			return zeroValue(inst.Type())
+		case isZero(inst.Y):
+			return inst.X
+		case isZero(inst.X):
+			return inst.Y
+		case isAllOnes(inst.Y):
+			// xor (xor x, -1), -1 -> x
+			if x, ok := notOperand(inst.X); ok {
+				return x
+			}
+		case isAllOnes(inst.X):
+			// xor -1, (xor x, -1) -> x
+			if x, ok := notOperand(inst.Y); ok {
+				return x
+			}
+		}
+	case *ir.InstFNeg:
+		if neg, ok := inst.X.(*ir.InstFNeg); ok {
+			return neg.X
+		}
+	case *ir.InstFreeze:
+		switch {
+		case isFreeze(inst.X):
+			// freeze (freeze x) -> freeze x
+			return inst.X
+		case isNeverPoison(inst.X):
+			return inst.X
+		}
+	case *ir.InstBitCast:
+		if types.Equal(inst.From.Type(), inst.To) {
+			return inst.From
+		}
+		if cast, ok := inst.From.(*ir.InstBitCast); ok && types.Equal(cast.From.Type(), inst.To) {
+			return cast.From
+		}
+	case *ir.InstTrunc:
+		// trunc (zext x to T) to typeof(x) -> x
+		// trunc (sext x to T) to typeof(x) -> x
+		switch ext := inst.From.(type) {
+		case *ir.InstZExt:
+			if types.Equal(ext.From.Type(), inst.To) {
+				return ext.From
+			}
+		case *ir.InstSExt:
+			if types.Equal(ext.From.Type(), inst.To) {
+				return ext.From
+			}
+		}
+	case *ir.InstGetElementPtr:
+		// getelementptr x, 0, ..., 0 -> x
+		if isZeroGEP(inst) && types.Equal(inst.Src.Type(), inst.Type()) {
+			return inst.Src
+		}
+	case *ir.InstSelect:
+		switch {
+		case isTrue(inst.Cond):
+			return inst.X
+		case isFalse(inst.Cond):
+			return inst.Y
+		case inst.X == inst.Y:
+			return inst.X
+		case types.Equal(inst.Type(), types.I1) && isTrue(inst.X) && isFalse(inst.Y):
+			// select c, true, false -> c
+			return inst.Cond
+		}
+	}
+	return nil
+}
+
+// combineInst returns a new instruction equivalent to the given instruction,
+// or nil if no rewrite applies.
+func combineInst(inst ir.Instruction) ir.Instruction {
+	switch inst := inst.(type) {
+	case *ir.InstSelect:
+		return combineSelect(inst)
+	case *ir.InstGetElementPtr:
+		return combineGEP(inst)
+	}
+	return combineCasts(inst)
+}
+
+// combineGEP returns a bitcast instruction equivalent to the given
+// getelementptr instruction with all-zero indices, or nil if the
+// getelementptr instruction cannot be rewritten.
+func combineGEP(inst *ir.InstGetElementPtr) ir.Instruction {
+	if !isZeroGEP(inst) {
+		return nil
+	}
+	// Pointer of different element type; vectors of pointers are not
+	// rewritten.
+	_, ok1 := inst.Src.Type().(*types.PointerType)
+	_, ok2 := inst.Type().(*types.PointerType)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	return ir.NewBitCast(inst.Src, inst.Type())
+}
+
+// isZeroGEP reports whether the given getelementptr instruction has all-zero
+// indices.
+func isZeroGEP(inst *ir.InstGetElementPtr) bool {
+	for _, index := range inst.Indices {
+		if !isZero(index) {
+			return false
+		}
+	}
+	return true
+}
+
+// combineSelect returns a logical operation equivalent to the given select
+// instruction with i1 operands, or nil if the select instruction cannot be
+// rewritten.
+func combineSelect(inst *ir.InstSelect) ir.Instruction {
+	if !types.Equal(inst.Type(), types.I1) || !types.Equal(inst.Cond.Type(), types.I1) {
+		return nil
+	}
+	switch {
+	case isTrue(inst.X) && isNeverPoison(inst.Y):
+		// select c, true, y -> or c, y
+		return ir.NewOr(inst.Cond, inst.Y)
+	case isFalse(inst.Y) && isNeverPoison(inst.X):
+		// select c, x, false -> and c, x
+		return ir.NewAnd(inst.Cond, inst.X)
+	}
+	return nil
+}
+
+// combineCasts returns a single instruction equivalent to the given chain of
+// integer conversion instructions, or nil if the chain cannot be combined.
+func combineCasts(inst ir.Instruction) ir.Instruction {
+	switch inst := inst.(type) {
+	case *ir.InstTrunc:
+		switch from := inst.From.(type) {
+		case *ir.InstTrunc:
+			return ir.NewTrunc(from.From, inst.To)
+		case *ir.InstZExt:
+			// The extended bits are either truncated or zero-extended.
+			switch x, n := from.From, intBits(inst.To); {
+			case n < intBits(x.Type()):
+				return ir.NewTrunc(x, inst.To)
+			case n > intBits(x.Type()):
+				return ir.NewZExt(x, inst.To)
+			}
+		case *ir.InstSExt:
+			// The extended bits are either truncated or sign-extended.
+			switch x, n := from.From, intBits(inst.To); {
+			case n < intBits(x.Type()):
+				return ir.NewTrunc(x, inst.To)
+			case n > intBits(x.Type()):
+				return ir.NewSExt(x, inst.To)
+			}
+		}
+	case *ir.InstZExt:
+		switch from := inst.From.(type) {
+		case *ir.InstZExt:
+			return ir.NewZExt(from.From, inst.To)
+		case *ir.InstTrunc:
+			// zext (trunc x to iN) to typeof(x) -> and x, 2^N-1
+			//
+			// The truncated bits of x are cleared by the mask.
+			t, ok := inst.To.(*types.IntType)
+			if !ok || !types.Equal(from.From.Type(), t) {
+				return nil
+			}
+			mask := new(big.Int).Lsh(big.NewInt(1), uint(intBits(from.To)))
+			mask.Sub(mask, big.NewInt(1))
+			return ir.NewAnd(from.From, &constant.Int{Typ: t, X: mask})
+		}
+	case *ir.InstSExt:
+		switch from := inst.From.(type) {
+		case *ir.InstSExt:
+			return ir.NewSExt(from.From, inst.To)
+		case *ir.InstZExt:
+			// The sign bit of a zero-extended value is zero.
+			return ir.NewZExt(from.From, inst.To)
+		}
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// isFreeze reports whether the given value is a freeze instruction.
+func isFreeze(v value.Value) bool {
+	_, ok := v.(*ir.InstFreeze)
+	return ok
+}
+
+// isNeverPoison reports whether the given value is provably never undef or
+// poison.
+func isNeverPoison(v value.Value) bool {
+	switch v := v.(type) {
+	case *constant.Int, *constant.Float, *constant.Null, *constant.ZeroInitializer, *constant.CharArray:
+		return true
+	case *constant.Struct:
+		return allNeverPoison(v.Fields)
+	case *constant.Array:
+		return allNeverPoison(v.Elems)
+	case *constant.Vector:
+		return allNeverPoison(v.Elems)
+	case *ir.Global, *ir.Func:
+		return true
+	case *ir.Param:
+		for _, attr := range v.Attrs {
+			if attr == enum.ParamAttrNoUndef {
+				return true
+			}
+		}
+	case *ir.InstCall:
+		attrs := v.ReturnAttrs
+		if callee, ok := v.Callee.(*ir.Func); ok {
+			attrs = append(attrs[:len(attrs):len(attrs)], callee.ReturnAttrs...)
+		}
+		for _, attr := range attrs {
+			if attr == enum.ReturnAttrNoUndef {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allNeverPoison reports whether the given constants are provably never undef
+// or poison.
+func allNeverPoison(cs []constant.Constant) bool {
+	for _, c := range cs {
+		if !isNeverPoison(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// notOperand returns the operand x of the given bitwise not instruction (i.e.
+// `xor x, -1` or `xor -1, x`). The boolean return value indicates success.
+func notOperand(v value.Value) (value.Value, bool) {
+	not, ok := v.(*ir.InstXor)
+	if !ok {
+		return nil, false
+	}
+	switch {
+	case isAllOnes(not.Y):
+		return not.X, true
+	case isAllOnes(not.X):
+		return not.Y, true
+	}
+	return nil, false
+}
+
+// isZero reports whether the given value is an integer zero constant.
+func isZero(v value.Value) bool {
+	switch v := v.(type) {
+	case *constant.Int:
+		return v.X.Sign() == 0
+	case *constant.ZeroInitializer:
+		return types.IsInt(v.Typ) || isIntVector(v.Typ)
+	}
+	return false
+}
+
+// isTrue reports whether the given value is the i1 constant true.
+func isTrue(v value.Value) bool {
+	c, ok := v.(*constant.Int)
+	return ok && c.Typ.BitSize == 1 && c.X.Sign() != 0
+}
+
+// isFalse reports whether the given value is the i1 constant false.
+func isFalse(v value.Value) bool {
+	c, ok := v.(*constant.Int)
+	return ok && c.Typ.BitSize == 1 && c.X.Sign() == 0
+}
+
+// isOne reports whether the given value is an integer one constant.
+func isOne(v value.Value) bool {
+	if v, ok := v.(*constant.Int); ok {
+		return v.X.Cmp(big.NewInt(1)) == 0 || (v.Typ.BitSize == 1 && isAllOnes(v))
+	}
+	return false
+}
+
+// isAllOnes reports whether the given value is an integer constant with all
+// bits set.
+func isAllOnes(v value.Value) bool {
+	if v, ok := v.(*constant.Int); ok {
+		if v.X.Cmp(big.NewInt(-1)) == 0 {
+			return true
+		}
+		// Unsigned representation; 2^n - 1.
+		max := new(big.Int).Lsh(big.NewInt(1), uint(v.Typ.BitSize))
+		max.Sub(max, big.NewInt(1))
+		return v.X.Cmp(max) == 0
+	}
+	return false
+}
+
+// intBits returns the bit size of the given integer type, or of the element
+// type of the given integer vector type.
+func intBits(t types.Type) uint64 {
+	if vt, ok := t.(*types.VectorType); ok {
+		t = vt.ElemType
+	}
+	return t.(*types.IntType).BitSize
+}
+
+// isIntVector reports whether the given type is an integer vector type.
+func isIntVector(t types.Type) bool {
+	if t, ok := t.(*types.VectorType); ok {
+		return types.IsInt(t.ElemType)
+	}
+	return false
+}
+
+// zeroValue returns the zero value of the given integer scalar or integer
+// vector type.
+func zeroValue(t types.Type) constant.Constant {
+	if t, ok := t.(*types.IntType); ok {
+		return constant.NewInt(t, 0)
+	}
+	return constant.NewZeroInitializer(t)
+}
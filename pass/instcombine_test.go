@@ -0,0 +1,537 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+func TestInstCombineLite(t *testing.T) {
+	i32 := types.I32
+	zero := constant.NewInt(i32, 0)
+	one := constant.NewInt(i32, 1)
+	minusOne := constant.NewInt(i32, -1)
+	golden := []struct {
+		name string
+		// build returns the value to return from a function with the integer
+		// parameter x and float parameter y.
+		build func(block *ir.Block, x, y value.Value) value.Value
+		// want returns the expected return value after simplification; nil if
+		// the function should not be changed.
+		want func(x, y value.Value) value.Value
+	}{
+		{
+			name:  "add x, 0",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewAdd(x, zero) },
+			want:  func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name: "add nsw 0, x",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				inst := b.NewAdd(zero, x)
+				inst.OverflowFlags = []enum.OverflowFlag{enum.OverflowFlagNSW}
+				return inst
+			},
+			want: func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name:  "mul x, 1",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewMul(x, one) },
+			want:  func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name:  "mul x, 0",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewMul(x, zero) },
+			want:  func(x, y value.Value) value.Value { return zero },
+		},
+		{
+			name:  "and x, x",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewAnd(x, x) },
+			want:  func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name:  "or x, -1",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewOr(x, minusOne) },
+			want:  func(x, y value.Value) value.Value { return minusOne },
+		},
+		{
+			name: "sub x, x",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewSub(x, x)
+			},
+			want: func(x, y value.Value) value.Value { return constant.NewInt(i32, 0) },
+		},
+		{
+			name:  "xor x, x",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewXor(x, x) },
+			want:  func(x, y value.Value) value.Value { return constant.NewInt(i32, 0) },
+		},
+		{
+			name: "sub 0, (sub 0, x)",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewSub(zero, b.NewSub(zero, x))
+			},
+			want: func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name: "xor (xor x, -1), -1",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewXor(b.NewXor(x, minusOne), minusOne)
+			},
+			want: func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name: "xor (xor -1, x), -1",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewXor(b.NewXor(minusOne, x), minusOne)
+			},
+			want: func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name: "xor -1, (xor x, -1)",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewXor(minusOne, b.NewXor(x, minusOne))
+			},
+			want: func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name: "xor -1, (xor -1, x)",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewXor(minusOne, b.NewXor(minusOne, x))
+			},
+			want: func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name: "bitcast (bitcast x to float) to i32",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewBitCast(b.NewBitCast(x, types.Float), i32)
+			},
+			want: func(x, y value.Value) value.Value { return x },
+		},
+		{
+			name: "bitcast (fneg (fneg y)) to i32",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewBitCast(b.NewFNeg(b.NewFNeg(y)), i32)
+			},
+			want: nil,
+		},
+		{
+			name:  "sub x, 1",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewSub(x, one) },
+			want:  nil,
+		},
+	}
+	for _, g := range golden {
+		x := ir.NewParam("x", i32)
+		y := ir.NewParam("y", types.Float)
+		f := ir.NewFunc("f", i32, x, y)
+		entry := f.NewBlock("entry")
+		ret := entry.NewRet(g.build(entry, x, y))
+		orig := ret.X
+		changed := InstCombineLite(f)
+		if g.want == nil {
+			// Only the nested fneg instructions may be simplified.
+			if ret.X != orig {
+				t.Errorf("%q: unexpected simplification of return value; got %v", g.name, ret.X)
+			}
+			continue
+		}
+		if !changed {
+			t.Errorf("%q: expected function to be changed", g.name)
+			continue
+		}
+		want := g.want(x, y)
+		if got := ret.X; got.String() != want.String() {
+			t.Errorf("%q: return value mismatch; expected %v, got %v", g.name, want, got)
+		}
+		for _, inst := range entry.Insts {
+			if inst.(value.Value) == orig {
+				t.Errorf("%q: expected simplified instruction to be removed", g.name)
+			}
+		}
+	}
+}
+
+func TestInstCombineLiteFreeze(t *testing.T) {
+	i32 := types.I32
+	c := constant.NewInt(i32, 42)
+	undef := constant.NewUndef(i32)
+	// Callee with the noundef return attribute.
+	g := ir.NewFunc("g", i32)
+	g.ReturnAttrs = append(g.ReturnAttrs, enum.ReturnAttrNoUndef)
+	golden := []struct {
+		name string
+		// build returns the value to return from a function with the integer
+		// parameter x, and the integer parameter y with the noundef attribute.
+		build func(block *ir.Block, x, y value.Value) value.Value
+		// want returns the expected return value after simplification, given
+		// the value returned by build; nil if the function should not be
+		// changed.
+		want func(x, y, ret value.Value) value.Value
+	}{
+		{
+			name: "freeze (freeze x)",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewFreeze(b.NewFreeze(x))
+			},
+			want: func(x, y, ret value.Value) value.Value { return ret.(*ir.InstFreeze).X },
+		},
+		{
+			name:  "freeze 42",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewFreeze(c) },
+			want:  func(x, y, ret value.Value) value.Value { return c },
+		},
+		{
+			name:  "freeze noundef y",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewFreeze(y) },
+			want:  func(x, y, ret value.Value) value.Value { return y },
+		},
+		{
+			name:  "freeze (call noundef @g())",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewFreeze(b.NewCall(g)) },
+			want:  func(x, y, ret value.Value) value.Value { return ret.(*ir.InstFreeze).X },
+		},
+		{
+			name:  "freeze x",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewFreeze(x) },
+			want:  nil,
+		},
+		{
+			name:  "freeze undef",
+			build: func(b *ir.Block, x, y value.Value) value.Value { return b.NewFreeze(undef) },
+			want:  nil,
+		},
+		{
+			name: "freeze { i32 42, i32 undef }",
+			build: func(b *ir.Block, x, y value.Value) value.Value {
+				return b.NewFreeze(constant.NewStruct(types.NewStruct(i32, i32), c, undef))
+			},
+			want: nil,
+		},
+	}
+	for _, gold := range golden {
+		x := ir.NewParam("x", i32)
+		y := ir.NewParam("y", i32)
+		y.Attrs = append(y.Attrs, enum.ParamAttrNoUndef)
+		f := ir.NewFunc("f", i32, x, y)
+		entry := f.NewBlock("entry")
+		ret := entry.NewRet(gold.build(entry, x, y))
+		orig := ret.X
+		changed := InstCombineLite(f)
+		if gold.want == nil {
+			if changed || ret.X != orig {
+				t.Errorf("%q: unexpected simplification of return value; got %v", gold.name, ret.X)
+			}
+			continue
+		}
+		if !changed {
+			t.Errorf("%q: expected function to be changed", gold.name)
+			continue
+		}
+		if want := gold.want(x, y, orig); ret.X != want {
+			t.Errorf("%q: return value mismatch; expected %v, got %v", gold.name, want, ret.X)
+		}
+		for _, inst := range entry.Insts {
+			if inst.(value.Value) == orig {
+				t.Errorf("%q: expected simplified instruction to be removed", gold.name)
+			}
+		}
+	}
+}
+
+func TestInstCombineLiteCasts(t *testing.T) {
+	i8, i16, i32, i64 := types.I8, types.I16, types.I32, types.I64
+	golden := []struct {
+		name string
+		// build returns the value to return from a function with the i32
+		// parameter x.
+		build func(block *ir.Block, x value.Value) value.Value
+		// want is the expected return value after simplification; either the
+		// identifier of a value or the LLVM syntax representation of an
+		// instruction. Empty if the function should not be changed.
+		want string
+	}{
+		{
+			name: "trunc (zext x to i64) to i32",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewTrunc(b.NewZExt(x, i64), i32)
+			},
+			want: "%x",
+		},
+		{
+			name: "trunc (sext x to i64) to i32",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewTrunc(b.NewSExt(x, i64), i32)
+			},
+			want: "%x",
+		},
+		{
+			name: "trunc (zext x to i64) to i16",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewTrunc(b.NewZExt(x, i64), i16)
+			},
+			want: "%1 = trunc i32 %x to i16",
+		},
+		{
+			name: "trunc (sext x to i128) to i64",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewTrunc(b.NewSExt(x, types.I128), i64)
+			},
+			want: "%1 = sext i32 %x to i64",
+		},
+		{
+			name: "trunc (trunc x to i16) to i8",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewTrunc(b.NewTrunc(x, i16), i8)
+			},
+			want: "%1 = trunc i32 %x to i8",
+		},
+		{
+			name: "sext (zext x to i64) to i128",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewSExt(b.NewZExt(x, i64), types.I128)
+			},
+			want: "%1 = zext i32 %x to i128",
+		},
+		{
+			name: "zext (trunc x to i8) to i32",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewZExt(b.NewTrunc(x, i8), i32)
+			},
+			want: "%1 = and i32 %x, 255",
+		},
+		// Lossy chains; the upper bits of x are lost by the truncation.
+		{
+			name: "sext (trunc x to i8) to i32",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewSExt(b.NewTrunc(x, i8), i32)
+			},
+		},
+		{
+			name: "zext (trunc x to i8) to i16",
+			build: func(b *ir.Block, x value.Value) value.Value {
+				return b.NewZExt(b.NewTrunc(x, i8), i16)
+			},
+		},
+	}
+	for _, g := range golden {
+		x := ir.NewParam("x", i32)
+		f := ir.NewFunc("f", types.Void, x)
+		entry := f.NewBlock("entry")
+		v := g.build(entry, x)
+		f.Sig.RetType = v.Type()
+		ret := entry.NewRet(v)
+		changed := InstCombineLite(f)
+		if len(g.want) == 0 {
+			if changed || ret.X != v {
+				t.Errorf("%q: unexpected simplification of return value; got %v", g.name, ret.X)
+			}
+			continue
+		}
+		if !changed {
+			t.Errorf("%q: expected function to be changed", g.name)
+			continue
+		}
+		if err := f.AssignIDs(); err != nil {
+			t.Fatalf("%q: unable to assign IDs; %v", g.name, err)
+		}
+		got := ret.X.Ident()
+		if inst, ok := ret.X.(ir.Instruction); ok {
+			got = inst.LLString()
+		}
+		if got != g.want {
+			t.Errorf("%q: return value mismatch; expected %q, got %q", g.name, g.want, got)
+		}
+	}
+}
+
+func TestInstCombineLiteSelect(t *testing.T) {
+	i1, i32 := types.I1, types.I32
+	a, b := constant.NewInt(i32, 1), constant.NewInt(i32, 2)
+	golden := []struct {
+		name string
+		// build returns the value to return from a function with the i1
+		// parameters c and d, the i1 parameter e with the noundef attribute,
+		// and the i32 parameter x.
+		build func(block *ir.Block, c, d, e, x value.Value) value.Value
+		// want is the expected return value after simplification; either the
+		// identifier of a value or the LLVM syntax representation of an
+		// instruction. Empty if the function should not be changed.
+		want string
+	}{
+		{
+			name: "select true, 1, 2",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(constant.True, a, b)
+			},
+			want: "1",
+		},
+		{
+			name: "select false, x, 2",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(constant.False, x, b)
+			},
+			want: "2",
+		},
+		{
+			name: "select c, x, x",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(c, x, x)
+			},
+			want: "%x",
+		},
+		{
+			name: "select c, true, false",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(c, constant.True, constant.False)
+			},
+			want: "%c",
+		},
+		{
+			name: "select c, true, noundef e",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(c, constant.True, e)
+			},
+			want: "%0 = or i1 %c, %e",
+		},
+		{
+			name: "select c, noundef e, false",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(c, e, constant.False)
+			},
+			want: "%0 = and i1 %c, %e",
+		},
+		// d may be poison; or and and would propagate poison of d when not
+		// selected.
+		{
+			name: "select c, true, d",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(c, constant.True, d)
+			},
+		},
+		{
+			name: "select c, d, false",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(c, d, constant.False)
+			},
+		},
+		{
+			name: "select c, x, 2",
+			build: func(bl *ir.Block, c, d, e, x value.Value) value.Value {
+				return bl.NewSelect(c, x, b)
+			},
+		},
+	}
+	for _, g := range golden {
+		c := ir.NewParam("c", i1)
+		d := ir.NewParam("d", i1)
+		e := ir.NewParam("e", i1)
+		e.Attrs = append(e.Attrs, enum.ParamAttrNoUndef)
+		x := ir.NewParam("x", i32)
+		f := ir.NewFunc("f", types.Void, c, d, e, x)
+		entry := f.NewBlock("entry")
+		v := g.build(entry, c, d, e, x)
+		f.Sig.RetType = v.Type()
+		ret := entry.NewRet(v)
+		changed := InstCombineLite(f)
+		if len(g.want) == 0 {
+			if changed || ret.X != v {
+				t.Errorf("%q: unexpected simplification of return value; got %v", g.name, ret.X)
+			}
+			continue
+		}
+		if !changed {
+			t.Errorf("%q: expected function to be changed", g.name)
+			continue
+		}
+		if err := f.AssignIDs(); err != nil {
+			t.Fatalf("%q: unable to assign IDs; %v", g.name, err)
+		}
+		got := ret.X.Ident()
+		if inst, ok := ret.X.(ir.Instruction); ok {
+			got = inst.LLString()
+		}
+		if got != g.want {
+			t.Errorf("%q: return value mismatch; expected %q, got %q", g.name, g.want, got)
+		}
+	}
+}
+
+func TestInstCombineLiteGEP(t *testing.T) {
+	i32, i64 := types.I32, types.I64
+	arr := types.NewArray(4, i32)
+	zero := constant.NewInt(i64, 0)
+	golden := []struct {
+		name string
+		// build returns the value to return from a function with the i32*
+		// parameter p, the [4 x i32]* parameter a and the i64 parameter n.
+		build func(block *ir.Block, p, a, n value.Value) value.Value
+		// want is the expected return value after simplification; either the
+		// identifier of a value or the LLVM syntax representation of an
+		// instruction. Empty if the function should not be changed.
+		want string
+	}{
+		{
+			name: "getelementptr i32, i32* p, i64 0",
+			build: func(b *ir.Block, p, a, n value.Value) value.Value {
+				return b.NewGetElementPtr(p, zero)
+			},
+			want: "%p",
+		},
+		{
+			name: "getelementptr [4 x i32], [4 x i32]* a, i64 0",
+			build: func(b *ir.Block, p, a, n value.Value) value.Value {
+				return b.NewGetElementPtr(a, zero)
+			},
+			want: "%a",
+		},
+		{
+			name: "getelementptr [4 x i32], [4 x i32]* a, i64 0, i64 0",
+			build: func(b *ir.Block, p, a, n value.Value) value.Value {
+				return b.NewGetElementPtr(a, zero, zero)
+			},
+			want: "%0 = bitcast [4 x i32]* %a to i32*",
+		},
+		{
+			name: "getelementptr i32, i32* p, i64 n",
+			build: func(b *ir.Block, p, a, n value.Value) value.Value {
+				return b.NewGetElementPtr(p, n)
+			},
+		},
+	}
+	for _, g := range golden {
+		p := ir.NewParam("p", types.NewPointer(i32))
+		a := ir.NewParam("a", types.NewPointer(arr))
+		n := ir.NewParam("n", i64)
+		f := ir.NewFunc("f", types.Void, p, a, n)
+		entry := f.NewBlock("entry")
+		v := g.build(entry, p, a, n)
+		f.Sig.RetType = v.Type()
+		ret := entry.NewRet(v)
+		changed := InstCombineLite(f)
+		if len(g.want) == 0 {
+			if changed || ret.X != v {
+				t.Errorf("%q: unexpected simplification of return value; got %v", g.name, ret.X)
+			}
+			continue
+		}
+		if !changed {
+			t.Errorf("%q: expected function to be changed", g.name)
+			continue
+		}
+		if err := f.AssignIDs(); err != nil {
+			t.Fatalf("%q: unable to assign IDs; %v", g.name, err)
+		}
+		got := ret.X.Ident()
+		if inst, ok := ret.X.(ir.Instruction); ok {
+			got = inst.LLString()
+		}
+		if got != g.want {
+			t.Errorf("%q: return value mismatch; expected %q, got %q", g.name, g.want, got)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package pass
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// maxUnrollMemSize specifies the maximum length in bytes of memory intrinsics
+// lowered to an unrolled sequence of loads and stores; longer memory
+// intrinsics are lowered to a loop.
+const maxUnrollMemSize = 16
+
+// LowerMemIntrinsics replaces the calls to the @llvm.memcpy, @llvm.memmove and
+// @llvm.memset intrinsics of the given function with explicit load and store
+// instructions, and reports whether the function was changed.
+//
+// Calls with a constant length of at most 16 bytes are replaced by an unrolled
+// sequence of byte-sized loads and stores, which retain the alignment of the
+// pointer operands (as specified by their align parameter attributes) where
+// known from the offset. The unrolled sequence of memmove loads every byte
+// before storing any, as the source and destination may overlap.
+//
+// Calls with longer or variable lengths are replaced by a loop copying (or
+// setting) one byte per iteration. The basic block of the call is split after
+// the call, and the loop is inserted between the two halves; variable-length
+// loops are skipped if the length is zero. Overlapping memmove copies backwards
+// if the source precedes the destination, and forwards otherwise.
+//
+// The loads and stores are volatile if the call is volatile (i.e. the isvolatile
+// argument is true). Calls with non-constant isvolatile arguments or with
+// pointer operands other than i8* are left unchanged.
+func LowerMemIntrinsics(f *ir.Func) bool {
+	changed := false
+	for i := 0; i < len(f.Blocks); i++ {
+		block := f.Blocks[i]
+		for j := 0; j < len(block.Insts); j++ {
+			call, ok := block.Insts[j].(*ir.InstCall)
+			if !ok {
+				continue
+			}
+			mem, ok := memIntrinsicOf(call)
+			if !ok {
+				continue
+			}
+			changed = true
+			if n, ok := mem.n.(*constant.Int); ok && n.X.Sign() >= 0 && n.X.Cmp(big.NewInt(maxUnrollMemSize)) <= 0 {
+				insts := mem.unroll(n.X.Int64())
+				tail := append(insts, block.Insts[j+1:]...)
+				block.Insts = append(block.Insts[:j], tail...)
+				j += len(insts) - 1
+				continue
+			}
+			// Lower to loop, and continue with the basic block following the
+			// loop.
+			blocks := mem.lowerLoop(block, j)
+			rest := make([]*ir.Block, 0, len(f.Blocks)+len(blocks))
+			rest = append(rest, f.Blocks[:i+1]...)
+			rest = append(rest, blocks...)
+			f.Blocks = append(rest, f.Blocks[i+1:]...)
+			i += len(blocks) - 1
+			break
+		}
+	}
+	return changed
+}
+
+// memIntrinsic is a call to a memory intrinsic.
+type memIntrinsic struct {
+	// Name of memory intrinsic; "memcpy", "memmove" or "memset".
+	kind string
+	// Destination pointer.
+	dst value.Value
+	// Source pointer of memcpy and memmove; nil for memset.
+	src value.Value
+	// Value to store of memset; nil for memcpy and memmove.
+	val value.Value
+	// Length in bytes.
+	n value.Value
+	// Alignment of destination and source; zero if unknown.
+	dstAlign, srcAlign ir.Align
+	// Volatile memory access.
+	volatile bool
+}
+
+// memIntrinsicOf returns the memory intrinsic called by the given call. The
+// boolean return value indicates success.
+func memIntrinsicOf(call *ir.InstCall) (*memIntrinsic, bool) {
+	callee, ok := call.Callee.(*ir.Func)
+	if !ok || len(call.Args) != 4 {
+		return nil, false
+	}
+	// Overloaded intrinsics are suffixed by their argument types (e.g.
+	// @llvm.memcpy.p0i8.p0i8.i64).
+	var kind string
+	for _, k := range []string{"memcpy", "memmove", "memset"} {
+		if strings.HasPrefix(callee.Name(), "llvm."+k+".") {
+			kind = k
+			break
+		}
+	}
+	if kind == "" {
+		return nil, false
+	}
+	mem := &memIntrinsic{kind: kind}
+	args := make([]value.Value, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = arg
+		if a, ok := arg.(*ir.Arg); ok {
+			args[i] = a.Value
+		}
+	}
+	isVolatile, ok := args[3].(*constant.Int)
+	if !ok || !isVolatile.Typ.Equal(types.I1) {
+		// Not a call to a memory intrinsic of the expected form (e.g.
+		// @llvm.memcpy.element.unordered.atomic).
+		return nil, false
+	}
+	mem.volatile = isVolatile.X.Sign() != 0
+	mem.dst, mem.n = args[0], args[2]
+	mem.dstAlign = argAlign(call, callee, 0)
+	if !isBytePtr(mem.dst) {
+		return nil, false
+	}
+	if kind == "memset" {
+		mem.val = args[1]
+	} else {
+		mem.src = args[1]
+		mem.srcAlign = argAlign(call, callee, 1)
+		if !isBytePtr(mem.src) {
+			return nil, false
+		}
+	}
+	if _, ok := mem.n.Type().(*types.IntType); !ok {
+		return nil, false
+	}
+	return mem, true
+}
+
+// unroll returns the unrolled sequence of loads and stores of the memory
+// intrinsic, with the given constant length in bytes.
+func (mem *memIntrinsic) unroll(n int64) []ir.Instruction {
+	var insts []ir.Instruction
+	var vals []value.Value
+	// Load bytes of source.
+	for k := int64(0); k < n && mem.src != nil; k++ {
+		src := byteAt(mem.src, constant.NewInt(mem.lenType(), k))
+		load := ir.NewLoad(src)
+		load.Volatile = mem.volatile
+		load.Align = offsetAlign(mem.srcAlign, k)
+		insts = append(insts, src, load)
+		vals = append(vals, load)
+	}
+	// Store bytes of destination.
+	var stores []ir.Instruction
+	for k := int64(0); k < n; k++ {
+		dst := byteAt(mem.dst, constant.NewInt(mem.lenType(), k))
+		v := mem.val
+		if v == nil {
+			v = vals[k]
+		}
+		store := ir.NewStore(v, dst)
+		store.Volatile = mem.volatile
+		store.Align = offsetAlign(mem.dstAlign, k)
+		stores = append(stores, dst, store)
+	}
+	if mem.kind == "memmove" {
+		// Load every byte before storing any.
+		return append(insts, stores...)
+	}
+	if mem.kind == "memset" {
+		return stores
+	}
+	// Interleave loads and stores of memcpy.
+	var seq []ir.Instruction
+	for k := range vals {
+		seq = append(seq, insts[2*k:2*k+2]...)
+		seq = append(seq, stores[2*k:2*k+2]...)
+	}
+	return seq
+}
+
+// lowerLoop replaces the call to the memory intrinsic at the given instruction
+// index of the basic block with a loop, and returns the new basic blocks (not
+// including the original basic block). The last of the new basic blocks holds
+// the instructions and terminator following the call.
+func (mem *memIntrinsic) lowerLoop(block *ir.Block, j int) []*ir.Block {
+	f := block.Parent
+	newBlock := func(suffix string) *ir.Block {
+		b := ir.NewBlock(memBlockName(block, suffix))
+		b.Parent = f
+		return b
+	}
+	// Split basic block after the call.
+	split := newBlock("split")
+	split.Insts = append([]ir.Instruction(nil), block.Insts[j+1:]...)
+	split.Term = block.Term
+	for _, succ := range uniqueBlocks(split.Term.Succs()) {
+		renamePhiPred(succ, block, split)
+	}
+	block.Insts = block.Insts[:j]
+	block.Term = nil
+	var blocks []*ir.Block
+	fwd := newBlock(mem.kind + ".loop")
+	if mem.kind != "memmove" {
+		mem.guardZero(block, fwd, split)
+		mem.forwardLoop(block, fwd, split)
+		blocks = append(blocks, fwd)
+		return append(blocks, split)
+	}
+	// Copy backwards if the source precedes the destination, as the source and
+	// destination may overlap.
+	entry := block
+	if _, ok := mem.n.(*constant.Int); !ok {
+		entry = newBlock(mem.kind + ".dir")
+		blocks = append(blocks, entry)
+	}
+	mem.guardZero(block, entry, split)
+	bwd := newBlock(mem.kind + ".loop.bwd")
+	before := entry.NewICmp(enum.IPredULT, mem.src, mem.dst)
+	entry.NewCondBr(before, bwd, fwd)
+	mem.backwardLoop(entry, bwd, split)
+	mem.forwardLoop(entry, fwd, split)
+	blocks = append(blocks, bwd, fwd)
+	return append(blocks, split)
+}
+
+// guardZero terminates the given basic block with a branch to target, which is
+// skipped in favour of exit if the length of the memory intrinsic is zero.
+// Constant lengths are non-zero, as zero-length memory intrinsics are
+// unrolled.
+func (mem *memIntrinsic) guardZero(block, target, exit *ir.Block) {
+	if _, ok := mem.n.(*constant.Int); ok {
+		if block != target {
+			block.NewBr(target)
+		}
+		return
+	}
+	zero := block.NewICmp(enum.IPredEQ, mem.n, constant.NewInt(mem.lenType(), 0))
+	block.NewCondBr(zero, exit, target)
+}
+
+// forwardLoop populates the given loop basic block with a loop over the bytes
+// of the memory intrinsic in increasing order, entered from pred and exiting
+// to exit.
+//
+//    %i = phi [ 0, %pred ], [ %next, %loop ]
+//    ...
+//    %next = add %i, 1
+//    %cond = icmp ult %next, %n
+//    br %cond, %loop, %exit
+func (mem *memIntrinsic) forwardLoop(pred, loop, exit *ir.Block) {
+	i := loop.NewPhi(ir.NewIncoming(constant.NewInt(mem.lenType(), 0), pred))
+	mem.loopBody(loop, i)
+	next := loop.NewAdd(i, constant.NewInt(mem.lenType(), 1))
+	i.Incs = append(i.Incs, ir.NewIncoming(next, loop))
+	cond := loop.NewICmp(enum.IPredULT, next, mem.n)
+	loop.NewCondBr(cond, loop, exit)
+}
+
+// backwardLoop populates the given loop basic block with a loop over the bytes
+// of the memory intrinsic in decreasing order, entered from pred and exiting
+// to exit.
+//
+//    %i = phi [ %n, %pred ], [ %next, %loop ]
+//    %next = sub %i, 1
+//    ...
+//    %cond = icmp ne %next, 0
+//    br %cond, %loop, %exit
+func (mem *memIntrinsic) backwardLoop(pred, loop, exit *ir.Block) {
+	i := loop.NewPhi(ir.NewIncoming(mem.n, pred))
+	next := loop.NewSub(i, constant.NewInt(mem.lenType(), 1))
+	i.Incs = append(i.Incs, ir.NewIncoming(next, loop))
+	mem.loopBody(loop, next)
+	cond := loop.NewICmp(enum.IPredNE, next, constant.NewInt(mem.lenType(), 0))
+	loop.NewCondBr(cond, loop, exit)
+}
+
+// loopBody appends the load and store of the byte at index i of the memory
+// intrinsic to the given loop basic block.
+func (mem *memIntrinsic) loopBody(loop *ir.Block, i value.Value) {
+	v := mem.val
+	if mem.src != nil {
+		src := byteAt(mem.src, i)
+		loop.Insts = append(loop.Insts, src)
+		load := loop.NewLoad(src)
+		load.Volatile = mem.volatile
+		v = load
+	}
+	dst := byteAt(mem.dst, i)
+	loop.Insts = append(loop.Insts, dst)
+	store := loop.NewStore(v, dst)
+	store.Volatile = mem.volatile
+}
+
+// lenType returns the integer type of the length of the memory intrinsic.
+func (mem *memIntrinsic) lenType() *types.IntType {
+	return mem.n.Type().(*types.IntType)
+}
+
+// ### [ Helper functions ] ####################################################
+
+// argAlign returns the alignment of the i:th argument of the given call, as
+// specified by the align parameter attribute of the call or of the callee; or
+// zero if not present.
+func argAlign(call *ir.InstCall, callee *ir.Func, i int) ir.Align {
+	var attrs []ir.ParamAttribute
+	if arg, ok := call.Args[i].(*ir.Arg); ok {
+		attrs = append(attrs, arg.Attrs...)
+	}
+	if i < len(callee.Params) {
+		attrs = append(attrs, callee.Params[i].Attrs...)
+	}
+	for _, attr := range attrs {
+		if align, ok := attr.(ir.Align); ok {
+			return align
+		}
+	}
+	return 0
+}
+
+// offsetAlign returns the alignment of the byte at the given offset from a
+// pointer with the given alignment; or zero if the alignment is unknown.
+func offsetAlign(align ir.Align, offset int64) ir.Align {
+	if align == 0 {
+		return 0
+	}
+	for offset%int64(align) != 0 {
+		align /= 2
+	}
+	return align
+}
+
+// byteAt returns a getelementptr instruction computing the address of the byte
+// at index i of the given i8* pointer.
+func byteAt(ptr, i value.Value) *ir.InstGetElementPtr {
+	gep := ir.NewGetElementPtr(ptr, i)
+	gep.InBounds = true
+	return gep
+}
+
+// isBytePtr reports whether the given value is of i8* type.
+func isBytePtr(v value.Value) bool {
+	t, ok := v.Type().(*types.PointerType)
+	return ok && t.ElemType.Equal(types.I8)
+}
+
+// renamePhiPred replaces every incoming value from the predecessor basic block
+// old of the phi instructions of the given basic block with an incoming value
+// from the predecessor basic block new.
+func renamePhiPred(block, old, new *ir.Block) {
+	for _, inst := range block.Insts {
+		if _, ok := inst.(*ir.DbgRecord); ok {
+			// Debug records may be interleaved with phi instructions.
+			continue
+		}
+		phi, ok := inst.(*ir.InstPhi)
+		if !ok {
+			// Phi instructions are grouped at the start of basic blocks.
+			break
+		}
+		for i, inc := range phi.Incs {
+			if inc.Pred == old {
+				phi.Incs[i] = ir.NewIncoming(inc.X, new)
+			}
+		}
+	}
+}
+
+// memBlockName returns the name of a new basic block of the lowering of a
+// memory intrinsic of the given basic block, with the given suffix. An empty
+// name is returned for unnamed basic blocks.
+func memBlockName(block *ir.Block, suffix string) string {
+	if block.IsUnnamed() {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s", block.Name(), suffix)
+}
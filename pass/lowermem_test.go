@@ -0,0 +1,128 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestLowerMemIntrinsics(t *testing.T) {
+	golden := []struct {
+		in, want string
+	}{
+		// Small constant memcpy lowered to unrolled sequence.
+		{
+			in: `
+define void @f(i8* %dst, i8* %src) {
+entry:
+	call void @llvm.memcpy.p0i8.p0i8.i64(i8* align 4 %dst, i8* align 2 %src, i64 3, i1 true)
+	ret void
+}
+
+declare void @llvm.memcpy.p0i8.p0i8.i64(i8*, i8*, i64, i1)`,
+			want: `define void @f(i8* %dst, i8* %src) {
+entry:
+	%0 = getelementptr inbounds i8, i8* %src, i64 0
+	%1 = load volatile i8, i8* %0, align 2
+	%2 = getelementptr inbounds i8, i8* %dst, i64 0
+	store volatile i8 %1, i8* %2, align 4
+	%3 = getelementptr inbounds i8, i8* %src, i64 1
+	%4 = load volatile i8, i8* %3, align 1
+	%5 = getelementptr inbounds i8, i8* %dst, i64 1
+	store volatile i8 %4, i8* %5, align 1
+	%6 = getelementptr inbounds i8, i8* %src, i64 2
+	%7 = load volatile i8, i8* %6, align 2
+	%8 = getelementptr inbounds i8, i8* %dst, i64 2
+	store volatile i8 %7, i8* %8, align 2
+	ret void
+}`,
+		},
+		// Variable-length memset lowered to loop.
+		{
+			in: `
+define void @g(i8* %dst, i64 %n) {
+entry:
+	call void @llvm.memset.p0i8.i64(i8* %dst, i8 0, i64 %n, i1 false)
+	ret void
+}
+
+declare void @llvm.memset.p0i8.i64(i8*, i8, i64, i1)`,
+			want: `define void @g(i8* %dst, i64 %n) {
+entry:
+	%0 = icmp eq i64 %n, 0
+	br i1 %0, label %entry.split, label %entry.memset.loop
+
+entry.memset.loop:
+	%1 = phi i64 [ 0, %entry ], [ %3, %entry.memset.loop ]
+	%2 = getelementptr inbounds i8, i8* %dst, i64 %1
+	store i8 0, i8* %2
+	%3 = add i64 %1, 1
+	%4 = icmp ult i64 %3, %n
+	br i1 %4, label %entry.memset.loop, label %entry.split
+
+entry.split:
+	ret void
+}`,
+		},
+		// Constant memmove lowered to loop.
+		{
+			in: `
+define void @h(i8* %dst, i8* %src) {
+entry:
+	call void @llvm.memmove.p0i8.p0i8.i32(i8* %dst, i8* %src, i32 64, i1 false)
+	ret void
+}
+
+declare void @llvm.memmove.p0i8.p0i8.i32(i8*, i8*, i32, i1)`,
+			want: `define void @h(i8* %dst, i8* %src) {
+entry:
+	%0 = icmp ult i8* %src, %dst
+	br i1 %0, label %entry.memmove.loop.bwd, label %entry.memmove.loop
+
+entry.memmove.loop.bwd:
+	%1 = phi i32 [ 64, %entry ], [ %2, %entry.memmove.loop.bwd ]
+	%2 = sub i32 %1, 1
+	%3 = getelementptr inbounds i8, i8* %src, i32 %2
+	%4 = load i8, i8* %3
+	%5 = getelementptr inbounds i8, i8* %dst, i32 %2
+	store i8 %4, i8* %5
+	%6 = icmp ne i32 %2, 0
+	br i1 %6, label %entry.memmove.loop.bwd, label %entry.split
+
+entry.memmove.loop:
+	%7 = phi i32 [ 0, %entry ], [ %11, %entry.memmove.loop ]
+	%8 = getelementptr inbounds i8, i8* %src, i32 %7
+	%9 = load i8, i8* %8
+	%10 = getelementptr inbounds i8, i8* %dst, i32 %7
+	store i8 %9, i8* %10
+	%11 = add i32 %7, 1
+	%12 = icmp ult i32 %11, 64
+	br i1 %12, label %entry.memmove.loop, label %entry.split
+
+entry.split:
+	ret void
+}`,
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString("<stdin>", g.in)
+		if err != nil {
+			t.Errorf("unable to parse module; %v", err)
+			continue
+		}
+		f := m.Funcs[0]
+		if !LowerMemIntrinsics(f) {
+			t.Errorf("expected change of function %s", f.Ident())
+		}
+		if err := f.AssignIDs(); err != nil {
+			t.Errorf("unable to assign IDs; %+v", err)
+			continue
+		}
+		if got := f.LLString(); got != g.want {
+			t.Errorf("function mismatch; expected `%s`, got `%s`", g.want, got)
+		}
+		if err := m.Verify(); err != nil {
+			t.Errorf("unable to verify module; %v", err)
+		}
+	}
+}
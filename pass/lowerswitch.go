@@ -0,0 +1,132 @@
+package pass
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/value"
+)
+
+// LowerSwitch replaces the switch terminators of the given function with
+// chains of icmp and conditional br instructions, and reports whether the
+// function was changed.
+//
+// The switch terminator of a basic block is replaced by a comparison against
+// the first case comparand, which branches to the case target or to a new basic
+// block comparing against the next case comparand; the last comparison branches
+// to the default target. A switch without cases is replaced by an
+// unconditional branch to the default target.
+//
+// The phi instructions of the case and default targets are updated to have one
+// incoming value for each control flow edge from the new chain of basic blocks,
+// in place of the incoming values of the original basic block.
+func LowerSwitch(f *ir.Func) bool {
+	changed := false
+	for i := 0; i < len(f.Blocks); i++ {
+		block := f.Blocks[i]
+		term, ok := block.Term.(*ir.TermSwitch)
+		if !ok {
+			continue
+		}
+		chain := lowerSwitch(block, term)
+		// Insert new basic blocks after the original basic block.
+		blocks := make([]*ir.Block, 0, len(f.Blocks)+len(chain))
+		blocks = append(blocks, f.Blocks[:i+1]...)
+		blocks = append(blocks, chain...)
+		f.Blocks = append(blocks, f.Blocks[i+1:]...)
+		i += len(chain)
+		changed = true
+	}
+	return changed
+}
+
+// lowerSwitch replaces the switch terminator of the given basic block with a
+// chain of icmp and conditional br instructions, and returns the new basic
+// blocks of the chain (not including the original basic block).
+func lowerSwitch(block *ir.Block, term *ir.TermSwitch) []*ir.Block {
+	// Targets of the original switch terminator, and control flow edges from
+	// the new chain of basic blocks to each target.
+	var targets []*ir.Block
+	edges := make(map[*ir.Block][]*ir.Block)
+	addEdge := func(from, to *ir.Block) {
+		edges[to] = append(edges[to], from)
+	}
+	for _, succ := range term.Succs() {
+		if _, ok := edges[succ]; !ok {
+			targets = append(targets, succ)
+			edges[succ] = nil
+		}
+	}
+	var chain []*ir.Block
+	if len(term.Cases) == 0 {
+		block.NewBr(term.TargetDefault)
+		addEdge(block, term.TargetDefault)
+	}
+	cur := block
+	for i, c := range term.Cases {
+		cond := cur.NewICmp(enum.IPredEQ, term.X, c.X)
+		if i == len(term.Cases)-1 {
+			cur.NewCondBr(cond, c.Target, term.TargetDefault)
+			addEdge(cur, c.Target)
+			addEdge(cur, term.TargetDefault)
+			break
+		}
+		next := ir.NewBlock(chainName(block, i+1))
+		next.Parent = block.Parent
+		chain = append(chain, next)
+		cur.NewCondBr(cond, c.Target, next)
+		addEdge(cur, c.Target)
+		cur = next
+	}
+	// Fix phi instructions of targets.
+	for _, target := range targets {
+		fixPhis(target, block, edges[target])
+	}
+	return chain
+}
+
+// fixPhis replaces the incoming values from the predecessor basic block old of
+// the phi instructions of the given basic block with one incoming value for
+// each of the new predecessor basic blocks.
+func fixPhis(block, old *ir.Block, preds []*ir.Block) {
+	for _, inst := range block.Insts {
+		if _, ok := inst.(*ir.DbgRecord); ok {
+			// Debug records may be interleaved with phi instructions.
+			continue
+		}
+		phi, ok := inst.(*ir.InstPhi)
+		if !ok {
+			// Phi instructions are grouped at the start of basic blocks.
+			break
+		}
+		var incs []*ir.Incoming
+		var x value.Value
+		for _, inc := range phi.Incs {
+			if inc.Pred == old {
+				if x == nil {
+					x = inc.X
+				}
+				continue
+			}
+			incs = append(incs, inc)
+		}
+		if x == nil {
+			continue
+		}
+		for _, pred := range preds {
+			incs = append(incs, ir.NewIncoming(x, pred))
+		}
+		phi.Incs = incs
+	}
+}
+
+// chainName returns the name of the i:th new basic block of the chain of
+// comparisons replacing the switch terminator of the given basic block. An
+// empty name is returned for unnamed basic blocks.
+func chainName(block *ir.Block, i int) string {
+	if block.IsUnnamed() {
+		return ""
+	}
+	return fmt.Sprintf("%s.case%d", block.Name(), i)
+}
@@ -0,0 +1,91 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestLowerSwitch(t *testing.T) {
+	const src = `
+define i32 @f(i32 %x) {
+entry:
+	switch i32 %x, label %default [
+		i32 0, label %a
+		i32 1, label %b
+		i32 2, label %a
+		i32 3, label %exit
+	]
+
+a:
+	%y = phi i32 [ 1, %entry ], [ 1, %entry ]
+	br label %exit
+
+b:
+	br label %exit
+
+default:
+	br label %exit
+
+exit:
+	%res = phi i32 [ %y, %a ], [ 20, %b ], [ 30, %default ], [ 40, %entry ]
+	ret i32 %res
+}`
+	const want = `define i32 @f(i32 %x) {
+entry:
+	%0 = icmp eq i32 %x, 0
+	br i1 %0, label %a, label %entry.case1
+
+entry.case1:
+	%1 = icmp eq i32 %x, 1
+	br i1 %1, label %b, label %entry.case2
+
+entry.case2:
+	%2 = icmp eq i32 %x, 2
+	br i1 %2, label %a, label %entry.case3
+
+entry.case3:
+	%3 = icmp eq i32 %x, 3
+	br i1 %3, label %exit, label %default
+
+a:
+	%y = phi i32 [ 1, %entry ], [ 1, %entry.case2 ]
+	br label %exit
+
+b:
+	br label %exit
+
+default:
+	br label %exit
+
+exit:
+	%res = phi i32 [ %y, %a ], [ 20, %b ], [ 30, %default ], [ 40, %entry.case3 ]
+	ret i32 %res
+}`
+	m, err := asm.ParseString("<stdin>", src)
+	if err != nil {
+		t.Fatalf("unable to parse module; %+v", err)
+	}
+	f := m.Funcs[0]
+	if !LowerSwitch(f) {
+		t.Fatalf("expected change of function %s", f.Ident())
+	}
+	if err := f.AssignIDs(); err != nil {
+		t.Fatalf("unable to assign IDs; %+v", err)
+	}
+	if got := f.LLString(); got != want {
+		t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+	}
+	// Each phi has one incoming value per control flow edge.
+	preds := f.Predecessors()
+	exit := f.Blocks[len(f.Blocks)-1]
+	if got, want := len(preds[exit]), 4; got != want {
+		t.Errorf("predecessor count mismatch of %s; expected %d, got %d", exit.Ident(), want, got)
+	}
+	if err := m.Verify(); err != nil {
+		t.Errorf("unable to verify module; %v", err)
+	}
+	if LowerSwitch(f) {
+		t.Errorf("unexpected change of lowered function %s", f.Ident())
+	}
+}
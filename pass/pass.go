@@ -0,0 +1,2 @@
+// Package pass implements transformation passes on LLVM IR.
+package pass
@@ -0,0 +1,75 @@
+package pass
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/value"
+)
+
+// SimplifyPhis replaces trivial phi instructions of the given function with
+// the value they always produce, and reports whether the function was changed.
+//
+// A phi instruction is trivial if all of its incoming values, ignoring
+// references to the phi instruction itself, are the same value; this includes
+// phi instructions in basic blocks with a single predecessor. Trivial phi
+// instructions are replaced by their unique incoming value, or by a poison
+// value if every incoming value is a reference to the phi instruction itself.
+//
+//    %x = phi i32 [ %y, %a ], [ %y, %b ]  -> %y
+//    %x = phi i32 [ %y, %a ], [ %x, %b ]  -> %y
+//
+// As replacing a phi instruction may render other phi instructions trivial
+// (e.g. phi instructions of nested loops which only reference each other), the
+// function is simplified until no trivial phi instructions remain.
+//
+// Uses of trivial phi instructions are replaced using ReplaceAllUsesWith,
+// after which the trivial phi instructions are removed.
+func SimplifyPhis(f *ir.Func) bool {
+	changed := false
+	for {
+		progress := false
+		for _, block := range f.Blocks {
+			for i := 0; i < len(block.Insts); i++ {
+				phi, ok := block.Insts[i].(*ir.InstPhi)
+				if !ok {
+					continue
+				}
+				v, ok := trivialPhiValue(phi)
+				if !ok {
+					continue
+				}
+				f.ReplaceAllUsesWith(phi, v)
+				block.Insts = append(block.Insts[:i], block.Insts[i+1:]...)
+				i--
+				progress = true
+			}
+		}
+		if !progress {
+			break
+		}
+		changed = true
+	}
+	return changed
+}
+
+// trivialPhiValue returns the value always produced by the given phi
+// instruction, ignoring references to the phi instruction itself. The boolean
+// return value indicates whether the phi instruction is trivial.
+func trivialPhiValue(phi *ir.InstPhi) (value.Value, bool) {
+	var v value.Value
+	for _, inc := range phi.Incs {
+		if inc.X == phi || inc.X == v {
+			continue
+		}
+		if v != nil {
+			// Incoming values differ.
+			return nil, false
+		}
+		v = inc.X
+	}
+	if v == nil {
+		// Only references to the phi instruction itself.
+		return constant.NewPoison(phi.Type()), true
+	}
+	return v, true
+}
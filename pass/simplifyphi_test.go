@@ -0,0 +1,101 @@
+package pass
+
+import (
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestSimplifyPhis(t *testing.T) {
+	golden := []struct {
+		in   string
+		want string
+	}{
+		// Phi with identical incoming values.
+		{
+			in: `
+define i32 @f(i1 %cond, i32 %y) {
+entry:
+	br i1 %cond, label %then, label %exit
+
+then:
+	br label %exit
+
+exit:
+	%x = phi i32 [ %y, %entry ], [ %y, %then ]
+	ret i32 %x
+}`,
+			want: `define i32 @f(i1 %cond, i32 %y) {
+entry:
+	br i1 %cond, label %then, label %exit
+
+then:
+	br label %exit
+
+exit:
+	ret i32 %y
+}`,
+		},
+		// Self-referential phis of nested loops; the outer phi becomes trivial
+		// once the inner phi is replaced.
+		{
+			in: `
+define i32 @g(i1 %cond, i32 %y) {
+entry:
+	br label %outer
+
+outer:
+	%a = phi i32 [ %y, %entry ], [ %b, %inner ]
+	br label %inner
+
+inner:
+	%b = phi i32 [ %a, %outer ], [ %b, %inner ]
+	br i1 %cond, label %inner, label %outer
+}`,
+			want: `define i32 @g(i1 %cond, i32 %y) {
+entry:
+	br label %outer
+
+outer:
+	br label %inner
+
+inner:
+	br i1 %cond, label %inner, label %outer
+}`,
+		},
+		// Phi with distinct incoming values.
+		{
+			in: `
+define i32 @h(i1 %cond) {
+entry:
+	br i1 %cond, label %then, label %exit
+
+then:
+	br label %exit
+
+exit:
+	%x = phi i32 [ 0, %entry ], [ 1, %then ]
+	ret i32 %x
+}`,
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString("<stdin>", g.in)
+		if err != nil {
+			t.Errorf("unable to parse module; %v", err)
+			continue
+		}
+		f := m.Funcs[0]
+		want := g.want
+		if len(want) == 0 {
+			want = g.in[1:]
+		}
+		wantChanged := len(g.want) > 0
+		if changed := SimplifyPhis(f); changed != wantChanged {
+			t.Errorf("change mismatch of function %s; expected %v, got %v", f.Ident(), wantChanged, changed)
+		}
+		if got := f.LLString(); got != want {
+			t.Errorf("function mismatch; expected `%s`, got `%s`", want, got)
+		}
+	}
+}
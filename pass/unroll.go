@@ -0,0 +1,457 @@
+package pass
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+)
+
+// maxTripCount is the maximum trip count of loops considered for unrolling.
+const maxTripCount = 1024
+
+// UnrollLoop unrolls the given natural loop by the given factor, duplicating the
+// basic blocks of the loop factor times. The loop is fully unrolled if factor
+// is equal to the trip count of the loop, or if factor is non-positive.
+//
+// Only loops with a constant trip count are unrolled, and the unroll factor must
+// divide the trip count, as no remainder loop is created. The loop must be in
+// the following form:
+//
+//    * the loop header has a single predecessor outside of the loop (the
+//      preheader), and a single latch;
+//    * the latch is the only exiting basic block of the loop, and is
+//      terminated by a conditional br instruction, the condition of which
+//      compares an induction variable against an integer constant;
+//    * the induction variable is a phi instruction of the loop header (or the
+//      incremented value thereof), with a constant start value from the
+//      preheader, which is incremented by a constant step (add or sub) in each
+//      iteration;
+//    * the basic blocks of the loop are terminated by br, conditional br or
+//      switch instructions.
+//
+// When partially unrolling, each duplicate of the loop body except the last
+// branches unconditionally to the next duplicate, and the last duplicate
+// branches back to the loop header. When fully unrolling, the last duplicate
+// branches to the exit basic block, and the phi instructions of the loop header
+// are replaced by their start values.
+//
+// Uses of values of the loop outside of the loop are updated to refer to the
+// values of the last duplicate. The function is left unchanged if an error is
+// returned.
+func UnrollLoop(loop *ir.Loop, factor int) error {
+	l, err := analyzeLoop(loop)
+	if err != nil {
+		return err
+	}
+	if factor <= 0 {
+		factor = int(l.tripCount)
+	}
+	if l.tripCount%int64(factor) != 0 {
+		return errors.Errorf("unroll factor %d does not divide trip count %d of loop %s", factor, l.tripCount, loop.Header.Ident())
+	}
+	full := int64(factor) == l.tripCount
+	if factor == 1 && !full {
+		return nil
+	}
+	// Duplicate loop body. The original basic blocks of the loop form the first
+	// iteration.
+	headers := []*ir.Block{l.header}
+	latches := []*ir.Block{l.latch}
+	// Value mapping of the previous iteration; the identity mapping for the
+	// first iteration.
+	prev := make(map[value.Value]value.Value)
+	var clones []*ir.Block
+	for k := 1; k < factor; k++ {
+		vmap := make(map[value.Value]value.Value)
+		bmap := make(map[*ir.Block]*ir.Block)
+		for _, phi := range l.phis {
+			vmap[phi] = remap(prev, incomingValue(phi, l.latch))
+		}
+		for _, block := range loop.Blocks {
+			clone := ir.NewBlock(cloneName(block, k))
+			clone.Parent = block.Parent
+			bmap[block] = clone
+			clones = append(clones, clone)
+		}
+		for _, block := range loop.Blocks {
+			clone := bmap[block]
+			for _, inst := range block.Insts {
+				if _, ok := inst.(*ir.InstPhi); ok && block == l.header {
+					// Phi instructions of the loop header are resolved through the
+					// value mapping.
+					continue
+				}
+				c := inst.Clone()
+				if v, ok := inst.(value.Named); ok {
+					if name := cloneName(v, k); len(name) > 0 {
+						c.(value.Named).SetName(name)
+					}
+					vmap[v] = c.(value.Value)
+				}
+				clone.Insts = append(clone.Insts, c)
+			}
+			if block != l.latch {
+				clone.Term = cloneTerm(block.Term, bmap)
+			}
+		}
+		// Remap operands after all instructions of the iteration have been
+		// duplicated, as operands may refer to values defined in succeeding
+		// basic blocks (e.g. incoming values of phi instructions).
+		for _, block := range loop.Blocks {
+			clone := bmap[block]
+			for _, inst := range clone.Insts {
+				remapOperands(inst.Operands(), vmap)
+				if phi, ok := inst.(*ir.InstPhi); ok {
+					for _, inc := range phi.Incs {
+						if pred, ok := bmap[inc.Pred]; ok {
+							inc.Pred = pred
+						}
+					}
+				}
+			}
+			if clone.Term != nil {
+				remapOperands(clone.Term.Operands(), vmap)
+			}
+		}
+		headers = append(headers, bmap[l.header])
+		latches = append(latches, bmap[l.latch])
+		prev = vmap
+	}
+	// Update uses outside of the loop to refer to values of the last iteration,
+	// before the original values of the loop are changed.
+	last := latches[len(latches)-1]
+	inLoop := make(map[*ir.Block]bool)
+	for _, block := range loop.Blocks {
+		inLoop[block] = true
+	}
+	for _, block := range l.f.Blocks {
+		if inLoop[block] {
+			continue
+		}
+		for _, inst := range block.Insts {
+			remapOperands(inst.Operands(), prev)
+			if phi, ok := inst.(*ir.InstPhi); ok && block == l.exit {
+				for _, inc := range phi.Incs {
+					if inc.Pred == l.latch {
+						inc.Pred = last
+					}
+				}
+			}
+		}
+		if block.Term != nil {
+			remapOperands(block.Term.Operands(), prev)
+		}
+	}
+	// Insert duplicated basic blocks after the last basic block of the loop.
+	end := 0
+	for i, block := range l.f.Blocks {
+		if inLoop[block] {
+			end = i + 1
+		}
+	}
+	blocks := make([]*ir.Block, 0, len(l.f.Blocks)+len(clones))
+	blocks = append(blocks, l.f.Blocks[:end]...)
+	blocks = append(blocks, clones...)
+	l.f.Blocks = append(blocks, l.f.Blocks[end:]...)
+	// Rewire latches.
+	for k := 0; k < factor-1; k++ {
+		latches[k].Term = ir.NewBr(headers[k+1])
+	}
+	if full {
+		last.Term = ir.NewBr(l.exit)
+		// Replace phi instructions of the loop header by their start values.
+		for _, phi := range l.phis {
+			l.f.ReplaceAllUsesWith(phi, incomingValue(phi, l.preheader))
+		}
+		var insts []ir.Instruction
+		for _, inst := range l.header.Insts {
+			if _, ok := inst.(*ir.InstPhi); !ok {
+				insts = append(insts, inst)
+			}
+		}
+		l.header.Insts = insts
+	} else {
+		term := l.latchTerm
+		cond := remap(prev, term.Cond)
+		if term.TargetTrue == l.header {
+			last.Term = ir.NewCondBr(cond, l.header, term.TargetFalse)
+		} else {
+			last.Term = ir.NewCondBr(cond, term.TargetTrue, l.header)
+		}
+		for _, phi := range l.phis {
+			for _, inc := range phi.Incs {
+				if inc.Pred == l.latch {
+					inc.X = remap(prev, inc.X)
+					inc.Pred = last
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// loopInfo holds information about a natural loop which may be unrolled.
+type loopInfo struct {
+	// Parent function of the loop.
+	f *ir.Func
+	// Loop header.
+	header *ir.Block
+	// Single predecessor of the loop header outside of the loop.
+	preheader *ir.Block
+	// Single latch and exiting basic block of the loop.
+	latch *ir.Block
+	// Terminator of the latch.
+	latchTerm *ir.TermCondBr
+	// Exit basic block of the loop.
+	exit *ir.Block
+	// Phi instructions of the loop header.
+	phis []*ir.InstPhi
+	// Number of iterations of the loop.
+	tripCount int64
+}
+
+// analyzeLoop analyzes the given natural loop, and determines its trip count.
+func analyzeLoop(loop *ir.Loop) (*loopInfo, error) {
+	header := loop.Header
+	f := header.Parent
+	if f == nil {
+		return nil, errors.Errorf("unable to locate parent function of loop header %s", header.Ident())
+	}
+	if len(loop.Latches) != 1 {
+		return nil, errors.Errorf("loop %s has %d latches; expected 1", header.Ident(), len(loop.Latches))
+	}
+	l := &loopInfo{f: f, header: header, latch: loop.Latches[0]}
+	term, ok := l.latch.Term.(*ir.TermCondBr)
+	if !ok {
+		return nil, errors.Errorf("invalid latch terminator of loop %s; expected conditional br, got %T", header.Ident(), l.latch.Term)
+	}
+	l.latchTerm = term
+	switch {
+	case term.TargetTrue == header && !loop.Contains(term.TargetFalse):
+		l.exit = term.TargetFalse
+	case term.TargetFalse == header && !loop.Contains(term.TargetTrue):
+		l.exit = term.TargetTrue
+	default:
+		return nil, errors.Errorf("latch %s of loop %s does not exit the loop", l.latch.Ident(), header.Ident())
+	}
+	for _, block := range loop.Blocks {
+		switch block.Term.(type) {
+		case *ir.TermBr, *ir.TermCondBr, *ir.TermSwitch:
+			// valid terminator.
+		default:
+			return nil, errors.Errorf("unsupported terminator %T of basic block %s in loop %s", block.Term, block.Ident(), header.Ident())
+		}
+		if block == l.latch {
+			continue
+		}
+		for _, succ := range block.Term.Succs() {
+			if !loop.Contains(succ) {
+				return nil, errors.Errorf("loop %s has multiple exiting basic blocks", header.Ident())
+			}
+		}
+	}
+	for _, pred := range f.Predecessors()[header] {
+		if loop.Contains(pred) {
+			continue
+		}
+		if l.preheader != nil {
+			return nil, errors.Errorf("loop %s has multiple entering control flow edges", header.Ident())
+		}
+		l.preheader = pred
+	}
+	if l.preheader == nil {
+		return nil, errors.Errorf("loop %s has no preheader", header.Ident())
+	}
+	for _, inst := range header.Insts {
+		if _, ok := inst.(*ir.DbgRecord); ok {
+			// Debug records may be interleaved with phi instructions.
+			continue
+		}
+		phi, ok := inst.(*ir.InstPhi)
+		if !ok {
+			break
+		}
+		l.phis = append(l.phis, phi)
+	}
+	tripCount, err := l.computeTripCount()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	l.tripCount = tripCount
+	return l, nil
+}
+
+// computeTripCount returns the constant trip count of the loop, based on the
+// exit condition of the latch.
+func (l *loopInfo) computeTripCount() (int64, error) {
+	cond, ok := l.latchTerm.Cond.(*ir.InstICmp)
+	if !ok {
+		return 0, errors.Errorf("invalid exit condition of loop %s; expected icmp, got %v", l.header.Ident(), l.latchTerm.Cond)
+	}
+	pred, x, y := cond.Pred, cond.X, cond.Y
+	if _, ok := x.(*constant.Int); ok {
+		pred, x, y = pred.Swapped(), y, x
+	}
+	bound, ok := y.(*constant.Int)
+	if !ok {
+		return 0, errors.Errorf("invalid exit condition of loop %s; expected comparison against integer constant, got %v", l.header.Ident(), y)
+	}
+	// Locate induction variable; either a phi instruction of the loop header or
+	// the incremented value thereof.
+	var phi *ir.InstPhi
+	offset := int64(0)
+	if p, ok := x.(*ir.InstPhi); ok && l.isHeaderPhi(p) {
+		phi = p
+	} else if p, _, ok := l.inductionStep(x); ok {
+		if incomingValue(p, l.latch) != x {
+			return 0, errors.Errorf("invalid induction variable %s of loop %s", x.Ident(), l.header.Ident())
+		}
+		phi, offset = p, 1
+	} else {
+		return 0, errors.Errorf("unable to locate induction variable of loop %s", l.header.Ident())
+	}
+	p, step, ok := l.inductionStep(incomingValue(phi, l.latch))
+	if !ok || p != phi {
+		return 0, errors.Errorf("unable to locate constant step of induction variable %s of loop %s", phi.Ident(), l.header.Ident())
+	}
+	start, ok := incomingValue(phi, l.preheader).(*constant.Int)
+	if !ok {
+		return 0, errors.Errorf("non-constant start value of induction variable %s of loop %s", phi.Ident(), l.header.Ident())
+	}
+	typ, ok := phi.Type().(*types.IntType)
+	if !ok {
+		return 0, errors.Errorf("invalid type of induction variable %s of loop %s; expected integer type, got %v", phi.Ident(), l.header.Ident(), phi.Type())
+	}
+	// The loop continues if the exit condition evaluates to cont.
+	cont := l.latchTerm.TargetTrue == l.header
+	for k := int64(0); k < maxTripCount; k++ {
+		v := new(big.Int).Mul(big.NewInt(k+offset), step)
+		v.Add(v, start.X)
+		c, ok := constant.Fold(constant.NewICmp(pred, &constant.Int{Typ: typ, X: v}, bound)).(*constant.Int)
+		if !ok {
+			return 0, errors.Errorf("unable to evaluate exit condition of loop %s", l.header.Ident())
+		}
+		if (c.X.Sign() != 0) != cont {
+			return k + 1, nil
+		}
+	}
+	return 0, errors.Errorf("trip count of loop %s exceeds %d", l.header.Ident(), maxTripCount)
+}
+
+// inductionStep returns the phi instruction of the loop header and constant
+// step of the given induction variable update (add or sub of a phi instruction
+// and integer constant).
+func (l *loopInfo) inductionStep(v value.Value) (*ir.InstPhi, *big.Int, bool) {
+	var x, y value.Value
+	neg := false
+	switch v := v.(type) {
+	case *ir.InstAdd:
+		x, y = v.X, v.Y
+		if _, ok := x.(*constant.Int); ok {
+			x, y = y, x
+		}
+	case *ir.InstSub:
+		x, y, neg = v.X, v.Y, true
+	default:
+		return nil, nil, false
+	}
+	phi, ok := x.(*ir.InstPhi)
+	if !ok || !l.isHeaderPhi(phi) {
+		return nil, nil, false
+	}
+	c, ok := y.(*constant.Int)
+	if !ok {
+		return nil, nil, false
+	}
+	step := new(big.Int).Set(c.X)
+	if neg {
+		step.Neg(step)
+	}
+	return phi, step, true
+}
+
+// isHeaderPhi reports whether the given phi instruction is part of the loop
+// header.
+func (l *loopInfo) isHeaderPhi(phi *ir.InstPhi) bool {
+	for _, p := range l.phis {
+		if p == phi {
+			return true
+		}
+	}
+	return false
+}
+
+// ### [ Helper functions ] ####################################################
+
+// cloneName returns the name of the k:th duplicate of the given local value,
+// or an empty string if unnamed.
+func cloneName(v value.Named, k int) string {
+	if u, ok := v.(interface{ IsUnnamed() bool }); ok && u.IsUnnamed() {
+		return ""
+	}
+	return fmt.Sprintf("%s.%d", v.Name(), k)
+}
+
+// cloneTerm returns a copy of the given terminator (br, conditional br or
+// switch), with target basic blocks mapped through bmap.
+func cloneTerm(term ir.Terminator, bmap map[*ir.Block]*ir.Block) ir.Terminator {
+	target := func(block *ir.Block) *ir.Block {
+		if b, ok := bmap[block]; ok {
+			return b
+		}
+		return block
+	}
+	switch term := term.(type) {
+	case *ir.TermBr:
+		t := ir.NewBr(target(term.Target))
+		t.Metadata = append(ir.Metadata(nil), term.Metadata...)
+		return t
+	case *ir.TermCondBr:
+		t := ir.NewCondBr(term.Cond, target(term.TargetTrue), target(term.TargetFalse))
+		t.Metadata = append(ir.Metadata(nil), term.Metadata...)
+		return t
+	case *ir.TermSwitch:
+		var cases []*ir.Case
+		for _, c := range term.Cases {
+			cases = append(cases, ir.NewCase(c.X, target(c.Target)))
+		}
+		t := ir.NewSwitch(term.X, target(term.TargetDefault), cases...)
+		t.Metadata = append(ir.Metadata(nil), term.Metadata...)
+		return t
+	default:
+		panic(fmt.Errorf("support for terminator %T not yet implemented", term))
+	}
+}
+
+// incomingValue returns the incoming value of the given phi instruction from
+// the predecessor basic block, or nil if not present.
+func incomingValue(phi *ir.InstPhi, pred *ir.Block) value.Value {
+	for _, inc := range phi.Incs {
+		if inc.Pred == pred {
+			return inc.X
+		}
+	}
+	return nil
+}
+
+// remap returns the value mapped to v in vmap, or v if not mapped.
+func remap(vmap map[value.Value]value.Value, v value.Value) value.Value {
+	if w, ok := vmap[v]; ok {
+		return w
+	}
+	return v
+}
+
+// remapOperands maps the given operands through vmap.
+func remapOperands(ops []*value.Value, vmap map[value.Value]value.Value) {
+	for _, op := range ops {
+		if *op != nil {
+			*op = remap(vmap, *op)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package pass
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/llir/llvm/asm"
+)
+
+func TestUnrollLoop(t *testing.T) {
+	const src = `
+define i32 @f() {
+entry:
+	br label %loop
+
+loop:
+	%i = phi i32 [ 0, %entry ], [ %i.next, %loop ]
+	%acc = phi i32 [ 0, %entry ], [ %acc.next, %loop ]
+	%acc.next = add i32 %acc, %i
+	%i.next = add i32 %i, 1
+	%cond = icmp slt i32 %i.next, N
+	br i1 %cond, label %loop, label %exit
+
+exit:
+	%res = phi i32 [ %acc.next, %loop ]
+	ret i32 %res
+}`
+	golden := []struct {
+		tripCount string
+		factor    int
+		want      string
+		err       string
+	}{
+		// Full unrolling.
+		{
+			tripCount: "3",
+			factor:    0,
+			want: `define i32 @f() {
+entry:
+	br label %loop
+
+loop:
+	%acc.next = add i32 0, 0
+	%i.next = add i32 0, 1
+	%cond = icmp slt i32 %i.next, 3
+	br label %loop.1
+
+loop.1:
+	%acc.next.1 = add i32 %acc.next, %i.next
+	%i.next.1 = add i32 %i.next, 1
+	%cond.1 = icmp slt i32 %i.next.1, 3
+	br label %loop.2
+
+loop.2:
+	%acc.next.2 = add i32 %acc.next.1, %i.next.1
+	%i.next.2 = add i32 %i.next.1, 1
+	%cond.2 = icmp slt i32 %i.next.2, 3
+	br label %exit
+
+exit:
+	%res = phi i32 [ %acc.next.2, %loop.2 ]
+	ret i32 %res
+}`,
+		},
+		// Partial unrolling.
+		{
+			tripCount: "4",
+			factor:    2,
+			want: `define i32 @f() {
+entry:
+	br label %loop
+
+loop:
+	%i = phi i32 [ 0, %entry ], [ %i.next.1, %loop.1 ]
+	%acc = phi i32 [ 0, %entry ], [ %acc.next.1, %loop.1 ]
+	%acc.next = add i32 %acc, %i
+	%i.next = add i32 %i, 1
+	%cond = icmp slt i32 %i.next, 4
+	br label %loop.1
+
+loop.1:
+	%acc.next.1 = add i32 %acc.next, %i.next
+	%i.next.1 = add i32 %i.next, 1
+	%cond.1 = icmp slt i32 %i.next.1, 4
+	br i1 %cond.1, label %loop, label %exit
+
+exit:
+	%res = phi i32 [ %acc.next.1, %loop.1 ]
+	ret i32 %res
+}`,
+		},
+		// Unroll factor not dividing trip count.
+		{
+			tripCount: "4",
+			factor:    3,
+			err:       "unroll factor 3 does not divide trip count 4 of loop %loop",
+		},
+	}
+	for _, g := range golden {
+		m, err := asm.ParseString("<stdin>", strings.Replace(src, "N", g.tripCount, 1))
+		if err != nil {
+			t.Fatalf("unable to parse module; %+v", err)
+		}
+		f := m.Funcs[0]
+		loops := f.Loops()
+		if len(loops) != 1 {
+			t.Fatalf("loop count mismatch; expected 1, got %d", len(loops))
+		}
+		before := f.LLString()
+		err = UnrollLoop(loops[0], g.factor)
+		if len(g.err) > 0 {
+			if err == nil || err.Error() != g.err {
+				t.Errorf("error mismatch; expected %q, got %v", g.err, err)
+			}
+			if got := f.LLString(); got != before {
+				t.Errorf("function changed on error; expected `%s`, got `%s`", before, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unable to unroll loop with trip count %s by factor %d; %+v", g.tripCount, g.factor, err)
+			continue
+		}
+		if got := f.LLString(); got != g.want {
+			t.Errorf("function mismatch; expected `%s`, got `%s`", g.want, got)
+		}
+	}
+}